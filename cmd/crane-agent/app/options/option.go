@@ -7,6 +7,8 @@ import (
 	cliflag "k8s.io/component-base/cli/flag"
 
 	topologyapi "github.com/gocrane/api/topology/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/ensurance/audit"
 )
 
 // Options hold the command-line options about crane manager
@@ -38,6 +40,10 @@ type Options struct {
 	CPUManagerReconcilePeriod time.Duration
 	// DefaultCPUPolicy is the default cpu policy, default to exclusive.
 	DefaultCPUPolicy string
+	// AvoidanceHistoryPath is the file to persist the history of avoidance actions, the history is kept in memory only if it is empty.
+	AvoidanceHistoryPath string
+	// AvoidanceHistoryCapacity is the max number of avoidance actions kept in the history.
+	AvoidanceHistoryCapacity int
 }
 
 // NewOptions builds an empty options.
@@ -70,5 +76,7 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 	flags.DurationVar(&o.MaxInactivity, "max-inactivity", 5*time.Minute, "Maximum time from last recorded activity before automatic restart, default: 5min")
 	flags.StringVar(&o.ExecuteExcess, "execute-excess", "10%", "The percentage of executions that exceed the gap between current usage and watermarks, default: 10%.")
 	flags.DurationVar(&o.CPUManagerReconcilePeriod, "cpu-manager-reconcile-period", 5*time.Second, "Specifies how often cpu manager reconciles.")
+	flags.StringVar(&o.AvoidanceHistoryPath, "avoidance-history-path", "/var/lib/crane-agent/avoidance-history", "The file to persist the history of avoidance actions, the history is kept in memory only if it is empty.")
+	flags.IntVar(&o.AvoidanceHistoryCapacity, "avoidance-history-capacity", audit.DefaultCapacity, "The max number of avoidance actions kept in the history, default: 1000.")
 	flags.StringVar(&o.DefaultCPUPolicy, "default-cpu-policy", topologyapi.AnnotationPodCPUPolicyExclusive, "The default cpu policy if pod does not specify, should be one of none, exclusive, numa or immovable, default to exclusive.")
 }
@@ -14,6 +14,7 @@ import (
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/scale"
@@ -39,6 +40,7 @@ import (
 	"github.com/gocrane/crane/pkg/known"
 	"github.com/gocrane/crane/pkg/metrics"
 	"github.com/gocrane/crane/pkg/oom"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
 	"github.com/gocrane/crane/pkg/predictor"
 	prometheus_adapter "github.com/gocrane/crane/pkg/prometheus-adapter"
 	"github.com/gocrane/crane/pkg/providers"
@@ -125,7 +127,7 @@ func Run(ctx context.Context, opts *options.Options) error {
 	}
 	// initialize data sources and predictor
 	realtimeDataSources, historyDataSources, dataSourceProviders := initDataSources(mgr, opts)
-	predictorMgr := initPredictorManager(opts, realtimeDataSources, historyDataSources)
+	predictorMgr := initPredictorManager(mgr, opts, realtimeDataSources, historyDataSources)
 
 	initScheme()
 	initFieldIndexer(mgr)
@@ -264,8 +266,13 @@ func initDataSources(mgr ctrl.Manager, opts *options.Options) (map[providers.Dat
 	return realtimeDataSources, historyDataSources, hybridDataSources
 }
 
-func initPredictorManager(opts *options.Options, realtimeDataSources map[providers.DataSourceType]providers.RealTime, historyDataSources map[providers.DataSourceType]providers.History) predictor.Manager {
-	return predictor.NewManager(realtimeDataSources, historyDataSources, predictor.DefaultPredictorsConfig(opts.AlgorithmModelConfig))
+func initPredictorManager(mgr ctrl.Manager, opts *options.Options, realtimeDataSources map[providers.DataSourceType]providers.RealTime, historyDataSources map[providers.DataSourceType]providers.History) predictor.Manager {
+	opts.CheckpointConfig.Namespace = known.CraneSystemNamespace
+	store, err := checkpoint.NewStore(opts.CheckpointConfig, kubernetes.NewForConfigOrDie(mgr.GetConfig()))
+	if err != nil {
+		klog.Exitf("unable to create prediction checkpoint store, err: %v", err)
+	}
+	return predictor.NewManager(realtimeDataSources, historyDataSources, predictor.DefaultPredictorsConfig(opts.AlgorithmModelConfig, opts.RemotePredictorConfig, store))
 }
 
 // initControllers setup controllers with manager
@@ -361,6 +368,9 @@ func initControllers(ctx context.Context, oomRecorder oom.Recorder, mgr ctrl.Man
 			opts.PredictionUpdateFrequency,
 			predictorMgr,
 			targetSelectorFetcher,
+			historyDataSource,
+			opts.PredictionAccuracyWindow,
+			opts.PredictionAutoSelectionInterval,
 		)
 		if err := tspController.SetupWithManager(mgr, opts.TimeSeriesPredictionMaxConcurrentReconciles); err != nil {
 			klog.Exit(err, "unable to create controller", "controller", "TspController")
@@ -391,10 +401,18 @@ func initControllers(ctx context.Context, oomRecorder oom.Recorder, mgr ctrl.Man
 			RecommenderMgr: recommenderMgr,
 			ScaleClient:    scaleClient,
 			Recorder:       mgr.GetEventRecorderFor("recommendation-controller"),
+			Provider:       historyDataSource,
+			OOMRecorder:    oomRecorder,
+			RolloutConfig:  opts.RecommendationRolloutConfig,
 		}).SetupWithManager(mgr); err != nil {
 			klog.Exit(err, "unable to create controller", "controller", "RecommendationController")
 		}
 
+		history := &recommendationctrl.HistoryRecorder{
+			Client: mgr.GetClient(),
+			Limit:  opts.RecommendationHistoryLimit,
+		}
+
 		if err := (&recommendationctrl.RecommendationRuleController{
 			Client:         mgr.GetClient(),
 			Scheme:         mgr.GetScheme(),
@@ -404,7 +422,9 @@ func initControllers(ctx context.Context, oomRecorder oom.Recorder, mgr ctrl.Man
 			OOMRecorder:    oomRecorder,
 			Provider:       historyDataSource,
 			PredictorMgr:   predictorMgr,
+			History:        history,
 			Recorder:       mgr.GetEventRecorderFor("recommendationrule-controller"),
+			Timeout:        opts.RecommendationTimeout,
 		}).SetupWithManager(mgr); err != nil {
 			klog.Exit(err, "unable to create controller", "controller", "RecommendationRuleController")
 		}
@@ -416,7 +436,9 @@ func initControllers(ctx context.Context, oomRecorder oom.Recorder, mgr ctrl.Man
 			OOMRecorder:    oomRecorder,
 			Provider:       historyDataSource,
 			PredictorMgr:   predictorMgr,
+			History:        history,
 			Recorder:       mgr.GetEventRecorderFor("recommendation-trigger-controller"),
+			Timeout:        opts.RecommendationTimeout,
 		}).SetupWithManager(mgr); err != nil {
 			klog.Exit(err, "unable to create controller", "controller", "RecommendationTriggerController")
 		}
@@ -425,6 +447,8 @@ func initControllers(ctx context.Context, oomRecorder oom.Recorder, mgr ctrl.Man
 			Client:          mgr.GetClient(),
 			MonitorInterval: opts.MonitorInterval,
 			OutDateInterval: opts.OutDateInterval,
+			DriftThreshold:  opts.RecommendationDriftThreshold,
+			History:         history,
 		}
 		checker.Run(ctx.Done())
 	}
@@ -1,12 +1,15 @@
 package options
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/pflag"
 	componentbaseconfig "k8s.io/component-base/config"
 
 	"github.com/gocrane/crane/pkg/controller/ehpa"
+	"github.com/gocrane/crane/pkg/controller/recommendation"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
 	"github.com/gocrane/crane/pkg/prediction/config"
 	"github.com/gocrane/crane/pkg/providers"
 	serverconfig "github.com/gocrane/crane/pkg/server/config"
@@ -27,6 +30,10 @@ type Options struct {
 	BindAddr string
 
 	PredictionUpdateFrequency time.Duration
+	// PredictionAccuracyWindow is the window of the rolling accuracy of TimeSeriesPrediction
+	PredictionAccuracyWindow time.Duration
+	// PredictionAutoSelectionInterval is the interval to select the algorithm of the auto metrics of TimeSeriesPrediction
+	PredictionAutoSelectionInterval time.Duration
 	// DataSource is the datasource of the predictor, such as prometheus, nodelocal, etc.
 	DataSource []string
 	// DataSourcePromConfig is the prometheus datasource config
@@ -38,6 +45,10 @@ type Options struct {
 
 	// AlgorithmModelConfig
 	AlgorithmModelConfig config.AlgorithmModelConfig
+	// RemotePredictorConfig is the config for remote predictor, which forwards the history to an external model server
+	RemotePredictorConfig config.RemoteConfig
+	// CheckpointConfig is the config for the store of prediction model checkpoints
+	CheckpointConfig checkpoint.Config
 
 	// WebhookConfig
 	WebhookConfig webhooks.WebhookConfig
@@ -55,6 +66,9 @@ type Options struct {
 	// EhpaControllerConfig is the configuration for Ehpa controller
 	EhpaControllerConfig ehpa.EhpaControllerConfig
 
+	// RecommendationRolloutConfig is the configuration for the safe rollout of Auto resource recommendations
+	RecommendationRolloutConfig recommendation.RolloutConfig
+
 	// RecommendationConfiguration is configuration file for recommendation framework.
 	// If unspecified, a default is provided.
 	RecommendationConfiguration string
@@ -73,6 +87,16 @@ type Options struct {
 
 	// OutDateInterval is the checking interval for identify a recommendation is outdated
 	OutDateInterval time.Duration
+
+	// RecommendationTimeout is the default timeout of a recommendation run
+	RecommendationTimeout time.Duration
+
+	// RecommendationHistoryLimit is the max number of past recommended values kept for a recommendation
+	RecommendationHistoryLimit int
+
+	// RecommendationDriftThreshold is the relative difference between the workload and the recommendation, beyond
+	// which the recommendation is marked as drifted
+	RecommendationDriftThreshold float64
 }
 
 // NewOptions builds an empty options.
@@ -89,7 +113,54 @@ func (o *Options) Complete() error {
 
 // Validate all required options.
 func (o *Options) Validate() []error {
-	return o.ServerOptions.Validate()
+	errs := o.ServerOptions.Validate()
+	if len(o.RemotePredictorConfig.Address) != 0 && o.RemotePredictorConfig.Future <= o.AlgorithmModelConfig.UpdateInterval {
+		errs = append(errs, fmt.Errorf("remote-predictor-future %v should be longer than model-update-interval %v",
+			o.RemotePredictorConfig.Future, o.AlgorithmModelConfig.UpdateInterval))
+	}
+	if len(o.RemotePredictorConfig.Address) != 0 && o.RemotePredictorConfig.HistoryResolution < time.Second {
+		errs = append(errs, fmt.Errorf("remote-predictor-history-resolution %v should be at least 1s", o.RemotePredictorConfig.HistoryResolution))
+	}
+	switch o.CheckpointConfig.Type {
+	case "", checkpoint.StoreTypeConfigMap:
+	case checkpoint.StoreTypeFile:
+		if len(o.CheckpointConfig.Path) == 0 {
+			errs = append(errs, fmt.Errorf("prediction-checkpoint-path is required by the file checkpoint store"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown prediction-checkpoint-store %s", o.CheckpointConfig.Type))
+	}
+	if o.RecommendationRolloutConfig.Enabled && o.RecommendationRolloutConfig.CheckInterval <= 0 {
+		errs = append(errs, fmt.Errorf("recommendation-rollout-check-interval should be positive"))
+	}
+	if o.PredictionAccuracyWindow <= 0 {
+		errs = append(errs, fmt.Errorf("prediction-accuracy-window should be positive"))
+	}
+	if o.PredictionAutoSelectionInterval <= 0 {
+		errs = append(errs, fmt.Errorf("prediction-auto-selection-interval should be positive"))
+	}
+	if o.AlgorithmModelConfig.LowerQuantile < 0 || o.AlgorithmModelConfig.LowerQuantile >= o.AlgorithmModelConfig.UpperQuantile || o.AlgorithmModelConfig.UpperQuantile > 1 {
+		errs = append(errs, fmt.Errorf("prediction-interval-lower-quantile %v and prediction-interval-upper-quantile %v should satisfy 0 <= lower < upper <= 1",
+			o.AlgorithmModelConfig.LowerQuantile, o.AlgorithmModelConfig.UpperQuantile))
+	}
+	if o.AlgorithmModelConfig.AnomalyRetrainInterval < 0 {
+		errs = append(errs, fmt.Errorf("prediction-anomaly-retrain-interval should not be negative"))
+	}
+	if o.AlgorithmModelConfig.AnomalyLowerQuantile < 0 || o.AlgorithmModelConfig.AnomalyLowerQuantile > o.AlgorithmModelConfig.LowerQuantile ||
+		o.AlgorithmModelConfig.AnomalyUpperQuantile < o.AlgorithmModelConfig.UpperQuantile || o.AlgorithmModelConfig.AnomalyUpperQuantile > 1 {
+		errs = append(errs, fmt.Errorf("prediction-anomaly-lower-quantile %v and prediction-anomaly-upper-quantile %v should satisfy 0 <= lower <= prediction-interval-lower-quantile and prediction-interval-upper-quantile <= upper <= 1",
+			o.AlgorithmModelConfig.AnomalyLowerQuantile, o.AlgorithmModelConfig.AnomalyUpperQuantile))
+	}
+	if o.AlgorithmModelConfig.AnomalyOutOfBandCount <= 0 {
+		errs = append(errs, fmt.Errorf("prediction-anomaly-out-of-band-count should be positive"))
+	}
+	if o.AlgorithmModelConfig.AnomalyCusumSlack <= 0 || o.AlgorithmModelConfig.AnomalyCusumThreshold <= 0 {
+		errs = append(errs, fmt.Errorf("prediction-anomaly-cusum-slack and prediction-anomaly-cusum-threshold should be positive"))
+	}
+	if o.RecommendationTimeout < 0 {
+		errs = append(errs, fmt.Errorf("recommendation-timeout should not be negative"))
+	}
+	return errs
 }
 
 func (o *Options) ApplyTo(cfg *serverconfig.Config) error {
@@ -114,6 +185,10 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 
 	flags.DurationVar(&o.PredictionUpdateFrequency, "prediction-update-frequency-duration", 30*time.Second,
 		"Specifies the update frequency of the prediction.")
+	flags.DurationVar(&o.PredictionAccuracyWindow, "prediction-accuracy-window", 24*time.Hour,
+		"Specifies the window of the rolling accuracy of the prediction, the past predictions are compared with the actual values in the window.")
+	flags.DurationVar(&o.PredictionAutoSelectionInterval, "prediction-auto-selection-interval", 24*time.Hour,
+		"Specifies the interval to backtest the predictors and select the most accurate one for the metrics of the auto algorithm.")
 	flags.StringSliceVar(&o.DataSource, "datasource", []string{"prom"}, "data source of the predictor, prom, mock is available")
 	flags.StringVar(&o.DataSourcePromConfig.Address, "prometheus-address", "", "prometheus address")
 	flags.StringVar(&o.DataSourcePromConfig.AdapterConfigMapNS, "prometheus-adapter-configmap-namespace", "", "prometheus adapter-configmap namespace")
@@ -135,6 +210,24 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&o.DataSourceGrpcConfig.Address, "grpc-ds-address", "localhost:50051", "grpc data source server address")
 	flags.DurationVar(&o.DataSourceGrpcConfig.Timeout, "grpc-ds-timeout", time.Minute, "grpc timeout")
 	flags.DurationVar(&o.AlgorithmModelConfig.UpdateInterval, "model-update-interval", 12*time.Hour, "algorithm model update interval, now used for dsp model update interval")
+	flags.StringVar(&o.RemotePredictorConfig.Address, "remote-predictor-address", "", "grpc model server address of the remote predictor, the remote predictor is disabled if it is empty")
+	flags.DurationVar(&o.RemotePredictorConfig.Timeout, "remote-predictor-timeout", time.Minute, "remote predictor grpc timeout")
+	flags.StringVar(&o.RemotePredictorConfig.Model, "remote-predictor-model", "", "model name passed to the model server of the remote predictor")
+	flags.DurationVar(&o.RemotePredictorConfig.HistoryLength, "remote-predictor-history-length", 7*24*time.Hour, "length of the history sent to the model server of the remote predictor")
+	flags.DurationVar(&o.RemotePredictorConfig.HistoryResolution, "remote-predictor-history-resolution", time.Minute, "step of the history and forecast of the remote predictor")
+	flags.DurationVar(&o.RemotePredictorConfig.Future, "remote-predictor-future", 24*time.Hour, "length of the forecast of the remote predictor, it should be longer than model-update-interval")
+	flags.StringVar(&o.CheckpointConfig.Type, "prediction-checkpoint-store", "", "store of percentile and dsp model checkpoints, configmap or file, checkpointing is disabled if it is empty")
+	flags.StringVar(&o.CheckpointConfig.Path, "prediction-checkpoint-path", "/var/lib/craned/checkpoints", "directory of the file checkpoint store")
+	flags.DurationVar(&o.AlgorithmModelConfig.CheckpointInterval, "prediction-checkpoint-interval", checkpoint.DefaultInterval, "interval to checkpoint percentile models, dsp models are checkpointed after each update")
+	flags.Float64Var(&o.AlgorithmModelConfig.LowerQuantile, "prediction-interval-lower-quantile", 0.1, "quantile of the lower bound of the prediction interval of dsp and percentile predictors")
+	flags.Float64Var(&o.AlgorithmModelConfig.UpperQuantile, "prediction-interval-upper-quantile", 0.9, "quantile of the upper bound of the prediction interval of dsp and percentile predictors")
+	flags.DurationVar(&o.AlgorithmModelConfig.AnomalyCheckInterval, "prediction-anomaly-check-interval", time.Minute, "interval to check the live values against the forecast of dsp predictor to detect anomalies and level shifts, 0 to disable the check")
+	flags.DurationVar(&o.AlgorithmModelConfig.AnomalyRetrainInterval, "prediction-anomaly-retrain-interval", time.Hour, "min interval between the update of a dsp model and its retraining triggered by anomalies")
+	flags.Float64Var(&o.AlgorithmModelConfig.AnomalyLowerQuantile, "prediction-anomaly-lower-quantile", 0.01, "quantile of the lower bound of the interval to detect anomalies of dsp predictor, it should not be greater than prediction-interval-lower-quantile")
+	flags.Float64Var(&o.AlgorithmModelConfig.AnomalyUpperQuantile, "prediction-anomaly-upper-quantile", 0.99, "quantile of the upper bound of the interval to detect anomalies of dsp predictor, it should not be less than prediction-interval-upper-quantile")
+	flags.IntVar(&o.AlgorithmModelConfig.AnomalyOutOfBandCount, "prediction-anomaly-out-of-band-count", 3, "number of the consecutive live values out of the detection interval to be an anomaly")
+	flags.Float64Var(&o.AlgorithmModelConfig.AnomalyCusumSlack, "prediction-anomaly-cusum-slack", 0.5, "slack of the cusum of the residuals normalized by the half width of the detection interval to detect level shifts")
+	flags.Float64Var(&o.AlgorithmModelConfig.AnomalyCusumThreshold, "prediction-anomaly-cusum-threshold", 5, "threshold of the cusum of the residuals normalized by the half width of the detection interval to detect level shifts")
 	flags.BoolVar(&o.WebhookConfig.Enabled, "webhook-enabled", true, "whether enable webhook or not, default to true")
 	flags.StringVar(&o.RecommendationConfigFile, "recommendation-config-file", "", "recommendation configuration file")
 	flags.StringVar(&o.RecommendationConfiguration, "recommendation-configuration-file", "/tmp/recommendation-framework/recommendation_configuration.yaml", "recommendation configuration file")
@@ -143,9 +236,18 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 	flags.StringSliceVar(&o.EhpaControllerConfig.PropagationConfig.AnnotationPrefixes, "ehpa-propagation-annotation-prefixes", []string{}, "propagate annotations whose key has the prefix to hpa")
 	flags.StringSliceVar(&o.EhpaControllerConfig.PropagationConfig.Labels, "ehpa-propagation-labels", []string{}, "propagate labels whose key is complete matching to hpa")
 	flags.StringSliceVar(&o.EhpaControllerConfig.PropagationConfig.Annotations, "ehpa-propagation-annotations", []string{}, "propagate annotations whose key is complete matching to hpa")
+	flags.BoolVar(&o.RecommendationRolloutConfig.Enabled, "recommendation-rollout-enabled", false, "patch Auto resource recommendations to the workload and roll back if its health regresses in the soak period")
+	flags.DurationVar(&o.RecommendationRolloutConfig.SoakPeriod, "recommendation-rollout-soak-period", 30*time.Minute, "period to watch the health of the workload after a resource recommendation is patched")
+	flags.DurationVar(&o.RecommendationRolloutConfig.CheckInterval, "recommendation-rollout-check-interval", time.Minute, "interval to check the health of the workload in the soak period")
+	flags.IntVar(&o.RecommendationRolloutConfig.MaxOOMs, "recommendation-rollout-max-ooms", 0, "max OOMKilled containers of the workload in the soak period")
+	flags.Int32Var(&o.RecommendationRolloutConfig.MaxRestarts, "recommendation-rollout-max-restarts", 3, "max container restarts of the pods created in the soak period")
+	flags.Float64Var(&o.RecommendationRolloutConfig.MaxCPUThrottleRatio, "recommendation-rollout-max-cpu-throttle-ratio", 0.25, "max ratio of throttled cfs periods of the workload in the soak period, 0 to disable the check")
 	flags.IntVar(&o.OOMRecordMaxNumber, "oom-record-max-number", 10000, "Max number for oom records to store in configmap")
 	flags.IntVar(&o.TimeSeriesPredictionMaxConcurrentReconciles, "time-series-prediction-max-concurrent-reconciles", 10, "Max concurrent reconciles for TimeSeriesPrediction controller")
 	flags.BoolVar(&o.CacheUnstructured, "cache-unstructured", true, "whether to cache Unstructured objects. When enabled, it will speed up reading Unstructured objects but will increase memory usage")
 	flags.DurationVar(&o.MonitorInterval, "recommendation-monitor-interval", time.Hour, "interval for recommendation checker")
 	flags.DurationVar(&o.OutDateInterval, "recommendation-outdate-interval", 24*time.Hour, "interval for identify a recommendation is outdated")
+	flags.DurationVar(&o.RecommendationTimeout, "recommendation-timeout", 10*time.Minute, "default timeout of a recommendation run, overridden by the analysis.crane.io/recommendation-timeout annotation of RecommendationRule, 0 to disable the timeout")
+	flags.IntVar(&o.RecommendationHistoryLimit, "recommendation-history-limit", 30, "max number of past recommended values kept for a recommendation, 0 to disable the history")
+	flags.Float64Var(&o.RecommendationDriftThreshold, "recommendation-drift-threshold", 0.1, "relative difference between the workload and the recommendation, beyond which the recommendation is marked as drifted")
 }
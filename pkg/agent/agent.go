@@ -36,6 +36,7 @@ import (
 	topologyapi "github.com/gocrane/api/topology/v1alpha1"
 
 	"github.com/gocrane/crane/pkg/ensurance/analyzer"
+	"github.com/gocrane/crane/pkg/ensurance/audit"
 	"github.com/gocrane/crane/pkg/ensurance/cm/cpumanager"
 	"github.com/gocrane/crane/pkg/ensurance/collector"
 	"github.com/gocrane/crane/pkg/ensurance/collector/cadvisor"
@@ -57,6 +58,8 @@ type Agent struct {
 	kubeClient  kubernetes.Interface
 	craneClient craneclientset.Interface
 	managers    []manager.Manager
+	// history is the history of avoidance actions done by the executor
+	history *audit.Store
 }
 
 func NewAgent(ctx context.Context,
@@ -77,6 +80,8 @@ func NewAgent(ctx context.Context,
 	executeExcess string,
 	cpuManagerReconcilePeriod time.Duration,
 	defaultCPUPolicy string,
+	avoidanceHistoryPath string,
+	avoidanceHistoryCapacity int,
 ) (*Agent, error) {
 	var managers []manager.Manager
 	var noticeCh = make(chan executor.AvoidanceExecutor)
@@ -93,6 +98,12 @@ func NewAgent(ctx context.Context,
 		return nil, err
 	}
 
+	history, err := audit.NewStore(avoidanceHistoryCapacity, avoidanceHistoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open avoidance history: %v", err)
+	}
+	agent.history = history
+
 	utilruntime.Must(ensuranceapi.AddToScheme(scheme.Scheme))
 	utilruntime.Must(topologyapi.AddToScheme(scheme.Scheme))
 	cadvisorManager := cadvisor.NewCadvisorManager(cgroupDriver)
@@ -111,11 +122,11 @@ func NewAgent(ctx context.Context,
 		}
 	}
 
-	stateCollector := collector.NewStateCollector(nodeName, sysPath, kubeClient, craneClient, nodeQOSInformer.Lister(), nrtInformer.Lister(), podInformer.Lister(), nodeInformer.Lister(), ifaces, healthCheck, collectInterval, exclusiveCPUSet, cadvisorManager)
+	stateCollector := collector.NewStateCollector(nodeName, sysPath, kubeClient, craneClient, nodeQOSInformer.Lister(), nrtInformer.Lister(), podInformer.Lister(), nodeInformer.Lister(), ifaces, healthCheck, collectInterval, exclusiveCPUSet, cadvisorManager, cgroupDriver)
 	managers = appendManagerIfNotNil(managers, stateCollector)
 	analyzerManager := analyzer.NewAnomalyAnalyzer(kubeClient, nodeName, podInformer, nodeInformer, nodeQOSInformer, podQOSInformer, actionInformer, stateCollector.AnalyzerChann, noticeCh)
 	managers = appendManagerIfNotNil(managers, analyzerManager)
-	avoidanceManager := executor.NewActionExecutor(kubeClient, nodeName, podInformer, nodeInformer, noticeCh, runtimeEndpoint, stateCollector.State, executeExcess)
+	avoidanceManager := executor.NewActionExecutor(kubeClient, nodeName, podInformer, nodeInformer, noticeCh, runtimeEndpoint, cgroupDriver, sysPath, stateCollector.State, executeExcess, history)
 	managers = appendManagerIfNotNil(managers, avoidanceManager)
 
 	if nodeResource := utilfeature.DefaultFeatureGate.Enabled(features.CraneNodeResource); nodeResource {
@@ -154,6 +165,7 @@ func (a *Agent) Run(healthCheck *metrics.HealthCheck, enableProfiling bool, bind
 		})
 
 		pathRecorderMux.HandleFunc("/health-check", healthCheck.ServeHTTP)
+		pathRecorderMux.Handle("/avoidance-history", a.history)
 		if enableProfiling {
 			routes.Profiling{}.Install(pathRecorderMux)
 		}
@@ -162,6 +174,10 @@ func (a *Agent) Run(healthCheck *metrics.HealthCheck, enableProfiling bool, bind
 	}()
 
 	<-a.ctx.Done()
+
+	if err := a.history.Close(); err != nil {
+		klog.Errorf("Failed to close avoidance history: %v", err)
+	}
 }
 
 func getAgentName(nodeName string) string {
@@ -279,7 +279,10 @@ func (c *EffectiveHPAController) GetHPAMetrics(ctx context.Context, ehpa *autosc
 				continue
 			}
 
-			if _, err := utils.GetReadyPredictionMetric(name, metricIdentifier, tsp); err != nil {
+			// scale on the bound of the prediction interval if it is specified
+			bound := utils.GetEHPAPredictionBound(ehpa)
+			name = utils.GetPredictionBoundMetricName(name, bound)
+			if _, err := utils.GetReadyPredictionBoundMetric(name, metricIdentifier, bound, tsp); err != nil {
 				// metric is not predictable
 				continue
 			}
@@ -21,6 +21,9 @@ import (
 	"github.com/gocrane/crane/pkg/utils"
 )
 
+// predictionAnnotations are the annotations of the TimeSeriesPrediction managed by the EffectiveHPA
+var predictionAnnotations = []string{known.TimeSeriesPredictionIntervalAnnotation, known.TimeSeriesPredictionCalendarAnnotation}
+
 func (c *EffectiveHPAController) ReconcilePredication(ctx context.Context, ehpa *autoscalingapi.EffectiveHorizontalPodAutoscaler) (*predictionapi.TimeSeriesPrediction, error) {
 	predictionList := &predictionapi.TimeSeriesPredictionList{}
 	opts := []client.ListOption{
@@ -86,8 +89,24 @@ func (c *EffectiveHPAController) UpdatePredictionIfNeed(ctx context.Context, ehp
 		return nil, err
 	}
 
-	if !equality.Semantic.DeepEqual(&predictionExist.Spec, &prediction.Spec) {
+	annotationsChanged := false
+	for _, annotation := range predictionAnnotations {
+		if predictionExist.Annotations[annotation] != prediction.Annotations[annotation] {
+			annotationsChanged = true
+		}
+	}
+	if !equality.Semantic.DeepEqual(&predictionExist.Spec, &prediction.Spec) || annotationsChanged {
 		predictionExist.Spec = prediction.Spec
+		for _, annotation := range predictionAnnotations {
+			if value := prediction.Annotations[annotation]; len(value) != 0 {
+				if predictionExist.Annotations == nil {
+					predictionExist.Annotations = map[string]string{}
+				}
+				predictionExist.Annotations[annotation] = value
+			} else {
+				delete(predictionExist.Annotations, annotation)
+			}
+		}
 		err := c.Update(ctx, predictionExist)
 		if err != nil {
 			c.Recorder.Event(ehpa, v1.EventTypeWarning, "FailedUpdatePrediction", err.Error())
@@ -240,6 +259,16 @@ func (c *EffectiveHPAController) NewPredictionObject(ehpa *autoscalingapi.Effect
 	}
 	prediction.Spec.PredictionMetrics = predictionMetrics
 
+	prediction.Annotations = map[string]string{}
+	// the bounds of the prediction interval are predicted only if the ehpa scales on one of them
+	if len(utils.GetEHPAPredictionBound(ehpa)) != 0 {
+		prediction.Annotations[known.TimeSeriesPredictionIntervalAnnotation] = "true"
+	}
+	// the predictions are overridden in the special periods of the calendar, so that the ehpa scales out before them
+	if name := ehpa.Annotations[known.EffectiveHorizontalPodAutoscalerPredictionCalendarAnnotation]; len(name) != 0 {
+		prediction.Annotations[known.TimeSeriesPredictionCalendarAnnotation] = name
+	}
+
 	// EffectiveHPA control the underground prediction so set controller reference for it here
 	if err := controllerutil.SetControllerReference(ehpa, prediction, c.Scheme); err != nil {
 		return nil, err
@@ -0,0 +1,20 @@
+package recommendation
+
+import "time"
+
+// RolloutConfig is the configuration for the safe rollout of Auto resource recommendations
+type RolloutConfig struct {
+	// Enabled indicates whether to patch Auto resource recommendations to the workload and watch its health
+	Enabled bool
+	// SoakPeriod is the period to watch the health of the workload after the recommendation is patched
+	SoakPeriod time.Duration
+	// CheckInterval is the interval to check the health of the workload during the soak period
+	CheckInterval time.Duration
+	// MaxOOMs is the max number of OOMKilled containers of the workload during the soak period
+	MaxOOMs int
+	// MaxRestarts is the max number of container restarts of the pods created during the soak period
+	MaxRestarts int32
+	// MaxCPUThrottleRatio is the max ratio of throttled cfs periods of the workload during the soak period, it is
+	// only regressed if it's also higher than the ratio before the rollout. 0 disables the check.
+	MaxCPUThrottleRatio float64
+}
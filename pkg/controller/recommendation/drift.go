@@ -0,0 +1,98 @@
+package recommendation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DriftConditionType is the condition of Recommendation which indicates the target drifts from the recommendation
+const DriftConditionType = "Drifted"
+
+// workloadPatch is the part of workload spec patched by resource and replicas recommendations
+type workloadPatch struct {
+	Spec struct {
+		Replicas *int32 `json:"replicas,omitempty"`
+		Template struct {
+			Spec struct {
+				Containers []corev1.Container `json:"containers,omitempty"`
+			} `json:"spec,omitempty"`
+		} `json:"template,omitempty"`
+	} `json:"spec,omitempty"`
+}
+
+// drift is the relative difference of a resource of a container or the replicas between the target and the
+// recommendation, it's positive if the target is larger than the recommendation.
+type drift struct {
+	container string
+	resource  string
+	value     float64
+}
+
+func (d drift) String() string {
+	if len(d.container) == 0 {
+		return fmt.Sprintf("%s drifts %+.0f%% from the recommendation", d.resource, d.value*100)
+	}
+	return fmt.Sprintf("%s of container %s drifts %+.0f%% from the recommendation", d.resource, d.container, d.value*100)
+}
+
+// computeDrifts compares the recommended info of a Recommendation with the current target
+func computeDrifts(recommendedInfo string, target *unstructured.Unstructured) ([]drift, error) {
+	var recommended, current workloadPatch
+	if err := json.Unmarshal([]byte(recommendedInfo), &recommended); err != nil {
+		return nil, fmt.Errorf("unmarshal recommended info failed: %v", err)
+	}
+	targetBytes, err := json.Marshal(target.Object)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(targetBytes, &current); err != nil {
+		return nil, fmt.Errorf("unmarshal target failed: %v", err)
+	}
+
+	var drifts []drift
+	if recommended.Spec.Replicas != nil && *recommended.Spec.Replicas != 0 && current.Spec.Replicas != nil {
+		drifts = append(drifts, drift{
+			resource: "replicas",
+			value:    float64(*current.Spec.Replicas-*recommended.Spec.Replicas) / float64(*recommended.Spec.Replicas),
+		})
+	}
+
+	for _, rc := range recommended.Spec.Template.Spec.Containers {
+		var requests corev1.ResourceList
+		for _, cc := range current.Spec.Template.Spec.Containers {
+			if cc.Name == rc.Name {
+				requests = cc.Resources.Requests
+				break
+			}
+		}
+		for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			recommendedQuantity, ok := rc.Resources.Requests[resourceName]
+			if !ok || recommendedQuantity.IsZero() {
+				continue
+			}
+			currentQuantity := requests[resourceName]
+			drifts = append(drifts, drift{
+				container: rc.Name,
+				resource:  resourceName.String(),
+				value:     (currentQuantity.AsApproximateFloat64() - recommendedQuantity.AsApproximateFloat64()) / recommendedQuantity.AsApproximateFloat64(),
+			})
+		}
+	}
+
+	return drifts, nil
+}
+
+// maxDrift returns the drift with the max absolute value
+func maxDrift(drifts []drift) (drift, bool) {
+	var result drift
+	for i, d := range drifts {
+		if i == 0 || math.Abs(d.value) > math.Abs(result.value) {
+			result = d
+		}
+	}
+	return result, len(drifts) != 0
+}
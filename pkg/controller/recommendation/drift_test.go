@@ -0,0 +1,125 @@
+package recommendation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	analysisapi "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/metrics"
+)
+
+func TestComputeDrifts(t *testing.T) {
+	target := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"spec": map[string]interface{}{
+			"replicas": int64(4),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name": "app",
+							"resources": map[string]interface{}{
+								"requests": map[string]interface{}{"cpu": "2", "memory": "1Gi"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name            string
+		recommendedInfo string
+		want            []drift
+		wantMax         drift
+	}{
+		{
+			name:            "replicas",
+			recommendedInfo: `{"spec":{"replicas":2}}`,
+			want:            []drift{{resource: "replicas", value: 1}},
+			wantMax:         drift{resource: "replicas", value: 1},
+		},
+		{
+			name:            "resource",
+			recommendedInfo: `{"spec":{"template":{"spec":{"containers":[{"name":"app","resources":{"requests":{"cpu":"1","memory":"2Gi"}}},{"name":"sidecar","resources":{"requests":{"cpu":"100m"}}}]}}}}`,
+			want: []drift{
+				{container: "app", resource: "cpu", value: 1},
+				{container: "app", resource: "memory", value: -0.5},
+				{container: "sidecar", resource: "cpu", value: -1},
+			},
+			wantMax: drift{container: "app", resource: "cpu", value: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drifts, err := computeDrifts(tt.recommendedInfo, target)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, drifts)
+			d, ok := maxDrift(drifts)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantMax, d)
+		})
+	}
+}
+
+func TestCheckerDeletesDrift(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, analysisapi.AddToScheme(scheme))
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:      "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+				}}},
+			},
+		},
+	}
+	recommendation := &analysisapi.Recommendation{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid"},
+		Spec: analysisapi.RecommendationSpec{
+			TargetRef: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "app"},
+			Type:      analysisapi.AnalysisTypeResource,
+		},
+	}
+	recommendation.Status.LastUpdateTime = &metav1.Time{Time: time.Now()}
+	recommendation.Status.RecommendedInfo = `{"spec":{"template":{"spec":{"containers":[{"name":"app","resources":{"requests":{"cpu":"500m"}}}]}}}}`
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment, recommendation).Build()
+	checker := &Checker{
+		Client:          c,
+		OutDateInterval: time.Hour,
+		DriftThreshold:  0.1,
+		History:         &HistoryRecorder{Client: c, Limit: 1},
+	}
+	metrics.RecommendationDrift.Reset()
+
+	checker.runChecker()
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.RecommendationDrift))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.RecommendationDrift.WithLabelValues(
+		"Resource", "apps/v1", "Deployment", "default", "app", "app", "cpu")))
+
+	// the drift of the deleted recommendation is not exposed any more
+	assert.NoError(t, c.Delete(context.TODO(), recommendation))
+	checker.runChecker()
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.RecommendationDrift))
+}
@@ -0,0 +1,157 @@
+package recommendation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+)
+
+const (
+	historyConfigMapPrefix = "recommendation-history-"
+	historyConfigMapKey    = "history"
+)
+
+// HistoryEntry is a past recommended value of a Recommendation
+type HistoryEntry struct {
+	Time             metav1.Time `json:"time"`
+	RecommendedValue string      `json:"recommendedValue,omitempty"`
+	RecommendedInfo  string      `json:"recommendedInfo,omitempty"`
+	CurrentInfo      string      `json:"currentInfo,omitempty"`
+}
+
+// HistoryRecorder keeps a bounded history of recommended values of each Recommendation in a companion ConfigMap in
+// the system namespace, which is labeled by the uid of the Recommendation and deleted by DeleteOrphans after it.
+type HistoryRecorder struct {
+	client.Client
+	// Limit is the max number of entries kept for a Recommendation, the history is disabled if it is not positive
+	Limit int
+}
+
+// Record appends the recommended value to the history if it changes from the last entry
+func (h *HistoryRecorder) Record(ctx context.Context, recommendation *analysisv1alph1.Recommendation) error {
+	if h == nil || h.Limit <= 0 || len(recommendation.Status.RecommendedValue) == 0 {
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	err := h.Get(ctx, client.ObjectKey{Namespace: known.CraneSystemNamespace, Name: historyConfigMapName(recommendation)}, cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if exists && cm.Labels[known.RecommendationHistoryLabel] != string(recommendation.UID) {
+		return fmt.Errorf("configmap %s is not the history of recommendation %s", klog.KObj(cm), klog.KObj(recommendation))
+	}
+
+	history, err := decodeHistory(cm)
+	if err != nil {
+		return err
+	}
+	if len(history) != 0 {
+		last := history[len(history)-1]
+		if last.RecommendedValue == recommendation.Status.RecommendedValue && last.RecommendedInfo == recommendation.Status.RecommendedInfo {
+			return nil
+		}
+	}
+
+	entry := HistoryEntry{
+		Time:             metav1.Now(),
+		RecommendedValue: recommendation.Status.RecommendedValue,
+		RecommendedInfo:  recommendation.Status.RecommendedInfo,
+		CurrentInfo:      recommendation.Status.CurrentInfo,
+	}
+	if recommendation.Status.LastUpdateTime != nil {
+		entry.Time = *recommendation.Status.LastUpdateTime
+	}
+	history = append(history, entry)
+	if len(history) > h.Limit {
+		history = history[len(history)-h.Limit:]
+	}
+
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[historyConfigMapKey] = string(data)
+
+	if exists {
+		return h.Update(ctx, cm)
+	}
+
+	// the Recommendation can not own the ConfigMap in another namespace, so it is labeled for DeleteOrphans
+	cm.Namespace = known.CraneSystemNamespace
+	cm.Name = historyConfigMapName(recommendation)
+	cm.Labels = map[string]string{known.RecommendationHistoryLabel: string(recommendation.UID)}
+	return h.Create(ctx, cm)
+}
+
+// GetHistory returns the history of the Recommendation from the oldest to the latest
+func (h *HistoryRecorder) GetHistory(ctx context.Context, recommendation *analysisv1alph1.Recommendation) ([]HistoryEntry, error) {
+	cm := &corev1.ConfigMap{}
+	if err := h.Get(ctx, client.ObjectKey{Namespace: known.CraneSystemNamespace, Name: historyConfigMapName(recommendation)}, cm); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if cm.Labels[known.RecommendationHistoryLabel] != string(recommendation.UID) {
+		return nil, nil
+	}
+	return decodeHistory(cm)
+}
+
+// DeleteOrphans deletes the histories created before since, whose Recommendations are not in recommendations any more.
+// Only the ConfigMaps labeled as histories are listed and deleted.
+func (h *HistoryRecorder) DeleteOrphans(ctx context.Context, recommendations []analysisv1alph1.Recommendation, since time.Time) error {
+	if h == nil {
+		return nil
+	}
+
+	cms := &corev1.ConfigMapList{}
+	if err := h.List(ctx, cms, client.InNamespace(known.CraneSystemNamespace), client.HasLabels{known.RecommendationHistoryLabel}); err != nil {
+		return err
+	}
+
+	uids := make(map[string]bool, len(recommendations))
+	for _, recommendation := range recommendations {
+		uids[string(recommendation.UID)] = true
+	}
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		// the history of a Recommendation created after listing the Recommendations is kept
+		if uids[cm.Labels[known.RecommendationHistoryLabel]] || !cm.CreationTimestamp.Time.Before(since) {
+			continue
+		}
+		if err := h.Delete(ctx, cm, client.Preconditions{UID: &cm.UID}); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		klog.V(4).Infof("Deleted the history %s of a deleted recommendation", klog.KObj(cm))
+	}
+	return nil
+}
+
+func decodeHistory(cm *corev1.ConfigMap) ([]HistoryEntry, error) {
+	var history []HistoryEntry
+	if data, ok := cm.Data[historyConfigMapKey]; ok && len(data) != 0 {
+		if err := json.Unmarshal([]byte(data), &history); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+// historyConfigMapName is unique in the system namespace for the Recommendations of all namespaces
+func historyConfigMapName(recommendation *analysisv1alph1.Recommendation) string {
+	return historyConfigMapPrefix + string(recommendation.UID)
+}
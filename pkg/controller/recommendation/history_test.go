@@ -0,0 +1,78 @@
+package recommendation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	analysisapi "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func TestHistoryRecorder(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+
+	h := &HistoryRecorder{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Limit:  2,
+	}
+	recommendation := &analysisapi.Recommendation{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid"},
+	}
+
+	for _, value := range []string{"a", "a", "b", "c"} {
+		recommendation.Status.RecommendedValue = value
+		assert.NoError(t, h.Record(context.TODO(), recommendation))
+	}
+
+	history, err := h.GetHistory(context.TODO(), recommendation)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, "b", history[0].RecommendedValue)
+	assert.Equal(t, "c", history[1].RecommendedValue)
+
+	// the history is kept in the system namespace
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, h.Get(context.TODO(), client.ObjectKey{Namespace: known.CraneSystemNamespace, Name: historyConfigMapName(recommendation)}, cm))
+
+	var disabled *HistoryRecorder
+	assert.NoError(t, disabled.Record(context.TODO(), recommendation))
+}
+
+func TestHistoryDeleteOrphans(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "craned", Namespace: known.CraneSystemNamespace}}
+	h := &HistoryRecorder{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(other).Build(),
+		Limit:  2,
+	}
+	kept := analysisapi.Recommendation{ObjectMeta: metav1.ObjectMeta{Name: "kept", Namespace: "default", UID: "kept"}}
+	deleted := analysisapi.Recommendation{ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: "default", UID: "deleted"}}
+	for _, recommendation := range []*analysisapi.Recommendation{&kept, &deleted} {
+		recommendation.Status.RecommendedValue = "a"
+		assert.NoError(t, h.Record(context.TODO(), recommendation))
+	}
+
+	assert.NoError(t, h.DeleteOrphans(context.TODO(), []analysisapi.Recommendation{kept}, time.Now()))
+
+	history, err := h.GetHistory(context.TODO(), &kept)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(history))
+	history, err = h.GetHistory(context.TODO(), &deleted)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(history))
+	// the ConfigMaps not labeled as histories are left alone
+	assert.NoError(t, h.Get(context.TODO(), client.ObjectKeyFromObject(other), &corev1.ConfigMap{}))
+}
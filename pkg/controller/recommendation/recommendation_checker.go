@@ -2,23 +2,38 @@ package recommendation
 
 import (
 	"context"
+	"math"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	analysisv1alpha1 "github.com/gocrane/api/analysis/v1alpha1"
 
 	"github.com/gocrane/crane/pkg/metrics"
+	"github.com/gocrane/crane/pkg/recommendation/cost"
 )
 
+// driftLabelValues are the values of the labels of the drift gauge
+type driftLabelValues [7]string
+
 type Checker struct {
 	client.Client
 	MonitorInterval time.Duration
 	OutDateInterval time.Duration
+	// DriftThreshold is the relative difference between the target and the recommendation, beyond which the
+	// Recommendation is marked as Drifted
+	DriftThreshold float64
+	// History is the recorder of recommendation histories, the histories of deleted Recommendations are deleted by the checker
+	History *HistoryRecorder
+
+	// driftLabels are the drift gauges set by the last check, the ones not set again are deleted
+	driftLabels map[driftLabelValues]struct{}
 }
 
-func (r Checker) Run(stopCh <-chan struct{}) {
+func (r *Checker) Run(stopCh <-chan struct{}) {
 	go func() {
 		ticker := time.NewTicker(r.MonitorInterval)
 		defer ticker.Stop()
@@ -34,13 +49,17 @@ func (r Checker) Run(stopCh <-chan struct{}) {
 	}()
 }
 
-func (r Checker) runChecker() {
+func (r *Checker) runChecker() {
+	listTime := time.Now()
 	recommendList := &analysisv1alpha1.RecommendationList{}
 	err := r.Client.List(context.TODO(), recommendList, []client.ListOption{}...)
 	if err != nil {
 		klog.Errorf("Failed to list recommendation: %v", err)
+	} else if err = r.History.DeleteOrphans(context.TODO(), recommendList.Items, listTime); err != nil {
+		klog.Errorf("Failed to delete the histories of deleted recommendations: %v", err)
 	}
 
+	driftLabels := map[driftLabelValues]struct{}{}
 	for _, recommend := range recommendList.Items {
 		updateStatus := "Updated"
 		if time.Now().Sub(recommend.Status.LastUpdateTime.Time) > r.OutDateInterval {
@@ -61,5 +80,76 @@ func (r Checker) runChecker() {
 			"update_status": updateStatus,
 			"result_status": resultStatus,
 		}).Set(time.Now().Sub(recommend.Status.LastUpdateTime.Time).Seconds())
+
+		r.checkDrift(&recommend, driftLabels)
+	}
+
+	// the drifts of deleted recommendations, or of the targets and containers no longer drifting, are not exposed
+	for values := range r.driftLabels {
+		if _, ok := driftLabels[values]; !ok {
+			metrics.RecommendationDrift.DeleteLabelValues(values[:]...)
+		}
+	}
+	r.driftLabels = driftLabels
+
+	metrics.RecommendationMonthlySavings.Reset()
+	for _, savings := range cost.AggregateSavings(recommendList.Items) {
+		metrics.RecommendationMonthlySavings.With(map[string]string{
+			"namespace":           savings.Namespace,
+			"recommendation_rule": savings.RecommendationRule,
+		}).Set(savings.MonthlySavings)
+	}
+}
+
+// checkDrift exposes the drift between the target and the recommendation, and updates the Drifted condition. The
+// label values of the drift gauges set are added to driftLabels
+func (r *Checker) checkDrift(recommend *analysisv1alpha1.Recommendation, driftLabels map[driftLabelValues]struct{}) {
+	if (recommend.Spec.Type != analysisv1alpha1.AnalysisTypeResource && recommend.Spec.Type != analysisv1alpha1.AnalysisTypeReplicas) ||
+		len(recommend.Status.RecommendedInfo) == 0 {
+		return
+	}
+
+	target := &unstructured.Unstructured{}
+	target.SetAPIVersion(recommend.Spec.TargetRef.APIVersion)
+	target.SetKind(recommend.Spec.TargetRef.Kind)
+	namespace := recommend.Spec.TargetRef.Namespace
+	if len(namespace) == 0 {
+		namespace = recommend.Namespace
+	}
+	if err := r.Client.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: recommend.Spec.TargetRef.Name}, target); err != nil {
+		klog.V(4).Infof("Failed to get target of recommendation %s: %v", klog.KObj(recommend), err)
+		return
+	}
+
+	drifts, err := computeDrifts(recommend.Status.RecommendedInfo, target)
+	if err != nil {
+		klog.Errorf("Failed to compute drift of recommendation %s: %v", klog.KObj(recommend), err)
+		return
+	}
+
+	for _, d := range drifts {
+		values := driftLabelValues{string(recommend.Spec.Type), recommend.Spec.TargetRef.APIVersion, recommend.Spec.TargetRef.Kind,
+			namespace, recommend.Spec.TargetRef.Name, d.container, d.resource}
+		metrics.RecommendationDrift.WithLabelValues(values[:]...).Set(d.value)
+		driftLabels[values] = struct{}{}
+	}
+
+	d, ok := maxDrift(drifts)
+	if !ok {
+		return
+	}
+	status, reason, message := metav1.ConditionFalse, "NotDrifted", "Target matches the recommendation"
+	if math.Abs(d.value) > r.DriftThreshold {
+		status, reason, message = metav1.ConditionTrue, "Drifted", d.String()
+	}
+
+	for _, condition := range recommend.Status.Conditions {
+		if condition.Type == DriftConditionType && condition.Status == status && condition.Message == message {
+			return
+		}
+	}
+	setCondition(&recommend.Status, DriftConditionType, status, reason, message)
+	if err = r.Client.Update(context.TODO(), recommend); err != nil {
+		klog.Errorf("Failed to update drift condition of recommendation %s: %v", klog.KObj(recommend), err)
 	}
 }
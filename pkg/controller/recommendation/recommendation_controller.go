@@ -18,6 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	analysisv1alpha1 "github.com/gocrane/api/analysis/v1alpha1"
+	"github.com/gocrane/crane/pkg/oom"
 	predictormgr "github.com/gocrane/crane/pkg/predictor"
 	"github.com/gocrane/crane/pkg/providers"
 	recommender "github.com/gocrane/crane/pkg/recommendation"
@@ -35,6 +36,8 @@ type RecommendationController struct {
 	ScaleClient    scale.ScalesGetter
 	PredictorMgr   predictormgr.Manager
 	Provider       providers.History
+	OOMRecorder    oom.Recorder
+	RolloutConfig  RolloutConfig
 }
 
 func (c *RecommendationController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -77,14 +80,22 @@ func (c *RecommendationController) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, c.UpdateStatus(ctx, recommendation, newStatus)
 	}
 
+	requeueAfter, err := c.rollout(ctx, recommendation, newStatus)
+	if err != nil {
+		c.Recorder.Event(recommendation, v1.EventTypeWarning, "FailedRollout", err.Error())
+		msg := fmt.Sprintf("Failed to rollout recommendation, Recommendation %s: %v", klog.KObj(recommendation), err)
+		klog.Errorf(msg)
+		setCondition(newStatus, RolloutConditionType, metav1.ConditionFalse, "FailedRollout", msg)
+		return ctrl.Result{}, c.UpdateStatus(ctx, recommendation, newStatus)
+	}
+
 	if updated {
 		c.Recorder.Event(recommendation, v1.EventTypeNormal, "UpdatedRecommendationValue", "")
 
 		setReadyCondition(newStatus, metav1.ConditionTrue, "RecommendationReady", "Recommendation is ready")
-		return ctrl.Result{}, c.UpdateStatus(ctx, recommendation, newStatus)
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, c.UpdateStatus(ctx, recommendation, newStatus)
 }
 
 func (c *RecommendationController) UpdateStatus(ctx context.Context, recommendation *analysisv1alpha1.Recommendation, newStatus *analysisv1alpha1.RecommendationStatus) error {
@@ -113,8 +124,12 @@ func (c *RecommendationController) SetupWithManager(mgr ctrl.Manager) error {
 }
 
 func setReadyCondition(status *analysisv1alpha1.RecommendationStatus, conditionStatus metav1.ConditionStatus, reason string, message string) {
+	setCondition(status, "Ready", conditionStatus, reason, message)
+}
+
+func setCondition(status *analysisv1alpha1.RecommendationStatus, conditionType string, conditionStatus metav1.ConditionStatus, reason string, message string) {
 	for i := range status.Conditions {
-		if status.Conditions[i].Type == "Ready" {
+		if status.Conditions[i].Type == conditionType {
 			status.Conditions[i].Status = conditionStatus
 			status.Conditions[i].Reason = reason
 			status.Conditions[i].Message = message
@@ -123,7 +138,7 @@ func setReadyCondition(status *analysisv1alpha1.RecommendationStatus, conditionS
 		}
 	}
 	status.Conditions = append(status.Conditions, metav1.Condition{
-		Type:               "Ready",
+		Type:               conditionType,
 		Status:             conditionStatus,
 		Reason:             reason,
 		Message:            message,
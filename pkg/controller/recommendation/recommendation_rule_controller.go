@@ -31,6 +31,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
@@ -40,6 +41,7 @@ import (
 	predictormgr "github.com/gocrane/crane/pkg/predictor"
 	"github.com/gocrane/crane/pkg/providers"
 	recommender "github.com/gocrane/crane/pkg/recommendation"
+	"github.com/gocrane/crane/pkg/recommendation/cost"
 	"github.com/gocrane/crane/pkg/recommendation/framework"
 	"github.com/gocrane/crane/pkg/utils"
 )
@@ -57,7 +59,13 @@ type RecommendationRuleController struct {
 	dynamicClient   dynamic.Interface
 	discoveryClient discovery.DiscoveryInterface
 	Provider        providers.History
+	History         *HistoryRecorder
 	dynamicLister   DynamicLister
+	// Timeout is the default timeout of a recommendation run, it's overridden by the timeout annotation of RecommendationRule
+	Timeout time.Duration
+	// runCancels cancel the running recommendations of RecommendationRules once they are deleted
+	runCancelsLock sync.Mutex
+	runCancels     map[types.NamespacedName]context.CancelFunc
 }
 
 func (c *RecommendationRuleController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -102,7 +110,11 @@ func (c *RecommendationRuleController) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}
 
-	finished := c.doReconcile(ctx, recommendationRule, interval)
+	// the running recommendations are canceled once the RecommendationRule is deleted
+	runCtx, cancel := c.startRun(ctx, req.NamespacedName)
+	defer c.finishRun(req.NamespacedName, cancel)
+
+	finished := c.doReconcile(runCtx, recommendationRule, interval)
 	if finished && len(strings.TrimSpace(recommendationRule.Spec.RunInterval)) != 0 {
 		klog.V(4).InfoS("Will re-sync", "after", interval)
 		// Arrange for next round.
@@ -198,17 +210,23 @@ func (c *RecommendationRuleController) doReconcile(ctx context.Context, recommen
 		}
 	}
 
+	timeout := recommendationTimeout(recommendationRule, c.Timeout)
 	wg := sync.WaitGroup{}
 	wg.Add(concurrency)
 	for index := executionIndex; index < len(identitiesArray) && index < concurrency+executionIndex; index++ {
 		if klog.V(6).Enabled() {
 			klog.V(6).InfoS("execute identities", "RecommendationRule", klog.KObj(recommendationRule), "target", identitiesArray[index].GetObjectReference())
 		}
-		go executeIdentity(ctx, &wg, c.RecommenderMgr, c.Provider, c.PredictorMgr, recommendationRule, identitiesArray[index], c.Client, c.ScaleClient, c.OOMRecorder, timeNow, newStatus.RunNumber)
+		go executeIdentity(ctx, &wg, c.RecommenderMgr, c.Provider, c.PredictorMgr, recommendationRule, identitiesArray[index], c.Client, c.ScaleClient, c.OOMRecorder, c.History, timeNow, newStatus.RunNumber, timeout)
 	}
 
 	wg.Wait()
 
+	if ctx.Err() != nil {
+		klog.Infof("RecommendationRule %s is canceled: %v", klog.KObj(recommendationRule), ctx.Err())
+		return false
+	}
+
 	finished := false
 	if executionIndex+concurrency == len(identitiesArray) || len(identitiesArray) == 0 {
 		finished = true
@@ -252,10 +270,64 @@ func (c *RecommendationRuleController) SetupWithManager(mgr ctrl.Manager) error
 	c.dynamicLister = NewDynamicInformerLister(dynamicInformerFactory)
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&analysisv1alph1.RecommendationRule{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&analysisv1alph1.RecommendationRule{}, builder.WithPredicates(predicate.Funcs{
+			// cancel the running recommendations here, since the reconciling of the deletion waits for them
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if e.ObjectNew.GetDeletionTimestamp() != nil {
+					c.cancelRun(types.NamespacedName{Namespace: e.ObjectNew.GetNamespace(), Name: e.ObjectNew.GetName()})
+				}
+				return true
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				c.cancelRun(types.NamespacedName{Namespace: e.Object.GetNamespace(), Name: e.Object.GetName()})
+				return true
+			},
+		}, predicate.GenerationChangedPredicate{})).
 		Complete(c)
 }
 
+// startRun returns the context of the recommendations of RecommendationRule, which is canceled by cancelRun
+func (c *RecommendationRuleController) startRun(ctx context.Context, key types.NamespacedName) (context.Context, context.CancelFunc) {
+	runCtx, cancel := context.WithCancel(ctx)
+
+	c.runCancelsLock.Lock()
+	defer c.runCancelsLock.Unlock()
+	if c.runCancels == nil {
+		c.runCancels = map[types.NamespacedName]context.CancelFunc{}
+	}
+	c.runCancels[key] = cancel
+	return runCtx, cancel
+}
+
+func (c *RecommendationRuleController) finishRun(key types.NamespacedName, cancel context.CancelFunc) {
+	cancel()
+
+	c.runCancelsLock.Lock()
+	defer c.runCancelsLock.Unlock()
+	delete(c.runCancels, key)
+}
+
+func (c *RecommendationRuleController) cancelRun(key types.NamespacedName) {
+	c.runCancelsLock.Lock()
+	defer c.runCancelsLock.Unlock()
+	if cancel, ok := c.runCancels[key]; ok {
+		klog.Infof("Cancel the running recommendations of RecommendationRule %s", key)
+		cancel()
+	}
+}
+
+// recommendationTimeout returns the timeout of a recommendation run of RecommendationRule, 0 means no timeout
+func recommendationTimeout(recommendationRule *analysisv1alph1.RecommendationRule, defaultTimeout time.Duration) time.Duration {
+	if value, ok := recommendationRule.Annotations[known.RecommendationTimeoutAnnotation]; ok {
+		timeout, err := time.ParseDuration(value)
+		if err == nil && timeout >= 0 {
+			return timeout
+		}
+		klog.Warningf("Invalid timeout %q of RecommendationRule %s, use the default %v", value, klog.KObj(recommendationRule), defaultTimeout)
+	}
+	return defaultTimeout
+}
+
 func (c *RecommendationRuleController) getIdentities(ctx context.Context, recommendationRule *analysisv1alph1.RecommendationRule) (map[string]ObjectIdentity, error) {
 	identities := map[string]ObjectIdentity{}
 
@@ -456,7 +528,7 @@ func generateRecommendationLabels(recommendationRule *analysisv1alph1.Recommenda
 }
 
 func executeIdentity(ctx context.Context, wg *sync.WaitGroup, recommenderMgr recommender.RecommenderManager, provider providers.History, predictorMgr predictormgr.Manager,
-	recommendationRule *analysisv1alph1.RecommendationRule, id ObjectIdentity, client client.Client, scaleClient scale.ScalesGetter, oomRecorder oom.Recorder, timeNow metav1.Time, currentRunNumber int32) {
+	recommendationRule *analysisv1alph1.RecommendationRule, id ObjectIdentity, client client.Client, scaleClient scale.ScalesGetter, oomRecorder oom.Recorder, history *HistoryRecorder, timeNow metav1.Time, currentRunNumber int32, timeout time.Duration) {
 	defer func() {
 		if wg != nil {
 			wg.Done()
@@ -489,10 +561,22 @@ func executeIdentity(ctx context.Context, wg *sync.WaitGroup, recommenderMgr rec
 			Labels:     id.Labels,
 			Object:     id.Object,
 		}
-		recommendationContext := framework.NewRecommendationContext(ctx, identity, recommendationRule, predictorMgr, p, recommendation, client, scaleClient, oomRecorder)
+		var runCtx context.Context
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			runCtx, cancel = context.WithTimeout(ctx, timeout)
+		} else {
+			runCtx, cancel = context.WithCancel(ctx)
+		}
+		recommendationContext := framework.NewRecommendationContext(runCtx, identity, recommendationRule, predictorMgr, p, recommendation, client, scaleClient, oomRecorder)
 		err = recommender.Run(&recommendationContext, r)
+		cancel()
 		if err != nil {
 			message = fmt.Sprintf("Failed to run recommendation flow in recommender %s: %s", r.Name(), err.Error())
+		} else if delta, ok, err := cost.EstimateMonthlyCostDelta(recommendation, &id.Object, recommenderMgr.GetPricingModel()); err != nil {
+			klog.Errorf("Failed to estimate cost of recommendation %s: %v", klog.KObj(recommendation), err)
+		} else if ok {
+			cost.SetMonthlyCostDelta(recommendation, delta)
 		}
 	}
 
@@ -525,6 +609,10 @@ func executeIdentity(ctx context.Context, wg *sync.WaitGroup, recommenderMgr rec
 
 		klog.Infof("Successfully to create Recommendation %s", klog.KObj(recommendation))
 	}
+
+	if err := history.Record(ctx, recommendation); err != nil {
+		klog.Errorf("Failed to record history of recommendation %s: %v", klog.KObj(recommendation), err)
+	}
 }
 
 func IsConvertFromAnalytics(recommendationRule *analysisv1alph1.RecommendationRule) (bool, string) {
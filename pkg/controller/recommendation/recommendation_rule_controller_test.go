@@ -1,12 +1,17 @@
 package recommendation
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/known"
 )
 
 func TestRecommendationIndex_GetRecommendation(t *testing.T) {
@@ -113,3 +118,61 @@ func TestRecommendationIndex_GetRecommendation(t *testing.T) {
 		})
 	}
 }
+
+func TestRecommendationTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        time.Duration
+	}{
+		{
+			name: "default",
+			want: 10 * time.Minute,
+		},
+		{
+			name:        "annotation",
+			annotations: map[string]string{known.RecommendationTimeoutAnnotation: "2m"},
+			want:        2 * time.Minute,
+		},
+		{
+			name:        "disabled",
+			annotations: map[string]string{known.RecommendationTimeoutAnnotation: "0s"},
+			want:        0,
+		},
+		{
+			name:        "invalid",
+			annotations: map[string]string{known.RecommendationTimeoutAnnotation: "soon"},
+			want:        10 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &analysisv1alph1.RecommendationRule{ObjectMeta: v1.ObjectMeta{Name: "rule", Annotations: tt.annotations}}
+			if got := recommendationTimeout(rule, 10*time.Minute); got != tt.want {
+				t.Errorf("recommendationTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelRun(t *testing.T) {
+	c := &RecommendationRuleController{}
+	key := types.NamespacedName{Name: "rule"}
+
+	ctx, cancel := c.startRun(context.TODO(), key)
+	c.cancelRun(types.NamespacedName{Name: "other"})
+	if ctx.Err() != nil {
+		t.Errorf("run of rule is canceled by other rule")
+	}
+
+	c.cancelRun(key)
+	if ctx.Err() != context.Canceled {
+		t.Errorf("run of rule is not canceled: %v", ctx.Err())
+	}
+
+	c.finishRun(key, cancel)
+	if len(c.runCancels) != 0 {
+		t.Errorf("run of rule is not finished")
+	}
+}
@@ -3,6 +3,7 @@ package recommendation
 import (
 	"context"
 	"fmt"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -39,6 +40,9 @@ type RecommendationTriggerController struct {
 	dynamicClient   dynamic.Interface
 	PredictorMgr    predictormgr.Manager
 	Provider        providers.History
+	History         *HistoryRecorder
+	// Timeout is the default timeout of a recommendation run, it's overridden by the timeout annotation of RecommendationRule
+	Timeout time.Duration
 }
 
 func (c *RecommendationTriggerController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -106,7 +110,7 @@ func (c *RecommendationTriggerController) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, nil
 	}
 
-	executeIdentity(context.TODO(), nil, c.RecommenderMgr, c.Provider, c.PredictorMgr, recommendationRule, id, c.Client, c.ScaleClient, c.OOMRecorder, metav1.Now(), newStatus.RunNumber)
+	executeIdentity(context.TODO(), nil, c.RecommenderMgr, c.Provider, c.PredictorMgr, recommendationRule, id, c.Client, c.ScaleClient, c.OOMRecorder, c.History, metav1.Now(), newStatus.RunNumber, recommendationTimeout(recommendationRule, c.Timeout))
 	if newStatus.Recommendations[currentMissionIndex].Message != "Success" {
 		err = c.Client.Delete(context.TODO(), recommendation)
 		if err != nil {
@@ -0,0 +1,275 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	analysisapi "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/metricquery"
+	"github.com/gocrane/crane/pkg/oom"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const (
+	RolloutPhaseSoaking    = "Soaking"
+	RolloutPhaseSucceeded  = "Succeeded"
+	RolloutPhaseRolledBack = "RolledBack"
+	RolloutPhaseFailed     = "Failed"
+
+	// RolloutConditionType is the condition of Recommendation which records the outcome of the rollout
+	RolloutConditionType = "RolloutSucceeded"
+)
+
+// rolloutHealth is the health of the target workload observed during the soak period
+type rolloutHealth struct {
+	ooms     int
+	restarts int32
+	// cpuThrottleRatio is the ratio of throttled cfs periods since the rollout, the baseline is the ratio in the
+	// soak period before the rollout. They are negative if unknown.
+	cpuThrottleRatio         float64
+	baselineCPUThrottleRatio float64
+}
+
+// regression returns the reason if the health regresses, or empty if it's healthy
+func (h rolloutHealth) regression(config RolloutConfig) string {
+	if h.ooms > config.MaxOOMs {
+		return fmt.Sprintf("%d containers are OOMKilled, exceeds %d", h.ooms, config.MaxOOMs)
+	}
+	if h.restarts > config.MaxRestarts {
+		return fmt.Sprintf("containers restarted %d times, exceeds %d", h.restarts, config.MaxRestarts)
+	}
+	if config.MaxCPUThrottleRatio > 0 && h.cpuThrottleRatio > config.MaxCPUThrottleRatio && h.cpuThrottleRatio > h.baselineCPUThrottleRatio {
+		return fmt.Sprintf("cpu throttle ratio %.3f exceeds %.3f and the baseline %.3f", h.cpuThrottleRatio, config.MaxCPUThrottleRatio, h.baselineCPUThrottleRatio)
+	}
+	return ""
+}
+
+// rollout patches the Auto resource recommendation to the target workload, and watches the health of the workload
+// for the soak period. The workload is patched back to the resources before the rollout if its health regresses.
+// The pods are replaced by the update strategy of the workload, so maxUnavailable or partition of the workload
+// controls the pace of the rollout. It returns the duration to requeue if the rollout is not finished.
+func (c *RecommendationController) rollout(ctx context.Context, recommendation *analysisapi.Recommendation, newStatus *analysisapi.RecommendationStatus) (time.Duration, error) {
+	if !c.RolloutConfig.Enabled || recommendation.Spec.AdoptionType != analysisapi.AdoptionTypeAuto ||
+		recommendation.Spec.Type != analysisapi.AnalysisTypeResource {
+		return 0, nil
+	}
+
+	annotations := recommendation.GetAnnotations()
+	if annotations[known.RolloutPhaseAnnotation] == RolloutPhaseSoaking {
+		return c.soak(ctx, recommendation, newStatus)
+	}
+
+	// the value which was rolled out is not rolled out again, no matter it succeeded or was rolled back
+	value := rolloutValue(recommendation.Status.RecommendedInfo)
+	if recommendation.Status.Action != "Patch" || annotations[known.RolloutValueAnnotation] == value {
+		return 0, nil
+	}
+
+	// the target can not be rolled back without its resources before the rollout, so it is not rolled out
+	if len(recommendation.Status.CurrentInfo) == 0 {
+		c.failRollout(recommendation, newStatus, value, "Recommended value is not rolled out, the current resources of target are unknown to roll back")
+		return 0, nil
+	}
+
+	if err := c.patchTarget(ctx, recommendation, recommendation.Status.RecommendedInfo); err != nil {
+		return 0, fmt.Errorf("patch recommended value to target failed: %v. ", err)
+	}
+
+	setRolloutAnnotations(recommendation, map[string]string{
+		known.RolloutPhaseAnnotation:     RolloutPhaseSoaking,
+		known.RolloutStartTimeAnnotation: time.Now().Format(time.RFC3339),
+		known.RolloutValueAnnotation:     value,
+		known.RolloutRollbackAnnotation:  recommendation.Status.CurrentInfo,
+	})
+	msg := fmt.Sprintf("Recommended value is patched to target, soaking for %v", c.RolloutConfig.SoakPeriod)
+	setCondition(newStatus, RolloutConditionType, metav1.ConditionUnknown, RolloutPhaseSoaking, msg)
+	c.Recorder.Event(recommendation, v1.EventTypeNormal, "RolloutStarted", msg)
+	klog.Infof("Rollout started, Recommendation %s", klog.KObj(recommendation))
+
+	return c.RolloutConfig.CheckInterval, nil
+}
+
+func (c *RecommendationController) soak(ctx context.Context, recommendation *analysisapi.Recommendation, newStatus *analysisapi.RecommendationStatus) (time.Duration, error) {
+	annotations := recommendation.GetAnnotations()
+	start, err := time.Parse(time.RFC3339, annotations[known.RolloutStartTimeAnnotation])
+	if err != nil {
+		return 0, fmt.Errorf("parse rollout start time failed: %v. ", err)
+	}
+
+	health, err := c.getRolloutHealth(ctx, recommendation, start)
+	if err != nil {
+		return 0, err
+	}
+
+	if reason := health.regression(c.RolloutConfig); reason != "" {
+		rollback := annotations[known.RolloutRollbackAnnotation]
+		if len(rollback) == 0 {
+			c.failRollout(recommendation, newStatus, annotations[known.RolloutValueAnnotation], fmt.Sprintf("Target can not be rolled back without its resources before the rollout: %s", reason))
+			return 0, nil
+		}
+		if err = c.patchTarget(ctx, recommendation, rollback); err != nil {
+			return 0, fmt.Errorf("rollback target failed: %v. ", err)
+		}
+
+		setRolloutAnnotations(recommendation, map[string]string{known.RolloutPhaseAnnotation: RolloutPhaseRolledBack})
+		msg := fmt.Sprintf("Target is rolled back: %s", reason)
+		setCondition(newStatus, RolloutConditionType, metav1.ConditionFalse, RolloutPhaseRolledBack, msg)
+		c.Recorder.Event(recommendation, v1.EventTypeWarning, "RolloutRolledBack", msg)
+		klog.Infof("Rollout rolled back, Recommendation %s: %s", klog.KObj(recommendation), reason)
+		return 0, nil
+	}
+
+	elapsed := time.Since(start)
+	if elapsed >= c.RolloutConfig.SoakPeriod {
+		setRolloutAnnotations(recommendation, map[string]string{known.RolloutPhaseAnnotation: RolloutPhaseSucceeded})
+		msg := fmt.Sprintf("Target is healthy in the soak period %v", c.RolloutConfig.SoakPeriod)
+		setCondition(newStatus, RolloutConditionType, metav1.ConditionTrue, RolloutPhaseSucceeded, msg)
+		c.Recorder.Event(recommendation, v1.EventTypeNormal, "RolloutSucceeded", msg)
+		klog.Infof("Rollout succeeded, Recommendation %s", klog.KObj(recommendation))
+		return 0, nil
+	}
+
+	if remaining := c.RolloutConfig.SoakPeriod - elapsed; remaining < c.RolloutConfig.CheckInterval {
+		return remaining, nil
+	}
+	return c.RolloutConfig.CheckInterval, nil
+}
+
+// failRollout marks the rollout of the value as failed, the value is not rolled out again
+func (c *RecommendationController) failRollout(recommendation *analysisapi.Recommendation, newStatus *analysisapi.RecommendationStatus, value string, msg string) {
+	setRolloutAnnotations(recommendation, map[string]string{
+		known.RolloutPhaseAnnotation: RolloutPhaseFailed,
+		known.RolloutValueAnnotation: value,
+	})
+	setCondition(newStatus, RolloutConditionType, metav1.ConditionFalse, RolloutPhaseFailed, msg)
+	c.Recorder.Event(recommendation, v1.EventTypeWarning, "RolloutFailed", msg)
+	klog.Warningf("Rollout failed, Recommendation %s: %s", klog.KObj(recommendation), msg)
+}
+
+func (c *RecommendationController) patchTarget(ctx context.Context, recommendation *analysisapi.Recommendation, patch string) error {
+	if len(patch) == 0 {
+		return fmt.Errorf("empty patch")
+	}
+
+	target := &unstructured.Unstructured{}
+	target.SetAPIVersion(recommendation.Spec.TargetRef.APIVersion)
+	target.SetKind(recommendation.Spec.TargetRef.Kind)
+	target.SetNamespace(recommendation.Spec.TargetRef.Namespace)
+	target.SetName(recommendation.Spec.TargetRef.Name)
+	return c.Client.Patch(ctx, target, client.RawPatch(types.StrategicMergePatchType, []byte(patch)))
+}
+
+func (c *RecommendationController) getRolloutHealth(ctx context.Context, recommendation *analysisapi.Recommendation, start time.Time) (rolloutHealth, error) {
+	targetRef := recommendation.Spec.TargetRef
+	podNameReg, err := regexp.Compile(utils.GetPodNameReg(targetRef.Name, targetRef.Kind))
+	if err != nil {
+		return rolloutHealth{}, err
+	}
+
+	podList := &v1.PodList{}
+	if err = c.Client.List(ctx, podList, client.InNamespace(targetRef.Namespace)); err != nil {
+		return rolloutHealth{}, fmt.Errorf("list pods failed: %v. ", err)
+	}
+
+	var records []oom.OOMRecord
+	if c.OOMRecorder != nil {
+		if records, err = c.OOMRecorder.GetOOMRecord(); err != nil {
+			return rolloutHealth{}, fmt.Errorf("get oom records failed: %v. ", err)
+		}
+	}
+
+	health := evaluateRolloutHealth(podList.Items, records, targetRef.Namespace, podNameReg, start)
+	health.cpuThrottleRatio, health.baselineCPUThrottleRatio = c.getCPUThrottleRatio(recommendation, start)
+	return health, nil
+}
+
+// evaluateRolloutHealth counts the oom kills of the target pods and the restarts of the target pods created since the rollout
+func evaluateRolloutHealth(pods []v1.Pod, records []oom.OOMRecord, namespace string, podNameReg *regexp.Regexp, start time.Time) rolloutHealth {
+	health := rolloutHealth{cpuThrottleRatio: -1, baselineCPUThrottleRatio: -1}
+	for _, pod := range pods {
+		if !podNameReg.MatchString(pod.Name) || pod.CreationTimestamp.Time.Before(start) {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			health.restarts += cs.RestartCount
+		}
+	}
+	for _, record := range records {
+		if record.Namespace == namespace && podNameReg.MatchString(record.Pod) && !record.OOMAt.Before(start) {
+			health.ooms++
+		}
+	}
+	return health
+}
+
+// getCPUThrottleRatio returns the average cpu throttle ratio since the rollout and in the soak period before the
+// rollout, they are -1 if the ratio can not be queried.
+func (c *RecommendationController) getCPUThrottleRatio(recommendation *analysisapi.Recommendation, start time.Time) (float64, float64) {
+	if c.Provider == nil || c.RolloutConfig.MaxCPUThrottleRatio <= 0 {
+		return -1, -1
+	}
+
+	targetRef := recommendation.Spec.TargetRef
+	namer := &metricnaming.GeneralMetricNamer{
+		CallerName: "rollout/" + klog.KObj(recommendation).String(),
+		Metric: &metricquery.Metric{
+			Type:       metricquery.PromQLMetricType,
+			MetricName: "cpu_throttle_ratio",
+			Prom: &metricquery.PromNamerInfo{
+				QueryExpr: utils.GetWorkloadCpuThrottleRatioExpression(targetRef.Namespace, targetRef.Name, targetRef.Kind),
+				Namespace: targetRef.Namespace,
+			},
+		},
+	}
+
+	average := func(start, end time.Time) float64 {
+		tsList, err := c.Provider.QueryTimeSeries(namer, start, end, time.Minute)
+		if err != nil {
+			klog.V(4).Infof("Failed to query cpu throttle ratio, Recommendation %s: %v", klog.KObj(recommendation), err)
+			return -1
+		}
+		sum, count := 0., 0
+		for _, ts := range tsList {
+			for _, sample := range ts.Samples {
+				sum += sample.Value
+				count++
+			}
+		}
+		if count == 0 {
+			return -1
+		}
+		return sum / float64(count)
+	}
+
+	return average(start, time.Now()), average(start.Add(-c.RolloutConfig.SoakPeriod), start)
+}
+
+func rolloutValue(recommendedInfo string) string {
+	h := fnv.New64a()
+	h.Write([]byte(recommendedInfo))
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+func setRolloutAnnotations(recommendation *analysisapi.Recommendation, values map[string]string) {
+	annotations := recommendation.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for k, v := range values {
+		annotations[k] = v
+	}
+	recommendation.SetAnnotations(annotations)
+}
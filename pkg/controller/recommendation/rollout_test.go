@@ -0,0 +1,202 @@
+package recommendation
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	analysisapi "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/oom"
+)
+
+func TestEvaluateRolloutHealth(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	podNameReg := regexp.MustCompile(`^app-[a-z0-9]+-[a-z0-9]{5}$`)
+	pod := func(name string, created time.Time, restarts int32) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(created)},
+			Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: restarts}}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		pods         []corev1.Pod
+		records      []oom.OOMRecord
+		wantRestarts int32
+		wantOOMs     int
+	}{
+		{
+			name: "restarts of pods created before the rollout are ignored",
+			pods: []corev1.Pod{
+				pod("app-5d8f7c9b4-abcde", start.Add(-time.Hour), 5),
+				pod("app-6c7d8e9f1-fghij", start.Add(time.Minute), 2),
+				pod("other-6c7d8e9f1-fghij", start.Add(time.Minute), 7),
+			},
+			wantRestarts: 2,
+		},
+		{
+			name: "oom of other workloads or before the rollout are ignored",
+			records: []oom.OOMRecord{
+				{Namespace: "default", Pod: "app-6c7d8e9f1-fghij", OOMAt: start.Add(time.Minute)},
+				{Namespace: "default", Pod: "app-5d8f7c9b4-abcde", OOMAt: start.Add(-time.Minute)},
+				{Namespace: "other", Pod: "app-6c7d8e9f1-fghij", OOMAt: start.Add(time.Minute)},
+				{Namespace: "default", Pod: "other-6c7d8e9f1-fghij", OOMAt: start.Add(time.Minute)},
+			},
+			wantOOMs: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := evaluateRolloutHealth(tt.pods, tt.records, "default", podNameReg, start)
+			assert.Equal(t, tt.wantRestarts, health.restarts)
+			assert.Equal(t, tt.wantOOMs, health.ooms)
+		})
+	}
+}
+
+func TestRolloutHealthRegression(t *testing.T) {
+	config := RolloutConfig{MaxOOMs: 0, MaxRestarts: 3, MaxCPUThrottleRatio: 0.2}
+
+	tests := []struct {
+		name      string
+		health    rolloutHealth
+		regressed bool
+	}{
+		{
+			name:   "healthy",
+			health: rolloutHealth{restarts: 3, cpuThrottleRatio: 0.1, baselineCPUThrottleRatio: 0.05},
+		},
+		{
+			name:      "oom",
+			health:    rolloutHealth{ooms: 1, cpuThrottleRatio: -1, baselineCPUThrottleRatio: -1},
+			regressed: true,
+		},
+		{
+			name:      "restarts",
+			health:    rolloutHealth{restarts: 4, cpuThrottleRatio: -1, baselineCPUThrottleRatio: -1},
+			regressed: true,
+		},
+		{
+			name:      "cpu throttled",
+			health:    rolloutHealth{cpuThrottleRatio: 0.3, baselineCPUThrottleRatio: 0.1},
+			regressed: true,
+		},
+		{
+			name:   "cpu throttled before the rollout",
+			health: rolloutHealth{cpuThrottleRatio: 0.3, baselineCPUThrottleRatio: 0.4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.regressed, tt.health.regression(config) != "")
+		})
+	}
+}
+
+type fakeOOMRecorder []oom.OOMRecord
+
+func (r fakeOOMRecorder) GetOOMRecord() ([]oom.OOMRecord, error) {
+	return r, nil
+}
+
+func TestRolloutRollback(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+
+	container := func(cpu string) string {
+		return `{"spec":{"template":{"spec":{"containers":[{"name":"app","resources":{"requests":{"cpu":"` + cpu + `"}}}]}}}}`
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:      "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+				}}},
+			},
+		},
+	}
+	recommendation := &analysisapi.Recommendation{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: analysisapi.RecommendationSpec{
+			TargetRef:    corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "app"},
+			Type:         analysisapi.AnalysisTypeResource,
+			AdoptionType: analysisapi.AdoptionTypeAuto,
+		},
+	}
+	recommendation.Status.Action = "Patch"
+	recommendation.Status.RecommendedInfo = container("500m")
+	recommendation.Status.CurrentInfo = container("1")
+
+	c := &RecommendationController{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment).Build(),
+		Recorder:      record.NewFakeRecorder(10),
+		OOMRecorder:   fakeOOMRecorder{{Namespace: "default", Pod: "app-6c7d8e9f1-fghij", OOMAt: time.Now().Add(time.Hour)}},
+		RolloutConfig: RolloutConfig{Enabled: true, SoakPeriod: time.Hour, CheckInterval: time.Minute},
+	}
+	getCPU := func() string {
+		d := &appsv1.Deployment{}
+		assert.NoError(t, c.Client.Get(context.TODO(), client.ObjectKeyFromObject(deployment), d))
+		cpu := d.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+		return cpu.String()
+	}
+
+	status := recommendation.Status.DeepCopy()
+	requeueAfter, err := c.rollout(context.TODO(), recommendation, status)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, requeueAfter)
+	assert.Equal(t, RolloutPhaseSoaking, recommendation.Annotations[known.RolloutPhaseAnnotation])
+	assert.Equal(t, "500m", getCPU())
+
+	requeueAfter, err = c.rollout(context.TODO(), recommendation, status)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), requeueAfter)
+	assert.Equal(t, RolloutPhaseRolledBack, recommendation.Annotations[known.RolloutPhaseAnnotation])
+	assert.Equal(t, "1", getCPU())
+	assert.Equal(t, metav1.ConditionFalse, status.Conditions[0].Status)
+
+	// the value rolled back is not rolled out again
+	requeueAfter, err = c.rollout(context.TODO(), recommendation, status)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), requeueAfter)
+	assert.Equal(t, "1", getCPU())
+
+	// the value is not rolled out if the target can not be rolled back
+	recommendation.Status.RecommendedInfo = container("250m")
+	recommendation.Status.CurrentInfo = ""
+	requeueAfter, err = c.rollout(context.TODO(), recommendation, status)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), requeueAfter)
+	assert.Equal(t, RolloutPhaseFailed, recommendation.Annotations[known.RolloutPhaseAnnotation])
+	assert.Equal(t, "1", getCPU())
+
+	// the rollout soaking without the rollback patch fails instead of retrying the rollback
+	recommendation.Status.RecommendedInfo = container("500m")
+	setRolloutAnnotations(recommendation, map[string]string{
+		known.RolloutPhaseAnnotation:     RolloutPhaseSoaking,
+		known.RolloutStartTimeAnnotation: time.Now().Format(time.RFC3339),
+		known.RolloutRollbackAnnotation:  "",
+	})
+	requeueAfter, err = c.rollout(context.TODO(), recommendation, status)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), requeueAfter)
+	assert.Equal(t, RolloutPhaseFailed, recommendation.Annotations[known.RolloutPhaseAnnotation])
+}
@@ -0,0 +1,239 @@
+package timeseriesprediction
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metrics"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/accuracy"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// defaultAccuracyStep is the step to query the actual values if it can not be inferred from the predictions
+const defaultAccuracyStep = time.Minute
+
+// accuracyPoint is a predicted value and the actual value at the same timestamp
+type accuracyPoint struct {
+	timestamp int64
+	actual    float64
+	predicted float64
+}
+
+// metricAccuracy is the rolling window of the accuracy points of a metric
+type metricAccuracy struct {
+	algorithm predictionapi.AlgorithmType
+	// lastTimestamp is the timestamp of the last scored prediction, the predictions before it are not scored again
+	lastTimestamp int64
+	points        []accuracyPoint
+}
+
+// add appends the points which are in chronological order
+func (m *metricAccuracy) add(points []accuracyPoint) {
+	if len(points) == 0 {
+		return
+	}
+	m.points = append(m.points, points...)
+	m.lastTimestamp = points[len(points)-1].timestamp
+}
+
+// prune drops the points before the start of the window
+func (m *metricAccuracy) prune(windowStart int64) {
+	i := 0
+	for i < len(m.points) && m.points[i].timestamp < windowStart {
+		i++
+	}
+	m.points = m.points[i:]
+}
+
+func (m *metricAccuracy) score() (*accuracy.Score, error) {
+	actual := make([]float64, len(m.points))
+	predicted := make([]float64, len(m.points))
+	for i, point := range m.points {
+		actual[i] = point.actual
+		predicted[i] = point.predicted
+	}
+	return accuracy.NewScore(actual, predicted)
+}
+
+// updateAccuracy compares the past predictions in the status with the actual values from the history provider, then
+// sets the rolling accuracy of each effective metric to the Accuracy condition of newStatus and the prometheus metrics.
+// It does nothing if the history provider is not provisioned.
+func (tc *Controller) updateAccuracy(tsPrediction *predictionapi.TimeSeriesPrediction, predictionMetrics []predictionapi.PredictionMetric, newStatus *predictionapi.TimeSeriesPredictionStatus, now time.Time) {
+	if tc.History == nil {
+		return
+	}
+
+	c, err := NewMetricContext(tc.TargetFetcher, tsPrediction, tc.predictorMgr)
+	if err != nil {
+		klog.Errorf("Failed to score accuracy for %v: %v", klog.KObj(tsPrediction), err)
+		return
+	}
+
+	key := GetTimeSeriesPredictionKey(tsPrediction)
+	value, _ := tc.accuracyMap.LoadOrStore(key, map[string]*metricAccuracy{})
+	accuracies := value.(map[string]*metricAccuracy)
+
+	windowStart := now.Add(-tc.AccuracyWindow).Unix()
+	specified := make(map[string]bool, len(predictionMetrics))
+	var messages []string
+	for i := range predictionMetrics {
+		metric := &predictionMetrics[i]
+		specified[metric.ResourceIdentifier] = true
+
+		ma, ok := accuracies[metric.ResourceIdentifier]
+		if !ok || ma.algorithm != metric.Algorithm.AlgorithmType {
+			// the accuracy of another algorithm is meaningless
+			if ok {
+				deleteAccuracyMetrics(tsPrediction, metric.ResourceIdentifier, ma.algorithm)
+			}
+			ma = &metricAccuracy{algorithm: metric.Algorithm.AlgorithmType}
+			accuracies[metric.ResourceIdentifier] = ma
+		}
+
+		if status := findPredictionMetricStatus(tsPrediction.Status.PredictionMetrics, metric.ResourceIdentifier); status != nil {
+			points, err := tc.accuracyPoints(c, metric, status, ma.lastTimestamp, now)
+			if err != nil {
+				klog.Warningf("Failed to score accuracy of metric %s for %v: %v", metric.ResourceIdentifier, klog.KObj(tsPrediction), err)
+			} else {
+				ma.add(points)
+			}
+		}
+		ma.prune(windowStart)
+
+		score, err := ma.score()
+		if err != nil {
+			continue
+		}
+		algorithm := string(ma.algorithm)
+		metrics.PredictionAccuracyMAPE.WithLabelValues(tsPrediction.Namespace, tsPrediction.Name, metric.ResourceIdentifier, algorithm).Set(score.MAPE)
+		metrics.PredictionAccuracyMAE.WithLabelValues(tsPrediction.Namespace, tsPrediction.Name, metric.ResourceIdentifier, algorithm).Set(score.MAE)
+		messages = append(messages, fmt.Sprintf("%s: %s", metric.ResourceIdentifier, score))
+	}
+
+	for resourceIdentifier, ma := range accuracies {
+		if !specified[resourceIdentifier] {
+			deleteAccuracyMetrics(tsPrediction, resourceIdentifier, ma.algorithm)
+			delete(accuracies, resourceIdentifier)
+		}
+	}
+
+	if len(messages) == 0 {
+		setCondition(newStatus, known.TimeSeriesPredictionConditionAccuracy, metav1.ConditionUnknown, known.ReasonTimeSeriesPredictionAccuracyUnknown, "no prediction is scored")
+		return
+	}
+	setCondition(newStatus, known.TimeSeriesPredictionConditionAccuracy, metav1.ConditionTrue, known.ReasonTimeSeriesPredictionAccuracyScored, strings.Join(messages, "; "))
+}
+
+// accuracyPoints joins the predictions in (after, now] with the actual values. The time series are joined by labels,
+// or directly if there is only one time series on both sides.
+func (tc *Controller) accuracyPoints(c *MetricContext, metric *predictionapi.PredictionMetric, status *predictionapi.PredictionMetricStatus, after int64, now time.Time) ([]accuracyPoint, error) {
+	predicted := map[string][]common.Sample{}
+	first, last := now.Unix(), after
+	for _, ts := range status.Prediction {
+		// only the point forecast is scored
+		if utils.GetPredictionBound(ts) != "" {
+			continue
+		}
+		labels := make([]common.Label, len(ts.Labels))
+		for i, label := range ts.Labels {
+			labels[i] = common.Label{Name: label.Name, Value: label.Value}
+		}
+		key := prediction.AggregateSignalKey(labels)
+		for _, sample := range ts.Samples {
+			if sample.Timestamp <= after || sample.Timestamp > now.Unix() {
+				continue
+			}
+			value, err := strconv.ParseFloat(sample.Value, 64)
+			if err != nil {
+				continue
+			}
+			predicted[key] = append(predicted[key], common.Sample{Timestamp: sample.Timestamp, Value: value})
+			if sample.Timestamp < first {
+				first = sample.Timestamp
+			}
+			if sample.Timestamp > last {
+				last = sample.Timestamp
+			}
+		}
+	}
+	if len(predicted) == 0 {
+		return nil, nil
+	}
+
+	namer := c.GetMetricNamer(metric)
+	if namer == nil {
+		return nil, fmt.Errorf("metric query is not supported")
+	}
+	tsList, err := tc.History.QueryTimeSeries(namer, time.Unix(first, 0), time.Unix(last, 0), predictionStep(status))
+	if err != nil {
+		return nil, err
+	}
+
+	var points []accuracyPoint
+	for _, ts := range tsList {
+		samples, ok := predicted[prediction.AggregateSignalKey(ts.Labels)]
+		if !ok && len(predicted) == 1 && len(tsList) == 1 {
+			for _, s := range predicted {
+				samples = s
+			}
+		}
+		values := make(map[int64]float64, len(samples))
+		for _, sample := range samples {
+			values[sample.Timestamp] = sample.Value
+		}
+		for _, sample := range ts.Samples {
+			if value, ok := values[sample.Timestamp]; ok {
+				points = append(points, accuracyPoint{timestamp: sample.Timestamp, actual: sample.Value, predicted: value})
+			}
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].timestamp < points[j].timestamp
+	})
+	return points, nil
+}
+
+// deleteAccuracy drops the accuracy of the time series prediction
+func (tc *Controller) deleteAccuracy(tsPrediction *predictionapi.TimeSeriesPrediction) {
+	value, ok := tc.accuracyMap.LoadAndDelete(GetTimeSeriesPredictionKey(tsPrediction))
+	if !ok {
+		return
+	}
+	for resourceIdentifier, ma := range value.(map[string]*metricAccuracy) {
+		deleteAccuracyMetrics(tsPrediction, resourceIdentifier, ma.algorithm)
+	}
+}
+
+func deleteAccuracyMetrics(tsPrediction *predictionapi.TimeSeriesPrediction, resourceIdentifier string, algorithm predictionapi.AlgorithmType) {
+	metrics.PredictionAccuracyMAPE.DeleteLabelValues(tsPrediction.Namespace, tsPrediction.Name, resourceIdentifier, string(algorithm))
+	metrics.PredictionAccuracyMAE.DeleteLabelValues(tsPrediction.Namespace, tsPrediction.Name, resourceIdentifier, string(algorithm))
+}
+
+func findPredictionMetricStatus(statuses []predictionapi.PredictionMetricStatus, resourceIdentifier string) *predictionapi.PredictionMetricStatus {
+	for i := range statuses {
+		if statuses[i].ResourceIdentifier == resourceIdentifier {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// predictionStep returns the interval of the predicted samples
+func predictionStep(status *predictionapi.PredictionMetricStatus) time.Duration {
+	for _, ts := range status.Prediction {
+		if len(ts.Samples) > 1 && ts.Samples[1].Timestamp > ts.Samples[0].Timestamp {
+			return time.Duration(ts.Samples[1].Timestamp-ts.Samples[0].Timestamp) * time.Second
+		}
+	}
+	return defaultAccuracyStep
+}
@@ -0,0 +1,95 @@
+package timeseriesprediction
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/providers/csv"
+)
+
+func TestUpdateAccuracy(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// the actual value is 1 in the last two hours
+	var buf bytes.Buffer
+	buf.WriteString("ts,value\n")
+	for ts := now.Add(-2 * time.Hour); !ts.After(now); ts = ts.Add(time.Minute) {
+		buf.WriteString(fmt.Sprintf("%d,1\n", ts.Unix()))
+	}
+	history, err := csv.NewProvider(&buf)
+	assert.NoError(t, err)
+
+	// the prediction is 1.2 from an hour ago to an hour later, with the upper bound 5 which is not scored
+	var samples, upperSamples []predictionapi.Sample
+	for ts := now.Add(-time.Hour); ts.Before(now.Add(time.Hour)); ts = ts.Add(time.Minute) {
+		samples = append(samples, predictionapi.Sample{Timestamp: ts.Unix(), Value: "1.20000"})
+		upperSamples = append(upperSamples, predictionapi.Sample{Timestamp: ts.Unix(), Value: "5.00000"})
+	}
+	upper := &predictionapi.MetricTimeSeries{
+		Labels:  []predictionapi.Label{{Name: known.PredictionBoundLabel, Value: known.PredictionBoundUpper}},
+		Samples: upperSamples,
+	}
+
+	cpu := corev1.ResourceCPU
+	tsp := &predictionapi.TimeSeriesPrediction{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec: predictionapi.TimeSeriesPredictionSpec{
+			TargetRef: corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+			PredictionMetrics: []predictionapi.PredictionMetric{
+				{
+					ResourceIdentifier: "cpu",
+					ResourceQuery:      &cpu,
+					Algorithm:          predictionapi.Algorithm{AlgorithmType: predictionapi.AlgorithmTypeDSP},
+				},
+			},
+		},
+		Status: predictionapi.TimeSeriesPredictionStatus{
+			PredictionMetrics: []predictionapi.PredictionMetricStatus{
+				{ResourceIdentifier: "cpu", Prediction: []*predictionapi.MetricTimeSeries{{Samples: samples}, upper}},
+			},
+		},
+	}
+
+	tc := &Controller{History: history, AccuracyWindow: 30 * time.Minute}
+	status := &predictionapi.TimeSeriesPredictionStatus{}
+	tc.updateAccuracy(tsp, tsp.Spec.PredictionMetrics, status, now)
+
+	assert.Len(t, status.Conditions, 1)
+	condition := status.Conditions[0]
+	assert.Equal(t, known.TimeSeriesPredictionConditionAccuracy, condition.Type)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "cpu: mape=0.2000, mae=0.2000, count=31", condition.Message)
+
+	value, ok := tc.accuracyMap.Load(GetTimeSeriesPredictionKey(tsp))
+	assert.True(t, ok)
+	ma := value.(map[string]*metricAccuracy)["cpu"]
+	assert.Equal(t, now.Unix(), ma.lastTimestamp)
+
+	// the scored predictions are not scored again
+	tc.updateAccuracy(tsp, tsp.Spec.PredictionMetrics, status, now)
+	assert.Len(t, ma.points, 31)
+
+	// the accuracy of another algorithm is dropped
+	tsp.Spec.PredictionMetrics[0].Algorithm.AlgorithmType = predictionapi.AlgorithmTypePercentile
+	tsp.Status.PredictionMetrics = nil
+	tc.updateAccuracy(tsp, tsp.Spec.PredictionMetrics, status, now)
+	assert.Equal(t, metav1.ConditionUnknown, status.Conditions[0].Status)
+
+	tc.deleteAccuracy(tsp)
+	_, ok = tc.accuracyMap.Load(GetTimeSeriesPredictionKey(tsp))
+	assert.False(t, ok)
+
+	// nothing is scored without history provider
+	tc = &Controller{AccuracyWindow: time.Hour}
+	status = &predictionapi.TimeSeriesPredictionStatus{}
+	tc.updateAccuracy(tsp, tsp.Spec.PredictionMetrics, status, now)
+	assert.Empty(t, status.Conditions)
+}
@@ -0,0 +1,78 @@
+package timeseriesprediction
+
+import (
+	"context"
+	"time"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metrics"
+	"github.com/gocrane/crane/pkg/prediction"
+)
+
+// checkAnomalies reports the anomalies detected by the predictors since the last check as events and metrics, it
+// returns true if the model of any metric is retrained because of them, so that the predictions are updated.
+func (tc *Controller) checkAnomalies(tsPrediction *predictionapi.TimeSeriesPrediction, predictionMetrics []predictionapi.PredictionMetric) bool {
+	c, err := NewMetricContext(tc.TargetFetcher, tsPrediction, tc.predictorMgr)
+	if err != nil {
+		klog.V(4).InfoS("Failed to check anomalies.", "timeSeriesPrediction", klog.KObj(tsPrediction), "err", err)
+		return false
+	}
+
+	key := GetTimeSeriesPredictionKey(tsPrediction)
+	value, _ := tc.anomalyMap.LoadOrStore(key, map[string]int64{})
+	lastTimestamps := value.(map[string]int64)
+
+	retrained := false
+	for i := range predictionMetrics {
+		metric := &predictionMetrics[i]
+		predictor, ok := tc.getPredictor(metric.Algorithm.AlgorithmType).(prediction.AnomalyInterface)
+		if !ok {
+			continue
+		}
+		namer := c.GetMetricNamer(metric)
+		if namer == nil {
+			continue
+		}
+
+		anomalies, err := predictor.QueryAnomalies(context.TODO(), namer, time.Unix(lastTimestamps[metric.ResourceIdentifier], 0))
+		if err != nil {
+			klog.V(4).InfoS("Failed to query anomalies.", "timeSeriesPrediction", klog.KObj(tsPrediction), "resourceIdentifier", metric.ResourceIdentifier, "err", err)
+			continue
+		}
+		for _, anomaly := range anomalies {
+			reason := known.ReasonTimeSeriesPredictionOutOfBand
+			if anomaly.Type == prediction.AnomalyTypeLevelShift {
+				reason = known.ReasonTimeSeriesPredictionLevelShift
+			}
+			tc.Recorder.Eventf(tsPrediction, v1.EventTypeWarning, reason, "metric %s, labels %v: value %.5f at %s, predicted %.5f in [%.5f, %.5f]",
+				metric.ResourceIdentifier, anomaly.Labels, anomaly.Value, time.Unix(anomaly.Timestamp, 0).UTC().Format(time.RFC3339), anomaly.Predicted, anomaly.Lower, anomaly.Upper)
+			metrics.PredictionAnomalies.WithLabelValues(tsPrediction.Namespace, tsPrediction.Name, metric.ResourceIdentifier, string(anomaly.Type)).Inc()
+
+			if anomaly.Timestamp > lastTimestamps[metric.ResourceIdentifier] {
+				lastTimestamps[metric.ResourceIdentifier] = anomaly.Timestamp
+			}
+			if anomaly.Retrained && !retrained {
+				retrained = true
+				tc.Recorder.Eventf(tsPrediction, v1.EventTypeNormal, known.ReasonTimeSeriesPredictionModelRetrained, "model of metric %s retrained on anomalies", metric.ResourceIdentifier)
+			}
+		}
+	}
+	return retrained
+}
+
+// deleteAnomalies drops the anomalies seen of the time series prediction
+func (tc *Controller) deleteAnomalies(tsPrediction *predictionapi.TimeSeriesPrediction) {
+	value, ok := tc.anomalyMap.LoadAndDelete(GetTimeSeriesPredictionKey(tsPrediction))
+	if !ok {
+		return
+	}
+	for resourceIdentifier := range value.(map[string]int64) {
+		for _, anomalyType := range []prediction.AnomalyType{prediction.AnomalyTypeOutOfBand, prediction.AnomalyTypeLevelShift} {
+			metrics.PredictionAnomalies.DeleteLabelValues(tsPrediction.Namespace, tsPrediction.Name, resourceIdentifier, string(anomalyType))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package timeseriesprediction
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction"
+	predconf "github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/dsp"
+)
+
+type fakeAnomalyPredictor struct {
+	prediction.Interface
+	anomalies []prediction.Anomaly
+}
+
+func (p *fakeAnomalyPredictor) QueryAnomalies(_ context.Context, _ metricnaming.MetricNamer, after time.Time) ([]prediction.Anomaly, error) {
+	var anomalies []prediction.Anomaly
+	for _, anomaly := range p.anomalies {
+		if anomaly.Timestamp > after.Unix() {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	return anomalies, nil
+}
+
+func TestCheckAnomalies(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cpu := corev1.ResourceCPU
+	tsp := &predictionapi.TimeSeriesPrediction{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec: predictionapi.TimeSeriesPredictionSpec{
+			TargetRef: corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+			PredictionMetrics: []predictionapi.PredictionMetric{
+				{
+					ResourceIdentifier: "cpu",
+					ResourceQuery:      &cpu,
+					Algorithm:          predictionapi.Algorithm{AlgorithmType: predictionapi.AlgorithmTypeDSP},
+				},
+			},
+		},
+	}
+
+	predictor := &fakeAnomalyPredictor{
+		Interface: dsp.NewPrediction(nil, nil, predconf.AlgorithmModelConfig{}, nil),
+		anomalies: []prediction.Anomaly{
+			{Type: prediction.AnomalyTypeOutOfBand, Timestamp: now.Unix(), Value: 5, Predicted: 1, Lower: 0.5, Upper: 1.5},
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	tc := &Controller{
+		Recorder: recorder,
+		predictorMgr: &fakePredictorManager{predictors: map[predictionapi.AlgorithmType]prediction.Interface{
+			predictionapi.AlgorithmTypeDSP: predictor,
+		}},
+	}
+
+	// the anomaly not retrained is reported without predicting again
+	assert.False(t, tc.checkAnomalies(tsp, tsp.Spec.PredictionMetrics))
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning PredictionOutOfBand metric cpu")
+
+	// the anomalies seen are not reported again
+	assert.False(t, tc.checkAnomalies(tsp, tsp.Spec.PredictionMetrics))
+	assert.Len(t, recorder.Events, 0)
+
+	// the retrained model is predicted again
+	predictor.anomalies = append(predictor.anomalies, prediction.Anomaly{Type: prediction.AnomalyTypeLevelShift, Timestamp: now.Add(time.Minute).Unix(), Retrained: true})
+	assert.True(t, tc.checkAnomalies(tsp, tsp.Spec.PredictionMetrics))
+	assert.Len(t, recorder.Events, 2)
+	assert.Contains(t, <-recorder.Events, "Warning PredictionLevelShift metric cpu")
+	assert.Contains(t, <-recorder.Events, "Normal ModelRetrained")
+
+	tc.deleteAnomalies(tsp)
+	_, ok := tc.anomalyMap.Load(GetTimeSeriesPredictionKey(tsp))
+	assert.False(t, ok)
+
+	// the predictors without anomaly detection are skipped
+	tc.predictorMgr = &fakePredictorManager{predictors: map[predictionapi.AlgorithmType]prediction.Interface{}}
+	assert.False(t, tc.checkAnomalies(tsp, tsp.Spec.PredictionMetrics))
+}
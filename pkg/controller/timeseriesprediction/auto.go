@@ -0,0 +1,266 @@
+package timeseriesprediction
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/prediction/accuracy"
+	"github.com/gocrane/crane/pkg/prediction/backtest"
+)
+
+// AlgorithmTypeAuto is the algorithm which selects the most accurate one of the registered dsp and percentile
+// predictors by backtesting them on the recent history of the metric periodically. The dsp and percentile params of
+// the metric are used by the candidates, the default ones are used if they are not specified.
+const AlgorithmTypeAuto predictionapi.AlgorithmType = "auto"
+
+const (
+	// autoHistoryLength and autoSampleInterval are used by the default params of the candidates
+	autoHistoryLength  = "7d"
+	autoSampleInterval = "1m"
+	// autoBacktestWindow is the length of the replayed history to select the algorithm
+	autoBacktestWindow = 2 * 24 * time.Hour
+	// autoBacktestTimeout is the timeout to backtest a metric
+	autoBacktestTimeout = time.Minute
+	// autoDefaultAlgorithm is used before the algorithm is selected successfully, because it does not rely on the
+	// periodicity of the metric
+	autoDefaultAlgorithm = predictionapi.AlgorithmTypePercentile
+)
+
+// autoBacktestConfig forecasts the next day from a week of history at each cutoff
+var autoBacktestConfig = backtest.Config{
+	HistoryLength:  7 * 24 * time.Hour,
+	Horizon:        24 * time.Hour,
+	Step:           24 * time.Hour,
+	SampleInterval: time.Minute,
+}
+
+// algorithmSelection is the algorithm selected for an auto metric
+type algorithmSelection struct {
+	algorithm predictionapi.AlgorithmType
+	// score is the backtest score of the algorithm, nil if the selection failed
+	score *accuracy.Score
+	// err is why the selection failed, the last selected or the default algorithm is used then
+	err          error
+	selectedTime time.Time
+	// metric is what the algorithm is selected for, the algorithm is selected again once the metric is changed
+	metric predictionapi.PredictionMetric
+}
+
+// algorithmSelections are the algorithms selected for the auto metrics of a time series prediction, keyed by the
+// resource identifier of the metric
+type algorithmSelections struct {
+	sync.Mutex
+	selections map[string]*algorithmSelection
+	// selecting is the metrics whose algorithms are being selected in the background
+	selecting map[string]bool
+	// applied is the algorithms the predictors are configured with
+	applied map[string]predictionapi.AlgorithmType
+}
+
+// effectivePredictionMetrics returns the metrics of the time series prediction whose auto algorithm is replaced by the
+// selected one, and whether the algorithm of any auto metric is changed. The algorithms are selected again in the
+// background after AutoSelectionInterval or once the metric is changed, so that the backtest does not block the
+// reconciliation, the selected algorithms are applied by the next reconciliation.
+func (tc *Controller) effectivePredictionMetrics(tsPrediction *predictionapi.TimeSeriesPrediction, now time.Time) ([]predictionapi.PredictionMetric, bool) {
+	value, _ := tc.selectionMap.LoadOrStore(GetTimeSeriesPredictionKey(tsPrediction), &algorithmSelections{
+		selections: map[string]*algorithmSelection{},
+		selecting:  map[string]bool{},
+		applied:    map[string]predictionapi.AlgorithmType{},
+	})
+	s := value.(*algorithmSelections)
+	s.Lock()
+	defer s.Unlock()
+
+	var metrics []predictionapi.PredictionMetric
+	changed := false
+	specified := map[string]bool{}
+	for i := range tsPrediction.Spec.PredictionMetrics {
+		metric := &tsPrediction.Spec.PredictionMetrics[i]
+		if metric.Algorithm.AlgorithmType != AlgorithmTypeAuto {
+			metrics = append(metrics, *metric)
+			continue
+		}
+		specified[metric.ResourceIdentifier] = true
+
+		last := s.selections[metric.ResourceIdentifier]
+		sameMetric := last != nil && equality.Semantic.DeepEqual(&last.metric, metric)
+		if (!sameMetric || now.Sub(last.selectedTime) >= tc.AutoSelectionInterval) && !s.selecting[metric.ResourceIdentifier] {
+			s.selecting[metric.ResourceIdentifier] = true
+			go tc.selectAlgorithmInBackground(s, tsPrediction.DeepCopy(), metric.DeepCopy(), now)
+		}
+
+		// the default algorithm is used until the algorithm of the metric is selected
+		algorithm := autoDefaultAlgorithm
+		if sameMetric {
+			algorithm = last.algorithm
+		}
+		if applied, ok := s.applied[metric.ResourceIdentifier]; !ok || applied != algorithm {
+			klog.Infof("Apply algorithm %s for metric %s of %v", algorithm, metric.ResourceIdentifier, klog.KObj(tsPrediction))
+			s.applied[metric.ResourceIdentifier] = algorithm
+			changed = true
+		}
+
+		effective := metric.DeepCopy()
+		effective.Algorithm = autoAlgorithm(metric.Algorithm, algorithm)
+		metrics = append(metrics, *effective)
+	}
+
+	for resourceIdentifier := range s.selections {
+		if !specified[resourceIdentifier] {
+			delete(s.selections, resourceIdentifier)
+		}
+	}
+	for resourceIdentifier := range s.applied {
+		if !specified[resourceIdentifier] {
+			delete(s.applied, resourceIdentifier)
+		}
+	}
+	return metrics, changed
+}
+
+// selectAlgorithmInBackground selects the algorithm of the metric and stores the selection, the last selected
+// algorithm is kept if the selection of the same metric fails.
+func (tc *Controller) selectAlgorithmInBackground(s *algorithmSelections, tsPrediction *predictionapi.TimeSeriesPrediction, metric *predictionapi.PredictionMetric, now time.Time) {
+	selection := tc.selectAlgorithm(context.Background(), tsPrediction, metric, now)
+
+	s.Lock()
+	defer s.Unlock()
+	delete(s.selecting, metric.ResourceIdentifier)
+	last := s.selections[metric.ResourceIdentifier]
+	if selection.err != nil && last != nil && equality.Semantic.DeepEqual(&last.metric, metric) {
+		selection.algorithm = last.algorithm
+	}
+	if last == nil || last.algorithm != selection.algorithm {
+		klog.Infof("Select algorithm %s for metric %s of %v", selection.algorithm, metric.ResourceIdentifier, klog.KObj(tsPrediction))
+	}
+	s.selections[metric.ResourceIdentifier] = selection
+}
+
+// autoCandidates returns the candidates of the auto metric whose predictor is registered
+func (tc *Controller) autoCandidates(algorithm predictionapi.Algorithm) []backtest.Candidate {
+	var candidates []backtest.Candidate
+	for _, candidate := range backtest.DefaultCandidates(autoHistoryLength, autoSampleInterval) {
+		if tc.getPredictor(candidate.Algorithm.AlgorithmType) == nil {
+			continue
+		}
+		candidate.Algorithm = autoAlgorithm(algorithm, candidate.Algorithm.AlgorithmType)
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// selectAlgorithm backtests the candidates on the recent history of the metric and selects the most accurate one
+func (tc *Controller) selectAlgorithm(ctx context.Context, tsPrediction *predictionapi.TimeSeriesPrediction, metric *predictionapi.PredictionMetric, now time.Time) *algorithmSelection {
+	selection := &algorithmSelection{
+		algorithm:    autoDefaultAlgorithm,
+		selectedTime: now,
+		metric:       *metric.DeepCopy(),
+	}
+
+	if tc.History == nil {
+		selection.err = fmt.Errorf("history provider not provisioned")
+		return selection
+	}
+
+	c, err := NewMetricContext(tc.TargetFetcher, tsPrediction, tc.predictorMgr)
+	if err != nil {
+		selection.err = err
+		return selection
+	}
+	namer := c.GetMetricNamer(metric)
+	if namer == nil {
+		selection.err = fmt.Errorf("metric query is not supported")
+		return selection
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, autoBacktestTimeout)
+	defer cancel()
+	end := now.Truncate(autoBacktestConfig.SampleInterval)
+	results, err := backtest.Run(ctx, tc.History, namer, end.Add(-autoBacktestWindow), end, autoBacktestConfig, tc.autoCandidates(metric.Algorithm))
+	if err != nil {
+		selection.err = err
+		return selection
+	}
+
+	best := backtest.Best(results)
+	if best == nil {
+		var errs []string
+		for _, result := range results {
+			if result.Err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", result.Candidate.Name, result.Err))
+			}
+		}
+		selection.err = fmt.Errorf("none of the algorithms is scored: %s", strings.Join(errs, "; "))
+		return selection
+	}
+
+	selection.algorithm = best.Candidate.Algorithm.AlgorithmType
+	selection.score = best.Score
+	return selection
+}
+
+// setAlgorithmCondition sets the selected algorithms and their scores to the AlgorithmSelected condition if the time
+// series prediction has any auto metric
+func (tc *Controller) setAlgorithmCondition(tsPrediction *predictionapi.TimeSeriesPrediction, status *predictionapi.TimeSeriesPredictionStatus) {
+	value, ok := tc.selectionMap.Load(GetTimeSeriesPredictionKey(tsPrediction))
+	if !ok {
+		return
+	}
+	s := value.(*algorithmSelections)
+	s.Lock()
+	defer s.Unlock()
+	selections := s.selections
+	if len(selections) == 0 {
+		return
+	}
+
+	resourceIdentifiers := make([]string, 0, len(selections))
+	for resourceIdentifier := range selections {
+		resourceIdentifiers = append(resourceIdentifiers, resourceIdentifier)
+	}
+	sort.Strings(resourceIdentifiers)
+
+	conditionStatus, reason := metav1.ConditionTrue, known.ReasonTimeSeriesPredictionAlgorithmSelected
+	messages := make([]string, 0, len(selections))
+	for _, resourceIdentifier := range resourceIdentifiers {
+		selection := selections[resourceIdentifier]
+		if selection.err != nil {
+			conditionStatus, reason = metav1.ConditionFalse, known.ReasonTimeSeriesPredictionAlgorithmSelectionFailed
+			messages = append(messages, fmt.Sprintf("%s: %s (selection failed: %v)", resourceIdentifier, selection.algorithm, selection.err))
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s (%s)", resourceIdentifier, selection.algorithm, selection.score))
+	}
+	setCondition(status, known.TimeSeriesPredictionConditionAlgorithmSelected, conditionStatus, reason, strings.Join(messages, "; "))
+}
+
+// autoAlgorithm returns the algorithm of the type with the params of the auto algorithm, or the default params
+func autoAlgorithm(auto predictionapi.Algorithm, algorithmType predictionapi.AlgorithmType) predictionapi.Algorithm {
+	algorithm := predictionapi.Algorithm{AlgorithmType: algorithmType}
+	for _, candidate := range backtest.DefaultCandidates(autoHistoryLength, autoSampleInterval) {
+		if candidate.Algorithm.AlgorithmType == algorithmType {
+			algorithm = candidate.Algorithm
+		}
+	}
+	switch algorithmType {
+	case predictionapi.AlgorithmTypeDSP:
+		if auto.DSP != nil {
+			algorithm.DSP = auto.DSP.DeepCopy()
+		}
+	case predictionapi.AlgorithmTypePercentile:
+		if auto.Percentile != nil {
+			algorithm.Percentile = auto.Percentile.DeepCopy()
+		}
+	}
+	return algorithm
+}
@@ -0,0 +1,142 @@
+package timeseriesprediction
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/prediction"
+	predconf "github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/dsp"
+	"github.com/gocrane/crane/pkg/prediction/percentile"
+	predictormgr "github.com/gocrane/crane/pkg/predictor"
+	"github.com/gocrane/crane/pkg/providers/csv"
+)
+
+type fakePredictorManager struct {
+	predictormgr.Manager
+	predictors map[predictionapi.AlgorithmType]prediction.Interface
+}
+
+func (m *fakePredictorManager) GetPredictor(algorithmType predictionapi.AlgorithmType) prediction.Interface {
+	return m.predictors[algorithmType]
+}
+
+func TestEffectivePredictionMetrics(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(9 * 24 * time.Hour)
+
+	// a series with a daily period is predicted by dsp more accurately
+	var buf bytes.Buffer
+	buf.WriteString("ts,value\n")
+	for ts := start; ts.Before(now); ts = ts.Add(time.Minute) {
+		phase := 2 * math.Pi * float64(ts.Sub(start)) / float64(24*time.Hour)
+		buf.WriteString(fmt.Sprintf("%d,%f\n", ts.Unix(), 5+4*math.Sin(phase)))
+	}
+	history, err := csv.NewProvider(&buf)
+	assert.NoError(t, err)
+
+	cpu := corev1.ResourceCPU
+	tsp := &predictionapi.TimeSeriesPrediction{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec: predictionapi.TimeSeriesPredictionSpec{
+			TargetRef: corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+			PredictionMetrics: []predictionapi.PredictionMetric{
+				{
+					ResourceIdentifier: "cpu",
+					ResourceQuery:      &cpu,
+					Algorithm: predictionapi.Algorithm{
+						AlgorithmType: AlgorithmTypeAuto,
+						Percentile:    &predictionapi.Percentile{SampleInterval: "1m", HistoryLength: "7d", Histogram: predictionapi.HistogramConfig{HalfLife: "12h"}},
+					},
+				},
+				{
+					ResourceIdentifier: "memory",
+					ResourceQuery:      &cpu,
+					Algorithm:          predictionapi.Algorithm{AlgorithmType: predictionapi.AlgorithmTypePercentile},
+				},
+			},
+		},
+	}
+
+	tc := &Controller{
+		History:               history,
+		AutoSelectionInterval: 24 * time.Hour,
+		predictorMgr: &fakePredictorManager{predictors: map[predictionapi.AlgorithmType]prediction.Interface{
+			predictionapi.AlgorithmTypeDSP:        dsp.NewPrediction(nil, nil, predconf.AlgorithmModelConfig{}, nil),
+			predictionapi.AlgorithmTypePercentile: percentile.NewPrediction(nil, nil, predconf.AlgorithmModelConfig{}, nil),
+		}},
+	}
+
+	// waitSelection waits for the background selection of the cpu metric
+	waitSelection := func() {
+		value, _ := tc.selectionMap.Load(GetTimeSeriesPredictionKey(tsp))
+		s := value.(*algorithmSelections)
+		assert.Eventually(t, func() bool {
+			s.Lock()
+			defer s.Unlock()
+			return !s.selecting["cpu"]
+		}, 2*autoBacktestTimeout, 10*time.Millisecond)
+	}
+
+	// the default algorithm is used until the algorithm is selected
+	metrics, changed := tc.effectivePredictionMetrics(tsp, now)
+	assert.True(t, changed)
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, predictionapi.AlgorithmTypePercentile, metrics[0].Algorithm.AlgorithmType)
+	waitSelection()
+
+	metrics, changed = tc.effectivePredictionMetrics(tsp, now)
+	assert.True(t, changed)
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, predictionapi.AlgorithmTypeDSP, metrics[0].Algorithm.AlgorithmType)
+	assert.NotNil(t, metrics[0].Algorithm.DSP)
+	assert.Equal(t, tsp.Spec.PredictionMetrics[1], metrics[1])
+	// the spec is not changed
+	assert.Equal(t, AlgorithmTypeAuto, tsp.Spec.PredictionMetrics[0].Algorithm.AlgorithmType)
+
+	status := &predictionapi.TimeSeriesPredictionStatus{}
+	tc.setAlgorithmCondition(tsp, status)
+	assert.Len(t, status.Conditions, 1)
+	assert.Equal(t, known.TimeSeriesPredictionConditionAlgorithmSelected, status.Conditions[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, status.Conditions[0].Status)
+	assert.True(t, strings.HasPrefix(status.Conditions[0].Message, "cpu: dsp (mape="), status.Conditions[0].Message)
+
+	// the algorithm is not selected again in the interval
+	tc.History = nil
+	metrics, changed = tc.effectivePredictionMetrics(tsp, now.Add(time.Hour))
+	assert.False(t, changed)
+	assert.Equal(t, predictionapi.AlgorithmTypeDSP, metrics[0].Algorithm.AlgorithmType)
+
+	// the last selected algorithm is kept if the selection fails
+	metrics, changed = tc.effectivePredictionMetrics(tsp, now.Add(24*time.Hour))
+	assert.False(t, changed)
+	assert.Equal(t, predictionapi.AlgorithmTypeDSP, metrics[0].Algorithm.AlgorithmType)
+	waitSelection()
+	metrics, changed = tc.effectivePredictionMetrics(tsp, now.Add(24*time.Hour))
+	assert.False(t, changed)
+	assert.Equal(t, predictionapi.AlgorithmTypeDSP, metrics[0].Algorithm.AlgorithmType)
+	tc.setAlgorithmCondition(tsp, status)
+	assert.Equal(t, metav1.ConditionFalse, status.Conditions[0].Status)
+	assert.Equal(t, "cpu: dsp (selection failed: history provider not provisioned)", status.Conditions[0].Message)
+
+	// the default algorithm is used if the changed metric fails to select
+	tsp.Spec.PredictionMetrics[0].Algorithm.Percentile.Percentile = "0.95"
+	metrics, changed = tc.effectivePredictionMetrics(tsp, now.Add(24*time.Hour))
+	assert.True(t, changed)
+	assert.Equal(t, predictionapi.AlgorithmTypePercentile, metrics[0].Algorithm.AlgorithmType)
+	assert.Equal(t, "0.95", metrics[0].Algorithm.Percentile.Percentile)
+	waitSelection()
+	metrics, changed = tc.effectivePredictionMetrics(tsp, now.Add(24*time.Hour))
+	assert.False(t, changed)
+	assert.Equal(t, predictionapi.AlgorithmTypePercentile, metrics[0].Algorithm.AlgorithmType)
+}
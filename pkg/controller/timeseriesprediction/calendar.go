@@ -0,0 +1,77 @@
+package timeseriesprediction
+
+import (
+	"context"
+	"fmt"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/calendar"
+)
+
+// syncCalendar sets the calendar of the time series prediction to the predictors of the metrics, it returns true if
+// the calendar is changed since the last sync, so that the predictions are updated. The last calendar is kept if the
+// calendar fails to load.
+func (tc *Controller) syncCalendar(ctx context.Context, tsPrediction *predictionapi.TimeSeriesPrediction, predictionMetrics []predictionapi.PredictionMetric) bool {
+	var cal *calendar.Calendar
+	var version string
+	if name := tsPrediction.Annotations[known.TimeSeriesPredictionCalendarAnnotation]; name != "" {
+		var err error
+		cal, version, err = tc.loadCalendar(ctx, tsPrediction.Namespace, name)
+		if err != nil {
+			klog.ErrorS(err, "Failed to load calendar.", "timeSeriesPrediction", klog.KObj(tsPrediction), "calendar", name)
+			tc.Recorder.Eventf(tsPrediction, v1.EventTypeWarning, known.ReasonTimeSeriesPredictionCalendarInvalid, "failed to load calendar %s: %v", name, err)
+			return false
+		}
+	}
+
+	c, err := NewMetricContext(tc.TargetFetcher, tsPrediction, tc.predictorMgr)
+	if err != nil {
+		klog.V(4).InfoS("Failed to sync calendar.", "timeSeriesPrediction", klog.KObj(tsPrediction), "err", err)
+		return false
+	}
+	for i := range predictionMetrics {
+		metric := &predictionMetrics[i]
+		predictor, ok := tc.getPredictor(metric.Algorithm.AlgorithmType).(prediction.CalendarInterface)
+		if !ok {
+			if cal != nil {
+				klog.V(4).InfoS("Calendar is not supported by the algorithm.", "timeSeriesPrediction", klog.KObj(tsPrediction), "resourceIdentifier", metric.ResourceIdentifier, "algorithm", metric.Algorithm.AlgorithmType)
+			}
+			continue
+		}
+		if namer := c.GetMetricNamer(metric); namer != nil {
+			predictor.WithCalendar(namer, cal)
+		}
+	}
+
+	key := GetTimeSeriesPredictionKey(tsPrediction)
+	last, _ := tc.calendarMap.Load(key)
+	if version == "" {
+		tc.calendarMap.Delete(key)
+		return last != nil
+	}
+	tc.calendarMap.Store(key, version)
+	return last != version
+}
+
+// loadCalendar returns the calendar in the configmap and the version of it
+func (tc *Controller) loadCalendar(ctx context.Context, namespace, name string) (*calendar.Calendar, string, error) {
+	cm := &v1.ConfigMap{}
+	if err := tc.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cm); err != nil {
+		return nil, "", err
+	}
+	data, ok := cm.Data[calendar.ConfigMapDataKey]
+	if !ok {
+		return nil, "", fmt.Errorf("key %s not found in configmap", calendar.ConfigMapDataKey)
+	}
+	cal, err := calendar.Parse([]byte(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return cal, fmt.Sprintf("%s/%s", name, cm.ResourceVersion), nil
+}
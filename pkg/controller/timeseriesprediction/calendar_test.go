@@ -0,0 +1,119 @@
+package timeseriesprediction
+
+import (
+	"context"
+	"testing"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/calendar"
+	predconf "github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/dsp"
+)
+
+type fakeCalendarPredictor struct {
+	prediction.Interface
+	calendars map[string]*calendar.Calendar
+}
+
+func (p *fakeCalendarPredictor) WithCalendar(namer metricnaming.MetricNamer, c *calendar.Calendar) {
+	p.calendars[namer.BuildUniqueKey()] = c
+}
+
+func TestSyncCalendar(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+
+	cpu := corev1.ResourceCPU
+	tsp := &predictionapi.TimeSeriesPrediction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node-1",
+			Namespace:   "default",
+			Annotations: map[string]string{known.TimeSeriesPredictionCalendarAnnotation: "sales"},
+		},
+		Spec: predictionapi.TimeSeriesPredictionSpec{
+			TargetRef: corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+			PredictionMetrics: []predictionapi.PredictionMetric{
+				{
+					ResourceIdentifier: "cpu",
+					ResourceQuery:      &cpu,
+					Algorithm:          predictionapi.Algorithm{AlgorithmType: predictionapi.AlgorithmTypeDSP},
+				},
+			},
+		},
+	}
+
+	predictor := &fakeCalendarPredictor{
+		Interface: dsp.NewPrediction(nil, nil, predconf.AlgorithmModelConfig{}, nil),
+		calendars: map[string]*calendar.Calendar{},
+	}
+	recorder := record.NewFakeRecorder(10)
+	tc := &Controller{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Recorder: recorder,
+		predictorMgr: &fakePredictorManager{predictors: map[predictionapi.AlgorithmType]prediction.Interface{
+			predictionapi.AlgorithmTypeDSP: predictor,
+		}},
+	}
+
+	// the calendar configmap does not exist
+	assert.False(t, tc.syncCalendar(context.TODO(), tsp, tsp.Spec.PredictionMetrics))
+	assert.Contains(t, <-recorder.Events, "Warning CalendarInvalid failed to load calendar sales")
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "sales", Namespace: "default"},
+		Data: map[string]string{calendar.ConfigMapDataKey: `
+periods:
+- name: sales
+  start: "2022-11-11T00:00:00Z"
+  end: "2022-11-12T00:00:00Z"
+  multiplier: 3
+`},
+	}
+	assert.NoError(t, tc.Client.Create(context.TODO(), cm))
+	assert.True(t, tc.syncCalendar(context.TODO(), tsp, tsp.Spec.PredictionMetrics))
+	assert.Len(t, predictor.calendars, 1)
+	for _, c := range predictor.calendars {
+		assert.NotNil(t, c)
+		assert.Equal(t, "sales", c.Periods[0].Name)
+	}
+
+	// the predictions are updated only if the calendar is changed
+	assert.False(t, tc.syncCalendar(context.TODO(), tsp, tsp.Spec.PredictionMetrics))
+	cm.Data[calendar.ConfigMapDataKey] = `
+periods:
+- name: sales
+  start: "2022-11-11T00:00:00Z"
+  end: "2022-11-12T00:00:00Z"
+  multiplier: 5
+`
+	assert.NoError(t, tc.Client.Update(context.TODO(), cm))
+	assert.True(t, tc.syncCalendar(context.TODO(), tsp, tsp.Spec.PredictionMetrics))
+
+	// the last calendar is kept if the calendar is invalid
+	cm.Data[calendar.ConfigMapDataKey] = "periods: [{name: sales}]"
+	assert.NoError(t, tc.Client.Update(context.TODO(), cm))
+	assert.False(t, tc.syncCalendar(context.TODO(), tsp, tsp.Spec.PredictionMetrics))
+	assert.Contains(t, <-recorder.Events, "Warning CalendarInvalid")
+	for _, c := range predictor.calendars {
+		assert.Equal(t, 5.0, *c.Periods[0].Multiplier)
+	}
+
+	// the calendar is removed with the annotation
+	delete(tsp.Annotations, known.TimeSeriesPredictionCalendarAnnotation)
+	assert.True(t, tc.syncCalendar(context.TODO(), tsp, tsp.Spec.PredictionMetrics))
+	for _, c := range predictor.calendars {
+		assert.Nil(t, c)
+	}
+	assert.False(t, tc.syncCalendar(context.TODO(), tsp, tsp.Spec.PredictionMetrics))
+}
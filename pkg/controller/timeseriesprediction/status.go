@@ -22,6 +22,7 @@ import (
 
 	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/prediction"
 )
 
@@ -32,13 +33,18 @@ const callerFormat = "TimeSeriesPredictionCaller-%s-%s"
 // driven by time tick not by events, because time series prediction need to update the prediction window data to avoid the data is out of date.
 // NOTE: update period is better higher resolution than the algorithm sample interval, reduce the possibility of the data is out date.
 // but it is a final consistent system, so the data will be in date when next update reconcile in controller runtime.
-func (tc *Controller) syncPredictionStatus(ctx context.Context, tsPrediction *predictionapi.TimeSeriesPrediction) (ctrl.Result, error) {
+// metrics are the effective metrics to predict, and the data is forced to predict if their algorithms are reselected.
+func (tc *Controller) syncPredictionStatus(ctx context.Context, tsPrediction *predictionapi.TimeSeriesPrediction, metrics []predictionapi.PredictionMetric, reselected bool) (ctrl.Result, error) {
 	newStatus := tsPrediction.Status.DeepCopy()
 	key := klog.KObj(tsPrediction)
 	if err := tc.Client.Get(ctx, client.ObjectKey{Name: tsPrediction.Name, Namespace: tsPrediction.Namespace}, tsPrediction); err != nil {
 		// If the prediction does not exist any more, we delete the prediction data from the map.
 		if apierrors.IsNotFound(err) {
 			tc.tsPredictionMap.Delete(key)
+			tc.selectionMap.Delete(GetTimeSeriesPredictionKey(tsPrediction))
+			tc.deleteAccuracy(tsPrediction)
+			tc.deleteAnomalies(tsPrediction)
+			tc.calendarMap.Delete(GetTimeSeriesPredictionKey(tsPrediction))
 		}
 		klog.Errorf("Failed to sync PredictionsStatus for %v, err: %v", key, err)
 		// time driven
@@ -50,16 +56,28 @@ func (tc *Controller) syncPredictionStatus(ctx context.Context, tsPrediction *pr
 	windowStart := time.Now()
 	windowEnd := windowStart.Add(time.Duration(tsPrediction.Spec.PredictionWindowSeconds) * time.Second)
 	warnings := tc.isPredictionDataOutDated(windowStart, windowEnd, tsPrediction.Status.PredictionMetrics)
+	if reselected {
+		warnings = append(warnings, "algorithm reselected")
+	}
+	if tc.checkAnomalies(tsPrediction, metrics) {
+		warnings = append(warnings, "model retrained on anomalies")
+	}
+	if tc.syncCalendar(ctx, tsPrediction, metrics) {
+		warnings = append(warnings, "calendar changed")
+	}
 	// force predict and update the status
 	if len(warnings) > 0 {
 		klog.V(4).Infof("Check status predict data is out of date. range: %v, key: %v", fmt.Sprintf("[%v, %v]", windowStart, windowEnd), key)
 		predictionStart := time.Now()
 		// double the time to predict so that crd consumer always see time series range [now, now + PredictionWindowSeconds] in PredictionWindowSeconds window
 		predictionEnd := predictionStart.Add(time.Duration(tsPrediction.Spec.PredictionWindowSeconds) * time.Second * 2)
+		// score the predictions to be replaced before they are gone
+		tc.updateAccuracy(tsPrediction, metrics, newStatus, predictionStart)
+		tc.setAlgorithmCondition(tsPrediction, newStatus)
 
-		predictedData, err := tc.doPredict(tsPrediction, predictionStart, predictionEnd)
+		predictedData, err := tc.doPredict(tsPrediction, metrics, predictionStart, predictionEnd)
 		newStatus.PredictionMetrics = predictedData
-		if len(tsPrediction.Spec.PredictionMetrics) != len(predictedData) || err != nil {
+		if len(metrics) != len(predictedData) || err != nil {
 			klog.V(4).Infof("DoPredict predict data is partial, predictedDataLen: %v, key: %v", len(predictedData), key)
 			setCondition(newStatus, predictionapi.TimeSeriesPredictionConditionReady, metav1.ConditionFalse, known.ReasonTimeSeriesPredictPartial, "not all metric predicted")
 			err = tc.UpdateStatus(ctx, tsPrediction, newStatus)
@@ -82,6 +100,13 @@ func (tc *Controller) syncPredictionStatus(ctx context.Context, tsPrediction *pr
 		return ctrl.Result{RequeueAfter: tc.UpdatePeriod}, nil
 
 	}
+
+	// the algorithm selection may fail or be scored again without changing the algorithm
+	newStatus = tsPrediction.Status.DeepCopy()
+	tc.setAlgorithmCondition(tsPrediction, newStatus)
+	if err := tc.UpdateStatus(ctx, tsPrediction, newStatus); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{RequeueAfter: tc.UpdatePeriod}, nil
 }
 
@@ -109,7 +134,7 @@ func (tc *Controller) getPredictor(algorithmType predictionapi.AlgorithmType) pr
 	return tc.predictorMgr.GetPredictor(algorithmType)
 }
 
-func (tc *Controller) doPredict(tsPrediction *predictionapi.TimeSeriesPrediction, start, end time.Time) ([]predictionapi.PredictionMetricStatus, error) {
+func (tc *Controller) doPredict(tsPrediction *predictionapi.TimeSeriesPrediction, metrics []predictionapi.PredictionMetric, start, end time.Time) ([]predictionapi.PredictionMetricStatus, error) {
 	var result []predictionapi.PredictionMetricStatus
 	c, err := NewMetricContext(tc.TargetFetcher, tsPrediction, tc.predictorMgr)
 	if err != nil {
@@ -117,7 +142,7 @@ func (tc *Controller) doPredict(tsPrediction *predictionapi.TimeSeriesPrediction
 	}
 
 	var errs []error
-	for _, metric := range tsPrediction.Spec.PredictionMetrics {
+	for _, metric := range metrics {
 		status := predictionapi.PredictionMetricStatus{ResourceIdentifier: metric.ResourceIdentifier, Ready: false}
 		predictor := tc.getPredictor(metric.Algorithm.AlgorithmType)
 		if predictor == nil {
@@ -139,6 +164,13 @@ func (tc *Controller) doPredict(tsPrediction *predictionapi.TimeSeriesPrediction
 			errs = append(errs, err)
 			continue
 		}
+		if tsPrediction.Annotations[known.TimeSeriesPredictionIntervalAnnotation] == "true" {
+			bounds, err := queryPredictedBounds(predictor, namer, start, end)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("metric %v predict intervals failed: %v", metric.ResourceIdentifier, err))
+			}
+			data = append(data, bounds...)
+		}
 		predictedData := CommonTimeSeries2ApiTimeSeries(data)
 		if klog.V(6).Enabled() {
 			apiDataBytes, err1 := json.Marshal(predictedData)
@@ -173,6 +205,38 @@ func (tc *Controller) doPredict(tsPrediction *predictionapi.TimeSeriesPrediction
 	return result, err
 }
 
+// queryPredictedBounds returns the lower and upper bounds of the prediction interval labeled with
+// known.PredictionBoundLabel, so that they are distinguished from the point forecast in the status. No bound is
+// returned if the predictor does not predict intervals.
+func queryPredictedBounds(predictor prediction.Interface, namer metricnaming.MetricNamer, start, end time.Time) ([]*common.TimeSeries, error) {
+	intervalPredictor, ok := predictor.(prediction.IntervalInterface)
+	if !ok {
+		klog.V(4).Infof("Predictor %s does not predict intervals, queryExpr: %v", predictor.Name(), namer.BuildUniqueKey())
+		return nil, nil
+	}
+	lower, upper, err := intervalPredictor.QueryPredictedIntervals(context.TODO(), namer, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(withBoundLabel(lower, known.PredictionBoundLower), withBoundLabel(upper, known.PredictionBoundUpper)...), nil
+}
+
+// withBoundLabel returns the copies of the time series with the bound label, the labels of the time series are not
+// changed since they may be shared with the predictor.
+func withBoundLabel(tsList []*common.TimeSeries, bound string) []*common.TimeSeries {
+	var result []*common.TimeSeries
+	for _, ts := range tsList {
+		labels := make([]common.Label, len(ts.Labels), len(ts.Labels)+1)
+		copy(labels, ts.Labels)
+		result = append(result, &common.TimeSeries{
+			Labels:  append(labels, common.Label{Name: known.PredictionBoundLabel, Value: bound}),
+			Samples: ts.Samples,
+		})
+	}
+	return result
+}
+
 func (tc *Controller) UpdateStatus(ctx context.Context, tsPrediction *predictionapi.TimeSeriesPrediction, newStatus *predictionapi.TimeSeriesPredictionStatus) error {
 	if !equality.Semantic.DeepEqual(&tsPrediction.Status, newStatus) {
 		tsPredictionCopy := tsPrediction.DeepCopy()
@@ -225,6 +289,10 @@ func CommonTimeSeries2ApiTimeSeries(tsList []*common.TimeSeries) predictionapi.M
 func setCondition(status *predictionapi.TimeSeriesPredictionStatus, conditionType predictionapi.PredictionConditionType, conditionStatus metav1.ConditionStatus, reason string, message string) {
 	for i := range status.Conditions {
 		if status.Conditions[i].Type == string(conditionType) {
+			// keep the condition unchanged to avoid updating the status if nothing is changed
+			if status.Conditions[i].Status == conditionStatus && status.Conditions[i].Reason == reason && status.Conditions[i].Message == message {
+				return
+			}
 			status.Conditions[i].Status = conditionStatus
 			status.Conditions[i].Reason = reason
 			status.Conditions[i].Message = message
@@ -0,0 +1,24 @@
+package timeseriesprediction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func TestWithBoundLabel(t *testing.T) {
+	labels := make([]common.Label, 1, 2)
+	labels[0] = common.Label{Name: "container", Value: "app"}
+	tsList := []*common.TimeSeries{{Labels: labels, Samples: []common.Sample{{Timestamp: 60, Value: 1}}}}
+
+	upper := withBoundLabel(tsList, known.PredictionBoundUpper)
+	lower := withBoundLabel(tsList, known.PredictionBoundLower)
+
+	assert.Equal(t, []common.Label{{Name: "container", Value: "app"}, {Name: known.PredictionBoundLabel, Value: known.PredictionBoundUpper}}, upper[0].Labels)
+	assert.Equal(t, []common.Label{{Name: "container", Value: "app"}, {Name: known.PredictionBoundLabel, Value: known.PredictionBoundLower}}, lower[0].Labels)
+	assert.Equal(t, tsList[0].Samples, upper[0].Samples)
+	assert.Len(t, tsList[0].Labels, 1, "labels of the predicted time series should not be changed")
+}
@@ -21,6 +21,7 @@ import (
 
 	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
 	predictormgr "github.com/gocrane/crane/pkg/predictor"
+	"github.com/gocrane/crane/pkg/providers"
 	"github.com/gocrane/crane/pkg/utils/target"
 )
 
@@ -32,9 +33,23 @@ type Controller struct {
 	Scheme        *runtime.Scheme
 	RestMapper    meta.RESTMapper
 	ScaleClient   scale.ScalesGetter
+	// History provides the actual values to score the accuracy of the predictions, the accuracy is not scored if it is nil
+	History providers.History
+	// AccuracyWindow is the window of the rolling accuracy of the predictions
+	AccuracyWindow time.Duration
+	// AutoSelectionInterval is the interval to select the algorithm of the auto metrics again
+	AutoSelectionInterval time.Duration
 
 	// Per tsPredictionMap map stores last observed prediction together with a local time when it was observed.
 	tsPredictionMap sync.Map
+	// accuracyMap stores the rolling accuracy of each metric of the time series predictions
+	accuracyMap sync.Map
+	// selectionMap stores the algorithm selected for each auto metric of the time series predictions
+	selectionMap sync.Map
+	// anomalyMap stores the timestamp of the last anomaly seen for each metric of the time series predictions
+	anomalyMap sync.Map
+	// calendarMap stores the version of the calendar applied to each time series prediction
+	calendarMap sync.Map
 
 	lock sync.Mutex
 	// predictors used to do predict and config, maybe the predictor should running as a independent system not as a built-in goroutines evaluator
@@ -47,13 +62,19 @@ func NewController(
 	updatePeriod time.Duration,
 	predictorMgr predictormgr.Manager,
 	targetFetcher target.SelectorFetcher,
+	history providers.History,
+	accuracyWindow time.Duration,
+	autoSelectionInterval time.Duration,
 ) *Controller {
 	return &Controller{
-		Client:        client,
-		Recorder:      recorder,
-		UpdatePeriod:  updatePeriod,
-		predictorMgr:  predictorMgr,
-		TargetFetcher: targetFetcher,
+		Client:                client,
+		Recorder:              recorder,
+		UpdatePeriod:          updatePeriod,
+		predictorMgr:          predictorMgr,
+		TargetFetcher:         targetFetcher,
+		History:               history,
+		AccuracyWindow:        accuracyWindow,
+		AutoSelectionInterval: autoSelectionInterval,
 	}
 }
 
@@ -102,7 +123,12 @@ func (tc *Controller) SetupWithManager(mgr ctrl.Manager, maxConcurrentReconciles
 func (tc *Controller) syncTimeSeriesPrediction(ctx context.Context, tsp *predictionapi.TimeSeriesPrediction) (ctrl.Result, error) {
 	key := GetTimeSeriesPredictionKey(tsp)
 
-	c, err := NewMetricContext(tc.TargetFetcher, tsp, tc.predictorMgr)
+	// the predictors are configured with the effective metrics whose auto algorithm is resolved
+	metrics, reselected := tc.effectivePredictionMetrics(tsp, time.Now())
+	effective := tsp.DeepCopy()
+	effective.Spec.PredictionMetrics = metrics
+
+	c, err := NewMetricContext(tc.TargetFetcher, effective, tc.predictorMgr)
 	if err != nil {
 		klog.ErrorS(err, "Failed to NewMetricContext.")
 		return ctrl.Result{}, err
@@ -111,29 +137,29 @@ func (tc *Controller) syncTimeSeriesPrediction(ctx context.Context, tsp *predict
 	func() {
 		last, ok := tc.tsPredictionMap.Load(key)
 		if !ok { // first time created or system start
-			c.WithApiConfigs(tsp.Spec.PredictionMetrics)
+			c.WithApiConfigs(effective.Spec.PredictionMetrics)
 			return
 		}
 		old, ok := last.(*predictionapi.TimeSeriesPrediction)
 		if !ok {
-			c.WithApiConfigs(tsp.Spec.PredictionMetrics)
+			c.WithApiConfigs(effective.Spec.PredictionMetrics)
 			return
 		}
 		// predictor needs an interface to query the config and then diff.
 		// now just diff the cache in the controller to decide, it can not cover all the cases when users modify the spec
 		for _, oldMetricConf := range old.Spec.PredictionMetrics {
-			if !ExistsPredictionMetric(oldMetricConf, tsp.Spec.PredictionMetrics) {
+			if !ExistsPredictionMetric(oldMetricConf, effective.Spec.PredictionMetrics) {
 				c.DeleteApiConfig(&oldMetricConf)
 			}
 		}
-		for _, newMetricConf := range tsp.Spec.PredictionMetrics {
+		for _, newMetricConf := range effective.Spec.PredictionMetrics {
 			c.WithApiConfig(&newMetricConf)
 		}
 	}()
 
-	tc.tsPredictionMap.Store(key, tsp)
+	tc.tsPredictionMap.Store(key, effective)
 
-	return tc.syncPredictionStatus(ctx, tsp)
+	return tc.syncPredictionStatus(ctx, tsp, metrics, reselected)
 
 }
 
@@ -147,6 +173,10 @@ func (tc *Controller) removeTimeSeriesPrediction(tsp *predictionapi.TimeSeriesPr
 	c.DeleteApiConfigs(tsp.Spec.PredictionMetrics)
 	key := GetTimeSeriesPredictionKey(tsp)
 	tc.tsPredictionMap.Delete(key)
+	tc.selectionMap.Delete(key)
+	tc.deleteAccuracy(tsp)
+	tc.deleteAnomalies(tsp)
+	tc.calendarMap.Delete(key)
 	return nil
 }
 
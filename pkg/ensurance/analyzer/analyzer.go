@@ -56,6 +56,9 @@ type AnomalyAnalyzer struct {
 	actionCh   chan<- executor.AvoidanceExecutor
 
 	evaluator         evaluator.Evaluator
+	opaEvaluator      *evaluator.OpaEvaluator
+	policyLoader      *policyLoader
+	windows           *sampleWindows
 	triggered         map[string]uint64
 	restored          map[string]uint64
 	actionEventStatus map[string]ecache.DetectionStatus
@@ -83,6 +86,9 @@ func NewAnomalyAnalyzer(kubeClient kubernetes.Interface,
 	return &AnomalyAnalyzer{
 		nodeName:              nodeName,
 		evaluator:             expressionEvaluator,
+		opaEvaluator:          evaluator.NewOpaEvaluator(),
+		policyLoader:          newPolicyLoader(kubeClient),
+		windows:               newSampleWindows(),
 		actionCh:              noticeCh,
 		recorder:              recorder,
 		podLister:             podInformer.Lister(),
@@ -174,13 +180,15 @@ func (s *AnomalyAnalyzer) Analyze(state map[string][]common.TimeSeries) {
 
 	// step 2: do analyze for nodeQOSs
 	var actionContexts []ecache.ActionContext
+	var ruleKeys = make(map[string]struct{})
 	for _, n := range nodeQOSs {
 		klog.V(6).Infof("Processing NodeQOS %s", n.Name)
 
 		for _, r := range n.Spec.Rules {
 			var key = strings.Join([]string{n.Name, r.Name}, ".")
 			klog.V(6).Infof("Processing Rule %s", key)
-			actionContext, err := s.analyze(key, r, state)
+			ruleKeys[key] = struct{}{}
+			actionContext, err := s.analyze(key, n, r, actionMap[r.AvoidanceActionName], state)
 			if err != nil {
 				metrics.UpdateAnalyzerWithKeyStatus(metrics.AnalyzeTypeAnalyzeError, key, 1.0)
 				klog.Errorf("Failed to analyze, %v.", err)
@@ -193,6 +201,10 @@ func (s *AnomalyAnalyzer) Analyze(state map[string][]common.TimeSeries) {
 		}
 	}
 
+	// drop the compiled policies of deleted rules and the samples of disappeared series
+	s.opaEvaluator.Forget(ruleKeys)
+	s.windows.prune()
+
 	klog.V(6).Infof("Analyze actionContexts: %#v", actionContexts)
 
 	//step 3 : merge
@@ -220,24 +232,45 @@ func (s *AnomalyAnalyzer) getSeries(state []common.TimeSeries, selector *metav1.
 	return series, nil
 }
 
-func (s *AnomalyAnalyzer) trigger(series []common.TimeSeries, object ensuranceapi.Rule) bool {
+func (s *AnomalyAnalyzer) trigger(series []common.TimeSeries, object ensuranceapi.Rule, evaluation ruleEvaluation) bool {
 	var aboveThreshold bool
 	for _, ts := range series {
-		triggered := s.evaluator.EvalWithMetric(object.MetricRule.Name, float64(object.MetricRule.Value.Value()), ts.Samples[0].Value)
+		samples := s.windows.record(object.MetricRule.Name, ts, evaluation.Window)
+		if len(samples) < evaluation.Window {
+			klog.V(4).Infof("Rule %s has %d samples, waiting for window %d, metrics labels: %s/%s",
+				object.Name+"."+object.MetricRule.Name,
+				len(samples),
+				evaluation.Window,
+				common.GetValueByName(ts.Labels, common.LabelNamePodNamespace),
+				common.GetValueByName(ts.Labels, common.LabelNamePodName))
+			continue
+		}
+
+		value, err := aggregate(samples, evaluation.Aggregation)
+		if err != nil {
+			klog.Errorf("Failed to aggregate samples for rule %s: %v", object.Name+"."+object.MetricRule.Name, err)
+			continue
+		}
+
+		triggered := s.evaluator.EvalWithOperator(object.MetricRule.Name, evaluation.Operator, float64(object.MetricRule.Value.Value()), value)
 
-		klog.V(4).Infof("Evaluation result is %v, rule: %s, watermark: %f, current metrics: %f, metrics labels: %s/%s",
+		klog.V(4).Infof("Evaluation result is %v, rule: %s, watermark: %s %f, current metrics: %s %f, metrics labels: %s/%s",
 			triggered,
 			object.Name+"."+object.MetricRule.Name,
+			evaluation.Operator,
 			float64(object.MetricRule.Value.Value()),
-			ts.Samples[0].Value,
+			evaluation.Aggregation,
+			value,
 			common.GetValueByName(ts.Labels, common.LabelNamePodNamespace),
 			common.GetValueByName(ts.Labels, common.LabelNamePodName))
 
 		if triggered {
-			klog.Warningf("Rule %s is triggered, watermark: %f, current metrics: %f, metrics labels: %s/%s",
+			klog.Warningf("Rule %s is triggered, watermark: %s %f, current metrics: %s %f, metrics labels: %s/%s",
 				object.Name+"."+object.MetricRule.Name,
+				evaluation.Operator,
 				float64(object.MetricRule.Value.Value()),
-				ts.Samples[0].Value,
+				evaluation.Aggregation,
+				value,
 				common.GetValueByName(ts.Labels, common.LabelNamePodNamespace),
 				common.GetValueByName(ts.Labels, common.LabelNamePodName))
 			aboveThreshold = true
@@ -246,15 +279,50 @@ func (s *AnomalyAnalyzer) trigger(series []common.TimeSeries, object ensuranceap
 	return aboveThreshold
 }
 
-func (s *AnomalyAnalyzer) analyze(key string, rule ensuranceapi.Rule, stateMap map[string][]common.TimeSeries) (ecache.ActionContext, error) {
+func (s *AnomalyAnalyzer) analyze(key string, nodeQOS *ensuranceapi.NodeQOS, rule ensuranceapi.Rule, action *ensuranceapi.AvoidanceAction, stateMap map[string][]common.TimeSeries) (ecache.ActionContext, error) {
 	klog.V(4).Infof("Starting analyze")
 	var actionContext = ecache.ActionContext{Strategy: rule.Strategy, RuleName: rule.Name, ActionName: rule.AvoidanceActionName}
 
+	// rule with a rego policy is evaluated against the whole stateMap
+	policy, err := s.policyLoader.getPolicy(key, nodeQOS, rule)
+	if err != nil {
+		return actionContext, err
+	}
+	if policy != nil {
+		triggered, err := s.opaEvaluator.EvalWithPolicy(policy.key, policy.version, policy.policy, buildPolicyInput(stateMap))
+		if err != nil {
+			return actionContext, err
+		}
+		klog.V(4).Infof("Evaluation result is %v, rule: %s, policy version: %s", triggered, key, policy.version)
+		s.computeActionContext(triggered, key, rule, &actionContext)
+		return actionContext, nil
+	}
+
+	if rule.MetricRule == nil {
+		return actionContext, fmt.Errorf("rule %s has neither metric rule nor rego policy", key)
+	}
+
 	state, ok := stateMap[rule.MetricRule.Name]
 	if !ok {
 		return actionContext, fmt.Errorf("metric %s not found", rule.MetricRule.Name)
 	}
 
+	evaluation, err := getRuleEvaluation(nodeQOS, rule)
+	if err != nil {
+		return actionContext, fmt.Errorf("rule %s: %v", key, err)
+	}
+	// throttle and eviction lower the usage, so a less-than rule can be closed by them only if the watermarks of its
+	// metric are lower bounds, e.g. the idle cpu, and the gap is the distance below the watermark
+	if action != nil && (action.Spec.Throttle != nil || action.Spec.Eviction != nil) {
+		if lowerBound, ok := executor.LowerBoundMetric(executor.WatermarkMetric(rule.MetricRule.Name)); ok && lowerBound != evaluator.LessOperator(evaluation.Operator) {
+			return actionContext, fmt.Errorf("rule %s: operator %q is not supported by metric %s for throttle and eviction", key, evaluation.Operator, rule.MetricRule.Name)
+		}
+	}
+	// the gaps to the watermarks are computed from the latest metric values, a rate can not be compared with them
+	if evaluation.Aggregation == AggregationRate && action != nil && (action.Spec.Throttle != nil || action.Spec.Eviction != nil) {
+		return actionContext, fmt.Errorf("rule %s: aggregation %q is only supported by the actions disabling scheduling", key, evaluation.Aggregation)
+	}
+
 	//step1: get series from value
 	series, err := s.getSeries(state, rule.MetricRule.Selector, rule.MetricRule.Name)
 	if err != nil {
@@ -262,7 +330,7 @@ func (s *AnomalyAnalyzer) analyze(key string, rule ensuranceapi.Rule, stateMap m
 	}
 
 	//step2: check if triggered for NodeQOSEnsurance
-	aboveThreshold := s.trigger(series, rule)
+	aboveThreshold := s.trigger(series, rule, evaluation)
 
 	//step3: check is triggered action or restored, set the detection
 	s.computeActionContext(aboveThreshold, key, rule, &actionContext)
@@ -339,6 +407,10 @@ func (s *AnomalyAnalyzer) merge(stateMap map[string][]common.TimeSeries, actionM
 		if action.Spec.Throttle != nil {
 			throttlePods, throttleUpPods := s.getThrottlePods(context, action, stateMap)
 
+			// the throttled pods are restored by the rule itself rather than the reconciliation of executor
+			if s.actionActive(context) {
+				executor.ThrottleExecutor.ThrottleActiveRules = appendRule(executor.ThrottleExecutor.ThrottleActiveRules, context)
+			}
 			// combine the throttle watermark
 			combineThrottleWatermark(&executor.ThrottleExecutor, context)
 			// combine the replicated pod
@@ -429,6 +501,12 @@ func (s *AnomalyAnalyzer) actionTriggered(ac ecache.ActionContext) bool {
 	return false
 }
 
+// actionActive returns true if the rule of action context is triggered and not restored yet
+func (s *AnomalyAnalyzer) actionActive(ac ecache.ActionContext) bool {
+	var key = strings.Join([]string{ac.NodeQOS.Name, ac.RuleName}, "/")
+	return s.actionEventStatus[key].IsTriggered
+}
+
 func (s *AnomalyAnalyzer) getThrottlePods(actionCtx ecache.ActionContext, action *ensuranceapi.AvoidanceAction, stateMap map[string][]common.TimeSeries) ([]podinfo.PodContext, []podinfo.PodContext) {
 
 	throttlePods, throttleUpPods := []podinfo.PodContext{}, []podinfo.PodContext{}
@@ -530,6 +608,7 @@ func (s *AnomalyAnalyzer) mergeSchedulingActions(actionContexts []ecache.ActionC
 			if ac.Triggered {
 				metrics.UpdateAnalyzerStatus(metrics.AnalyzeTypeEnableScheduling, float64(0))
 				s.ToggleScheduleSetting(avoidanceExecutor, true)
+				avoidanceExecutor.ScheduleExecutor.Rules = appendRule(nil, ac)
 				break
 			}
 
@@ -542,6 +621,7 @@ func (s *AnomalyAnalyzer) mergeSchedulingActions(actionContexts []ecache.ActionC
 				if !now.After(s.lastTriggeredTime.Add(time.Duration(action.Spec.CoolDownSeconds) * time.Second)) {
 					metrics.UpdateAnalyzerStatus(metrics.AnalyzeTypeEnableScheduling, float64(0))
 					s.ToggleScheduleSetting(avoidanceExecutor, true)
+					avoidanceExecutor.ScheduleExecutor.Rules = appendRule(nil, ac)
 					break
 				} else {
 					metrics.UpdateAnalyzerStatus(metrics.AnalyzeTypeEnableScheduling, float64(1))
@@ -610,8 +690,9 @@ func combineThrottleWatermark(e *executor.ThrottleExecutor, ac ecache.ActionCont
 	}
 
 	if ac.Triggered {
+		e.ThrottleDownRules = appendRule(e.ThrottleDownRules, ac)
 		for _, ensurance := range ac.NodeQOS.Spec.Rules {
-			if ensurance.Name == ac.RuleName {
+			if ensurance.Name == ac.RuleName && ensurance.MetricRule != nil {
 				if e.ThrottleDownWatermark == nil {
 					e.ThrottleDownWatermark = make(map[executor.WatermarkMetric]*executor.Watermark)
 				}
@@ -629,8 +710,9 @@ func combineThrottleWatermark(e *executor.ThrottleExecutor, ac ecache.ActionCont
 	}
 
 	if ac.Restored {
+		e.ThrottleUpRules = appendRule(e.ThrottleUpRules, ac)
 		for _, ensurance := range ac.NodeQOS.Spec.Rules {
-			if ensurance.Name == ac.RuleName {
+			if ensurance.Name == ac.RuleName && ensurance.MetricRule != nil {
 				if e.ThrottleUpWatermark == nil {
 					e.ThrottleUpWatermark = make(map[executor.WatermarkMetric]*executor.Watermark)
 				}
@@ -652,8 +734,9 @@ func combineEvictWatermark(e *executor.EvictExecutor, ac ecache.ActionContext) {
 		return
 	}
 
+	e.EvictRules = appendRule(e.EvictRules, ac)
 	for _, ensurance := range ac.NodeQOS.Spec.Rules {
-		if ensurance.Name == ac.RuleName {
+		if ensurance.Name == ac.RuleName && ensurance.MetricRule != nil {
 			if e.EvictWatermark == nil {
 				e.EvictWatermark = make(map[executor.WatermarkMetric]*executor.Watermark)
 			}
@@ -668,3 +751,14 @@ func combineEvictWatermark(e *executor.EvictExecutor, ac ecache.ActionContext) {
 		klog.V(6).Infof("EvictWatermark info: metric: %s, value: %#v", watermarkMetric, watermarks)
 	}
 }
+
+// appendRule appends the rule of the action context in the format of <NodeQOS>/<Rule> if it is not in rules
+func appendRule(rules []string, ac ecache.ActionContext) []string {
+	var key = strings.Join([]string{ac.NodeQOS.Name, ac.RuleName}, "/")
+	for _, r := range rules {
+		if r == key {
+			return rules
+		}
+	}
+	return append(rules, key)
+}
@@ -0,0 +1,207 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/analyzer/evaluator"
+	ecache "github.com/gocrane/crane/pkg/ensurance/cache"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/ensurance/executor"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func TestAnalyzeLessOperator(t *testing.T) {
+	stateMap := map[string][]common.TimeSeries{
+		string(types.MetricNameCpuTotalUsage):    {{Samples: []common.Sample{{Value: 1000, Timestamp: 1}}}},
+		string(types.MetricNameExclusiveCPUIdle): {{Samples: []common.Sample{{Value: 1000, Timestamp: 1}}}},
+	}
+	throttle := &ensuranceapi.AvoidanceAction{Spec: ensuranceapi.AvoidanceActionSpec{Throttle: &ensuranceapi.ThrottleAction{}}}
+	eviction := &ensuranceapi.AvoidanceAction{Spec: ensuranceapi.AvoidanceActionSpec{Eviction: &ensuranceapi.EvictionAction{}}}
+
+	tests := []struct {
+		name     string
+		metric   types.MetricName
+		operator string
+		action   *ensuranceapi.AvoidanceAction
+		wantErr  bool
+	}{
+		{
+			name:     "disable scheduling",
+			metric:   types.MetricNameCpuTotalUsage,
+			operator: "<",
+			action:   &ensuranceapi.AvoidanceAction{},
+		},
+		{
+			name:     "throttle",
+			metric:   types.MetricNameCpuTotalUsage,
+			operator: "<",
+			action:   throttle,
+			wantErr:  true,
+		},
+		{
+			name:     "eviction",
+			metric:   types.MetricNameCpuTotalUsage,
+			operator: "<",
+			action:   eviction,
+			wantErr:  true,
+		},
+		{
+			name:     "throttle on lower bound metric",
+			metric:   types.MetricNameExclusiveCPUIdle,
+			operator: "<",
+			action:   throttle,
+		},
+		{
+			name:     "eviction on lower bound metric",
+			metric:   types.MetricNameExclusiveCPUIdle,
+			operator: "<=",
+			action:   eviction,
+		},
+		{
+			name:     "greater than on lower bound metric",
+			metric:   types.MetricNameExclusiveCPUIdle,
+			operator: ">",
+			action:   throttle,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeQOS := &ensuranceapi.NodeQOS{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "idle",
+					Annotations: map[string]string{
+						known.NodeQOSRuleEvaluationAnnotationPrefix + "/cpu-idle": fmt.Sprintf(`{"operator": %q}`, tt.operator),
+					},
+				},
+				Spec: ensuranceapi.NodeQOSSpec{
+					Rules: []ensuranceapi.Rule{{
+						Name:               "cpu-idle",
+						AvoidanceThreshold: 1,
+						RestoreThreshold:   1,
+						MetricRule: &ensuranceapi.MetricRule{
+							Name:  string(tt.metric),
+							Value: resource.MustParse("4000"),
+						},
+					}},
+				},
+			}
+			s := &AnomalyAnalyzer{
+				evaluator:    evaluator.NewExpressionEvaluator(),
+				policyLoader: newPolicyLoader(nil),
+				windows:      newSampleWindows(),
+				triggered:    make(map[string]uint64),
+				restored:     make(map[string]uint64),
+			}
+			rule := nodeQOS.Spec.Rules[0]
+			actionContext, err := s.analyze("idle.cpu-idle", nodeQOS, rule, tt.action, stateMap)
+			if tt.wantErr {
+				if err == nil || actionContext.Triggered {
+					t.Fatalf("expect operator %s on %s with action %s to be rejected", tt.operator, tt.metric, tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !actionContext.Triggered {
+				t.Errorf("expect the rule to be triggered")
+			}
+		})
+	}
+}
+
+func TestAnalyzeRateAggregation(t *testing.T) {
+	nodeQOS := &ensuranceapi.NodeQOS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "growth",
+			Annotations: map[string]string{
+				known.NodeQOSRuleEvaluationAnnotationPrefix + "/cpu-growth": `{"aggregation": "rate", "window": 2}`,
+			},
+		},
+		Spec: ensuranceapi.NodeQOSSpec{
+			Rules: []ensuranceapi.Rule{{
+				Name:               "cpu-growth",
+				AvoidanceThreshold: 1,
+				RestoreThreshold:   1,
+				MetricRule: &ensuranceapi.MetricRule{
+					Name:  string(types.MetricNameCpuTotalUsage),
+					Value: resource.MustParse("10"),
+				},
+			}},
+		},
+	}
+	stateMap := map[string][]common.TimeSeries{
+		string(types.MetricNameCpuTotalUsage): {{Samples: []common.Sample{{Value: 1000, Timestamp: 1}}}},
+	}
+
+	tests := []struct {
+		name    string
+		action  *ensuranceapi.AvoidanceAction
+		wantErr bool
+	}{
+		{
+			name:   "disable scheduling",
+			action: &ensuranceapi.AvoidanceAction{},
+		},
+		{
+			name:    "throttle",
+			action:  &ensuranceapi.AvoidanceAction{Spec: ensuranceapi.AvoidanceActionSpec{Throttle: &ensuranceapi.ThrottleAction{}}},
+			wantErr: true,
+		},
+		{
+			name:    "eviction",
+			action:  &ensuranceapi.AvoidanceAction{Spec: ensuranceapi.AvoidanceActionSpec{Eviction: &ensuranceapi.EvictionAction{}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AnomalyAnalyzer{
+				evaluator:    evaluator.NewExpressionEvaluator(),
+				policyLoader: newPolicyLoader(nil),
+				windows:      newSampleWindows(),
+				triggered:    make(map[string]uint64),
+				restored:     make(map[string]uint64),
+			}
+			_, err := s.analyze("growth.cpu-growth", nodeQOS, nodeQOS.Spec.Rules[0], tt.action, stateMap)
+			if tt.wantErr != (err != nil) {
+				t.Errorf("expect error %v for rate rule with action %s, got %v", tt.wantErr, tt.name, err)
+			}
+		})
+	}
+}
+
+func TestCombineWatermarkOfTriggeredRule(t *testing.T) {
+	nodeQOS := &ensuranceapi.NodeQOS{
+		ObjectMeta: metav1.ObjectMeta{Name: "busy"},
+		Spec: ensuranceapi.NodeQOSSpec{
+			Rules: []ensuranceapi.Rule{{
+				Name: "cpu-usage",
+				MetricRule: &ensuranceapi.MetricRule{
+					Name:  string(types.MetricNameCpuTotalUsage),
+					Value: resource.MustParse("4000"),
+				},
+			}},
+		},
+	}
+
+	var e executor.ThrottleExecutor
+	combineThrottleWatermark(&e, ecache.ActionContext{Triggered: true, RuleName: "cpu-usage", NodeQOS: nodeQOS})
+	watermark := e.ThrottleDownWatermark[executor.CpuUsage]
+	if watermark == nil || watermark.PopSmallest().Value() != 4000 {
+		t.Fatalf("unexpected throttle down watermark %v", watermark)
+	}
+	if len(e.ThrottleDownRules) != 1 || e.ThrottleDownRules[0] != "busy/cpu-usage" {
+		t.Errorf("unexpected throttle down rules %v", e.ThrottleDownRules)
+	}
+}
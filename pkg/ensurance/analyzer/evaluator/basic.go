@@ -1,16 +1,40 @@
 package evaluator
 
+import (
+	"fmt"
+)
+
 type ExpressionEvaluator struct {
+	opa *OpaEvaluator
 }
 
 func NewExpressionEvaluator() Evaluator {
-	return &ExpressionEvaluator{}
+	return &ExpressionEvaluator{
+		opa: NewOpaEvaluator(),
+	}
 }
 
 func (c *ExpressionEvaluator) EvalWithMetric(metricName string, targetValue float64, value float64) bool {
-	return value > targetValue
+	return c.EvalWithOperator(metricName, OperatorGreaterThan, targetValue, value)
 }
 
-func (c *ExpressionEvaluator) EvalWithRawQuery(input string, rule string) bool {
+func (c *ExpressionEvaluator) EvalWithOperator(metricName string, operator Operator, targetValue float64, value float64) bool {
+	switch operator {
+	case OperatorGreaterThan:
+		return value > targetValue
+	case OperatorGreaterOrEqual:
+		return value >= targetValue
+	case OperatorLessThan:
+		return value < targetValue
+	case OperatorLessOrEqual:
+		return value <= targetValue
+	}
 	return false
 }
+
+// EvalWithRawQuery evaluates a single rego expression with the json encoded input,
+// e.g. "input.node.cpu_total_utilization > 80".
+func (c *ExpressionEvaluator) EvalWithRawQuery(input string, rule string) bool {
+	policy := fmt.Sprintf("package crane.ensurance.expression\n\n%s {\n\t%s\n}\n", TriggerRuleName, rule)
+	return c.opa.EvalWithRawQuery(input, policy)
+}
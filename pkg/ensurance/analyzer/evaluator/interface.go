@@ -1,6 +1,31 @@
 package evaluator
 
+// Operator is the comparison operator between the metric value and the target value.
+type Operator string
+
+const (
+	OperatorGreaterThan    Operator = ">"
+	OperatorGreaterOrEqual Operator = ">="
+	OperatorLessThan       Operator = "<"
+	OperatorLessOrEqual    Operator = "<="
+)
+
+// ValidOperator returns true if the operator is supported.
+func ValidOperator(operator Operator) bool {
+	switch operator {
+	case OperatorGreaterThan, OperatorGreaterOrEqual, OperatorLessThan, OperatorLessOrEqual:
+		return true
+	}
+	return false
+}
+
+// LessOperator returns true if the operator is triggered when the metric value is below the target value.
+func LessOperator(operator Operator) bool {
+	return operator == OperatorLessThan || operator == OperatorLessOrEqual
+}
+
 type Evaluator interface {
 	EvalWithMetric(metricName string, targetValue float64, value float64) bool
+	EvalWithOperator(metricName string, operator Operator, targetValue float64, value float64) bool
 	EvalWithRawQuery(input string, rule string) bool
 }
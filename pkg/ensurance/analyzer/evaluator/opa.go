@@ -1,23 +1,168 @@
 package evaluator
 
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"k8s.io/klog/v2"
+)
+
+// TriggerRuleName is the rule a policy must define, the policy is triggered when it evaluates to true.
+const TriggerRuleName = "trigger"
+
+const metricPolicy = `package crane.ensurance.metric
+
+trigger {
+	input.operator == ">"
+	input.value > input.target
+}
+
+trigger {
+	input.operator == ">="
+	input.value >= input.target
+}
+
+trigger {
+	input.operator == "<"
+	input.value < input.target
+}
+
+trigger {
+	input.operator == "<="
+	input.value <= input.target
+}
+`
+
+type preparedPolicy struct {
+	version string
+	query   rego.PreparedEvalQuery
+}
+
+// OpaEvaluator evaluates rego policies, policies are compiled once and cached per key and version.
 type OpaEvaluator struct {
+	lock     sync.Mutex
+	policies map[string]*preparedPolicy
 }
 
-func NewOpaEvaluator() Evaluator {
-	return &OpaEvaluator{}
+func NewOpaEvaluator() *OpaEvaluator {
+	return &OpaEvaluator{
+		policies: make(map[string]*preparedPolicy),
+	}
 }
 
 func (c *OpaEvaluator) EvalWithMetric(metricName string, targetValue float64, value float64) bool {
+	return c.EvalWithOperator(metricName, OperatorGreaterThan, targetValue, value)
+}
 
-	// step1 splice policy and input strings
-	// step2 opa.New().WithPolicyBytes
-	// step3 rego.Eval
-	// step4 transfer opa.result to bool
-	// return
+func (c *OpaEvaluator) EvalWithOperator(metricName string, operator Operator, targetValue float64, value float64) bool {
+	input := map[string]interface{}{
+		"metric":   metricName,
+		"operator": string(operator),
+		"target":   targetValue,
+		"value":    value,
+	}
 
-	return false
+	triggered, err := c.EvalWithPolicy("metric", "", metricPolicy, input)
+	if err != nil {
+		klog.Errorf("Failed to evaluate metric %s: %v", metricName, err)
+		return false
+	}
+	return triggered
 }
 
+// EvalWithRawQuery evaluates the rego policy with the json encoded input.
 func (c *OpaEvaluator) EvalWithRawQuery(input string, rule string) bool {
-	return false
+	var document interface{}
+	if err := json.Unmarshal([]byte(input), &document); err != nil {
+		klog.Errorf("Failed to decode input for rego policy: %v", err)
+		return false
+	}
+
+	triggered, err := c.EvalWithPolicy(policyHash(rule), "", rule, document)
+	if err != nil {
+		klog.Errorf("Failed to evaluate rego policy: %v", err)
+		return false
+	}
+	return triggered
+}
+
+// EvalWithPolicy evaluates the trigger rule of the policy with input. The compiled policy is cached by key,
+// and it is recompiled only when the version changes. If version is empty, the policy hash is used as version.
+func (c *OpaEvaluator) EvalWithPolicy(key string, version string, policy string, input interface{}) (bool, error) {
+	if len(version) == 0 {
+		version = policyHash(policy)
+	}
+
+	query, err := c.prepare(key, version, policy)
+	if err != nil {
+		return false, err
+	}
+
+	rs, err := query.Eval(context.TODO(), rego.EvalInput(input))
+	if err != nil {
+		return false, fmt.Errorf("failed to eval policy %s: %v", key, err)
+	}
+
+	return resultTrue(rs), nil
+}
+
+// Forget drops the compiled policies whose key is not in keys.
+func (c *OpaEvaluator) Forget(keys map[string]struct{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key := range c.policies {
+		if _, ok := keys[key]; !ok {
+			delete(c.policies, key)
+		}
+	}
+}
+
+func (c *OpaEvaluator) prepare(key string, version string, policy string) (rego.PreparedEvalQuery, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if p, ok := c.policies[key]; ok && p.version == version {
+		return p.query, nil
+	}
+
+	module, err := ast.ParseModule(key+".rego", policy)
+	if err != nil {
+		return rego.PreparedEvalQuery{}, fmt.Errorf("failed to parse policy %s: %v", key, err)
+	}
+	if module == nil {
+		return rego.PreparedEvalQuery{}, fmt.Errorf("policy %s is empty", key)
+	}
+
+	query, err := rego.New(
+		rego.Query(module.Package.Path.String()+"."+TriggerRuleName),
+		rego.ParsedModule(module),
+	).PrepareForEval(context.TODO())
+	if err != nil {
+		return rego.PreparedEvalQuery{}, fmt.Errorf("failed to compile policy %s: %v", key, err)
+	}
+
+	klog.V(4).Infof("Compiled rego policy %s, version %s", key, version)
+	c.policies[key] = &preparedPolicy{version: version, query: query}
+	return query, nil
+}
+
+// resultTrue returns true only if the query is defined and evaluated to boolean true.
+func resultTrue(rs rego.ResultSet) bool {
+	if len(rs) != 1 || len(rs[0].Expressions) != 1 {
+		return false
+	}
+	value, ok := rs[0].Expressions[0].Value.(bool)
+	return ok && value
+}
+
+func policyHash(policy string) string {
+	sum := sha256.Sum256([]byte(policy))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+const multiMetricPolicy = `package crane.ensurance.cpu
+
+trigger {
+	input.node.cpu_total_utilization > 80
+	input.node.cpu_load_5_min > input.node.cpu_core_numbers * 1.5
+}
+`
+
+func TestOpaEvaluatorEvalWithPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  map[string]interface{}
+		expect bool
+	}{
+		{
+			name: "all conditions met",
+			input: map[string]interface{}{"node": map[string]interface{}{
+				"cpu_total_utilization": 90, "cpu_load_5_min": 13, "cpu_core_numbers": 8,
+			}},
+			expect: true,
+		},
+		{
+			name: "load below cores",
+			input: map[string]interface{}{"node": map[string]interface{}{
+				"cpu_total_utilization": 90, "cpu_load_5_min": 10, "cpu_core_numbers": 8,
+			}},
+			expect: false,
+		},
+		{
+			name:   "metric missing",
+			input:  map[string]interface{}{"node": map[string]interface{}{}},
+			expect: false,
+		},
+	}
+
+	e := NewOpaEvaluator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			triggered, err := e.EvalWithPolicy("nodeqos.cpu", "1", multiMetricPolicy, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if triggered != tt.expect {
+				t.Errorf("expect %v, got %v", tt.expect, triggered)
+			}
+		})
+	}
+}
+
+func TestOpaEvaluatorPolicyCache(t *testing.T) {
+	e := NewOpaEvaluator()
+	input := map[string]interface{}{"value": 1}
+
+	if _, err := e.EvalWithPolicy("rule", "1", "package a\n\ntrigger { input.value > 0 }", input); err != nil {
+		t.Fatal(err)
+	}
+	// same version, the cached policy is used even if the text changes
+	triggered, _ := e.EvalWithPolicy("rule", "1", "package a\n\ntrigger { input.value > 10 }", input)
+	if !triggered {
+		t.Errorf("expect cached policy to be used")
+	}
+	// new version, the policy is recompiled
+	triggered, _ = e.EvalWithPolicy("rule", "2", "package a\n\ntrigger { input.value > 10 }", input)
+	if triggered {
+		t.Errorf("expect policy to be recompiled for the new version")
+	}
+
+	if _, err := e.EvalWithPolicy("invalid", "1", "package a\n\ntrigger {", input); err == nil {
+		t.Errorf("expect error for invalid policy")
+	}
+}
+
+func TestEvalWithRawQuery(t *testing.T) {
+	if !NewExpressionEvaluator().EvalWithRawQuery(`{"node": {"cpu_total_utilization": 85}}`, "input.node.cpu_total_utilization > 80") {
+		t.Errorf("expect expression to be triggered")
+	}
+	if NewOpaEvaluator().EvalWithRawQuery(`{"value": 1}`, "package a\n\ntrigger { input.value > 10 }") {
+		t.Errorf("expect policy not to be triggered")
+	}
+}
+
+func TestOpaEvaluatorEvalWithMetric(t *testing.T) {
+	e := NewOpaEvaluator()
+	if !e.EvalWithMetric("cpu_total_usage", 10, 11) {
+		t.Errorf("expect 11 > 10 to be triggered")
+	}
+	if e.EvalWithMetric("cpu_total_usage", 10, 9) {
+		t.Errorf("expect 9 > 10 not to be triggered")
+	}
+}
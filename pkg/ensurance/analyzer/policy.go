@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+// configMapRefreshInterval is how often a policy ConfigMap is refetched from api server.
+const configMapRefreshInterval = time.Minute
+
+// regoPolicy is a rego policy attached to a NodeQOS rule.
+type regoPolicy struct {
+	// key identifies the rule, e.g. <nodeqos>.<rule>
+	key string
+	// version changes when the policy source changes
+	version string
+	policy  string
+}
+
+type cachedConfigMap struct {
+	resourceVersion string
+	policy          string
+	fetchTime       time.Time
+}
+
+// policyLoader resolves rego policies from NodeQOS annotations, inline or from ConfigMap.
+type policyLoader struct {
+	kubeClient kubernetes.Interface
+
+	lock       sync.Mutex
+	configMaps map[string]cachedConfigMap
+}
+
+func newPolicyLoader(kubeClient kubernetes.Interface) *policyLoader {
+	return &policyLoader{
+		kubeClient: kubeClient,
+		configMaps: make(map[string]cachedConfigMap),
+	}
+}
+
+// getPolicy returns the rego policy of the rule, nil if the rule has no policy.
+func (l *policyLoader) getPolicy(key string, nodeQOS *ensuranceapi.NodeQOS, rule ensuranceapi.Rule) (*regoPolicy, error) {
+	if nodeQOS == nil || nodeQOS.Annotations == nil {
+		return nil, nil
+	}
+
+	if policy, ok := nodeQOS.Annotations[known.NodeQOSRegoPolicyAnnotationPrefix+"/"+rule.Name]; ok {
+		return &regoPolicy{
+			key:     key,
+			version: nodeQOS.ResourceVersion,
+			policy:  policy,
+		}, nil
+	}
+
+	if ref, ok := nodeQOS.Annotations[known.NodeQOSRegoConfigMapAnnotationPrefix+"/"+rule.Name]; ok {
+		resourceVersion, policy, err := l.getConfigMapPolicy(ref)
+		if err != nil {
+			return nil, err
+		}
+		return &regoPolicy{
+			key:     key,
+			version: nodeQOS.ResourceVersion + "/" + resourceVersion,
+			policy:  policy,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func (l *policyLoader) getConfigMapPolicy(ref string) (string, string, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if cached, ok := l.configMaps[ref]; ok && time.Since(cached.fetchTime) < configMapRefreshInterval {
+		return cached.resourceVersion, cached.policy, nil
+	}
+
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid rego policy configmap reference %q, expect <namespace>/<name>", ref)
+	}
+
+	configMap, err := l.kubeClient.CoreV1().ConfigMaps(parts[0]).Get(context.TODO(), parts[1], metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get rego policy configmap %s: %v", ref, err)
+	}
+
+	policy, ok := configMap.Data[known.NodeQOSRegoConfigMapPolicyKey]
+	if !ok {
+		return "", "", fmt.Errorf("rego policy configmap %s has no key %s", ref, known.NodeQOSRegoConfigMapPolicyKey)
+	}
+
+	l.configMaps[ref] = cachedConfigMap{resourceVersion: configMap.ResourceVersion, policy: policy, fetchTime: time.Now()}
+	klog.V(4).Infof("Loaded rego policy from configmap %s, resourceVersion %s", ref, configMap.ResourceVersion)
+	return configMap.ResourceVersion, policy, nil
+}
+
+// buildPolicyInput converts the stateMap to the input document of rego policies:
+//
+//	{
+//	  "node":    {"<metric>": <latest value of the series without labels>},
+//	  "metrics": {"<metric>": [{"labels": {...}, "value": <latest value>, "timestamp": <unix seconds>}]}
+//	}
+func buildPolicyInput(stateMap map[string][]common.TimeSeries) map[string]interface{} {
+	node := make(map[string]interface{})
+	metrics := make(map[string]interface{})
+
+	for name, series := range stateMap {
+		var items []interface{}
+		for _, ts := range series {
+			if len(ts.Samples) == 0 {
+				continue
+			}
+			sample := ts.Samples[0]
+			if len(ts.Labels) == 0 {
+				node[name] = sample.Value
+			}
+			items = append(items, map[string]interface{}{
+				"labels":    common.Labels2Maps(ts.Labels),
+				"value":     sample.Value,
+				"timestamp": sample.Timestamp,
+			})
+		}
+		metrics[name] = items
+	}
+
+	return map[string]interface{}{
+		"node":    node,
+		"metrics": metrics,
+	}
+}
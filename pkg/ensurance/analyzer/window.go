@@ -0,0 +1,225 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/montanaflynn/stats"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/analyzer/evaluator"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+// MaxSampleWindow is the max number of recent samples kept for each time series.
+const MaxSampleWindow = 120
+
+type Aggregation string
+
+const (
+	// AggregationLatest uses the latest sample
+	AggregationLatest Aggregation = "latest"
+	AggregationAvg    Aggregation = "avg"
+	AggregationMax    Aggregation = "max"
+	AggregationMin    Aggregation = "min"
+	// AggregationRate is the rate of change per second between the first and the last sample in window
+	AggregationRate Aggregation = "rate"
+	// AggregationPercentilePrefix is the prefix of percentile aggregation, e.g. p95
+	AggregationPercentilePrefix = "p"
+)
+
+// ruleEvaluation defines how the samples of a metric rule are compared with the target value.
+type ruleEvaluation struct {
+	// Operator compares the aggregated value with the target value, default is ">"
+	Operator evaluator.Operator `json:"operator,omitempty"`
+	// Aggregation of the samples in window, default is latest
+	Aggregation Aggregation `json:"aggregation,omitempty"`
+	// Window is the number of recent samples to aggregate, default is 1
+	Window int `json:"window,omitempty"`
+}
+
+var defaultRuleEvaluation = ruleEvaluation{
+	Operator:    evaluator.OperatorGreaterThan,
+	Aggregation: AggregationLatest,
+	Window:      1,
+}
+
+// getRuleEvaluation reads the rule evaluation from NodeQOS annotations, default is the latest sample greater than target.
+func getRuleEvaluation(nodeQOS *ensuranceapi.NodeQOS, rule ensuranceapi.Rule) (ruleEvaluation, error) {
+	evaluation := defaultRuleEvaluation
+	if nodeQOS == nil || nodeQOS.Annotations == nil {
+		return evaluation, nil
+	}
+
+	value, ok := nodeQOS.Annotations[known.NodeQOSRuleEvaluationAnnotationPrefix+"/"+rule.Name]
+	if !ok {
+		return evaluation, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &evaluation); err != nil {
+		return evaluation, fmt.Errorf("invalid rule evaluation %q: %v", value, err)
+	}
+	if len(evaluation.Operator) == 0 {
+		evaluation.Operator = defaultRuleEvaluation.Operator
+	}
+	if len(evaluation.Aggregation) == 0 {
+		evaluation.Aggregation = defaultRuleEvaluation.Aggregation
+	}
+	if evaluation.Window <= 0 {
+		evaluation.Window = defaultRuleEvaluation.Window
+	}
+
+	if !evaluator.ValidOperator(evaluation.Operator) {
+		return evaluation, fmt.Errorf("unsupported operator %q", evaluation.Operator)
+	}
+	if evaluation.Window > MaxSampleWindow {
+		return evaluation, fmt.Errorf("window %d exceeds the max window %d", evaluation.Window, MaxSampleWindow)
+	}
+	if _, err := aggregate([]common.Sample{{Value: 0, Timestamp: 0}}, evaluation.Aggregation); err != nil {
+		return evaluation, err
+	}
+
+	return evaluation, nil
+}
+
+// aggregate computes the aggregation of samples in chronological order.
+func aggregate(samples []common.Sample, aggregation Aggregation) (float64, error) {
+	if len(samples) == 0 {
+		return 0, fmt.Errorf("no samples to aggregate")
+	}
+
+	var values stats.Float64Data
+	for _, s := range samples {
+		values = append(values, s.Value)
+	}
+
+	switch aggregation {
+	case AggregationLatest:
+		return samples[len(samples)-1].Value, nil
+	case AggregationAvg:
+		return stats.Mean(values)
+	case AggregationMax:
+		return stats.Max(values)
+	case AggregationMin:
+		return stats.Min(values)
+	case AggregationRate:
+		first, last := samples[0], samples[len(samples)-1]
+		if last.Timestamp == first.Timestamp {
+			return 0, nil
+		}
+		return (last.Value - first.Value) / float64(last.Timestamp-first.Timestamp), nil
+	}
+
+	if strings.HasPrefix(string(aggregation), AggregationPercentilePrefix) {
+		percent, err := strconv.ParseFloat(strings.TrimPrefix(string(aggregation), AggregationPercentilePrefix), 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return 0, fmt.Errorf("unsupported aggregation %q", aggregation)
+		}
+		return stats.Percentile(values, percent)
+	}
+
+	return 0, fmt.Errorf("unsupported aggregation %q", aggregation)
+}
+
+// sampleRing is a fixed size ring buffer of samples.
+type sampleRing struct {
+	samples []common.Sample
+	next    int
+	full    bool
+}
+
+func newSampleRing(size int) *sampleRing {
+	return &sampleRing{samples: make([]common.Sample, size)}
+}
+
+func (r *sampleRing) len() int {
+	if r.full {
+		return len(r.samples)
+	}
+	return r.next
+}
+
+func (r *sampleRing) last() (common.Sample, bool) {
+	if r.len() == 0 {
+		return common.Sample{}, false
+	}
+	return r.samples[(r.next-1+len(r.samples))%len(r.samples)], true
+}
+
+func (r *sampleRing) add(sample common.Sample) {
+	// the same sample may be analyzed more than once, replace it
+	if last, ok := r.last(); ok && last.Timestamp == sample.Timestamp {
+		r.samples[(r.next-1+len(r.samples))%len(r.samples)] = sample
+		return
+	}
+
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// recent returns the last n samples in chronological order.
+func (r *sampleRing) recent(n int) []common.Sample {
+	if n > r.len() {
+		n = r.len()
+	}
+	result := make([]common.Sample, 0, n)
+	for i := n; i > 0; i-- {
+		result = append(result, r.samples[(r.next-i+len(r.samples))%len(r.samples)])
+	}
+	return result
+}
+
+// sampleWindows keeps recent samples per metric and label set.
+type sampleWindows struct {
+	rings   map[string]*sampleRing
+	touched map[string]struct{}
+}
+
+func newSampleWindows() *sampleWindows {
+	return &sampleWindows{
+		rings:   make(map[string]*sampleRing),
+		touched: make(map[string]struct{}),
+	}
+}
+
+// record adds the latest sample of the time series and returns its recent n samples.
+func (w *sampleWindows) record(metricName string, ts common.TimeSeries, n int) []common.Sample {
+	key := seriesKey(metricName, ts.Labels)
+	ring, ok := w.rings[key]
+	if !ok {
+		ring = newSampleRing(MaxSampleWindow)
+		w.rings[key] = ring
+	}
+	if len(ts.Samples) > 0 {
+		ring.add(ts.Samples[0])
+	}
+	w.touched[key] = struct{}{}
+	return ring.recent(n)
+}
+
+// prune drops the time series that are not recorded since last prune, e.g. the pod is deleted.
+func (w *sampleWindows) prune() {
+	for key := range w.rings {
+		if _, ok := w.touched[key]; !ok {
+			delete(w.rings, key)
+		}
+	}
+	w.touched = make(map[string]struct{})
+}
+
+func seriesKey(metricName string, labels []common.Label) string {
+	var pairs []string
+	for _, l := range labels {
+		pairs = append(pairs, l.String())
+	}
+	sort.Strings(pairs)
+	return metricName + "{" + strings.Join(pairs, ",") + "}"
+}
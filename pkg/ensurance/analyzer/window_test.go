@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/analyzer/evaluator"
+)
+
+func TestAggregate(t *testing.T) {
+	samples := []common.Sample{
+		{Value: 10, Timestamp: 0},
+		{Value: 30, Timestamp: 10},
+		{Value: 20, Timestamp: 20},
+	}
+
+	tests := []struct {
+		aggregation Aggregation
+		expect      float64
+		wantErr     bool
+	}{
+		{aggregation: AggregationLatest, expect: 20},
+		{aggregation: AggregationAvg, expect: 20},
+		{aggregation: AggregationMax, expect: 30},
+		{aggregation: AggregationMin, expect: 10},
+		{aggregation: AggregationRate, expect: 0.5},
+		{aggregation: "p100", expect: 30},
+		{aggregation: "p0", wantErr: true},
+		{aggregation: "sum", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.aggregation), func(t *testing.T) {
+			value, err := aggregate(samples, tt.aggregation)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expect error for aggregation %s", tt.aggregation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value != tt.expect {
+				t.Errorf("expect %f, got %f", tt.expect, value)
+			}
+		})
+	}
+}
+
+func TestSampleWindows(t *testing.T) {
+	w := newSampleWindows()
+	ts := common.TimeSeries{Labels: []common.Label{{Name: "diskName", Value: "vda"}}}
+
+	for i := 0; i < MaxSampleWindow+5; i++ {
+		ts.Samples = []common.Sample{{Value: float64(i), Timestamp: int64(i)}}
+		w.record("disk_read_kibps", ts, 3)
+	}
+
+	samples := w.record("disk_read_kibps", ts, 3)
+	if len(samples) != 3 || samples[0].Value != MaxSampleWindow+2 || samples[2].Value != MaxSampleWindow+4 {
+		t.Errorf("unexpected recent samples %v", samples)
+	}
+	if samples = w.record("disk_read_kibps", ts, MaxSampleWindow+10); len(samples) != MaxSampleWindow {
+		t.Errorf("expect %d samples, got %d", MaxSampleWindow, len(samples))
+	}
+
+	w.prune()
+	w.prune()
+	if len(w.rings) != 0 {
+		t.Errorf("expect untouched series to be pruned")
+	}
+}
+
+func TestGetRuleEvaluation(t *testing.T) {
+	rule := ensuranceapi.Rule{Name: "idle"}
+	nodeQOS := &ensuranceapi.NodeQOS{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		"rule-evaluation.ensurance.crane.io/idle": `{"operator":"<","aggregation":"avg","window":5}`,
+	}}}
+
+	evaluation, err := getRuleEvaluation(nodeQOS, rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evaluation.Operator != evaluator.OperatorLessThan || evaluation.Aggregation != AggregationAvg || evaluation.Window != 5 {
+		t.Errorf("unexpected evaluation %+v", evaluation)
+	}
+
+	evaluation, err = getRuleEvaluation(nodeQOS, ensuranceapi.Rule{Name: "other"})
+	if err != nil || evaluation != defaultRuleEvaluation {
+		t.Errorf("expect default evaluation, got %+v, %v", evaluation, err)
+	}
+
+	nodeQOS.Annotations["rule-evaluation.ensurance.crane.io/idle"] = `{"operator":"!="}`
+	if _, err = getRuleEvaluation(nodeQOS, rule); err == nil {
+		t.Errorf("expect error for unsupported operator")
+	}
+}
@@ -0,0 +1,343 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+type Action string
+
+const (
+	ActionThrottleDown    Action = "ThrottleDown"
+	ActionThrottleUp      Action = "ThrottleUp"
+	ActionEvict           Action = "Evict"
+	ActionDisableSchedule Action = "DisableSchedule"
+	ActionEnableSchedule  Action = "EnableSchedule"
+)
+
+// DefaultCapacity is the default number of records kept in the store
+const DefaultCapacity = 1000
+
+// Record is an avoidance action done by the executor and why it was done.
+type Record struct {
+	Time   time.Time `json:"time"`
+	Action Action    `json:"action"`
+	// Rules are the NodeQOS rules triggered or restored, in the format of <NodeQOS>/<Rule>
+	Rules []string `json:"rules,omitempty"`
+	// Metrics are the node usage of the metrics in watermarks when the action is done
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+	// Watermarks are the lowest watermarks of the metrics
+	Watermarks map[string]float64 `json:"watermarks,omitempty"`
+	// Gaps are the differences between usage and watermarks before the action, only for the quantified metrics
+	Gaps map[string]float64 `json:"gaps,omitempty"`
+	// Pods are the pods acted on, in the format of <namespace>/<name>
+	Pods  []string `json:"pods,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// Query filters the records, the zero value matches all records.
+type Query struct {
+	Action Action
+	Rule   string
+	Pod    string
+	Since  time.Time
+	// Limit is the max number of records returned, no limit if it is not positive
+	Limit int
+}
+
+func (q Query) match(r *Record) bool {
+	if len(q.Action) != 0 && q.Action != r.Action {
+		return false
+	}
+	if len(q.Rule) != 0 && !contains(r.Rules, q.Rule) {
+		return false
+	}
+	if len(q.Pod) != 0 && !contains(r.Pods, q.Pod) {
+		return false
+	}
+	if !q.Since.IsZero() && r.Time.Before(q.Since) {
+		return false
+	}
+	return true
+}
+
+// Store keeps the latest records in memory, and appends them to a file if the path is set so that the history
+// survives restarts of the agent. The file is compacted when it holds twice as many records as the capacity.
+type Store struct {
+	mu       sync.RWMutex
+	capacity int
+	// records is a ring buffer, next is the index to write
+	records []Record
+	next    int
+	full    bool
+
+	path    string
+	file    *os.File
+	written int
+}
+
+// NewStore creates a store of capacity records, the records are loaded from and persisted to path if it is not empty.
+func NewStore(capacity int, path string) (*Store, error) {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+
+	s := &Store{
+		capacity: capacity,
+		records:  make([]Record, capacity),
+		path:     path,
+	}
+
+	if len(path) == 0 {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add appends a record, the oldest record is dropped if the store is full.
+func (s *Store) Add(r Record) {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.append(r)
+
+	if s.file == nil {
+		return
+	}
+	if err := s.persist(r); err != nil {
+		klog.Errorf("Failed to persist avoidance record: %v", err)
+	}
+}
+
+// List returns the records matching the query, the latest first.
+func (s *Store) List(q Query) []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Record
+	for i := 0; i < s.len(); i++ {
+		r := s.at(i)
+		if !q.match(r) {
+			continue
+		}
+		result = append(result, *r)
+		if q.Limit > 0 && len(result) >= q.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// Latest returns the latest record of the actions, false if there is no such record.
+func (s *Store) Latest(actions ...Action) (Record, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := 0; i < s.len(); i++ {
+		r := s.at(i)
+		for _, action := range actions {
+			if r.Action == action {
+				return *r, true
+			}
+		}
+	}
+	return Record{}, false
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// ServeHTTP returns the records in json, the records can be filtered by the parameters:
+// action, rule, pod(<namespace>/<name>), since(RFC3339 time or a duration such as 1h) and limit.
+func (s *Store) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q, err := parseQuery(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records := s.List(q)
+	if records == nil {
+		records = []Record{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		klog.Errorf("Failed to write avoidance records: %v", err)
+	}
+}
+
+func parseQuery(req *http.Request) (Query, error) {
+	values := req.URL.Query()
+	q := Query{
+		Action: Action(values.Get("action")),
+		Rule:   values.Get("rule"),
+		Pod:    values.Get("pod"),
+	}
+
+	if since := values.Get("since"); len(since) != 0 {
+		if d, err := time.ParseDuration(since); err == nil {
+			q.Since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+			q.Since = t
+		} else {
+			return q, fmt.Errorf("invalid since %q, should be a duration or RFC3339 time", since)
+		}
+	}
+
+	if limit := values.Get("limit"); len(limit) != 0 {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return q, fmt.Errorf("invalid limit %q: %v", limit, err)
+		}
+		q.Limit = l
+	}
+	return q, nil
+}
+
+func (s *Store) append(r Record) {
+	s.records[s.next] = r
+	s.next = (s.next + 1) % s.capacity
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+func (s *Store) len() int {
+	if s.full {
+		return s.capacity
+	}
+	return s.next
+}
+
+// at returns the i-th latest record
+func (s *Store) at(i int) *Record {
+	return &s.records[(s.next-1-i+s.capacity)%s.capacity]
+}
+
+func (s *Store) persist(r Record) error {
+	if s.written >= 2*s.capacity {
+		return s.compact()
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err = s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.written++
+	return nil
+}
+
+// load reads the records of the file, the broken lines such as a partial write are skipped.
+func (s *Store) load() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			klog.Warningf("Skip broken avoidance record in %s: %v", s.path, err)
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	for _, r := range records {
+		s.append(r)
+	}
+	return nil
+}
+
+// compact rewrites the file with the records in memory, and reopens it for appending.
+func (s *Store) compact() error {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+
+	var lines []string
+	for i := s.len() - 1; i >= 0; i-- {
+		data, err := json.Marshal(s.at(i))
+		if err != nil {
+			return err
+		}
+		lines = append(lines, string(data)+"\n")
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(lines, "")), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.written = len(lines)
+	return nil
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
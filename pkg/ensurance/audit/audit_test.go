@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreList(t *testing.T) {
+	s, err := NewStore(3, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	s.Add(Record{Time: now.Add(-4 * time.Hour), Action: ActionThrottleDown, Pods: []string{"default/a"}})
+	s.Add(Record{Time: now.Add(-3 * time.Hour), Action: ActionEvict, Rules: []string{"qos/cpu"}, Pods: []string{"default/a"}})
+	s.Add(Record{Time: now.Add(-2 * time.Hour), Action: ActionThrottleDown, Pods: []string{"default/b"}})
+	s.Add(Record{Time: now.Add(-1 * time.Hour), Action: ActionEvict, Rules: []string{"qos/mem"}, Pods: []string{"default/b"}})
+
+	tests := []struct {
+		name   string
+		query  Query
+		expect []time.Duration
+	}{
+		{"all records latest first, the oldest is dropped", Query{}, []time.Duration{-1 * time.Hour, -2 * time.Hour, -3 * time.Hour}},
+		{"by action", Query{Action: ActionEvict}, []time.Duration{-1 * time.Hour, -3 * time.Hour}},
+		{"by pod", Query{Pod: "default/a"}, []time.Duration{-3 * time.Hour}},
+		{"by rule", Query{Rule: "qos/mem"}, []time.Duration{-1 * time.Hour}},
+		{"since", Query{Since: now.Add(-150 * time.Minute)}, []time.Duration{-1 * time.Hour, -2 * time.Hour}},
+		{"limit", Query{Limit: 1}, []time.Duration{-1 * time.Hour}},
+	}
+
+	for _, test := range tests {
+		records := s.List(test.query)
+		if len(records) != len(test.expect) {
+			t.Errorf("%s: expect %d records, got %v", test.name, len(test.expect), records)
+			continue
+		}
+		for i, r := range records {
+			if !r.Time.Equal(now.Add(test.expect[i])) {
+				t.Errorf("%s: expect record %d at %v, got %v", test.name, i, now.Add(test.expect[i]), r.Time)
+			}
+		}
+	}
+
+	if r, ok := s.Latest(ActionThrottleDown); !ok || !r.Time.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("unexpected latest throttle record %v", r)
+	}
+	if _, ok := s.Latest(ActionDisableSchedule); ok {
+		t.Errorf("expect no schedule record")
+	}
+}
+
+func TestStorePersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	s, err := NewStore(2, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	// more than twice of the capacity to trigger the compaction
+	for i := 0; i < 5; i++ {
+		s.Add(Record{Time: now.Add(time.Duration(i) * time.Minute), Action: ActionEvict, Gaps: map[string]float64{"cpu_total_usage": float64(i)}})
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewStore(2, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	records := s.List(Query{})
+	if len(records) != 2 {
+		t.Fatalf("expect 2 records, got %v", records)
+	}
+	if !records[0].Time.Equal(now.Add(4*time.Minute)) || records[0].Gaps["cpu_total_usage"] != 4 || !records[1].Time.Equal(now.Add(3*time.Minute)) {
+		t.Errorf("unexpected records %v", records)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	s, err := NewStore(10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(Record{Action: ActionEvict, Pods: []string{"default/a"}})
+	s.Add(Record{Action: ActionThrottleDown, Pods: []string{"default/a"}})
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/avoidance-history?action=Evict&since=1h", nil))
+	var records []Record
+	if err = json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Action != ActionEvict {
+		t.Errorf("unexpected records %v", records)
+	}
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/avoidance-history?since=yesterday", nil))
+	if w.Code != 400 {
+		t.Errorf("expect bad request for invalid since, got %d", w.Code)
+	}
+}
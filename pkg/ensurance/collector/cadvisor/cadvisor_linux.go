@@ -32,6 +32,8 @@ var cadvisorMetrics = []types.MetricName{
 	types.MetricNameContainerCpuLimit,
 	types.MetricNameContainerCpuQuota,
 	types.MetricNameContainerCpuPeriod,
+	types.MetricNameContainerDiskReadKiBPS,
+	types.MetricNameContainerDiskWriteKiBPS,
 }
 
 type ContainerState struct {
@@ -70,6 +72,7 @@ func NewCadvisorManager(cgroupDriver string) Manager {
 	var includedMetrics = cadvisorcontainer.MetricSet{
 		cadvisorcontainer.CpuUsageMetrics:         struct{}{},
 		cadvisorcontainer.ProcessSchedulerMetrics: struct{}{},
+		cadvisorcontainer.DiskIOMetrics:           struct{}{},
 	}
 
 	allowDynamic := true
@@ -190,6 +193,10 @@ func (c *CadvisorCollector) Collect() (map[string][]common.TimeSeries, error) {
 				addSampleToStateMap(types.MetricNameContainerCpuLimit, composeSample(containerLabels, float64(state.stat.Spec.Cpu.Limit), now), stateMap)
 				addSampleToStateMap(types.MetricNameContainerCpuQuota, composeSample(containerLabels, float64(containerInfoV1.Spec.Cpu.Quota), now), stateMap)
 				addSampleToStateMap(types.MetricNameContainerCpuPeriod, composeSample(containerLabels, float64(containerInfoV1.Spec.Cpu.Period), now), stateMap)
+				if readKiBps, writeKiBps, ok := calculateDiskIO(&v, &state); ok {
+					addSampleToStateMap(types.MetricNameContainerDiskReadKiBPS, composeSample(containerLabels, readKiBps, now), stateMap)
+					addSampleToStateMap(types.MetricNameContainerDiskWriteKiBPS, composeSample(containerLabels, writeKiBps, now), stateMap)
+				}
 
 				klog.V(6).Infof("Pod: %s, containerName: %s, key %s, scheduler run queue time %.2f, container_cpu_total_usage %#v", klog.KObj(pod), containerName, key, schedRunqueueTime, cpuUsageSample)
 			}
@@ -246,6 +253,37 @@ func caculateCPUUsage(info *cadvisorapiv2.ContainerInfo, state *ContainerState)
 	return cpuUsageSample, schedRunqueueTime
 }
 
+// calculateDiskIO returns the disk read and write KiB per second of container since last collection
+func calculateDiskIO(info *cadvisorapiv2.ContainerInfo, state *ContainerState) (float64, float64, bool) {
+	if info == nil ||
+		state == nil ||
+		len(info.Stats) == 0 ||
+		info.Stats[0].DiskIo == nil || len(state.stat.Stats) == 0 || state.stat.Stats[0].DiskIo == nil {
+		return 0, 0, false
+	}
+	timeIncrease := info.Stats[0].Timestamp.Sub(state.stat.Stats[0].Timestamp).Seconds()
+	if timeIncrease <= 0 {
+		return 0, 0, false
+	}
+
+	read, write := sumDiskIoServiceBytes(info.Stats[0].DiskIo)
+	lastRead, lastWrite := sumDiskIoServiceBytes(state.stat.Stats[0].DiskIo)
+	// the counter is reset if the container is restarted
+	if read < lastRead || write < lastWrite {
+		return 0, 0, false
+	}
+
+	return float64(read-lastRead) / types.UintConversionStep1024 / timeIncrease, float64(write-lastWrite) / types.UintConversionStep1024 / timeIncrease, true
+}
+
+func sumDiskIoServiceBytes(stats *info.DiskIoStats) (read uint64, write uint64) {
+	for _, disk := range stats.IoServiceBytes {
+		read += disk.Stats["Read"]
+		write += disk.Stats["Write"]
+	}
+	return
+}
+
 func GetContainerLabels(pod *v1.Pod, containerId, containerName string, hasExtRes bool) []common.Label {
 	return []common.Label{
 		{Name: common.LabelNamePodName, Value: pod.Name},
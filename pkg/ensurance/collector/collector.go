@@ -20,6 +20,9 @@ import (
 	"github.com/gocrane/crane/pkg/ensurance/collector/nodelocal"
 	"github.com/gocrane/crane/pkg/ensurance/collector/noderesource"
 	"github.com/gocrane/crane/pkg/ensurance/collector/noderesourcetopology"
+	"github.com/gocrane/crane/pkg/ensurance/collector/podnetwork"
+	"github.com/gocrane/crane/pkg/ensurance/collector/psi"
+	"github.com/gocrane/crane/pkg/ensurance/collector/schedstat"
 	"github.com/gocrane/crane/pkg/ensurance/collector/types"
 	"github.com/gocrane/crane/pkg/features"
 	"github.com/gocrane/crane/pkg/known"
@@ -42,6 +45,7 @@ type StateCollector struct {
 	exclusiveCPUSet   func() cpuset.CPUSet
 	collectors        *sync.Map
 	cadvisorManager   cadvisor.Manager
+	cgroupDriver      string
 	AnalyzerChann     chan map[string][]common.TimeSeries
 	NodeResourceChann chan map[string][]common.TimeSeries
 	PodResourceChann  chan map[string][]common.TimeSeries
@@ -53,7 +57,7 @@ func NewStateCollector(nodeName, sysPath string, kubeClient kubernetes.Interface
 	nodeQOSLister ensuranceListers.NodeQOSLister, nrtLister topologylisters.NodeResourceTopologyLister,
 	podLister corelisters.PodLister, nodeLister corelisters.NodeLister, ifaces []string,
 	healthCheck *metrics.HealthCheck, collectInterval time.Duration, exclusiveCPUSet func() cpuset.CPUSet,
-	manager cadvisor.Manager,
+	manager cadvisor.Manager, cgroupDriver string,
 ) *StateCollector {
 	analyzerChann := make(chan map[string][]common.TimeSeries)
 	nodeResourceChann := make(chan map[string][]common.TimeSeries)
@@ -76,6 +80,7 @@ func NewStateCollector(nodeName, sysPath string, kubeClient kubernetes.Interface
 		PodResourceChann:  podResourceChann,
 		collectors:        &sync.Map{},
 		cadvisorManager:   manager,
+		cgroupDriver:      cgroupDriver,
 		exclusiveCPUSet:   exclusiveCPUSet,
 		State:             State,
 	}
@@ -210,6 +215,20 @@ func (s *StateCollector) UpdateCollectors() {
 			s.collectors.Store(types.CadvisorCollectorType, cadvisor.NewCadvisorCollector(s.podLister, s.GetCadvisorManager()))
 		}
 
+		if _, exists := s.collectors.Load(types.PsiCollectorType); !exists {
+			s.collectors.Store(types.PsiCollectorType, psi.NewPSI(s.podLister, s.cgroupDriver, s.sysPath))
+		}
+
+		if _, exists := s.collectors.Load(types.PodNetworkCollectorType); !exists {
+			s.collectors.Store(types.PodNetworkCollectorType, podnetwork.NewPodNetwork(s.podLister, s.cgroupDriver, s.sysPath))
+		}
+
+		if utilfeature.DefaultFeatureGate.Enabled(features.CraneSchedStatCollector) {
+			if _, exists := s.collectors.Load(types.SchedStatCollectorType); !exists {
+				s.collectors.Store(types.SchedStatCollectorType, schedstat.NewSchedStat(s.podLister, s.cgroupDriver, s.sysPath))
+			}
+		}
+
 		break
 	}
 	// if node resource controller is enabled, it indicates local metrics need to be collected no matter nodeqos is defined or not
@@ -231,7 +250,7 @@ func (s *StateCollector) UpdateCollectors() {
 		nodeLocal = true
 	}
 	if !nodeLocal {
-		stopCollectors := []types.CollectType{types.NodeLocalCollectorType, types.CadvisorCollectorType}
+		stopCollectors := []types.CollectType{types.NodeLocalCollectorType, types.CadvisorCollectorType, types.PsiCollectorType, types.PodNetworkCollectorType, types.SchedStatCollectorType}
 
 		for _, collector := range stopCollectors {
 			if value, exists := s.collectors.Load(collector); exists {
@@ -281,6 +300,18 @@ func CheckMetricNameExist(name string) bool {
 		return true
 	}
 
+	if psi.CheckMetricNameExist(name) {
+		return true
+	}
+
+	if podnetwork.CheckMetricNameExist(name) {
+		return true
+	}
+
+	if schedstat.CheckMetricNameExist(name) {
+		return true
+	}
+
 	return false
 }
 
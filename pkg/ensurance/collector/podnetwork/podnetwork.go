@@ -0,0 +1,156 @@
+package podnetwork
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	ctypes "github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// DefaultProcPath is the default mount point of procfs
+const DefaultProcPath = "/proc"
+
+type sentState struct {
+	bytes     uint64
+	timestamp time.Time
+}
+
+// PodNetwork collects the network bandwidth of pods from /proc/<pid>/net/dev of a process in the pod, the pods
+// in host network are skipped.
+type PodNetwork struct {
+	name         ctypes.CollectType
+	podLister    corelisters.PodLister
+	cgroupDriver string
+	cgroupRoot   string
+	procPath     string
+
+	latestStates map[types.UID]sentState
+}
+
+func NewPodNetwork(podLister corelisters.PodLister, cgroupDriver, sysPath string) *PodNetwork {
+	root, _ := utils.GetCgroupRoot(sysPath, "cpu")
+	return &PodNetwork{
+		name:         ctypes.PodNetworkCollectorType,
+		podLister:    podLister,
+		cgroupDriver: cgroupDriver,
+		cgroupRoot:   root,
+		procPath:     DefaultProcPath,
+		latestStates: make(map[types.UID]sentState),
+	}
+}
+
+func (p *PodNetwork) GetType() ctypes.CollectType {
+	return p.name
+}
+
+func (p *PodNetwork) Collect() (map[string][]common.TimeSeries, error) {
+	allPods, err := p.podLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list all pods: %v", err)
+		return nil, err
+	}
+
+	var stateMap = make(map[string][]common.TimeSeries)
+	var currentStates = make(map[types.UID]sentState)
+	for _, pod := range allPods {
+		if utils.IsStaticPod(pod) || pod.Spec.HostNetwork {
+			continue
+		}
+
+		cgroupPath := utils.GetCgroupPath(pod, p.cgroupDriver)
+		if len(cgroupPath) == 0 {
+			continue
+		}
+		pid, err := utils.GetFirstPidInCgroup(p.cgroupRoot, cgroupPath)
+		if err != nil {
+			klog.V(4).Infof("Failed to get process of pod %s: %v", klog.KObj(pod), err)
+			continue
+		}
+
+		var now = time.Now()
+		sent, err := readSentBytes(filepath.Join(p.procPath, strconv.Itoa(pid), "net", "dev"))
+		if err != nil {
+			klog.V(4).Infof("Failed to read network of pod %s: %v", klog.KObj(pod), err)
+			continue
+		}
+
+		current := sentState{bytes: sent, timestamp: now}
+		currentStates[pod.UID] = current
+		if last, ok := p.latestStates[pod.UID]; ok {
+			duration := current.timestamp.Sub(last.timestamp).Seconds()
+			if duration <= 0 || current.bytes < last.bytes {
+				continue
+			}
+			kibps := float64(current.bytes-last.bytes) * 8 / 1000 / duration
+			stateMap[string(ctypes.MetricNamePodNetworkSentKiBPS)] = append(stateMap[string(ctypes.MetricNamePodNetworkSentKiBPS)], common.TimeSeries{
+				Labels: []common.Label{
+					{Name: common.LabelNamePodName, Value: pod.Name},
+					{Name: common.LabelNamePodNamespace, Value: pod.Namespace},
+					{Name: common.LabelNamePodUid, Value: string(pod.UID)},
+				},
+				Samples: []common.Sample{{Value: kibps, Timestamp: now.Unix()}},
+			})
+		}
+	}
+	p.latestStates = currentStates
+
+	return stateMap, nil
+}
+
+func (p *PodNetwork) Stop() error {
+	return nil
+}
+
+func CheckMetricNameExist(name string) bool {
+	return name == string(ctypes.MetricNamePodNetworkSentKiBPS)
+}
+
+// readSentBytes sums the transmitted bytes of all the interfaces except loopback in /proc/<pid>/net/dev
+func readSentBytes(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var total uint64
+	var found bool
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// format: <iface>: <8 receive fields> <transmit bytes> <7 transmit fields>
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		iface := strings.TrimSpace(parts[0])
+		fields := strings.Fields(parts[1])
+		if iface == "lo" || len(fields) < 9 {
+			continue
+		}
+		sent, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid net dev line %q: %v", scanner.Text(), err)
+		}
+		total += sent
+		found = true
+	}
+	if err = scanner.Err(); err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("no interface found in %s", path)
+	}
+	return total, nil
+}
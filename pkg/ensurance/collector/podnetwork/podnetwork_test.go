@@ -0,0 +1,25 @@
+package podnetwork
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSentBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev")
+	content := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:    1000      10    0    0    0     0          0         0     1000      10    0    0    0     0       0          0
+  eth0:    2000      20    0    0    0     0          0         0     3000      30    0    0    0     0       0          0
+ tunl0:       0       0    0    0    0     0          0         0       50       1    0    0    0     0       0          0
+`
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := readSentBytes(path)
+	if err != nil || sent != 3050 {
+		t.Errorf("expect 3050 bytes sent, got %d, %v", sent, err)
+	}
+}
@@ -0,0 +1,212 @@
+package psi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// DefaultProcPath is the default mount point of procfs
+const DefaultProcPath = "/proc"
+
+// pressureSeries maps a line and a window of the pressure file of a resource to the node and pod metric.
+type pressureSeries struct {
+	resource   string
+	line       string
+	window     string
+	nodeMetric types.MetricName
+	podMetric  types.MetricName
+}
+
+var allPressureSeries = []pressureSeries{
+	{"cpu", "some", "avg10", types.MetricNameCpuSomeAvg10, types.MetricNamePodCpuSomeAvg10},
+	{"cpu", "some", "avg60", types.MetricNameCpuSomeAvg60, types.MetricNamePodCpuSomeAvg60},
+	{"memory", "some", "avg10", types.MetricNameMemorySomeAvg10, types.MetricNamePodMemorySomeAvg10},
+	{"memory", "some", "avg60", types.MetricNameMemorySomeAvg60, types.MetricNamePodMemorySomeAvg60},
+	{"memory", "full", "avg10", types.MetricNameMemoryFullAvg10, types.MetricNamePodMemoryFullAvg10},
+	{"memory", "full", "avg60", types.MetricNameMemoryFullAvg60, types.MetricNamePodMemoryFullAvg60},
+	{"io", "some", "avg10", types.MetricNameIoSomeAvg10, types.MetricNamePodIoSomeAvg10},
+	{"io", "some", "avg60", types.MetricNameIoSomeAvg60, types.MetricNamePodIoSomeAvg60},
+	{"io", "full", "avg10", types.MetricNameIoFullAvg10, types.MetricNamePodIoFullAvg10},
+	{"io", "full", "avg60", types.MetricNameIoFullAvg60, types.MetricNamePodIoFullAvg60},
+}
+
+var pressureResources = []string{"cpu", "memory", "io"}
+
+// pressure is the content of a pressure file, keyed by line(some or full) and window(avg10, avg60, avg300)
+type pressure map[string]map[string]float64
+
+// PSI collects the pressure stall information of node from /proc/pressure and of pods from the cgroup v2 pressure files.
+type PSI struct {
+	name         types.CollectType
+	podLister    corelisters.PodLister
+	cgroupDriver string
+	// unifiedRoot is the mount point of cgroup v2, it is empty if cgroup v2 is not mounted
+	unifiedRoot string
+	procPath    string
+}
+
+func NewPSI(podLister corelisters.PodLister, cgroupDriver, sysPath string) *PSI {
+	p := PSI{
+		name:         types.PsiCollectorType,
+		podLister:    podLister,
+		cgroupDriver: cgroupDriver,
+		unifiedRoot:  unifiedRoot(sysPath),
+		procPath:     DefaultProcPath,
+	}
+
+	if len(p.unifiedRoot) == 0 {
+		klog.Warningf("Cgroup v2 is not mounted, the pressure of pods will not be collected")
+	}
+
+	return &p
+}
+
+func (p *PSI) GetType() types.CollectType {
+	return p.name
+}
+
+func (p *PSI) Collect() (map[string][]common.TimeSeries, error) {
+	var stateMap = make(map[string][]common.TimeSeries)
+	var now = time.Now()
+
+	for _, resource := range pressureResources {
+		pr, err := readPressure(filepath.Join(p.procPath, "pressure", resource))
+		if err != nil {
+			klog.V(4).Infof("Failed to read node %s pressure: %v", resource, err)
+			continue
+		}
+		addPressureSamples(stateMap, resource, pr, false, []common.Label{}, now)
+	}
+
+	if len(p.unifiedRoot) == 0 {
+		return stateMap, nil
+	}
+
+	allPods, err := p.podLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list all pods: %v", err)
+		return stateMap, err
+	}
+
+	for _, pod := range allPods {
+		if utils.IsStaticPod(pod) {
+			continue
+		}
+
+		cgroupPath := utils.GetCgroupPath(pod, p.cgroupDriver)
+		if len(cgroupPath) == 0 {
+			continue
+		}
+
+		podLabels := []common.Label{
+			{Name: common.LabelNamePodName, Value: pod.Name},
+			{Name: common.LabelNamePodNamespace, Value: pod.Namespace},
+			{Name: common.LabelNamePodUid, Value: string(pod.UID)},
+		}
+		for _, resource := range pressureResources {
+			pr, err := readPressure(filepath.Join(p.unifiedRoot, cgroupPath, resource+".pressure"))
+			if err != nil {
+				klog.V(4).Infof("Failed to read %s pressure of pod %s: %v", resource, klog.KObj(pod), err)
+				continue
+			}
+			addPressureSamples(stateMap, resource, pr, true, podLabels, now)
+		}
+	}
+
+	return stateMap, nil
+}
+
+func (p *PSI) Stop() error {
+	return nil
+}
+
+func CheckMetricNameExist(name string) bool {
+	for _, s := range allPressureSeries {
+		if string(s.nodeMetric) == name || string(s.podMetric) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func addPressureSamples(stateMap map[string][]common.TimeSeries, resource string, pr pressure, pod bool, labels []common.Label, now time.Time) {
+	for _, s := range allPressureSeries {
+		if s.resource != resource {
+			continue
+		}
+		value, ok := pr[s.line][s.window]
+		if !ok {
+			continue
+		}
+
+		key := string(s.nodeMetric)
+		if pod {
+			key = string(s.podMetric)
+		}
+		stateMap[key] = append(stateMap[key], common.TimeSeries{
+			Labels:  labels,
+			Samples: []common.Sample{{Value: value, Timestamp: now.Unix()}},
+		})
+	}
+}
+
+// readPressure parses a pressure file, the format is:
+// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+// full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readPressure(path string) (pressure, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pr := make(pressure)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		windows := make(map[string]float64)
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 || !strings.HasPrefix(kv[0], "avg") {
+				continue
+			}
+			value, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pressure line %q: %v", line, err)
+			}
+			windows[kv[0]] = value
+		}
+		pr[fields[0]] = windows
+	}
+
+	if len(pr) == 0 {
+		return nil, fmt.Errorf("no pressure found in %s", path)
+	}
+	return pr, nil
+}
+
+// unifiedRoot returns the mount point of cgroup v2 in pure or hybrid mode, or empty if not mounted.
+func unifiedRoot(sysPath string) string {
+	cgroupRoot := filepath.Join(sysPath, "fs", "cgroup")
+	for _, root := range []string{cgroupRoot, filepath.Join(cgroupRoot, "unified")} {
+		if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+			return root
+		}
+	}
+	return ""
+}
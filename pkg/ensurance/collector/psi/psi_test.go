@@ -0,0 +1,88 @@
+package psi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadPressure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory")
+	writeFile(t, path, "some avg10=1.50 avg60=0.75 avg300=0.10 total=1234\nfull avg10=0.50 avg60=0.25 avg300=0.00 total=567\n")
+
+	pr, err := readPressure(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr["some"]["avg10"] != 1.5 || pr["some"]["avg60"] != 0.75 || pr["full"]["avg10"] != 0.5 || pr["full"]["avg60"] != 0.25 {
+		t.Errorf("unexpected pressure %v", pr)
+	}
+	if _, ok := pr["some"]["total"]; ok {
+		t.Errorf("total should not be parsed as a window")
+	}
+
+	writeFile(t, path, "some avg10=abc\n")
+	if _, err = readPressure(path); err == nil {
+		t.Errorf("expect error for invalid pressure")
+	}
+}
+
+func TestCollect(t *testing.T) {
+	root := t.TempDir()
+	sysPath := filepath.Join(root, "sys")
+	procPath := filepath.Join(root, "proc")
+	cgroupRoot := filepath.Join(sysPath, "fs", "cgroup")
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default", UID: "123"},
+		Status:     v1.PodStatus{QOSClass: v1.PodQOSBurstable},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(pod); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(cgroupRoot, "cgroup.controllers"), "cpu memory io")
+	writeFile(t, filepath.Join(procPath, "pressure", "cpu"), "some avg10=10.00 avg60=5.00 avg300=1.00 total=100\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n")
+	writeFile(t, filepath.Join(procPath, "pressure", "memory"), "some avg10=2.00 avg60=1.00 avg300=0.00 total=100\nfull avg10=1.00 avg60=0.50 avg300=0.00 total=50\n")
+	writeFile(t, filepath.Join(cgroupRoot, "kubepods", "burstable", "pod123", "cpu.pressure"), "some avg10=8.00 avg60=4.00 avg300=1.00 total=80\n")
+
+	p := NewPSI(corelisters.NewPodLister(indexer), "cgroupfs", sysPath)
+	p.procPath = procPath
+
+	stateMap, err := p.Collect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if series := stateMap[string(types.MetricNameCpuSomeAvg10)]; len(series) != 1 || series[0].Samples[0].Value != 10 {
+		t.Errorf("unexpected node cpu pressure %v", series)
+	}
+	if series := stateMap[string(types.MetricNameMemoryFullAvg60)]; len(series) != 1 || series[0].Samples[0].Value != 0.5 {
+		t.Errorf("unexpected node memory pressure %v", series)
+	}
+	if _, ok := stateMap[string(types.MetricNameIoSomeAvg10)]; ok {
+		t.Errorf("io pressure should not be collected when the file is missing")
+	}
+	series := stateMap[string(types.MetricNamePodCpuSomeAvg60)]
+	if len(series) != 1 || series[0].Samples[0].Value != 4 || len(series[0].Labels) != 3 {
+		t.Errorf("unexpected pod cpu pressure %v", series)
+	}
+}
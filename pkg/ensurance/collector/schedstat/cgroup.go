@@ -0,0 +1,127 @@
+package schedstat
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupFS reads the cpu controller of cgroup v1 or the unified hierarchy of cgroup v2.
+type cgroupFS struct {
+	// cpuRoot is the mount point of the cpu controller, e.g. /sys/fs/cgroup/cpu or /sys/fs/cgroup
+	cpuRoot string
+	// procRoot is the mount point of procfs
+	procRoot string
+}
+
+func newCgroupFS(sysPath string, procRoot string) *cgroupFS {
+	cgroupRoot := filepath.Join(sysPath, "fs", "cgroup")
+	fs := &cgroupFS{procRoot: procRoot}
+
+	// pure cgroup v2, the cpu controller is in cgroup v1 in hybrid mode
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err == nil {
+		fs.cpuRoot = cgroupRoot
+	} else {
+		fs.cpuRoot = filepath.Join(cgroupRoot, "cpu")
+	}
+	return fs
+}
+
+// containerDirs returns the child cgroups of the pod cgroup which contain container id, keyed by cgroup dir name.
+func (fs *cgroupFS) containerDirs(podCgroupPath string, containerIds []string) (map[string]string, error) {
+	podDir := filepath.Join(fs.cpuRoot, podCgroupPath)
+	entries, err := ioutil.ReadDir(podDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		for _, id := range containerIds {
+			if len(id) != 0 && strings.Contains(entry.Name(), id) {
+				dirs[entry.Name()] = filepath.Join(podCgroupPath, entry.Name())
+				break
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// throttledTime returns the accumulated throttled time of the cgroup in nanoseconds.
+func (fs *cgroupFS) throttledTime(cgroupPath string) (uint64, error) {
+	stat, err := readKeyValueFile(filepath.Join(fs.cpuRoot, cgroupPath, "cpu.stat"))
+	if err != nil {
+		return 0, err
+	}
+
+	// cgroup v1
+	if value, ok := stat["throttled_time"]; ok {
+		return value, nil
+	}
+	// cgroup v2
+	if value, ok := stat["throttled_usec"]; ok {
+		return value * 1000, nil
+	}
+	return 0, fmt.Errorf("no throttled time in cpu.stat of %s", cgroupPath)
+}
+
+// nodeRunQueueDelay returns the sum of run queue delay of all cpus from /proc/schedstat in nanoseconds and the cpu number.
+func (fs *cgroupFS) nodeRunQueueDelay() (uint64, int, error) {
+	f, err := os.Open(filepath.Join(fs.procRoot, "schedstat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	var total uint64
+	var cpus int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// format: cpu<N> <yld_count> <legacy> <sched_count> <sched_goidle> <ttwu_count> <ttwu_local> <run_time> <run_delay> <pcount>
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 9 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		delay, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid schedstat line %q: %v", scanner.Text(), err)
+		}
+		total += delay
+		cpus++
+	}
+	if err = scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	if cpus == 0 {
+		return 0, 0, fmt.Errorf("no cpu found in schedstat")
+	}
+	return total, cpus, nil
+}
+
+func readKeyValueFile(path string) (map[string]uint64, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[fields[0]] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,188 @@
+package schedstat
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// DefaultProcPath is the default mount point of procfs
+const DefaultProcPath = "/proc"
+
+var schedStatMetrics = []types.MetricName{
+	types.MetricNameCpuRunQueueDelay,
+	types.MetricNameContainerCpuThrottledTime,
+}
+
+// counterState is the last value of an accumulated counter.
+type counterState struct {
+	value     uint64
+	timestamp time.Time
+}
+
+// SchedStat collects the run queue delay of node from /proc/schedstat and the cpu throttled time of containers from
+// their cpu cgroups. The run queue time of containers is collected by cadvisor as container_sched_run_queue_time.
+type SchedStat struct {
+	name         types.CollectType
+	podLister    corelisters.PodLister
+	cgroupDriver string
+	fs           *cgroupFS
+	StatusCache  sync.Map
+}
+
+func NewSchedStat(podLister corelisters.PodLister, cgroupDriver, sysPath string) *SchedStat {
+	s := SchedStat{
+		name:         types.SchedStatCollectorType,
+		podLister:    podLister,
+		cgroupDriver: cgroupDriver,
+		fs:           newCgroupFS(sysPath, DefaultProcPath),
+		StatusCache:  sync.Map{},
+	}
+	return &s
+}
+
+func (s *SchedStat) GetType() types.CollectType {
+	return s.name
+}
+
+func (s *SchedStat) Collect() (map[string][]common.TimeSeries, error) {
+	var stateMap = make(map[string][]common.TimeSeries)
+	var now = time.Now()
+
+	if delay, cpus, err := s.fs.nodeRunQueueDelay(); err == nil {
+		if rate, ok := s.rate("node", delay, now); ok {
+			addSample(stateMap, types.MetricNameCpuRunQueueDelay, []common.Label{}, rate/float64(cpus), now)
+		}
+	} else {
+		klog.V(4).Infof("Failed to read node schedstat: %v", err)
+	}
+
+	allPods, err := s.podLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list all pods: %v", err)
+		return stateMap, err
+	}
+
+	for _, pod := range allPods {
+		if utils.IsStaticPod(pod) {
+			continue
+		}
+		s.collectPod(pod, now, stateMap)
+	}
+
+	s.forgetStale(now)
+
+	return stateMap, nil
+}
+
+func (s *SchedStat) collectPod(pod *v1.Pod, now time.Time, stateMap map[string][]common.TimeSeries) {
+	var containerIds []string
+	for _, cs := range pod.Status.ContainerStatuses {
+		containerIds = append(containerIds, utils.GetContainerIdFromKey(cs.ContainerID))
+	}
+
+	dirs, err := s.fs.containerDirs(utils.GetCgroupPath(pod, s.cgroupDriver), containerIds)
+	if err != nil {
+		klog.V(4).Infof("Failed to list container cgroups of pod %s: %v", klog.KObj(pod), err)
+		return
+	}
+
+	for containerId, cgroupPath := range dirs {
+		containerName := utils.GetContainerNameFromPod(pod, containerId)
+		if len(containerName) == 0 {
+			containerName = containerNameByCgroup(pod, containerId)
+		}
+		containerLabels := getContainerLabels(pod, containerId, containerName)
+
+		if throttled, err := s.fs.throttledTime(cgroupPath); err == nil {
+			if rate, ok := s.rate("throttled/"+cgroupPath, throttled, now); ok {
+				addSample(stateMap, types.MetricNameContainerCpuThrottledTime, containerLabels, rate, now)
+			}
+		} else {
+			klog.V(4).Infof("Failed to read throttled time of %s: %v", cgroupPath, err)
+		}
+	}
+}
+
+// rate returns the increase of the accumulated counter in milliseconds per second since the last collection.
+func (s *SchedStat) rate(key string, value uint64, now time.Time) (float64, bool) {
+	last, ok := s.StatusCache.Load(key)
+	s.StatusCache.Store(key, counterState{value: value, timestamp: now})
+	if !ok {
+		return 0, false
+	}
+
+	state := last.(counterState)
+	elapsed := now.Sub(state.timestamp)
+	// the counter decreases when threads exit or the cgroup is recreated
+	if elapsed <= 0 || value < state.value {
+		return 0, false
+	}
+
+	return float64(value-state.value) / float64(time.Millisecond) / elapsed.Seconds(), true
+}
+
+// forgetStale drops the counters not updated in this collection, s.g. the container is deleted.
+func (s *SchedStat) forgetStale(now time.Time) {
+	s.StatusCache.Range(func(key, value interface{}) bool {
+		if value.(counterState).timestamp.Before(now) {
+			s.StatusCache.Delete(key)
+		}
+		return true
+	})
+}
+
+func (s *SchedStat) Stop() error {
+	return nil
+}
+
+func CheckMetricNameExist(name string) bool {
+	for _, vv := range schedStatMetrics {
+		if string(vv) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// containerNameByCgroup finds the container whose id is part of the cgroup dir name, s.g. docker-<id>.scope.
+func containerNameByCgroup(pod *v1.Pod, cgroupDir string) string {
+	for _, cs := range pod.Status.ContainerStatuses {
+		id := utils.GetContainerIdFromKey(cs.ContainerID)
+		if len(id) != 0 && strings.Contains(cgroupDir, id) {
+			return cs.Name
+		}
+	}
+	return ""
+}
+
+func getContainerLabels(pod *v1.Pod, containerId, containerName string) []common.Label {
+	_, hasExtCpuRes := utils.GetContainerExtCpuResFromPod(pod, containerName)
+	_, hasExtMemRes := utils.GetContainerExtMemResFromPod(pod, containerName)
+	return []common.Label{
+		{Name: common.LabelNamePodName, Value: pod.Name},
+		{Name: common.LabelNamePodNamespace, Value: pod.Namespace},
+		{Name: common.LabelNamePodUid, Value: string(pod.UID)},
+		{Name: common.LabelNameContainerName, Value: containerName},
+		{Name: common.LabelNameContainerId, Value: containerId},
+		{Name: common.LabelNameHasExtRes, Value: strconv.FormatBool(hasExtCpuRes || hasExtMemRes)},
+	}
+}
+
+func addSample(stateMap map[string][]common.TimeSeries, metricName types.MetricName, labels []common.Label, value float64, now time.Time) {
+	key := string(metricName)
+	stateMap[key] = append(stateMap[key], common.TimeSeries{
+		Labels:  labels,
+		Samples: []common.Sample{{Value: value, Timestamp: now.Unix()}},
+	})
+}
@@ -0,0 +1,96 @@
+package schedstat
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCgroupFSV1(t *testing.T) {
+	root := t.TempDir()
+	sysPath := filepath.Join(root, "sys")
+	procPath := filepath.Join(root, "proc")
+	podPath := "/kubepods/burstable/pod123"
+	containerDir := filepath.Join(sysPath, "fs", "cgroup", "cpu", podPath, "abcdef")
+
+	writeFile(t, filepath.Join(containerDir, "cpu.stat"), "nr_periods 10\nnr_throttled 2\nthrottled_time 5000000\n")
+	writeFile(t, filepath.Join(procPath, "schedstat"), "version 15\ntimestamp 1\ncpu0 0 0 0 0 0 0 100 2000 10\ncpu1 0 0 0 0 0 0 100 4000 10\ndomain0 3 0\n")
+
+	fs := newCgroupFS(sysPath, procPath)
+	if fs.cpuRoot != filepath.Join(sysPath, "fs", "cgroup", "cpu") {
+		t.Fatalf("expect the cpu controller of cgroup v1, got %s", fs.cpuRoot)
+	}
+
+	dirs, err := fs.containerDirs(podPath, []string{"abcdef", "notexist"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirs["abcdef"] != filepath.Join(podPath, "abcdef") {
+		t.Fatalf("unexpected container dirs %v", dirs)
+	}
+
+	throttled, err := fs.throttledTime(dirs["abcdef"])
+	if err != nil || throttled != 5000000 {
+		t.Errorf("expect throttled time 5000000, got %d, %v", throttled, err)
+	}
+
+	nodeDelay, cpus, err := fs.nodeRunQueueDelay()
+	if err != nil || nodeDelay != 6000 || cpus != 2 {
+		t.Errorf("expect node run queue delay 6000 on 2 cpus, got %d on %d, %v", nodeDelay, cpus, err)
+	}
+}
+
+func TestCgroupFSV2(t *testing.T) {
+	root := t.TempDir()
+	sysPath := filepath.Join(root, "sys")
+	cgroupPath := "/kubepods.slice/kubepods-pod123.slice/cri-containerd-abcdef.scope"
+
+	writeFile(t, filepath.Join(sysPath, "fs", "cgroup", "cgroup.controllers"), "cpu memory")
+	writeFile(t, filepath.Join(sysPath, "fs", "cgroup", cgroupPath, "cpu.stat"), "usage_usec 100\nthrottled_usec 42\n")
+
+	fs := newCgroupFS(sysPath, filepath.Join(root, "proc"))
+	if fs.cpuRoot != filepath.Join(sysPath, "fs", "cgroup") {
+		t.Fatalf("expect the unified hierarchy of cgroup v2, got %s", fs.cpuRoot)
+	}
+
+	throttled, err := fs.throttledTime(cgroupPath)
+	if err != nil || throttled != 42000 {
+		t.Errorf("expect throttled time 42000, got %d, %v", throttled, err)
+	}
+}
+
+func TestRate(t *testing.T) {
+	s := &SchedStat{}
+	now := time.Now()
+
+	if _, ok := s.rate("key", 0, now); ok {
+		t.Errorf("expect no rate for the first sample")
+	}
+	rate, ok := s.rate("key", uint64(2*time.Second/time.Nanosecond), now.Add(10*time.Second))
+	if !ok || rate != 200 {
+		t.Errorf("expect 200ms per second, got %f", rate)
+	}
+	if _, ok = s.rate("key", 0, now.Add(20*time.Second)); ok {
+		t.Errorf("expect no rate when the counter decreases")
+	}
+
+	s.rate("stale", 0, now)
+	s.forgetStale(now.Add(20 * time.Second))
+	if _, ok = s.StatusCache.Load("stale"); ok {
+		t.Errorf("expect stale counter to be dropped")
+	}
+	if _, ok = s.StatusCache.Load("key"); !ok {
+		t.Errorf("expect updated counter to be kept")
+	}
+}
@@ -5,7 +5,9 @@ type CollectType string
 const (
 	NodeLocalCollectorType            CollectType = "node-local"
 	CadvisorCollectorType             CollectType = "cadvisor"
-	EbpfCollectorType                 CollectType = "ebpf"
+	SchedStatCollectorType            CollectType = "schedstat"
+	PsiCollectorType                  CollectType = "psi"
+	PodNetworkCollectorType           CollectType = "pod-network"
 	MetricsServerCollectorType        CollectType = "metrics-server"
 	NodeResourceCollectorType         CollectType = "node-resource"
 	NodeResourceTopologyCollectorType CollectType = "node-resource-topology"
@@ -20,6 +22,8 @@ const (
 	MetricNameCpuLoad5Min         MetricName = "cpu_load_5_min"
 	MetricNameCpuLoad15Min        MetricName = "cpu_load_15_min"
 	MetricNameCpuCoreNumbers      MetricName = "cpu_core_numbers"
+	// MetricNameCpuRunQueueDelay is the average time in milliseconds per second a task waits in the run queue of each cpu
+	MetricNameCpuRunQueueDelay MetricName = "cpu_run_queue_delay"
 
 	MetricNameExclusiveCPUIdle MetricName = "exclusive_cpu_idle"
 
@@ -40,16 +44,48 @@ const (
 	MetricNetworkDropIn       MetricName = "network_drop_in"
 	MetricNetworkDropOut      MetricName = "network_drop_out"
 
+	// The pressure stall information of node, it is the percentage of time in the recent 10s or 60s that some or all
+	// non-idle tasks are stalled on the resource
+	MetricNameCpuSomeAvg10    MetricName = "cpu_some_avg10"
+	MetricNameCpuSomeAvg60    MetricName = "cpu_some_avg60"
+	MetricNameMemorySomeAvg10 MetricName = "memory_some_avg10"
+	MetricNameMemorySomeAvg60 MetricName = "memory_some_avg60"
+	MetricNameMemoryFullAvg10 MetricName = "memory_full_avg10"
+	MetricNameMemoryFullAvg60 MetricName = "memory_full_avg60"
+	MetricNameIoSomeAvg10     MetricName = "io_some_avg10"
+	MetricNameIoSomeAvg60     MetricName = "io_some_avg60"
+	MetricNameIoFullAvg10     MetricName = "io_full_avg10"
+	MetricNameIoFullAvg60     MetricName = "io_full_avg60"
+
 	// Attention: this value is cpuUsageIncrease/timeIncrease, not cpuUsage
 	MetricNameContainerCpuTotalUsage     MetricName = "container_cpu_total_usage"
 	MetricNameContainerCpuLimit          MetricName = "container_cpu_limit"
 	MetricNameContainerCpuQuota          MetricName = "container_cpu_quota"
 	MetricNameContainerCpuPeriod         MetricName = "container_cpu_period"
 	MetricNameContainerSchedRunQueueTime MetricName = "container_sched_run_queue_time"
+	// MetricNameContainerCpuThrottledTime is the time in milliseconds per second the container is throttled by cfs quota
+	MetricNameContainerCpuThrottledTime MetricName = "container_cpu_throttled_time"
 
 	MetricNameExtResContainerCpuTotalUsage MetricName = "ext_res_container_cpu_total_usage"
 	MetricNameExtCpuTotalDistribute        MetricName = "ext_cpu_total_distribute"
 
 	MetricNameContainerMemTotalUsage       MetricName = "container_mem_total_usage"
 	MetricNameExtResContainerMemTotalUsage MetricName = "ext_res_container_mem_total_usage"
+
+	MetricNameContainerDiskReadKiBPS  MetricName = "container_disk_read_kibps"
+	MetricNameContainerDiskWriteKiBPS MetricName = "container_disk_write_kibps"
+	// MetricNamePodNetworkSentKiBPS is the kilobits per second sent by pod, the same unit as network_sent_kibps
+	MetricNamePodNetworkSentKiBPS MetricName = "pod_network_sent_kibps"
+
+	// The pressure stall information of pod, read from the pod cgroup of cgroup v2
+	MetricNamePodCpuSomeAvg10    MetricName = "pod_cpu_some_avg10"
+	MetricNamePodCpuSomeAvg60    MetricName = "pod_cpu_some_avg60"
+	MetricNamePodMemorySomeAvg10 MetricName = "pod_memory_some_avg10"
+	MetricNamePodMemorySomeAvg60 MetricName = "pod_memory_some_avg60"
+	MetricNamePodMemoryFullAvg10 MetricName = "pod_memory_full_avg10"
+	MetricNamePodMemoryFullAvg60 MetricName = "pod_memory_full_avg60"
+	MetricNamePodIoSomeAvg10     MetricName = "pod_io_some_avg10"
+	MetricNamePodIoSomeAvg60     MetricName = "pod_io_some_avg60"
+	MetricNamePodIoFullAvg10     MetricName = "pod_io_full_avg10"
+	MetricNamePodIoFullAvg60     MetricName = "pod_io_full_avg60"
 )
@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/audit"
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+// startAudit clears the pods and gaps of the last action
+func (ctx *ExecuteContext) startAudit() {
+	ctx.actedPods = nil
+	ctx.actionGaps = nil
+}
+
+// auditPod remembers the pod acted on, a pod is acted on if the action releases any resource of it
+func (ctx *ExecuteContext) auditPod(pod podinfo.PodContext, released ReleaseResource) {
+	if released == nil {
+		return
+	}
+	key := pod.Key.String()
+	ctx.podSucceeded(key)
+	for _, p := range ctx.actedPods {
+		if p == key {
+			return
+		}
+	}
+	ctx.actedPods = append(ctx.actedPods, key)
+}
+
+// auditGaps remembers the gaps before acting, the metrics whose usage is missed are skipped
+func (ctx *ExecuteContext) auditGaps(gaps Gaps) {
+	ctx.actionGaps = Gaps{}
+	for m, v := range gaps {
+		if v != maxFloat {
+			ctx.actionGaps[m] = v
+		}
+	}
+}
+
+// recordAction adds the action to the history if it acted on any pod or failed
+func (ctx *ExecuteContext) recordAction(action audit.Action, rules []string, watermarks Watermarks, err error) {
+	if ctx.History == nil || (len(ctx.actedPods) == 0 && err == nil) {
+		return
+	}
+
+	record := audit.Record{
+		Action:     action,
+		Rules:      rules,
+		Metrics:    map[string]float64{},
+		Watermarks: map[string]float64{},
+		Pods:       ctx.actedPods,
+	}
+	for m, w := range watermarks {
+		if w == nil || w.Len() == 0 {
+			continue
+		}
+		record.Watermarks[string(m)] = float64(w.PopSmallest().Value())
+		if used, ok := nodeUsage(ctx.stateMap, m); ok {
+			record.Metrics[string(m)] = used
+		}
+	}
+	if len(ctx.actionGaps) != 0 {
+		record.Gaps = map[string]float64{}
+		for m, v := range ctx.actionGaps {
+			record.Gaps[string(m)] = v
+		}
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	klog.V(4).Infof("Record avoidance action %s of rules %v on pods %v", action, rules, record.Pods)
+	ctx.History.Add(record)
+}
+
+// recordSchedule adds the schedule action to the history if the schedule setting is changed or it failed.
+// The schedule action is done in every round of analysis, so the unchanged setting is not recorded.
+func (ctx *ExecuteContext) recordSchedule(action audit.Action, rules []string, err error) {
+	if ctx.History == nil {
+		return
+	}
+
+	if err == nil {
+		last, ok := ctx.History.Latest(audit.ActionDisableSchedule, audit.ActionEnableSchedule)
+		if (ok && last.Action == action && len(last.Error) == 0) || (!ok && action == audit.ActionEnableSchedule) {
+			return
+		}
+	}
+
+	record := audit.Record{
+		Action: action,
+		Rules:  rules,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	klog.V(4).Infof("Record avoidance action %s of rules %v", action, rules)
+	ctx.History.Add(record)
+}
+
+// nodeUsage returns the biggest value of the metric in the state map, e.g. the busiest disk or network interface
+func nodeUsage(stateMap map[string][]common.TimeSeries, m WatermarkMetric) (float64, bool) {
+	series, ok := stateMap[string(m)]
+	if !ok {
+		return 0, false
+	}
+
+	var maxUsed float64
+	for _, ts := range series {
+		if len(ts.Samples) > 0 && ts.Samples[0].Value > maxUsed {
+			maxUsed = ts.Samples[0].Value
+		}
+	}
+	return maxUsed, true
+}
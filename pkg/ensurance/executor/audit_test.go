@@ -0,0 +1,113 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/audit"
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestRecordAction(t *testing.T) {
+	history, _ := audit.NewStore(10, "")
+	ctx := &ExecuteContext{
+		History: history,
+		stateMap: map[string][]common.TimeSeries{
+			string(CpuUsage): {{Samples: []common.Sample{{Value: 6}}}},
+		},
+	}
+	watermarks := Watermarks{CpuUsage: &Watermark{resource.MustParse("4")}}
+
+	// nothing is recorded if no pod is acted on
+	ctx.startAudit()
+	ctx.recordAction(audit.ActionThrottleDown, []string{"qos/cpu"}, watermarks, nil)
+	if records := history.List(audit.Query{}); len(records) != 0 {
+		t.Fatalf("expect no record, got %v", records)
+	}
+
+	pod := podinfo.PodContext{Key: types.NamespacedName{Namespace: "default", Name: "a"}}
+	ctx.startAudit()
+	ctx.auditGaps(Gaps{CpuUsage: 2, MemUsage: maxFloat})
+	ctx.auditPod(pod, nil)
+	ctx.auditPod(pod, ReleaseResource{CpuUsage: 1})
+	ctx.auditPod(pod, ReleaseResource{CpuUsage: 1})
+	ctx.recordAction(audit.ActionThrottleDown, []string{"qos/cpu"}, watermarks, errors.New("failed"))
+
+	records := history.List(audit.Query{})
+	if len(records) != 1 {
+		t.Fatalf("expect one record, got %v", records)
+	}
+	r := records[0]
+	if len(r.Pods) != 1 || r.Pods[0] != "default/a" || r.Rules[0] != "qos/cpu" || r.Error != "failed" {
+		t.Errorf("unexpected record %v", r)
+	}
+	if r.Metrics[string(CpuUsage)] != 6 || r.Watermarks[string(CpuUsage)] != 4 || r.Gaps[string(CpuUsage)] != 2 || len(r.Gaps) != 1 {
+		t.Errorf("unexpected values of record %v", r)
+	}
+}
+
+func TestAuditEvictions(t *testing.T) {
+	existing := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"}}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(existing); err != nil {
+		t.Fatal(err)
+	}
+	history, _ := audit.NewStore(10, "")
+	ctx := &ExecuteContext{
+		History:   history,
+		Client:    fake.NewSimpleClientset(existing),
+		PodLister: corelisters.NewPodLister(indexer),
+	}
+
+	var pods EvictPods
+	for _, name := range []string{"existing", "missing"} {
+		pods = append(pods, podinfo.PodContext{Key: types.NamespacedName{Namespace: "default", Name: name}, ActionType: podinfo.Evict, PodCPUUsage: 1})
+	}
+	e := &EvictExecutor{EvictPods: pods}
+
+	// the pod failed to evict in the goroutine is not recorded as evicted
+	ctx.startAudit()
+	errPodKeys := e.evictPods(ctx, &ReleaseResource{}, CpuUsage)
+	if len(errPodKeys) != 2 || errPodKeys[1] != "default/missing" {
+		t.Errorf("expect the error of the missing pod, got %v", errPodKeys)
+	}
+	if len(ctx.actedPods) != 1 || ctx.actedPods[0] != "default/existing" {
+		t.Errorf("expect only the existing pod acted on, got %v", ctx.actedPods)
+	}
+}
+
+func TestRecordSchedule(t *testing.T) {
+	history, _ := audit.NewStore(10, "")
+	ctx := &ExecuteContext{History: history}
+
+	tests := []struct {
+		action audit.Action
+		err    error
+		expect int
+	}{
+		// the scheduling is enabled by default
+		{audit.ActionEnableSchedule, nil, 0},
+		{audit.ActionDisableSchedule, nil, 1},
+		{audit.ActionDisableSchedule, nil, 1},
+		{audit.ActionDisableSchedule, errors.New("failed"), 2},
+		{audit.ActionDisableSchedule, nil, 3},
+		{audit.ActionEnableSchedule, nil, 4},
+		{audit.ActionEnableSchedule, nil, 4},
+	}
+
+	for i, test := range tests {
+		ctx.recordSchedule(test.action, nil, test.err)
+		if records := history.List(audit.Query{}); len(records) != test.expect {
+			t.Errorf("step %d: expect %d records, got %d", i, test.expect, len(records))
+		}
+	}
+}
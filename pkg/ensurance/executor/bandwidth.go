@@ -0,0 +1,189 @@
+package executor
+
+import (
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/ensurance/executor/bandwidth"
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/ensurance/executor/sort"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// bandwidthResource describes how to get and limit the disk or network bandwidth of a pod
+type bandwidthResource struct {
+	metric   WatermarkMetric
+	usage    func(pod podinfo.PodContext) float64
+	throttle func(pod podinfo.PodContext) podinfo.BandwidthThrottle
+	getLimit func(ctx *ExecuteContext, cgroupPath string) (float64, bool, error)
+	setLimit func(ctx *ExecuteContext, cgroupPath string, limit float64) error
+}
+
+var diskRead = bandwidthResource{
+	metric:   DiskReadKiBPS,
+	usage:    func(pod podinfo.PodContext) float64 { return pod.PodDiskReadKiBps },
+	throttle: func(pod podinfo.PodContext) podinfo.BandwidthThrottle { return pod.DiskThrottle },
+	getLimit: func(ctx *ExecuteContext, cgroupPath string) (float64, bool, error) {
+		return ctx.BandwidthLimiter.DiskLimit(cgroupPath, bandwidth.Read)
+	},
+	setLimit: func(ctx *ExecuteContext, cgroupPath string, limit float64) error {
+		return ctx.BandwidthLimiter.SetDiskLimit(cgroupPath, bandwidth.Read, limit)
+	},
+}
+
+var diskWrite = bandwidthResource{
+	metric:   DiskWriteKiBPS,
+	usage:    func(pod podinfo.PodContext) float64 { return pod.PodDiskWriteKiBps },
+	throttle: func(pod podinfo.PodContext) podinfo.BandwidthThrottle { return pod.DiskThrottle },
+	getLimit: func(ctx *ExecuteContext, cgroupPath string) (float64, bool, error) {
+		return ctx.BandwidthLimiter.DiskLimit(cgroupPath, bandwidth.Write)
+	},
+	setLimit: func(ctx *ExecuteContext, cgroupPath string, limit float64) error {
+		return ctx.BandwidthLimiter.SetDiskLimit(cgroupPath, bandwidth.Write, limit)
+	},
+}
+
+var networkSent = bandwidthResource{
+	metric:   NetworkSentKiBPS,
+	usage:    func(pod podinfo.PodContext) float64 { return pod.PodNetworkSentKiBps },
+	throttle: func(pod podinfo.PodContext) podinfo.BandwidthThrottle { return pod.NetworkThrottle },
+	getLimit: func(ctx *ExecuteContext, cgroupPath string) (float64, bool, error) {
+		return ctx.BandwidthLimiter.EgressLimit(cgroupPath)
+	},
+	setLimit: func(ctx *ExecuteContext, cgroupPath string, limit float64) error {
+		return ctx.BandwidthLimiter.SetEgressLimit(cgroupPath, limit)
+	},
+}
+
+func init() {
+	registerMetricMap(newBandwidthMetric(diskRead, sort.DiskReadSort))
+	registerMetricMap(newBandwidthMetric(diskWrite, sort.DiskWriteSort))
+	registerMetricMap(newBandwidthMetric(networkSent, sort.NetworkSentSort))
+}
+
+func newBandwidthMetric(r bandwidthResource, sortFunc func(pods []podinfo.PodContext)) metric {
+	return metric{
+		Name:           r.metric,
+		ActionPriority: 3,
+		Sortable:       true,
+		SortFunc:       sortFunc,
+
+		Throttleable:       true,
+		ThrottleQuantified: true,
+		ThrottleFunc:       throttleOnePodBandwidth(r),
+		RestoreFunc:        restoreOnePodBandwidth(r),
+
+		Evictable:       false,
+		EvictQuantified: false,
+		EvictFunc:       nil,
+	}
+}
+
+// throttleOnePodBandwidth lowers the bandwidth limit of pod by StepRatio of its current limit or usage, but not
+// lower than MinKiBps. The released resource is the usage above the new limit.
+func throttleOnePodBandwidth(r bandwidthResource) func(ctx *ExecuteContext, index int, ThrottleDownPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	return func(ctx *ExecuteContext, index int, ThrottleDownPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+		podContext := ThrottleDownPods[index]
+		cgroupPath, err := getPodCgroupPath(ctx, podContext)
+		if err != nil {
+			errPodKeys = append(errPodKeys, err.Error())
+			return
+		}
+
+		limit, limited, err := r.getLimit(ctx, cgroupPath)
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to get %s limit of pod %s: %v", r.metric, podContext.Key.String(), err))
+			return
+		}
+
+		usage := r.usage(podContext)
+		throttle := r.throttle(podContext)
+		newLimit := calculateThrottleDownLimit(usage, limit, limited, throttle)
+		if (limited && newLimit >= limit) || (!limited && newLimit >= usage) {
+			klog.V(6).Infof("The %s of pod %s is not throttled, usage %.2f, limit %.2f", r.metric, podContext.Key.String(), usage, limit)
+			return
+		}
+
+		if err = r.setLimit(ctx, cgroupPath, newLimit); err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to set %s limit of pod %s: %v", r.metric, podContext.Key.String(), err))
+			return
+		}
+		klog.V(4).Infof("ThrottleExecutor avoid pod %s, set %s limit %.2f", podContext.Key.String(), r.metric, newLimit)
+		ctx.markThrottled(podContext.Key, r.metric, true)
+
+		released = ReleaseResource{}
+		if usage > newLimit {
+			released[r.metric] = usage - newLimit
+		}
+		totalReleasedResource.Add(released)
+		return
+	}
+}
+
+// restoreOnePodBandwidth raises the bandwidth limit of pod by StepRatio, the limit is removed when it exceeds MaxKiBps.
+func restoreOnePodBandwidth(r bandwidthResource) func(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	return func(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+		podContext := ThrottleUpPods[index]
+		cgroupPath, err := getPodCgroupPath(ctx, podContext)
+		if err != nil {
+			errPodKeys = append(errPodKeys, err.Error())
+			return
+		}
+
+		limit, limited, err := r.getLimit(ctx, cgroupPath)
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to get %s limit of pod %s: %v", r.metric, podContext.Key.String(), err))
+			return
+		}
+		if !limited {
+			ctx.markThrottled(podContext.Key, r.metric, false)
+			return
+		}
+
+		throttle := r.throttle(podContext)
+		newLimit := limit * (1.0 + float64(throttle.StepRatio)/MaxRatio)
+		if newLimit >= throttle.MaxKiBps {
+			newLimit = throttle.MaxKiBps
+			err = r.setLimit(ctx, cgroupPath, 0)
+		} else {
+			err = r.setLimit(ctx, cgroupPath, newLimit)
+		}
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to restore %s limit of pod %s: %v", r.metric, podContext.Key.String(), err))
+			return
+		}
+		klog.V(4).Infof("ThrottleExecutor restore pod %s, raise %s limit from %.2f to %.2f", podContext.Key.String(), r.metric, limit, newLimit)
+		if newLimit >= throttle.MaxKiBps {
+			ctx.markThrottled(podContext.Key, r.metric, false)
+		}
+
+		released = ReleaseResource{r.metric: newLimit - limit}
+		totalReleasedResource.Add(released)
+		return
+	}
+}
+
+func calculateThrottleDownLimit(usage, limit float64, limited bool, throttle podinfo.BandwidthThrottle) float64 {
+	base := usage
+	if limited && limit < base {
+		base = limit
+	}
+	newLimit := base * (1.0 - float64(throttle.StepRatio)/MaxRatio)
+	if newLimit < throttle.MinKiBps {
+		newLimit = throttle.MinKiBps
+	}
+	return newLimit
+}
+
+func getPodCgroupPath(ctx *ExecuteContext, podContext podinfo.PodContext) (string, error) {
+	pod, err := ctx.PodLister.Pods(podContext.Key.Namespace).Get(podContext.Key.Name)
+	if err != nil {
+		return "", fmt.Errorf("pod %s not found", podContext.Key.String())
+	}
+	cgroupPath := utils.GetCgroupPath(pod, ctx.CgroupDriver)
+	if len(cgroupPath) == 0 {
+		return "", fmt.Errorf("unknown cgroup of pod %s", podContext.Key.String())
+	}
+	return cgroupPath, nil
+}
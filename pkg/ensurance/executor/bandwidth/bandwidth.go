@@ -0,0 +1,146 @@
+package bandwidth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+type Direction string
+
+const (
+	Read  Direction = "read"
+	Write Direction = "write"
+)
+
+// Limiter limits the disk bandwidth of pod cgroups by blkio throttle of cgroup v1 or io.max of cgroup v2,
+// and the egress bandwidth of pods by a tbf qdisc in the network namespace of pod.
+type Limiter struct {
+	sysPath string
+	// blkioRoot is the mount point of blkio controller in cgroup v1, or the mount point of cgroup v2
+	blkioRoot string
+	unified   bool
+}
+
+func NewLimiter(sysPath string) *Limiter {
+	root, unified := utils.GetCgroupRoot(sysPath, "blkio")
+	return &Limiter{
+		sysPath:   sysPath,
+		blkioRoot: root,
+		unified:   unified,
+	}
+}
+
+// DiskLimit returns the smallest limit in KiB/s of the devices, false if the cgroup is not limited.
+func (l *Limiter) DiskLimit(cgroupPath string, direction Direction) (float64, bool, error) {
+	content, err := ioutil.ReadFile(filepath.Join(l.blkioRoot, cgroupPath, l.diskLimitFile(direction)))
+	if err != nil {
+		return 0, false, err
+	}
+
+	var limit float64
+	var limited bool
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		value := fields[1]
+		if l.unified {
+			value = ""
+			for _, field := range fields[1:] {
+				if kv := strings.SplitN(field, "=", 2); len(kv) == 2 && kv[0] == ioMaxKey(direction) {
+					value = kv[1]
+				}
+			}
+		}
+
+		bps, err := strconv.ParseUint(value, 10, 64)
+		// max or 0 means no limit
+		if err != nil || bps == 0 {
+			continue
+		}
+		kibps := float64(bps) / 1024
+		if !limited || kibps < limit {
+			limit = kibps
+		}
+		limited = true
+	}
+	return limit, limited, nil
+}
+
+// SetDiskLimit limits the bandwidth of all the physical disks in KiB/s, a non-positive value removes the limit.
+func (l *Limiter) SetDiskLimit(cgroupPath string, direction Direction, kibps float64) error {
+	devices, err := l.devices()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(l.blkioRoot, cgroupPath, l.diskLimitFile(direction))
+	for _, device := range devices {
+		var value string
+		if kibps > 0 {
+			value = strconv.FormatUint(uint64(kibps*1024), 10)
+		} else if l.unified {
+			value = "max"
+		} else {
+			value = "0"
+		}
+
+		line := fmt.Sprintf("%s %s", device, value)
+		if l.unified {
+			line = fmt.Sprintf("%s %s=%s", device, ioMaxKey(direction), value)
+		}
+		// the kernel accepts one device per write
+		if err = ioutil.WriteFile(path, []byte(line), 0644); err != nil {
+			return fmt.Errorf("failed to write %q to %s: %v", line, path, err)
+		}
+	}
+	return nil
+}
+
+func (l *Limiter) diskLimitFile(direction Direction) string {
+	if l.unified {
+		return "io.max"
+	}
+	return fmt.Sprintf("blkio.throttle.%s_bps_device", direction)
+}
+
+func ioMaxKey(direction Direction) string {
+	if direction == Read {
+		return "rbps"
+	}
+	return "wbps"
+}
+
+// devices returns the major:minor of physical disks in /sys/block, the virtual devices such as loop and
+// device mapper are skipped.
+func (l *Limiter) devices() ([]string, error) {
+	blockPath := filepath.Join(l.sysPath, "block")
+	entries, err := ioutil.ReadDir(blockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []string
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(blockPath, entry.Name(), "device")); err != nil {
+			continue
+		}
+		dev, err := ioutil.ReadFile(filepath.Join(blockPath, entry.Name(), "dev"))
+		if err != nil {
+			continue
+		}
+		devices = append(devices, strings.TrimSpace(string(dev)))
+	}
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("no physical disk found in %s", blockPath)
+	}
+	return devices, nil
+}
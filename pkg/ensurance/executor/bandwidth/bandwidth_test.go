@@ -0,0 +1,94 @@
+package bandwidth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func writeDevices(t *testing.T, sysPath string) {
+	writeFile(t, filepath.Join(sysPath, "block", "vda", "dev"), "253:0\n")
+	writeFile(t, filepath.Join(sysPath, "block", "vda", "device", "model"), "")
+	// virtual device without device dir is skipped
+	writeFile(t, filepath.Join(sysPath, "block", "loop0", "dev"), "7:0\n")
+}
+
+func TestDiskLimitV1(t *testing.T) {
+	sysPath := t.TempDir()
+	podPath := "/kubepods/besteffort/pod123"
+	readFilePath := filepath.Join(sysPath, "fs", "cgroup", "blkio", podPath, "blkio.throttle.read_bps_device")
+	writeDevices(t, sysPath)
+	writeFile(t, readFilePath, "")
+
+	l := NewLimiter(sysPath)
+	if l.unified {
+		t.Fatalf("expect cgroup v1")
+	}
+
+	if _, limited, err := l.DiskLimit(podPath, Read); err != nil || limited {
+		t.Errorf("expect not limited, got %v, %v", limited, err)
+	}
+
+	if err := l.SetDiskLimit(podPath, Read, 1024); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, readFilePath); content != "253:0 1048576" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if limit, limited, err := l.DiskLimit(podPath, Read); err != nil || !limited || limit != 1024 {
+		t.Errorf("expect limit 1024, got %f, %v, %v", limit, limited, err)
+	}
+
+	if err := l.SetDiskLimit(podPath, Read, 0); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, readFilePath); content != "253:0 0" {
+		t.Errorf("unexpected content %q", content)
+	}
+}
+
+func TestDiskLimitV2(t *testing.T) {
+	sysPath := t.TempDir()
+	podPath := "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod123.slice"
+	ioMaxPath := filepath.Join(sysPath, "fs", "cgroup", podPath, "io.max")
+	writeDevices(t, sysPath)
+	writeFile(t, filepath.Join(sysPath, "fs", "cgroup", "cgroup.controllers"), "io cpu memory")
+	writeFile(t, ioMaxPath, "253:0 rbps=max wbps=2097152 riops=max wiops=max\n")
+
+	l := NewLimiter(sysPath)
+	if !l.unified {
+		t.Fatalf("expect cgroup v2")
+	}
+
+	if _, limited, err := l.DiskLimit(podPath, Read); err != nil || limited {
+		t.Errorf("expect read not limited, got %v, %v", limited, err)
+	}
+	if limit, limited, err := l.DiskLimit(podPath, Write); err != nil || !limited || limit != 2048 {
+		t.Errorf("expect write limit 2048, got %f, %v, %v", limit, limited, err)
+	}
+
+	if err := l.SetDiskLimit(podPath, Write, 0); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, ioMaxPath); content != "253:0 wbps=max" {
+		t.Errorf("unexpected content %q", content)
+	}
+}
@@ -0,0 +1,117 @@
+//go:build linux
+// +build linux
+
+package bandwidth
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const (
+	// minBurst is the min bucket size in bytes of tbf, it should be larger than the mtu
+	minBurst = 16 * 1024
+	// tbfLatency is the max time in seconds a packet waits in the tbf queue
+	tbfLatency = 0.05
+)
+
+// EgressLimit returns the egress limit in kbit/s of pod, false if the pod is not limited.
+func (l *Limiter) EgressLimit(cgroupPath string) (float64, bool, error) {
+	var limit float64
+	var limited bool
+	err := l.inPodNetns(cgroupPath, func(h *netlink.Handle, link netlink.Link) error {
+		qdiscs, err := h.QdiscList(link)
+		if err != nil {
+			return err
+		}
+		for _, qdisc := range qdiscs {
+			if tbf, ok := qdisc.(*netlink.Tbf); ok && tbf.Parent == netlink.HANDLE_ROOT {
+				kbps := float64(tbf.Rate) * 8 / 1000
+				if !limited || kbps < limit {
+					limit = kbps
+				}
+				limited = true
+			}
+		}
+		return nil
+	})
+	return limit, limited, err
+}
+
+// SetEgressLimit limits the egress bandwidth of pod in kbit/s, a non-positive value removes the limit.
+func (l *Limiter) SetEgressLimit(cgroupPath string, kbps float64) error {
+	return l.inPodNetns(cgroupPath, func(h *netlink.Handle, link netlink.Link) error {
+		attrs := netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		}
+
+		if kbps <= 0 {
+			qdiscs, err := h.QdiscList(link)
+			if err != nil {
+				return err
+			}
+			for _, qdisc := range qdiscs {
+				if tbf, ok := qdisc.(*netlink.Tbf); ok && tbf.Parent == netlink.HANDLE_ROOT {
+					return h.QdiscDel(tbf)
+				}
+			}
+			return nil
+		}
+
+		rate := uint64(kbps * 1000 / 8)
+		burst := uint32(rate / 10)
+		if burst < minBurst {
+			burst = minBurst
+		}
+		tbf := &netlink.Tbf{
+			QdiscAttrs: attrs,
+			Rate:       rate,
+			Limit:      uint32(float64(rate)*tbfLatency) + burst,
+			Buffer:     uint32(netlink.Xmittime(rate, burst)),
+		}
+		return h.QdiscReplace(tbf)
+	})
+}
+
+// inPodNetns calls fn for each link that is up in the network namespace of pod, except the loopback.
+func (l *Limiter) inPodNetns(cgroupPath string, fn func(h *netlink.Handle, link netlink.Link) error) error {
+	root, _ := utils.GetCgroupRoot(l.sysPath, "cpu")
+	pid, err := utils.GetFirstPidInCgroup(root, cgroupPath)
+	if err != nil {
+		return err
+	}
+
+	ns, err := netns.GetFromPid(pid)
+	if err != nil {
+		return fmt.Errorf("failed to get network namespace of pid %d: %v", pid, err)
+	}
+	defer ns.Close()
+
+	h, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		return err
+	}
+	defer h.Delete()
+
+	links, err := h.LinkList()
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		flags := link.Attrs().Flags
+		if flags&net.FlagLoopback != 0 || flags&net.FlagUp == 0 {
+			continue
+		}
+		if err = fn(h, link); err != nil {
+			return fmt.Errorf("failed on link %s: %v", link.Attrs().Name, err)
+		}
+	}
+	return nil
+}
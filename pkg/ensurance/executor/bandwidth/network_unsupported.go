@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package bandwidth
+
+import "fmt"
+
+func (l *Limiter) EgressLimit(cgroupPath string) (float64, bool, error) {
+	return 0, false, fmt.Errorf("egress limit is not supported on this platform")
+}
+
+func (l *Limiter) SetEgressLimit(cgroupPath string, kbps float64) error {
+	return fmt.Errorf("egress limit is not supported on this platform")
+}
@@ -0,0 +1,32 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestCalculateThrottleDownLimit(t *testing.T) {
+	throttle := podinfo.BandwidthThrottle{MinKiBps: 100, MaxKiBps: 10000, StepRatio: 20}
+
+	tests := []struct {
+		name    string
+		usage   float64
+		limit   float64
+		limited bool
+		expect  float64
+	}{
+		{name: "not limited", usage: 1000, expect: 800},
+		{name: "limited above usage", usage: 1000, limit: 2000, limited: true, expect: 800},
+		{name: "limited below usage", usage: 1000, limit: 500, limited: true, expect: 400},
+		{name: "min limit", usage: 110, expect: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calculateThrottleDownLimit(tt.usage, tt.limit, tt.limited, throttle); got != tt.expect {
+				t.Errorf("expect limit %f, got %f", tt.expect, got)
+			}
+		})
+	}
+}
@@ -106,6 +106,7 @@ func throttleOnePodCpu(ctx *ExecuteContext, index int, ThrottleDownPods Throttle
 				klog.V(6).Infof("For pod %s, container %s, release %f cpu usage", ThrottleDownPods[index].Key.String(), container.Name, released[CpuUsage])
 
 				totalReleasedResource.Add(released)
+				ctx.markThrottled(ThrottleDownPods[index].Key, CpuUsage, true)
 			}
 		}
 	}
@@ -210,13 +211,14 @@ func cpuUsageEvictPod(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalR
 
 		pod, err := ctx.PodLister.Pods(evictPod.Key.Namespace).Get(evictPod.Key.Name)
 		if err != nil {
-			errPodKeys = append(errPodKeys, "not found ", evictPod.Key.String())
+			ctx.evictFailed("not found ", evictPod.Key.String())
 			return
 		}
 		klog.Warningf("Evicting pod %v", evictPod.Key)
 		err = utils.EvictPodWithGracePeriod(ctx.Client, pod, evictPod.DeletionGracePeriodSeconds)
 		if err != nil {
-			errPodKeys = append(errPodKeys, "evict failed ", evictPod.Key.String())
+			ctx.evictFailed("evict failed ", evictPod.Key.String())
+			ctx.actionFailed(podinfo.Evict, CpuUsage, evictPod)
 			klog.Warningf("Failed to evict pod %s: %v", evictPod.Key.String(), err)
 			return
 		}
@@ -43,13 +43,14 @@ func cpuUsagePercentEvictPod(wg *sync.WaitGroup, ctx *ExecuteContext, index int,
 
 		pod, err := ctx.PodLister.Pods(evictPod.Key.Namespace).Get(evictPod.Key.Name)
 		if err != nil {
-			errPodKeys = append(errPodKeys, "not found ", evictPod.Key.String())
+			ctx.evictFailed("not found ", evictPod.Key.String())
 			return
 		}
 		klog.Warningf("Evicting pod %v", evictPod.Key)
 		err = utils.EvictPodWithGracePeriod(ctx.Client, pod, evictPod.DeletionGracePeriodSeconds)
 		if err != nil {
-			errPodKeys = append(errPodKeys, "evict failed ", evictPod.Key.String())
+			ctx.evictFailed("evict failed ", evictPod.Key.String())
+			ctx.actionFailed(podinfo.Evict, CpuUsagePercent, evictPod)
 			klog.Warningf("Failed to evict pod %s: %v", evictPod.Key.String(), err)
 			return
 		}
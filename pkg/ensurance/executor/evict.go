@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
 	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
@@ -19,6 +20,8 @@ type EvictExecutor struct {
 	EvictPods EvictPods
 	// All metrics(not only can be quantified metrics) metioned in triggerd NodeQOS and their corresponding watermarks
 	EvictWatermark Watermarks
+	// The NodeQOS rules triggered, in the format of <NodeQOS>/<Rule>
+	EvictRules []string
 }
 
 type EvictPods []podinfo.PodContext
@@ -73,6 +76,7 @@ func (e *EvictExecutor) Avoid(ctx *ExecuteContext) error {
 		}
 	} else {
 		ctx.ToBeEvict = calculateGaps(ctx.stateMap, nil, e, ctx.executeExcessPercent)
+		ctx.auditGaps(ctx.ToBeEvict)
 
 		if ctx.ToBeEvict.HasUsageMissedMetric() {
 			klog.V(6).Infof("There is a metric usage missed")
@@ -83,6 +87,7 @@ func (e *EvictExecutor) Avoid(ctx *ExecuteContext) error {
 		} else {
 			// The metrics in ToBeEvict are can be EvictQuantified and has current usage, then evict precisely
 			var released ReleaseResource
+			var evicted []evictedPod
 			wg := sync.WaitGroup{}
 			for _, m := range quantified {
 				klog.V(6).Infof("Evict precisely on metric %s, and current gaps are %+v", m, ctx.ToBeEvict)
@@ -102,6 +107,7 @@ func (e *EvictExecutor) Avoid(ctx *ExecuteContext) error {
 						errKeys, released = metricMap[m].EvictFunc(&wg, ctx, index, &totalReleased, e.EvictPods)
 						errPodKeys = append(errPodKeys, errKeys...)
 						klog.Warningf("Evicted pods %s, released %f of %s", e.EvictPods[index].Key, released[m], m)
+						evicted = append(evicted, evictedPod{pod: e.EvictPods[index], released: released})
 						e.EvictPods[index].Executed = true
 						ctx.ToBeEvict[m] -= released[m]
 					} else {
@@ -111,6 +117,9 @@ func (e *EvictExecutor) Avoid(ctx *ExecuteContext) error {
 				}
 			}
 			wg.Wait()
+			evictErrPodKeys := ctx.takeEvictErrPodKeys()
+			errPodKeys = append(errPodKeys, evictErrPodKeys...)
+			ctx.auditEvictions(evicted, evictErrPodKeys)
 		}
 	}
 
@@ -126,11 +135,50 @@ func (e *EvictExecutor) Restore(ctx *ExecuteContext) error {
 }
 
 func (e *EvictExecutor) evictPods(ctx *ExecuteContext, totalReleasedResource *ReleaseResource, m WatermarkMetric) (errPodKeys []string) {
+	var evicted []evictedPod
 	wg := sync.WaitGroup{}
 	for i := range e.EvictPods {
-		errKeys, _ := metricMap[m].EvictFunc(&wg, ctx, i, totalReleasedResource, e.EvictPods)
+		errKeys, released := metricMap[m].EvictFunc(&wg, ctx, i, totalReleasedResource, e.EvictPods)
 		errPodKeys = append(errPodKeys, errKeys...)
+		evicted = append(evicted, evictedPod{pod: e.EvictPods[i], released: released})
 	}
 	wg.Wait()
+	evictErrPodKeys := ctx.takeEvictErrPodKeys()
+	errPodKeys = append(errPodKeys, evictErrPodKeys...)
+	ctx.auditEvictions(evicted, evictErrPodKeys)
 	return
 }
+
+// evictedPod is a pod whose eviction is started in a goroutine and the resource expected to be released by it
+type evictedPod struct {
+	pod      podinfo.PodContext
+	released ReleaseResource
+}
+
+// auditEvictions remembers the pods evicted once the evictions in goroutines are done. The pods failed to evict are
+// skipped, their keys are in the errors remembered by evictFailed.
+func (ctx *ExecuteContext) auditEvictions(evicted []evictedPod, evictErrPodKeys []string) {
+	failed := sets.NewString(evictErrPodKeys...)
+	for _, e := range evicted {
+		if !failed.Has(e.pod.Key.String()) {
+			ctx.auditPod(e.pod, e.released)
+		}
+	}
+}
+
+// evictFailed remembers the error of an eviction in a goroutine, the errors can't be returned by the EvictFunc
+// because it returns before the goroutine is done. The key of the pod should be one of the errPodKeys.
+func (ctx *ExecuteContext) evictFailed(errPodKeys ...string) {
+	ctx.resultLock.Lock()
+	defer ctx.resultLock.Unlock()
+	ctx.evictErrPodKeys = append(ctx.evictErrPodKeys, errPodKeys...)
+}
+
+// takeEvictErrPodKeys returns and clears the errors of the evictions in goroutines, it is called once they are done
+func (ctx *ExecuteContext) takeEvictErrPodKeys() []string {
+	ctx.resultLock.Lock()
+	defer ctx.resultLock.Unlock()
+	errPodKeys := ctx.evictErrPodKeys
+	ctx.evictErrPodKeys = nil
+	return errPodKeys
+}
@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"sync"
+
+	"github.com/gocrane/crane/pkg/ensurance/executor/sort"
+)
+
+func init() {
+	registerMetricMap(exclusiveCpuIdle)
+}
+
+// exclusiveCpuIdle is the idle cpu of the exclusive cores, its watermarks are lower bounds. The cpu released from the
+// pods is counted as the idle cpu gained, since both of them are in milli cores.
+var exclusiveCpuIdle = metric{
+	Name:           ExclusiveCpuIdle,
+	ActionPriority: 5,
+	LowerBound:     true,
+	Sortable:       true,
+	SortFunc:       sort.CpuUsageSort,
+
+	Throttleable:       true,
+	ThrottleQuantified: true,
+	ThrottleFunc:       throttleOnePodExclusiveCpuIdle,
+	RestoreFunc:        restoreOnePodExclusiveCpuIdle,
+
+	Evictable:       true,
+	EvictQuantified: true,
+	EvictFunc:       exclusiveCpuIdleEvictPod,
+}
+
+func throttleOnePodExclusiveCpuIdle(ctx *ExecuteContext, index int, ThrottleDownPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	errPodKeys, released = throttleOnePodCpu(ctx, index, ThrottleDownPods, totalReleasedResource)
+	return errPodKeys, releaseExclusiveCpuIdle(released)
+}
+
+func restoreOnePodExclusiveCpuIdle(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	errPodKeys, released = restoreOnePodCpu(ctx, index, ThrottleUpPods, totalReleasedResource)
+	return errPodKeys, releaseExclusiveCpuIdle(released)
+}
+
+func exclusiveCpuIdleEvictPod(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (errPodKeys []string, released ReleaseResource) {
+	errPodKeys, released = cpuUsageEvictPod(wg, ctx, index, totalReleasedResource, EvictPods)
+	return errPodKeys, releaseExclusiveCpuIdle(released)
+}
+
+func releaseExclusiveCpuIdle(released ReleaseResource) ReleaseResource {
+	if value, ok := released[CpuUsage]; ok {
+		released[ExclusiveCpuIdle] = value
+	}
+	return released
+}
@@ -1,17 +1,22 @@
 package executor
 
 import (
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/util/wait"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	pb "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 	"k8s.io/klog/v2"
 
 	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/audit"
+	"github.com/gocrane/crane/pkg/ensurance/executor/bandwidth"
 	cgrpc "github.com/gocrane/crane/pkg/ensurance/grpc"
 	cruntime "github.com/gocrane/crane/pkg/ensurance/runtime"
 	"github.com/gocrane/crane/pkg/known"
@@ -32,14 +37,30 @@ type ActionExecutor struct {
 	runtimeClient pb.RuntimeServiceClient
 	runtimeConn   *grpc.ClientConn
 
+	cgroupDriver     string
+	bandwidthLimiter *bandwidth.Limiter
+	history          *audit.Store
+
+	// stateMap is the state of the last execution, the failed actions are retried with it
 	stateMap map[string][]common.TimeSeries
 
 	executeExcessPercent float64
+
+	// actionLock serializes the actions, the retries and the reconciliation
+	actionLock sync.Mutex
+	// retryQueue holds the keys of pods whose action failed, the failed actions are in retryItems
+	retryQueue workqueue.RateLimitingInterface
+	retryLock  sync.Mutex
+	retryItems map[string]retryItem
+	// lastThrottleDown is the last time that pods were throttled down, the throttled pods are not reconciled
+	// until no pod is throttled down for a reconcile period
+	lastThrottleDown time.Time
+	lastReconcile    time.Time
 }
 
 // NewActionExecutor create enforcer manager
 func NewActionExecutor(client clientset.Interface, nodeName string, podInformer coreinformers.PodInformer, nodeInformer coreinformers.NodeInformer,
-	noticeCh <-chan AvoidanceExecutor, runtimeEndpoint string, stateMap map[string][]common.TimeSeries, executeExcess string) *ActionExecutor {
+	noticeCh <-chan AvoidanceExecutor, runtimeEndpoint, cgroupDriver, sysPath string, stateMap map[string][]common.TimeSeries, executeExcess string, history *audit.Store) *ActionExecutor {
 
 	runtimeClient, runtimeConn, err := cruntime.GetRuntimeClient(runtimeEndpoint)
 	if err != nil {
@@ -63,8 +84,13 @@ func NewActionExecutor(client clientset.Interface, nodeName string, podInformer
 		nodeSynced:           nodeInformer.Informer().HasSynced,
 		runtimeClient:        runtimeClient,
 		runtimeConn:          runtimeConn,
+		cgroupDriver:         cgroupDriver,
+		bandwidthLimiter:     bandwidth.NewLimiter(sysPath),
+		history:              history,
 		stateMap:             stateMap,
 		executeExcessPercent: executeExcessPercent,
+		retryQueue:           workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Second, time.Minute), "action-executor"),
+		retryItems:           make(map[string]retryItem),
 	}
 }
 
@@ -84,6 +110,8 @@ func (a *ActionExecutor) Run(stop <-chan struct{}) {
 		return
 	}
 
+	go wait.Until(a.runRetryWorker, time.Second, stop)
+
 	go func() {
 		for {
 			select {
@@ -91,13 +119,16 @@ func (a *ActionExecutor) Run(stop <-chan struct{}) {
 				start := time.Now()
 				metrics.UpdateLastTime(string(known.ModuleActionExecutor), metrics.StepMain, start)
 				if err := a.execute(as, stop); err != nil {
-					// TODO: if it failed in action, how to retry
+					// the failed actions of pods are retried by the retry worker
 					klog.Errorf("Failed to execute action: %v", err)
 				}
+				// reconcile with the state just collected, the collector does not update it until the next round
+				a.reconcile(as)
 				metrics.UpdateDurationFromStart(string(known.ModuleActionExecutor), metrics.StepMain, start)
 
 			case <-stop:
 				klog.Infof("Exiting action executor.")
+				a.retryQueue.ShutDown()
 				if err := cgrpc.CloseGrpcConnection(a.runtimeConn); err != nil {
 					klog.Errorf("Failed to close grpc connection: %v", err)
 				}
@@ -110,15 +141,15 @@ func (a *ActionExecutor) Run(stop <-chan struct{}) {
 }
 
 func (a *ActionExecutor) execute(ae AvoidanceExecutor, _ <-chan struct{}) error {
-	var ctx = &ExecuteContext{
-		NodeName:             a.nodeName,
-		Client:               a.client,
-		PodLister:            a.podLister,
-		NodeLister:           a.nodeLister,
-		RuntimeClient:        a.runtimeClient,
-		RuntimeConn:          a.runtimeConn,
-		stateMap:             ae.StateMap,
-		executeExcessPercent: a.executeExcessPercent,
+	a.actionLock.Lock()
+	defer a.actionLock.Unlock()
+
+	var ctx = a.newExecuteContext(ae.StateMap)
+	defer a.updateRetries(ctx)
+	a.stateMap = ae.StateMap
+
+	if len(ae.ThrottleExecutor.ThrottleDownPods) != 0 {
+		a.lastThrottleDown = time.Now()
 	}
 
 	//step1 do enforcer actions
@@ -134,25 +165,51 @@ func (a *ActionExecutor) execute(ae AvoidanceExecutor, _ <-chan struct{}) error
 	return nil
 }
 
+func (a *ActionExecutor) newExecuteContext(stateMap map[string][]common.TimeSeries) *ExecuteContext {
+	return &ExecuteContext{
+		NodeName:             a.nodeName,
+		Client:               a.client,
+		PodLister:            a.podLister,
+		NodeLister:           a.nodeLister,
+		RuntimeClient:        a.runtimeClient,
+		RuntimeConn:          a.runtimeConn,
+		CgroupDriver:         a.cgroupDriver,
+		BandwidthLimiter:     a.bandwidthLimiter,
+		History:              a.history,
+		stateMap:             stateMap,
+		executeExcessPercent: a.executeExcessPercent,
+	}
+}
+
 func avoid(ctx *ExecuteContext, ae AvoidanceExecutor) error {
 	var start = time.Now()
 	metrics.UpdateLastTime(string(known.ModuleActionExecutor), metrics.StepAvoid, start)
 	defer metrics.UpdateDurationFromStart(string(known.ModuleActionExecutor), metrics.StepRestore, start)
 
 	//step1 do DisableScheduled action
-	if err := ae.ScheduleExecutor.Avoid(ctx); err != nil {
+	err := ae.ScheduleExecutor.Avoid(ctx)
+	if ae.ScheduleExecutor.ToBeDisable {
+		ctx.recordSchedule(audit.ActionDisableSchedule, ae.ScheduleExecutor.Rules, err)
+	}
+	if err != nil {
 		metrics.ExecutorErrorCounterInc(metrics.SubComponentSchedule, metrics.StepAvoid)
 		return err
 	}
 
 	//step2 do Evict action
-	if err := ae.EvictExecutor.Avoid(ctx); err != nil {
+	ctx.startAudit()
+	err = ae.EvictExecutor.Avoid(ctx)
+	ctx.recordAction(audit.ActionEvict, ae.EvictExecutor.EvictRules, ae.EvictExecutor.EvictWatermark, err)
+	if err != nil {
 		metrics.ExecutorErrorCounterInc(metrics.SubComponentEvict, metrics.StepAvoid)
 		return err
 	}
 
 	//step3 do Throttle action
-	if err := ae.ThrottleExecutor.Avoid(ctx); err != nil {
+	ctx.startAudit()
+	err = ae.ThrottleExecutor.Avoid(ctx)
+	ctx.recordAction(audit.ActionThrottleDown, ae.ThrottleExecutor.ThrottleDownRules, ae.ThrottleExecutor.ThrottleDownWatermark, err)
+	if err != nil {
 		metrics.ExecutorErrorCounterInc(metrics.SubComponentThrottle, metrics.StepAvoid)
 		return err
 	}
@@ -166,19 +223,26 @@ func restore(ctx *ExecuteContext, ae AvoidanceExecutor) error {
 	defer metrics.UpdateDurationFromStart(string(known.ModuleActionExecutor), metrics.StepRestore, start)
 
 	//step1 do DisableScheduled action
-	if err := ae.ScheduleExecutor.Restore(ctx); err != nil {
+	err := ae.ScheduleExecutor.Restore(ctx)
+	if ae.ScheduleExecutor.ToBeRestore {
+		ctx.recordSchedule(audit.ActionEnableSchedule, nil, err)
+	}
+	if err != nil {
 		metrics.ExecutorErrorCounterInc(metrics.SubComponentSchedule, metrics.StepRestore)
 		return err
 	}
 
 	//step2 do Evict action
-	if err := ae.EvictExecutor.Restore(ctx); err != nil {
+	if err = ae.EvictExecutor.Restore(ctx); err != nil {
 		metrics.ExecutorErrorCounterInc(metrics.SubComponentEvict, metrics.StepRestore)
 		return err
 	}
 
 	//step3 do Throttle action
-	if err := ae.ThrottleExecutor.Restore(ctx); err != nil {
+	ctx.startAudit()
+	err = ae.ThrottleExecutor.Restore(ctx)
+	ctx.recordAction(audit.ActionThrottleUp, ae.ThrottleExecutor.ThrottleUpRules, ae.ThrottleExecutor.ThrottleUpWatermark, err)
+	if err != nil {
 		metrics.ExecutorErrorCounterInc(metrics.SubComponentThrottle, metrics.StepRestore)
 		return err
 	}
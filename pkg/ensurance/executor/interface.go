@@ -1,12 +1,16 @@
 package executor
 
 import (
+	"sync"
+
 	"google.golang.org/grpc"
 	clientset "k8s.io/client-go/kubernetes"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	pb "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 
 	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/audit"
+	"github.com/gocrane/crane/pkg/ensurance/executor/bandwidth"
 )
 
 type Executor interface {
@@ -28,6 +32,11 @@ type ExecuteContext struct {
 	NodeLister    corelisters.NodeLister
 	RuntimeClient pb.RuntimeServiceClient
 	RuntimeConn   *grpc.ClientConn
+	CgroupDriver  string
+	// BandwidthLimiter limits the disk and network bandwidth of pods
+	BandwidthLimiter *bandwidth.Limiter
+	// History stores the avoidance actions, nil if the history is disabled
+	History *audit.Store
 
 	// Gap for metrics Evictable/ThrottleAble
 	// Key is the metric name, value is (actual used)-(the lowest watermark for NodeQOSEnsurancePolicies which use throttleDown action)
@@ -39,5 +48,14 @@ type ExecuteContext struct {
 
 	stateMap map[string][]common.TimeSeries
 
+	// The pods acted on and the gaps before acting of the current action, they are added to History
+	actedPods  []string
+	actionGaps Gaps
+	// The results of the pods acted on, nil if the action succeeded, otherwise the failed action to be retried
+	podResults map[string]*retryItem
+	// The errors of the evictions in goroutines, they are collected once the goroutines are done
+	evictErrPodKeys []string
+	resultLock      sync.Mutex
+
 	executeExcessPercent float64
 }
@@ -16,6 +16,10 @@ type metric struct {
 	// Some incompressible metric such as memory usage can be given a higher priority
 	ActionPriority int
 
+	// LowerBound means the watermarks of the metric are lower bounds, e.g. the idle cpu, the metric is too low
+	// when it is below the watermarks, and the gap is the distance below them
+	LowerBound bool
+
 	Sortable bool
 	SortFunc func(pods []podinfo.PodContext)
 
@@ -35,3 +39,9 @@ var metricMap = make(map[WatermarkMetric]metric)
 func registerMetricMap(m metric) {
 	metricMap[m.Name] = m
 }
+
+// LowerBoundMetric returns whether the watermarks of the metric are lower bounds, and whether the metric is known by the executors
+func LowerBoundMetric(m WatermarkMetric) (lowerBound bool, ok bool) {
+	metric, ok := metricMap[m]
+	return metric.LowerBound, ok
+}
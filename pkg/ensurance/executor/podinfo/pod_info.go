@@ -1,15 +1,18 @@
 package podinfo
 
 import (
+	"encoding/json"
 	"fmt"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
 
 	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
 	"github.com/gocrane/crane/pkg/common"
 	stypes "github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/known"
 	"github.com/gocrane/crane/pkg/utils"
 )
 
@@ -65,6 +68,20 @@ func GetPodUsage(metricName string, stateMap map[string][]common.TimeSeries, pod
 	return podUsage, containerUsages
 }
 
+// PodPressureMetrics are the pressure stall information metrics of pod
+var PodPressureMetrics = []stypes.MetricName{
+	stypes.MetricNamePodCpuSomeAvg10,
+	stypes.MetricNamePodCpuSomeAvg60,
+	stypes.MetricNamePodMemorySomeAvg10,
+	stypes.MetricNamePodMemorySomeAvg60,
+	stypes.MetricNamePodMemoryFullAvg10,
+	stypes.MetricNamePodMemoryFullAvg60,
+	stypes.MetricNamePodIoSomeAvg10,
+	stypes.MetricNamePodIoSomeAvg60,
+	stypes.MetricNamePodIoFullAvg10,
+	stypes.MetricNamePodIoFullAvg60,
+}
+
 type CPURatio struct {
 	//the min of cpu ratio for pods
 	MinCPURatio uint64 `json:"minCPURatio,omitempty"`
@@ -73,6 +90,60 @@ type CPURatio struct {
 	StepCPURatio uint64 `json:"stepCPURatio,omitempty"`
 }
 
+// BandwidthThrottle defines how to throttle the disk or network bandwidth of pods
+type BandwidthThrottle struct {
+	// MinKiBps is the min limit of the bandwidth
+	MinKiBps float64 `json:"minKiBps,omitempty"`
+	// MaxKiBps is the max limit of the bandwidth, the limit is removed when it is restored above MaxKiBps
+	MaxKiBps float64 `json:"maxKiBps,omitempty"`
+	// StepRatio is the step of the bandwidth limit for once down-size or up-size (1-100)
+	StepRatio uint64 `json:"stepRatio,omitempty"`
+}
+
+var (
+	DefaultDiskThrottle = BandwidthThrottle{MinKiBps: 1024, MaxKiBps: 1024 * 1024, StepRatio: 20}
+	// DefaultNetworkThrottle is in kbit/s, the same unit as network_sent_kibps
+	DefaultNetworkThrottle = BandwidthThrottle{MinKiBps: 1000, MaxKiBps: 10 * 1000 * 1000, StepRatio: 20}
+)
+
+// GetBandwidthThrottle reads the bandwidth throttle from the annotation of AvoidanceAction, the unset fields are defaulted.
+func GetBandwidthThrottle(action *ensuranceapi.AvoidanceAction, annotation string, defaultThrottle BandwidthThrottle) (BandwidthThrottle, error) {
+	throttle := defaultThrottle
+	if action == nil || action.Annotations == nil {
+		return throttle, nil
+	}
+	value, ok := action.Annotations[annotation]
+	if !ok {
+		return throttle, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &throttle); err != nil {
+		return defaultThrottle, fmt.Errorf("invalid %s %q: %v", annotation, value, err)
+	}
+	if throttle.MinKiBps <= 0 {
+		throttle.MinKiBps = defaultThrottle.MinKiBps
+	}
+	if throttle.MaxKiBps <= throttle.MinKiBps {
+		throttle.MaxKiBps = defaultThrottle.MaxKiBps
+	}
+	if throttle.StepRatio == 0 || throttle.StepRatio >= 100 {
+		throttle.StepRatio = defaultThrottle.StepRatio
+	}
+	return throttle, nil
+}
+
+// GetPodTotalUsage returns the usage of pod, or the sum of its containers if there is no pod level usage.
+func GetPodTotalUsage(metricName string, stateMap map[string][]common.TimeSeries, pod *v1.Pod) float64 {
+	podUsage, containerUsages := GetPodUsage(metricName, stateMap, pod)
+	if podUsage != 0 {
+		return podUsage
+	}
+	for _, c := range containerUsages {
+		podUsage += c.Value
+	}
+	return podUsage
+}
+
 type MemoryThrottleExecutor struct {
 	// to force gc the page cache of low level pods
 	ForceGC bool `json:"forceGC,omitempty"`
@@ -93,6 +164,16 @@ type PodContext struct {
 
 	PodMemUsage float64
 
+	// PodPressure is the pressure stall information of pod, keyed by the pod pressure metric name
+	PodPressure map[stypes.MetricName]float64
+
+	PodDiskReadKiBps, PodDiskWriteKiBps float64
+	// PodNetworkSentKiBps is in kbit/s
+	PodNetworkSentKiBps float64
+
+	DiskThrottle    BandwidthThrottle
+	NetworkThrottle BandwidthThrottle
+
 	ActionType  ActionType
 	CPUThrottle CPURatio
 	Executed    bool
@@ -133,6 +214,15 @@ func BuildPodActionContext(pod *v1.Pod, stateMap map[string][]common.TimeSeries,
 	podContext.ElasticMemLimit = utils.GetElasticResourceLimit(pod, v1.ResourceMemory)
 	podContext.PodMemUsage, _ = GetPodUsage(string(stypes.MetricNameContainerMemTotalUsage), stateMap, pod)
 
+	podContext.PodPressure = make(map[stypes.MetricName]float64, len(PodPressureMetrics))
+	for _, m := range PodPressureMetrics {
+		podContext.PodPressure[m], _ = GetPodUsage(string(m), stateMap, pod)
+	}
+
+	podContext.PodDiskReadKiBps = GetPodTotalUsage(string(stypes.MetricNameContainerDiskReadKiBPS), stateMap, pod)
+	podContext.PodDiskWriteKiBps = GetPodTotalUsage(string(stypes.MetricNameContainerDiskWriteKiBPS), stateMap, pod)
+	podContext.PodNetworkSentKiBps = GetPodTotalUsage(string(stypes.MetricNamePodNetworkSentKiBPS), stateMap, pod)
+
 	podContext.StartTime = pod.Status.StartTime
 
 	if action.Spec.Throttle != nil {
@@ -140,6 +230,14 @@ func BuildPodActionContext(pod *v1.Pod, stateMap map[string][]common.TimeSeries,
 		podContext.CPUThrottle.StepCPURatio = uint64(action.Spec.Throttle.CPUThrottle.StepCPURatio)
 	}
 
+	var err error
+	if podContext.DiskThrottle, err = GetBandwidthThrottle(action, known.AvoidanceActionDiskThrottleAnnotation, DefaultDiskThrottle); err != nil {
+		klog.Warningf("Failed to get disk throttle of AvoidanceAction %s: %v", action.Name, err)
+	}
+	if podContext.NetworkThrottle, err = GetBandwidthThrottle(action, known.AvoidanceActionNetworkThrottleAnnotation, DefaultNetworkThrottle); err != nil {
+		klog.Warningf("Failed to get network throttle of AvoidanceAction %s: %v", action.Name, err)
+	}
+
 	podContext.ActionType = actionType
 
 	return podContext
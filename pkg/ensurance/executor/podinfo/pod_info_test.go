@@ -0,0 +1,35 @@
+package podinfo
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func TestGetBandwidthThrottle(t *testing.T) {
+	action := &ensuranceapi.AvoidanceAction{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		known.AvoidanceActionDiskThrottleAnnotation: `{"minKiBps":2048,"stepRatio":10}`,
+	}}}
+
+	throttle, err := GetBandwidthThrottle(action, known.AvoidanceActionDiskThrottleAnnotation, DefaultDiskThrottle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if throttle.MinKiBps != 2048 || throttle.StepRatio != 10 || throttle.MaxKiBps != DefaultDiskThrottle.MaxKiBps {
+		t.Errorf("unexpected throttle %+v", throttle)
+	}
+
+	throttle, err = GetBandwidthThrottle(action, known.AvoidanceActionNetworkThrottleAnnotation, DefaultNetworkThrottle)
+	if err != nil || throttle != DefaultNetworkThrottle {
+		t.Errorf("expect default network throttle, got %+v, %v", throttle, err)
+	}
+
+	action.Annotations[known.AvoidanceActionDiskThrottleAnnotation] = "{"
+	if throttle, err = GetBandwidthThrottle(action, known.AvoidanceActionDiskThrottleAnnotation, DefaultDiskThrottle); err == nil || throttle != DefaultDiskThrottle {
+		t.Errorf("expect error and default throttle for invalid annotation")
+	}
+}
@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/ensurance/executor/sort"
+	"github.com/gocrane/crane/pkg/metrics"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// pressureMetric is a node pressure metric and the pod pressure metric of the same resource, line and window
+type pressureMetric struct {
+	node           types.MetricName
+	pod            types.MetricName
+	actionPriority int
+	// throttleable is true for cpu pressure only, as there is no throttle for memory and io yet
+	throttleable bool
+}
+
+var pressureMetrics = []pressureMetric{
+	{types.MetricNameCpuSomeAvg10, types.MetricNamePodCpuSomeAvg10, 5, true},
+	{types.MetricNameCpuSomeAvg60, types.MetricNamePodCpuSomeAvg60, 5, true},
+	{types.MetricNameMemorySomeAvg10, types.MetricNamePodMemorySomeAvg10, 6, false},
+	{types.MetricNameMemorySomeAvg60, types.MetricNamePodMemorySomeAvg60, 6, false},
+	{types.MetricNameMemoryFullAvg10, types.MetricNamePodMemoryFullAvg10, 7, false},
+	{types.MetricNameMemoryFullAvg60, types.MetricNamePodMemoryFullAvg60, 7, false},
+	{types.MetricNameIoSomeAvg10, types.MetricNamePodIoSomeAvg10, 5, false},
+	{types.MetricNameIoSomeAvg60, types.MetricNamePodIoSomeAvg60, 5, false},
+	{types.MetricNameIoFullAvg10, types.MetricNamePodIoFullAvg10, 6, false},
+	{types.MetricNameIoFullAvg60, types.MetricNamePodIoFullAvg60, 6, false},
+}
+
+func init() {
+	for _, pm := range pressureMetrics {
+		registerMetricMap(newPressureMetric(pm))
+	}
+}
+
+// newPressureMetric builds the metric of node pressure. The throttle can't be quantified, because reducing the cpu
+// quota of a pod may increase its pressure. The eviction is quantified by the pod pressure, the pods stalled most
+// are evicted first.
+func newPressureMetric(pm pressureMetric) metric {
+	m := metric{
+		Name:           WatermarkMetric(pm.node),
+		ActionPriority: pm.actionPriority,
+		Sortable:       true,
+		SortFunc:       sort.PressureSort(pm.pod),
+
+		Evictable:       true,
+		EvictQuantified: true,
+		EvictFunc:       pressureEvictPodFunc(pm),
+	}
+	if pm.throttleable {
+		m.Throttleable = true
+		m.ThrottleQuantified = false
+		m.ThrottleFunc = throttleOnePodCpu
+		m.RestoreFunc = restoreOnePodCpu
+	}
+	return m
+}
+
+func pressureEvictPodFunc(pm pressureMetric) func(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (errPodKeys []string, released ReleaseResource) {
+	return func(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (errPodKeys []string, released ReleaseResource) {
+		wg.Add(1)
+
+		// Calculate release resources
+		released = releasePressure(pm, EvictPods[index])
+		totalReleasedResource.Add(released)
+
+		go func(evictPod podinfo.PodContext) {
+			defer wg.Done()
+
+			pod, err := ctx.PodLister.Pods(evictPod.Key.Namespace).Get(evictPod.Key.Name)
+			if err != nil {
+				ctx.evictFailed("not found ", evictPod.Key.String())
+				return
+			}
+			klog.Warningf("Evicting pod %v for %s", evictPod.Key, pm.node)
+			err = utils.EvictPodWithGracePeriod(ctx.Client, pod, evictPod.DeletionGracePeriodSeconds)
+			if err != nil {
+				ctx.evictFailed("evict failed ", evictPod.Key.String())
+				ctx.actionFailed(podinfo.Evict, WatermarkMetric(pm.node), evictPod)
+				klog.Warningf("Failed to evict pod %s: %v", evictPod.Key.String(), err)
+				return
+			}
+			metrics.ExecutorEvictCountsInc()
+
+			klog.Warningf("Pod %s is evicted", klog.KObj(pod))
+		}(EvictPods[index])
+		return
+	}
+}
+
+// releasePressure assumes that the node pressure decreases by the pressure of the evicted pod. If the pod pressure
+// is not collected, e.g. on cgroup v1, only one pod is evicted each time to avoid evicting all the candidate pods.
+func releasePressure(pm pressureMetric, pod podinfo.PodContext) ReleaseResource {
+	if pod.ActionType == podinfo.Evict {
+		released := pod.PodPressure[pm.pod]
+		if released <= 0 {
+			released = maxFloat
+		}
+		return ReleaseResource{
+			WatermarkMetric(pm.node): released,
+		}
+	}
+	return ReleaseResource{}
+}
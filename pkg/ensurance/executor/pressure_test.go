@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"sync"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types2 "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestPressureMetric(t *testing.T) {
+	cpu, ok := metricMap[WatermarkMetric(types.MetricNameCpuSomeAvg10)]
+	if !ok || !cpu.Throttleable || cpu.ThrottleQuantified || !cpu.EvictQuantified {
+		t.Errorf("unexpected cpu pressure metric %+v", cpu)
+	}
+	memory, ok := metricMap[WatermarkMetric(types.MetricNameMemoryFullAvg60)]
+	if !ok || memory.Throttleable || !memory.Evictable {
+		t.Errorf("unexpected memory pressure metric %+v", memory)
+	}
+
+	pm := pressureMetrics[0]
+	pod := podinfo.PodContext{
+		ActionType:  podinfo.Evict,
+		PodPressure: map[types.MetricName]float64{pm.pod: 3},
+	}
+	if released := releasePressure(pm, pod); released[WatermarkMetric(pm.node)] != 3 {
+		t.Errorf("expect released 3, got %v", released)
+	}
+
+	pod.PodPressure = nil
+	if released := releasePressure(pm, pod); released[WatermarkMetric(pm.node)] != maxFloat {
+		t.Errorf("expect the whole gap released when pod pressure is missing, got %v", released)
+	}
+
+	pod.ActionType = podinfo.ThrottleDown
+	if released := releasePressure(pm, pod); len(released) != 0 {
+		t.Errorf("expect nothing released for throttle, got %v", released)
+	}
+}
+
+func TestPressureEvictPodFunc(t *testing.T) {
+	existing := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"}}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(existing); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &ExecuteContext{
+		Client:    fake.NewSimpleClientset(existing),
+		PodLister: corelisters.NewPodLister(indexer),
+	}
+
+	var pods EvictPods
+	for _, name := range []string{"existing", "missing-1", "missing-2", "missing-3"} {
+		pods = append(pods, podinfo.PodContext{Key: types2.NamespacedName{Namespace: "default", Name: name}, ActionType: podinfo.Evict})
+	}
+
+	// the errors of the evictions in goroutines are collected once they are done
+	evict := pressureEvictPodFunc(pressureMetrics[2])
+	wg := sync.WaitGroup{}
+	for i := range pods {
+		evict(&wg, ctx, i, &ReleaseResource{}, pods)
+	}
+	wg.Wait()
+	errPodKeys := ctx.takeEvictErrPodKeys()
+	if len(errPodKeys) != 6 {
+		t.Errorf("expect errors of the 3 missing pods, got %v", errPodKeys)
+	}
+	if len(ctx.takeEvictErrPodKeys()) != 0 {
+		t.Errorf("expect the errors cleared once taken")
+	}
+}
@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/audit"
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metrics"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const (
+	// ReconcilePeriod is the period to restore the pods left throttled
+	ReconcilePeriod = time.Minute
+	// reconcileStepRatio is the step in percent to restore the cpu quota and bandwidth limit each period
+	reconcileStepRatio = 20
+)
+
+// reconcile restores the pods left throttled step by step, e.g. the restore failed too many times, the agent
+// restarted after throttling, or the NodeQOS was deleted. Only the resources recorded on the pods as throttled
+// down by crane-agent are restored. It is skipped while any throttle rule is triggered and not restored yet,
+// which is restored by the analyzer after its restore threshold, or if any pod has been throttled down in the
+// last reconcile period, so that it does not fight with the throttle of the rules.
+func (a *ActionExecutor) reconcile(ae AvoidanceExecutor) {
+	a.actionLock.Lock()
+	defer a.actionLock.Unlock()
+
+	if len(ae.ThrottleExecutor.ThrottleActiveRules) != 0 {
+		klog.V(6).Infof("Skip reconciliation, the throttle rules %v are active", ae.ThrottleExecutor.ThrottleActiveRules)
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(a.lastThrottleDown) < ReconcilePeriod || now.Sub(a.lastReconcile) < ReconcilePeriod {
+		return
+	}
+	a.lastReconcile = now
+	stateMap := ae.StateMap
+
+	start := time.Now()
+	metrics.UpdateLastTime(string(known.ModuleActionExecutor), metrics.StepReconcile, start)
+	defer metrics.UpdateDurationFromStart(string(known.ModuleActionExecutor), metrics.StepReconcile, start)
+
+	pods, err := a.podLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list pods for reconciliation: %v", err)
+		return
+	}
+
+	ctx := a.newExecuteContext(stateMap)
+	ctx.startAudit()
+	var errPodKeys []string
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning || utils.IsStaticPod(pod) {
+			continue
+		}
+
+		errPodKeys = append(errPodKeys, reconcilePod(ctx, pod, stateMap)...)
+	}
+
+	if len(ctx.actedPods) != 0 {
+		klog.Infof("Reconciled the throttled pods %v", ctx.actedPods)
+		metrics.ExecutorStatusCounterInc(metrics.SubComponentThrottle, metrics.StepReconcile)
+		ctx.recordAction(audit.ActionThrottleUp, nil, nil, nil)
+	}
+	if len(errPodKeys) != 0 {
+		// the pods which are not reconciled are retried in the next period
+		klog.V(4).Infof("Failed to reconcile some pods: %v", errPodKeys)
+		metrics.ExecutorErrorCounterInc(metrics.SubComponentThrottle, metrics.StepReconcile)
+	}
+}
+
+// reconcilePod restores the resources of pod recorded as throttled down by crane-agent one step
+func reconcilePod(ctx *ExecuteContext, pod *v1.Pod, stateMap map[string][]common.TimeSeries) (errPodKeys []string) {
+	var resources []bandwidthResource
+	for _, r := range []bandwidthResource{diskRead, diskWrite, networkSent} {
+		if throttledByCrane(pod, r.metric) {
+			resources = append(resources, r)
+		}
+	}
+	cpu := throttledByCrane(pod, CpuUsage)
+	if !cpu && len(resources) == 0 {
+		return nil
+	}
+
+	podContext := podinfo.BuildPodActionContext(pod, stateMap, &ensuranceapi.AvoidanceAction{}, podinfo.ThrottleUp)
+	podContext.CPUThrottle.StepCPURatio = reconcileStepRatio
+	podContext.DiskThrottle.StepRatio = reconcileStepRatio
+	podContext.NetworkThrottle.StepRatio = reconcileStepRatio
+	throttlePods := ThrottlePods{podContext}
+
+	if cpu {
+		if cpuThrottled(pod, podContext) {
+			errKeys, released := restoreOnePodCpu(ctx, 0, throttlePods, &ReleaseResource{})
+			errPodKeys = append(errPodKeys, errKeys...)
+			ctx.auditPod(podContext, released)
+		} else {
+			ctx.markThrottled(podContext.Key, CpuUsage, false)
+		}
+	}
+
+	for _, r := range resources {
+		errKeys, released := restoreOnePodBandwidth(r)(ctx, 0, throttlePods, &ReleaseResource{})
+		errPodKeys = append(errPodKeys, errKeys...)
+		ctx.auditPod(podContext, released)
+	}
+	return errPodKeys
+}
+
+// cpuThrottled returns true if the cpu quota of any container is lower than the limit of it, or the quota is
+// set for a container without cpu limit and elastic cpu. It is only checked for the pods whose cpu is throttled
+// down by crane-agent, so the quota of a container without limit is set by the throttle.
+func cpuThrottled(pod *v1.Pod, podContext podinfo.PodContext) bool {
+	for _, quota := range podContext.ContainerCPUQuotas {
+		// skip pause container and the container without quota
+		if quota.ContainerName == "" || quota.Value <= 0 {
+			continue
+		}
+
+		period, err := podinfo.GetUsageById(podContext.ContainerCPUPeriods, quota.ContainerId)
+		if err != nil {
+			continue
+		}
+		container, err := utils.GetPodContainerByName(pod, quota.ContainerName)
+		if err != nil {
+			continue
+		}
+
+		var limit float64
+		if limitCPU, ok := container.Resources.Limits[v1.ResourceCPU]; ok {
+			limit = float64(limitCPU.MilliValue()) / CpuQuotaCoefficient
+		} else if extCPU, ok := utils.GetExtCpuRes(container); ok {
+			limit = float64(extCPU.MilliValue()) / CpuQuotaCoefficient
+		} else {
+			return true
+		}
+
+		if quota.Value < limit*period.Value && !utils.AlmostEqual(quota.Value, limit*period.Value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestCPUThrottled(t *testing.T) {
+	newPod := func(limits v1.ResourceList) *v1.Pod {
+		return &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "c", Resources: v1.ResourceRequirements{Limits: limits}}}}}
+	}
+	newContext := func(quota float64) podinfo.PodContext {
+		return podinfo.PodContext{
+			ContainerCPUQuotas:  []podinfo.ContainerState{{ContainerName: "c", ContainerId: "id", Value: quota}},
+			ContainerCPUPeriods: []podinfo.ContainerState{{ContainerName: "c", ContainerId: "id", Value: 100000}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		limits v1.ResourceList
+		quota  float64
+		expect bool
+	}{
+		{"quota equals to limit", v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}, 200000, false},
+		{"quota below limit", v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}, 100000, true},
+		{"quota equals to elastic cpu", v1.ResourceList{"gocrane.io/cpu": resource.MustParse("1")}, 100000, false},
+		{"quota below elastic cpu", v1.ResourceList{"gocrane.io/cpu": resource.MustParse("1")}, 50000, true},
+		{"no quota", nil, -1, false},
+		{"quota without limit", nil, 50000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cpuThrottled(newPod(tt.limits), newContext(tt.quota)); got != tt.expect {
+				t.Errorf("expect %v, got %v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestReconcileSkippedWhileRulesActive(t *testing.T) {
+	a := &ActionExecutor{podLister: corelisters.NewPodLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))}
+
+	a.reconcile(AvoidanceExecutor{ThrottleExecutor: ThrottleExecutor{ThrottleActiveRules: []string{"nodeqos/cpu-usage"}}})
+	if !a.lastReconcile.IsZero() {
+		t.Fatalf("expect reconciliation to be skipped while the throttle rules are active")
+	}
+
+	a.reconcile(AvoidanceExecutor{})
+	if a.lastReconcile.IsZero() {
+		t.Errorf("expect reconciliation after the throttle rules are restored")
+	}
+}
+
+func TestMarkThrottled(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(pod); err != nil {
+		t.Fatal(err)
+	}
+	client := fake.NewSimpleClientset(pod)
+	ctx := &ExecuteContext{Client: client, PodLister: corelisters.NewPodLister(indexer)}
+	key := types.NamespacedName{Namespace: "default", Name: "a"}
+
+	mark := func(throttled bool) *v1.Pod {
+		ctx.markThrottled(key, DiskReadKiBPS, throttled)
+		updated, err := client.CoreV1().Pods("default").Get(context.TODO(), "a", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = indexer.Update(updated); err != nil {
+			t.Fatal(err)
+		}
+		return updated
+	}
+
+	if updated := mark(true); !throttledByCrane(updated, DiskReadKiBPS) || throttledByCrane(updated, CpuUsage) {
+		t.Errorf("expect only the disk read to be recorded as throttled, got %v", updated.Annotations)
+	}
+	if updated := mark(false); throttledByCrane(updated, DiskReadKiBPS) {
+		t.Errorf("expect the disk read to be recorded as restored, got %v", updated.Annotations)
+	}
+}
+
+func TestReconcilePod(t *testing.T) {
+	newPod := func(annotations map[string]string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Annotations: annotations}}
+	}
+
+	// the pod is not throttled by crane-agent, so neither its cgroup nor its network namespace is touched
+	ctx := &ExecuteContext{PodLister: corelisters.NewPodLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))}
+	if errPodKeys := reconcilePod(ctx, newPod(nil), nil); len(errPodKeys) != 0 {
+		t.Errorf("expect the pod not throttled to be skipped, got %v", errPodKeys)
+	}
+
+	// the pod is not in the lister, so restoring the resource recorded as throttled fails
+	errPodKeys := reconcilePod(ctx, newPod(map[string]string{throttledAnnotation(NetworkSentKiBPS): "true"}), nil)
+	if len(errPodKeys) != 1 {
+		t.Errorf("expect the throttled network to be restored, got %v", errPodKeys)
+	}
+}
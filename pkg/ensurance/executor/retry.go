@@ -0,0 +1,191 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/ensurance/audit"
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/metrics"
+)
+
+// maxActionRetries is the max times to retry a failed action of a pod
+const maxActionRetries = 5
+
+// retryItem is a failed action of a pod, it is retried by the func of the metric
+type retryItem struct {
+	actionType podinfo.ActionType
+	metric     WatermarkMetric
+	pod        podinfo.PodContext
+}
+
+// podSucceeded remembers that the action of pod succeeded, unless it has failed in the same round
+func (ctx *ExecuteContext) podSucceeded(key string) {
+	ctx.resultLock.Lock()
+	defer ctx.resultLock.Unlock()
+
+	if ctx.podResults == nil {
+		ctx.podResults = make(map[string]*retryItem)
+	}
+	if _, ok := ctx.podResults[key]; !ok {
+		ctx.podResults[key] = nil
+	}
+}
+
+// actionFailed remembers the failed action of pod to retry it, it may be called in the goroutines of eviction
+func (ctx *ExecuteContext) actionFailed(actionType podinfo.ActionType, metric WatermarkMetric, pod podinfo.PodContext) {
+	ctx.resultLock.Lock()
+	defer ctx.resultLock.Unlock()
+
+	if ctx.podResults == nil {
+		ctx.podResults = make(map[string]*retryItem)
+	}
+	ctx.podResults[pod.Key.String()] = &retryItem{actionType: actionType, metric: metric, pod: pod}
+}
+
+// updateRetries enqueues the failed actions, and drops the pending retries of the pods acted on successfully,
+// because the latest action supersedes the failed one.
+func (a *ActionExecutor) updateRetries(ctx *ExecuteContext) {
+	ctx.resultLock.Lock()
+	defer ctx.resultLock.Unlock()
+
+	a.retryLock.Lock()
+	defer a.retryLock.Unlock()
+
+	for key, item := range ctx.podResults {
+		if item == nil {
+			if _, ok := a.retryItems[key]; ok {
+				klog.V(4).Infof("Drop the retry of pod %s, it is superseded by a succeeded action", key)
+				delete(a.retryItems, key)
+				a.retryQueue.Forget(key)
+			}
+			continue
+		}
+
+		klog.V(4).Infof("Action %s of pod %s on metric %s failed, will retry it", item.actionType, key, item.metric)
+		a.retryItems[key] = *item
+		a.retryQueue.AddRateLimited(key)
+	}
+}
+
+func (a *ActionExecutor) runRetryWorker() {
+	for a.processNextRetry() {
+	}
+}
+
+func (a *ActionExecutor) processNextRetry() bool {
+	obj, shutdown := a.retryQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer a.retryQueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		a.retryQueue.Forget(obj)
+		return true
+	}
+
+	a.retryLock.Lock()
+	item, ok := a.retryItems[key]
+	a.retryLock.Unlock()
+	if !ok {
+		a.retryQueue.Forget(key)
+		return true
+	}
+
+	err := a.retry(item)
+
+	a.retryLock.Lock()
+	defer a.retryLock.Unlock()
+	// the item may be superseded by the actions during the retry
+	if current, ok := a.retryItems[key]; !ok || current.actionType != item.actionType || current.metric != item.metric {
+		return true
+	}
+
+	if err == nil {
+		klog.Infof("Retried action %s of pod %s successfully", item.actionType, key)
+		delete(a.retryItems, key)
+		a.retryQueue.Forget(key)
+		return true
+	}
+
+	if a.retryQueue.NumRequeues(key) < maxActionRetries {
+		utilruntime.HandleError(fmt.Errorf("failed to retry action %s of pod %s: %v, requeuing", item.actionType, key, err))
+		a.retryQueue.AddRateLimited(key)
+		return true
+	}
+
+	klog.Errorf("Give up action %s of pod %s after %d retries: %v", item.actionType, key, maxActionRetries, err)
+	delete(a.retryItems, key)
+	a.retryQueue.Forget(key)
+	return true
+}
+
+// retry does the failed action of pod again, the actions are serialized with the ones triggered by the analyzer.
+func (a *ActionExecutor) retry(item retryItem) error {
+	a.actionLock.Lock()
+	defer a.actionLock.Unlock()
+
+	if _, err := a.podLister.Pods(item.pod.Key.Namespace).Get(item.pod.Key.Name); err != nil {
+		if errors.IsNotFound(err) {
+			// nothing to do for the deleted pod
+			return nil
+		}
+		return err
+	}
+
+	m, ok := metricMap[item.metric]
+	if !ok {
+		return nil
+	}
+
+	ctx := a.newExecuteContext(a.stateMap)
+	ctx.startAudit()
+
+	var errKeys []string
+	var released ReleaseResource
+	var subComponent = metrics.SubComponentThrottle
+	var auditAction audit.Action
+	switch item.actionType {
+	case podinfo.ThrottleDown:
+		auditAction = audit.ActionThrottleDown
+		errKeys, released = m.ThrottleFunc(ctx, 0, ThrottlePods{item.pod}, &ReleaseResource{})
+	case podinfo.ThrottleUp:
+		auditAction = audit.ActionThrottleUp
+		errKeys, released = m.RestoreFunc(ctx, 0, ThrottlePods{item.pod}, &ReleaseResource{})
+	case podinfo.Evict:
+		subComponent = metrics.SubComponentEvict
+		auditAction = audit.ActionEvict
+		wg := sync.WaitGroup{}
+		errKeys, released = m.EvictFunc(&wg, ctx, 0, &ReleaseResource{}, EvictPods{item.pod})
+		wg.Wait()
+		errKeys = append(errKeys, ctx.takeEvictErrPodKeys()...)
+	default:
+		return nil
+	}
+
+	ctx.resultLock.Lock()
+	failed := ctx.podResults[item.pod.Key.String()] != nil
+	ctx.resultLock.Unlock()
+	if failed && len(errKeys) == 0 {
+		errKeys = append(errKeys, fmt.Sprintf("%s failed", item.actionType))
+	}
+
+	var err error
+	if len(errKeys) != 0 {
+		err = fmt.Errorf("%s", strings.Join(errKeys, ";"))
+		metrics.ExecutorErrorCounterInc(subComponent, metrics.StepRetry)
+	} else {
+		metrics.ExecutorStatusCounterInc(subComponent, metrics.StepRetry)
+		ctx.auditPod(item.pod, released)
+	}
+	ctx.recordAction(auditAction, nil, nil, err)
+
+	return err
+}
@@ -0,0 +1,42 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestUpdateRetries(t *testing.T) {
+	a := &ActionExecutor{
+		retryQueue: workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond)),
+		retryItems: make(map[string]retryItem),
+	}
+	defer a.retryQueue.ShutDown()
+
+	podA := podinfo.PodContext{Key: types.NamespacedName{Namespace: "default", Name: "a"}}
+	podB := podinfo.PodContext{Key: types.NamespacedName{Namespace: "default", Name: "b"}}
+
+	ctx := &ExecuteContext{}
+	ctx.actionFailed(podinfo.ThrottleUp, CpuUsage, podA)
+	ctx.actionFailed(podinfo.ThrottleDown, CpuUsage, podB)
+	// the failure of the same round is not overwritten by the success
+	ctx.podSucceeded(podB.Key.String())
+	a.updateRetries(ctx)
+
+	if len(a.retryItems) != 2 || a.retryItems["default/a"].actionType != podinfo.ThrottleUp || a.retryItems["default/b"].actionType != podinfo.ThrottleDown {
+		t.Fatalf("unexpected retry items %v", a.retryItems)
+	}
+
+	// the succeeded action supersedes the pending retry
+	ctx = &ExecuteContext{}
+	ctx.auditPod(podA, ReleaseResource{CpuUsage: 1})
+	a.updateRetries(ctx)
+
+	if _, ok := a.retryItems["default/a"]; ok || len(a.retryItems) != 1 {
+		t.Errorf("expect the retry of pod a is dropped, got %v", a.retryItems)
+	}
+}
@@ -17,6 +17,8 @@ const (
 
 type ScheduleExecutor struct {
 	ToBeDisable, ToBeRestore bool
+	// Rules are the NodeQOS rules that disable the scheduling, in the format of <NodeQOS>/<Rule>
+	Rules []string
 }
 
 func (b *ScheduleExecutor) Avoid(ctx *ExecuteContext) error {
@@ -0,0 +1,33 @@
+package sort
+
+import (
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+func DiskReadSort(pods []podinfo.PodContext) {
+	orderedBy(ComparePriority, ComparePodQOSClass, CompareDiskRead, CompareRunningTime).Sort(pods)
+}
+
+func DiskWriteSort(pods []podinfo.PodContext) {
+	orderedBy(ComparePriority, ComparePodQOSClass, CompareDiskWrite, CompareRunningTime).Sort(pods)
+}
+
+func NetworkSentSort(pods []podinfo.PodContext) {
+	orderedBy(ComparePriority, ComparePodQOSClass, CompareNetworkSent, CompareRunningTime).Sort(pods)
+}
+
+// CompareDiskRead compares the disk read bandwidth of pods, the pod reads most goes first
+func CompareDiskRead(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(p2.PodDiskReadKiBps, p1.PodDiskReadKiBps)
+}
+
+// CompareDiskWrite compares the disk write bandwidth of pods, the pod writes most goes first
+func CompareDiskWrite(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(p2.PodDiskWriteKiBps, p1.PodDiskWriteKiBps)
+}
+
+// CompareNetworkSent compares the egress bandwidth of pods, the pod sends most goes first
+func CompareNetworkSent(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(p2.PodNetworkSentKiBps, p1.PodNetworkSentKiBps)
+}
@@ -0,0 +1,22 @@
+package sort
+
+import (
+	stypes "github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// PressureSort returns the sort func which sorts pods by the pressure of podMetric, the pod stalled most goes first
+// in the pods with the same priority and qos class
+func PressureSort(podMetric stypes.MetricName) func(pods []podinfo.PodContext) {
+	return func(pods []podinfo.PodContext) {
+		orderedBy(ComparePriority, ComparePodQOSClass, ComparePressure(podMetric), CompareRunningTime).Sort(pods)
+	}
+}
+
+// ComparePressure returns the cmp func which compares the pressure of podMetric
+func ComparePressure(podMetric stypes.MetricName) cmpFunc {
+	return func(p1, p2 podinfo.PodContext) int32 {
+		return utils.CmpFloat(p2.PodPressure[podMetric], p1.PodPressure[podMetric])
+	}
+}
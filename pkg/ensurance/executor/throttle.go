@@ -26,6 +26,12 @@ type ThrottleExecutor struct {
 	// All metrics(not only metrics that can be quantified) metioned in triggerd NodeQOS and their corresponding watermarks
 	ThrottleDownWatermark Watermarks
 	ThrottleUpWatermark   Watermarks
+	// The NodeQOS rules triggered or restored, in the format of <NodeQOS>/<Rule>
+	ThrottleDownRules []string
+	ThrottleUpRules   []string
+	// The NodeQOS rules triggered and not restored yet, in the format of <NodeQOS>/<Rule>,
+	// the throttled pods are not reconciled while any of them is active
+	ThrottleActiveRules []string
 }
 
 type ThrottlePods []podinfo.PodContext
@@ -87,6 +93,7 @@ func (t *ThrottleExecutor) Avoid(ctx *ExecuteContext) error {
 		}
 	} else {
 		ctx.ToBeThrottleDown = calculateGaps(ctx.stateMap, t, nil, ctx.executeExcessPercent)
+		ctx.auditGaps(ctx.ToBeThrottleDown)
 
 		if ctx.ToBeThrottleDown.HasUsageMissedMetric() {
 			klog.V(6).Info("There is a metric usage missed")
@@ -116,6 +123,10 @@ func (t *ThrottleExecutor) Avoid(ctx *ExecuteContext) error {
 
 					errKeys, released = metricMap[m].ThrottleFunc(ctx, index, t.ThrottleDownPods, &totalReleased)
 					klog.V(6).Infof("ThrottleDown pods %s, released %f resource", t.ThrottleDownPods[index].Key, released[m])
+					ctx.auditPod(t.ThrottleDownPods[index], released)
+					if len(errKeys) != 0 {
+						ctx.actionFailed(podinfo.ThrottleDown, m, t.ThrottleDownPods[index])
+					}
 					errPodKeys = append(errPodKeys, errKeys...)
 
 					ctx.ToBeThrottleDown[m] -= released[m]
@@ -133,8 +144,12 @@ func (t *ThrottleExecutor) Avoid(ctx *ExecuteContext) error {
 
 func (t *ThrottleExecutor) throttlePods(ctx *ExecuteContext, totalReleasedResource *ReleaseResource, m WatermarkMetric) (errPodKeys []string) {
 	for i := range t.ThrottleDownPods {
-		errKeys, _ := metricMap[m].ThrottleFunc(ctx, i, t.ThrottleDownPods, totalReleasedResource)
+		errKeys, released := metricMap[m].ThrottleFunc(ctx, i, t.ThrottleDownPods, totalReleasedResource)
 		errPodKeys = append(errPodKeys, errKeys...)
+		ctx.auditPod(t.ThrottleDownPods[i], released)
+		if len(errKeys) != 0 {
+			ctx.actionFailed(podinfo.ThrottleDown, m, t.ThrottleDownPods[i])
+		}
 	}
 	return
 }
@@ -178,6 +193,7 @@ func (t *ThrottleExecutor) Restore(ctx *ExecuteContext) error {
 		}
 	} else {
 		ctx.ToBeThrottleUp = calculateGaps(ctx.stateMap, t, nil, ctx.executeExcessPercent)
+		ctx.auditGaps(ctx.ToBeThrottleUp)
 
 		if ctx.ToBeThrottleUp.HasUsageMissedMetric() {
 			klog.V(6).Info("There is a metric usage missed")
@@ -207,6 +223,10 @@ func (t *ThrottleExecutor) Restore(ctx *ExecuteContext) error {
 
 					errKeys, released = metricMap[m].RestoreFunc(ctx, index, t.ThrottleUpPods, &totalReleased)
 					klog.V(6).Infof("ThrottleUp pods %s, released %f resource", t.ThrottleUpPods[index].Key, released[m])
+					ctx.auditPod(t.ThrottleUpPods[index], released)
+					if len(errKeys) != 0 {
+						ctx.actionFailed(podinfo.ThrottleUp, m, t.ThrottleUpPods[index])
+					}
 					errPodKeys = append(errPodKeys, errKeys...)
 
 					ctx.ToBeThrottleUp[m] -= released[m]
@@ -224,8 +244,12 @@ func (t *ThrottleExecutor) Restore(ctx *ExecuteContext) error {
 
 func (t *ThrottleExecutor) restorePods(ctx *ExecuteContext, totalReleasedResource *ReleaseResource, m WatermarkMetric) (errPodKeys []string) {
 	for i := range t.ThrottleUpPods {
-		errKeys, _ := metricMap[m].RestoreFunc(ctx, i, t.ThrottleDownPods, totalReleasedResource)
+		errKeys, released := metricMap[m].RestoreFunc(ctx, i, t.ThrottleUpPods, totalReleasedResource)
 		errPodKeys = append(errPodKeys, errKeys...)
+		ctx.auditPod(t.ThrottleUpPods[i], released)
+		if len(errKeys) != 0 {
+			ctx.actionFailed(podinfo.ThrottleUp, m, t.ThrottleUpPods[i])
+		}
 	}
 	return
 }
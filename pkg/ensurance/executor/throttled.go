@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/known"
+)
+
+// throttledAnnotation is the annotation of pods whose resource of the metric is throttled down by crane-agent
+func throttledAnnotation(m WatermarkMetric) string {
+	return known.PodThrottledAnnotationPrefix + "/" + string(m)
+}
+
+// throttledByCrane returns true if the resource of the metric is throttled down by crane-agent and not restored yet
+func throttledByCrane(pod *v1.Pod, m WatermarkMetric) bool {
+	_, ok := pod.Annotations[throttledAnnotation(m)]
+	return ok
+}
+
+// markThrottled records on the pod whether the resource of the metric is throttled down by crane-agent, so that
+// only the resources throttled by it are reconciled, even after the agent restarts. The failure is only logged,
+// the resource is recorded again by the next throttle.
+func (ctx *ExecuteContext) markThrottled(key types.NamespacedName, m WatermarkMetric, throttled bool) {
+	pod, err := ctx.PodLister.Pods(key.Namespace).Get(key.Name)
+	if err != nil || throttledByCrane(pod, m) == throttled {
+		return
+	}
+
+	var value interface{}
+	if throttled {
+		value = "true"
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{throttledAnnotation(m): value},
+		},
+	})
+	if err != nil {
+		klog.Errorf("Failed to marshal the throttled annotation of pod %s: %v", key, err)
+		return
+	}
+
+	if _, err = ctx.Client.CoreV1().Pods(key.Namespace).Patch(context.TODO(), key.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.Warningf("Failed to record the throttled %s of pod %s: %v", m, key, err)
+	}
+}
@@ -16,10 +16,14 @@ type WatermarkMetric string
 
 // Be consistent with metrics in collector/types/types.go
 const (
-	CpuUsage        = WatermarkMetric(types.MetricNameCpuTotalUsage)
-	CpuUsagePercent = WatermarkMetric(types.MetricNameCpuTotalUtilization)
-	MemUsage        = WatermarkMetric(types.MetricNameMemoryTotalUsage)
-	MemUsagePercent = WatermarkMetric(types.MetricNameMemoryTotalUtilization)
+	CpuUsage         = WatermarkMetric(types.MetricNameCpuTotalUsage)
+	CpuUsagePercent  = WatermarkMetric(types.MetricNameCpuTotalUtilization)
+	MemUsage         = WatermarkMetric(types.MetricNameMemoryTotalUsage)
+	MemUsagePercent  = WatermarkMetric(types.MetricNameMemoryTotalUtilization)
+	DiskReadKiBPS    = WatermarkMetric(types.MetricDiskReadKiBPS)
+	DiskWriteKiBPS   = WatermarkMetric(types.MetricDiskWriteKiBPS)
+	NetworkSentKiBPS = WatermarkMetric(types.MetricNetworkSentKiBPS)
+	ExclusiveCpuIdle = WatermarkMetric(types.MetricNameExclusiveCPUIdle)
 )
 
 const (
@@ -55,6 +59,17 @@ func (w *Watermark) PopSmallest() *resource.Quantity {
 	return &wl[0]
 }
 
+// Largest returns the largest watermark, which is the strictest one of the lower bounds
+func (w Watermark) Largest() *resource.Quantity {
+	largest := &w[0]
+	for i := range w {
+		if w[i].Cmp(*largest) > 0 {
+			largest = &w[i]
+		}
+	}
+	return largest
+}
+
 func (w Watermark) Less(i, j int) bool {
 	cmp := w[i].Cmp(w[j])
 	if cmp == -1 {
@@ -143,6 +158,11 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 			if !m.Evictable {
 				continue
 			}
+			// Metrics such as pressure may be not collected on some nodes, skip the metrics not in watermarks
+			// so that they are not treated as usage missed
+			if _, ok := evictExecutor.EvictWatermark[m.Name]; !ok {
+				continue
+			}
 			// Get the series for each metric
 			series, ok := stateMap[string(m.Name)]
 			if !ok {
@@ -152,11 +172,7 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 				continue
 			}
 
-			// Find the biggest used value
-			var maxUsed float64
-			if series[0].Samples[0].Value > maxUsed {
-				maxUsed = series[0].Samples[0].Value
-			}
+			used := usedOfSeries(m, series)
 
 			// Get the watermark for each metric cannot be quantified
 			evictWatermark, evictExist := evictExecutor.EvictWatermark[m.Name]
@@ -164,28 +180,28 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 			if !evictExist {
 				delete(result, m.Name)
 			} else {
-				klog.V(6).Infof("BuildEvictWatermarkGap: For metrics %+v, maxUsed is %f, watermark is %f", m, maxUsed, float64(evictWatermark.PopSmallest().Value()))
+				klog.V(6).Infof("BuildEvictWatermarkGap: For metrics %+v, used is %f, watermark is %f", m, used, float64(evictWatermark.PopSmallest().Value()))
 				if m.Name == CpuUsagePercent {
 					cpuCoreNums, ok := stateMap[string(types.MetricNameCpuCoreNumbers)]
 					if !ok {
 						klog.Warningf("Can't get MetricNameCpuCoreNumbers")
 					} else {
-						cpuPercentToUsage := (1 + executeExcessPercent) * (maxUsed - float64(evictWatermark.PopSmallest().Value())) * cpuCoreNums[0].Samples[0].Value * 1000 / types.MaxPercentage
+						cpuPercentToUsage := (1 + executeExcessPercent) * (used - float64(evictWatermark.PopSmallest().Value())) * cpuCoreNums[0].Samples[0].Value * 1000 / types.MaxPercentage
 						result[m.Name] = cpuPercentToUsage
-						klog.V(6).Infof("cpuPercent maxUsed is %f, watermark is %f, cpuPercentToUsageGap is %f", maxUsed, float64(evictWatermark.PopSmallest().Value()), cpuPercentToUsage)
+						klog.V(6).Infof("cpuPercent used is %f, watermark is %f, cpuPercentToUsageGap is %f", used, float64(evictWatermark.PopSmallest().Value()), cpuPercentToUsage)
 					}
 				} else if m.Name == MemUsagePercent {
 					totalMem, ok := stateMap[string(types.MetricNameMemoryTotal)]
 					if !ok {
 						klog.Warningf("Can't get MetricNameMemoryTotal")
 					} else {
-						memPercentToUsage := (1 + executeExcessPercent) * (maxUsed - float64(evictWatermark.PopSmallest().Value())) * totalMem[0].Samples[0].Value / types.MaxPercentage
+						memPercentToUsage := (1 + executeExcessPercent) * (used - float64(evictWatermark.PopSmallest().Value())) * totalMem[0].Samples[0].Value / types.MaxPercentage
 						result[m.Name] = memPercentToUsage
-						klog.V(6).Infof("memPercent maxUsed is %f, watermark is %f, memPercentToUsageGap is %f", maxUsed, float64(evictWatermark.PopSmallest().Value()), memPercentToUsage)
+						klog.V(6).Infof("memPercent used is %f, watermark is %f, memPercentToUsageGap is %f", used, float64(evictWatermark.PopSmallest().Value()), memPercentToUsage)
 					}
 
 				} else {
-					result[m.Name] = (1 + executeExcessPercent) * (maxUsed - float64(evictWatermark.PopSmallest().Value()))
+					result[m.Name] = (1 + executeExcessPercent) * gapToWatermark(m, used, evictWatermark)
 				}
 			}
 		}
@@ -196,6 +212,11 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 			if !m.Throttleable {
 				continue
 			}
+			_, throttleDownExist := throttleExecutor.ThrottleDownWatermark[m.Name]
+			_, throttleUpExist := throttleExecutor.ThrottleUpWatermark[m.Name]
+			if !throttleDownExist && !throttleUpExist {
+				continue
+			}
 			// Get the series for each metric
 			series, ok := stateMap[string(m.Name)]
 			if !ok {
@@ -205,11 +226,7 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 				continue
 			}
 
-			// Find the biggest used value
-			var maxUsed float64
-			if series[0].Samples[0].Value > maxUsed {
-				maxUsed = series[0].Samples[0].Value
-			}
+			used := usedOfSeries(m, series)
 
 			// Get the watermark for each metric in WatermarkMetricsCanBeQuantified
 			throttleDownWatermark, throttleDownExist := throttleExecutor.ThrottleDownWatermark[m.Name]
@@ -219,23 +236,49 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 			if !throttleDownExist {
 				delete(result, m.Name)
 			} else {
-				klog.V(6).Infof("BuildThrottleDownWatermarkGap: For metrics %s, maxUsed is %f, watermark is %f", m.Name, maxUsed, float64(throttleDownWatermark.PopSmallest().Value()))
-				result[m.Name] = (1 + executeExcessPercent) * (maxUsed - float64(throttleDownWatermark.PopSmallest().Value()))
+				klog.V(6).Infof("BuildThrottleDownWatermarkGap: For metrics %s, used is %f, watermark is %f", m.Name, used, float64(throttleDownWatermark.PopSmallest().Value()))
+				result[m.Name] = (1 + executeExcessPercent) * gapToWatermark(m, used, throttleDownWatermark)
 			}
 
 			// If metric not exist in ThrottleUpWatermark, throttleUpGapToWatermarks of metric will can't be calculated
 			if !throttleUpExist {
 				delete(result, m.Name)
 			} else {
-				klog.V(6).Infof("BuildThrottleUpWatermarkGap: For metrics %s, maxUsed is %f, watermark is %f", m.Name, maxUsed, float64(throttleUpWatermark.PopSmallest().Value()))
+				klog.V(6).Infof("BuildThrottleUpWatermarkGap: For metrics %s, used is %f, watermark is %f", m.Name, used, float64(throttleUpWatermark.PopSmallest().Value()))
 				// Attention: different with throttleDown and evict, use watermark - used
-				result[m.Name] = (1 + executeExcessPercent) * (float64(throttleUpWatermark.PopSmallest().Value()) - maxUsed)
+				result[m.Name] = -(1 + executeExcessPercent) * gapToWatermark(m, used, throttleUpWatermark)
 			}
 		}
 	}
 	return result
 }
 
+// usedOfSeries finds the used value to compare with the watermarks, the biggest one, e.g. the busiest disk or network
+// interface, or the smallest one if the watermarks of the metric are lower bounds
+func usedOfSeries(m metric, series []common.TimeSeries) float64 {
+	var used float64
+	var found bool
+	for _, ts := range series {
+		if len(ts.Samples) == 0 {
+			continue
+		}
+		value := ts.Samples[0].Value
+		if !found || (m.LowerBound && value < used) || (!m.LowerBound && value > used) {
+			used, found = value, true
+		}
+	}
+	return used
+}
+
+// gapToWatermark is the distance of the used value beyond the strictest watermark, above the smallest one, or below
+// the largest one if the watermarks of the metric are lower bounds
+func gapToWatermark(m metric, used float64, watermark *Watermark) float64 {
+	if m.LowerBound {
+		return float64(watermark.Largest().Value()) - used
+	}
+	return used - float64(watermark.PopSmallest().Value())
+}
+
 // Whether no gaps in Gaps
 func (g Gaps) GapsAllRemoved() bool {
 	for _, v := range g {
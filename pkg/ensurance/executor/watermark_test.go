@@ -8,6 +8,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
 )
 
 func (w Watermark) verify(t *testing.T, i int) {
@@ -48,3 +50,52 @@ func TestPopSmallest(t *testing.T) {
 		h.verify(t, 0)
 	}
 }
+
+// TestCalculateGaps make sure that the gaps are the usage to release above the watermarks, and the usage to restore below them
+func TestCalculateGaps(t *testing.T) {
+	watermark := func(v string) *Watermark {
+		w := &Watermark{}
+		heap.Push(w, resource.MustParse(v))
+		return w
+	}
+	stateMap := map[string][]common.TimeSeries{
+		string(CpuUsage): {{Samples: []common.Sample{{Value: 5000, Timestamp: 1}}}},
+	}
+
+	gaps := calculateGaps(stateMap, nil, &EvictExecutor{EvictWatermark: Watermarks{CpuUsage: watermark("4000")}}, 0)
+	assert.Equal(t, 1000.0, gaps[CpuUsage])
+
+	gaps = calculateGaps(stateMap, nil, &EvictExecutor{EvictWatermark: Watermarks{CpuUsage: watermark("4000")}}, 0.1)
+	assert.InDelta(t, 1100.0, gaps[CpuUsage], 1e-6)
+
+	gaps = calculateGaps(stateMap, &ThrottleExecutor{ThrottleUpWatermark: Watermarks{CpuUsage: watermark("6000")}}, nil, 0)
+	assert.Equal(t, 1000.0, gaps[CpuUsage])
+
+	// the usage is below the watermark, so there is nothing to release
+	gaps = calculateGaps(stateMap, nil, &EvictExecutor{EvictWatermark: Watermarks{CpuUsage: watermark("8000")}}, 0)
+	assert.True(t, gaps.TargetGapsRemoved(CpuUsage))
+}
+
+// TestCalculateLowerBoundGaps make sure that the gaps of lower bound watermarks are the distance below the largest watermark
+func TestCalculateLowerBoundGaps(t *testing.T) {
+	watermarks := func(values ...string) *Watermark {
+		w := &Watermark{}
+		for _, v := range values {
+			heap.Push(w, resource.MustParse(v))
+		}
+		return w
+	}
+	stateMap := map[string][]common.TimeSeries{
+		string(ExclusiveCpuIdle): {{Samples: []common.Sample{{Value: 1000, Timestamp: 1}}}},
+	}
+
+	gaps := calculateGaps(stateMap, nil, &EvictExecutor{EvictWatermark: Watermarks{ExclusiveCpuIdle: watermarks("2000", "3000")}}, 0)
+	assert.Equal(t, 2000.0, gaps[ExclusiveCpuIdle])
+
+	// the idle cpu is above the watermark, so the cpu can be restored
+	gaps = calculateGaps(stateMap, &ThrottleExecutor{ThrottleUpWatermark: Watermarks{ExclusiveCpuIdle: watermarks("600")}}, nil, 0)
+	assert.Equal(t, 400.0, gaps[ExclusiveCpuIdle])
+
+	gaps = calculateGaps(stateMap, nil, &EvictExecutor{EvictWatermark: Watermarks{ExclusiveCpuIdle: watermarks("500")}}, 0)
+	assert.True(t, gaps.TargetGapsRemoved(ExclusiveCpuIdle))
+}
@@ -34,6 +34,9 @@ const (
 	// CraneDashboardControl enables the control from Dashboard.
 	CraneDashboardControl featuregate.Feature = "DashboardControl"
 
+	// CraneSchedStatCollector enables the collector of run queue delay of node and cpu throttled time of containers.
+	CraneSchedStatCollector featuregate.Feature = "SchedStatCollector"
+
 	// QOSInitializer enables the qos initialization featrues.
 	QOSInitializer featuregate.Feature = "QOSInitializer"
 )
@@ -48,6 +51,7 @@ var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	CraneTimeSeriesPrediction:  {Default: true, PreRelease: featuregate.Alpha},
 	CraneCPUManager:            {Default: false, PreRelease: featuregate.Alpha},
 	QOSInitializer:             {Default: false, PreRelease: featuregate.Alpha},
+	CraneSchedStatCollector:    {Default: false, PreRelease: featuregate.Alpha},
 	CraneDashboardControl:      {Default: false, PreRelease: featuregate.Alpha},
 }
 
@@ -8,9 +8,61 @@ const (
 	AnalyticsConversionAnnotation         = "analysis.crane.io/analytics-conversion"
 	LastStartTimeAnnotation               = "analysis.crane.io/last-start-time"
 	MessageAnnotation                     = "analysis.crane.io/message"
+	RolloutPhaseAnnotation                = "analysis.crane.io/rollout-phase"
+	RolloutStartTimeAnnotation            = "analysis.crane.io/rollout-start-time"
+	RolloutValueAnnotation                = "analysis.crane.io/rollout-value"
+	RolloutRollbackAnnotation             = "analysis.crane.io/rollout-rollback"
+	MonthlyCostDeltaAnnotation            = "analysis.crane.io/monthly-cost-delta"
+	// RecommendationTimeoutAnnotation is the annotation of RecommendationRule to override the timeout of a recommendation run
+	RecommendationTimeoutAnnotation = "analysis.crane.io/recommendation-timeout"
 )
 
 const (
 	EffectiveHorizontalPodAutoscalerCurrentMetricsAnnotation        = "autoscaling.crane.io/effective-hpa-current-metrics"
 	EffectiveHorizontalPodAutoscalerExternalMetricsAnnotationPrefix = "metric-query.autoscaling.crane.io"
+	// EffectiveHorizontalPodAutoscalerPredictionBoundAnnotation is the annotation of EffectiveHorizontalPodAutoscaler to
+	// scale on a bound of the prediction interval instead of the point forecast, the value is lower or upper.
+	EffectiveHorizontalPodAutoscalerPredictionBoundAnnotation = "autoscaling.crane.io/prediction-bound"
+	// EffectiveHorizontalPodAutoscalerPredictionCalendarAnnotation is the annotation of EffectiveHorizontalPodAutoscaler
+	// to override the predictions in the special periods of a calendar, the value is the name of the calendar configmap.
+	EffectiveHorizontalPodAutoscalerPredictionCalendarAnnotation = "autoscaling.crane.io/prediction-calendar"
+)
+
+const (
+	// TimeSeriesPredictionIntervalAnnotation is the annotation of TimeSeriesPrediction to predict the lower and upper
+	// bounds of the prediction interval alongside the point forecast, the value is "true".
+	TimeSeriesPredictionIntervalAnnotation = "prediction.crane.io/prediction-interval"
+	// TimeSeriesPredictionCalendarAnnotation is the annotation of TimeSeriesPrediction to override the predictions in
+	// the special periods of a calendar, the value is the name of the calendar configmap in the same namespace.
+	TimeSeriesPredictionCalendarAnnotation = "prediction.crane.io/calendar"
+)
+
+const (
+	// NodeQOSRegoPolicyAnnotationPrefix is the annotation prefix of NodeQOS for inline rego policy, the annotation
+	// key is <prefix>/<rule-name>.
+	NodeQOSRegoPolicyAnnotationPrefix = "rego-policy.ensurance.crane.io"
+	// NodeQOSRegoConfigMapAnnotationPrefix is the annotation prefix of NodeQOS for rego policy loaded from
+	// a ConfigMap, the annotation key is <prefix>/<rule-name> and the value is <namespace>/<name>.
+	NodeQOSRegoConfigMapAnnotationPrefix = "rego-configmap.ensurance.crane.io"
+	// NodeQOSRegoConfigMapPolicyKey is the data key of the rego policy in ConfigMap.
+	NodeQOSRegoConfigMapPolicyKey = "policy.rego"
+	// NodeQOSRuleEvaluationAnnotationPrefix is the annotation prefix of NodeQOS for how a metric rule is evaluated,
+	// the annotation key is <prefix>/<rule-name> and the value is json, e.g. {"operator":"<","aggregation":"avg","window":5}.
+	// Throttle and eviction support the operators < and <= only for the metrics whose watermarks are lower bounds,
+	// e.g. exclusive_cpu_idle, and the other operators only for the other metrics. The rate aggregation is only
+	// supported by the rules whose actions disable scheduling, since the gaps to the watermarks are computed from
+	// the latest metric values.
+	NodeQOSRuleEvaluationAnnotationPrefix = "rule-evaluation.ensurance.crane.io"
+)
+
+const (
+	// AvoidanceActionDiskThrottleAnnotation is the annotation of AvoidanceAction for how to throttle the disk bandwidth
+	// of pods, the value is json, e.g. {"minKiBps":1024,"maxKiBps":1048576,"stepRatio":20}.
+	AvoidanceActionDiskThrottleAnnotation = "ensurance.crane.io/disk-throttle"
+	// AvoidanceActionNetworkThrottleAnnotation is the annotation of AvoidanceAction for how to throttle the egress
+	// bandwidth of pods in kbit/s, the value is json, e.g. {"minKiBps":1000,"maxKiBps":10000000,"stepRatio":20}.
+	AvoidanceActionNetworkThrottleAnnotation = "ensurance.crane.io/network-throttle"
+	// PodThrottledAnnotationPrefix is the annotation prefix of pods throttled down by crane-agent, the annotation key
+	// is <prefix>/<metric> of the resource throttled, e.g. cpu_total_usage, it is removed once the resource is restored.
+	PodThrottledAnnotationPrefix = "throttled.ensurance.crane.io"
 )
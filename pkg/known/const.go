@@ -4,6 +4,19 @@ const (
 	MetricNamePrediction  = "crane_autoscaling_prediction"
 	MetricNameCron        = "crane_autoscaling_cron"
 	MetricNamePodCpuUsage = "crane_pod_cpu_usage"
+	// MetricNamePredictionLower and MetricNamePredictionUpper are the external metrics of the lower and upper bounds
+	// of the prediction interval
+	MetricNamePredictionLower = "crane_autoscaling_prediction_lower"
+	MetricNamePredictionUpper = "crane_autoscaling_prediction_upper"
+)
+
+const (
+	// TimeSeriesPredictionConditionAccuracy is the condition of TimeSeriesPrediction with the rolling accuracy of the
+	// predictions of each metric in its message
+	TimeSeriesPredictionConditionAccuracy = "Accuracy"
+	// TimeSeriesPredictionConditionAlgorithmSelected is the condition of TimeSeriesPrediction with the algorithm selected
+	// for each auto metric and its backtest score in its message
+	TimeSeriesPredictionConditionAlgorithmSelected = "AlgorithmSelected"
 )
 
 const (
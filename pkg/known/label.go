@@ -10,6 +10,14 @@ const (
 	EnsuranceAnalyzedPressureConditionKey = "interference-identified"
 )
 
+const (
+	// PredictionBoundLabel is the label of the time series in TimeSeriesPrediction status which is a bound of the
+	// prediction interval instead of the point forecast, the value is PredictionBoundLower or PredictionBoundUpper.
+	PredictionBoundLabel = "prediction.crane.io/bound"
+	PredictionBoundLower = "lower"
+	PredictionBoundUpper = "upper"
+)
+
 const (
 	AnalyticsNameLabel = "analysis.crane.io/analytics-name"
 	AnalyticsUidLabel  = "analysis.crane.io/analytics-uid"
@@ -24,4 +32,5 @@ const (
 	RecommendationRuleTargetVersionLabel   = "analysis.crane.io/recommendation-target-version"
 	RecommendationRuleTargetNameLabel      = "analysis.crane.io/recommendation-target-name"
 	RecommendationRuleTargetNamespaceLabel = "analysis.crane.io/recommendation-target-namespace"
+	RecommendationHistoryLabel             = "analysis.crane.io/recommendation-history"
 )
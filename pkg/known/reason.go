@@ -4,4 +4,16 @@ const (
 	ReasonTimeSeriesPredictFailed  = "PredictFailed"
 	ReasonTimeSeriesPredictPartial = "PredictPartial"
 	ReasonTimeSeriesPredictSucceed = "PredictSucceed"
+
+	ReasonTimeSeriesPredictionAccuracyScored  = "AccuracyScored"
+	ReasonTimeSeriesPredictionAccuracyUnknown = "AccuracyUnknown"
+
+	ReasonTimeSeriesPredictionAlgorithmSelected        = "AlgorithmSelected"
+	ReasonTimeSeriesPredictionAlgorithmSelectionFailed = "AlgorithmSelectionFailed"
+
+	ReasonTimeSeriesPredictionOutOfBand      = "PredictionOutOfBand"
+	ReasonTimeSeriesPredictionLevelShift     = "PredictionLevelShift"
+	ReasonTimeSeriesPredictionModelRetrained = "ModelRetrained"
+
+	ReasonTimeSeriesPredictionCalendarInvalid = "CalendarInvalid"
 )
@@ -60,7 +60,7 @@ func (p *ExternalMetricProvider) GetExternalMetric(ctx context.Context, namespac
 	switch info.Metric {
 	case known.MetricNameCron:
 		return p.GetCronExternalMetrics(ctx, namespace, metricSelector, info)
-	case known.MetricNamePrediction:
+	case known.MetricNamePrediction, known.MetricNamePredictionLower, known.MetricNamePredictionUpper:
 		predictions, err := GetPredictions(ctx, p.client, namespace, metricSelector)
 		if err != nil {
 			return nil, err
@@ -72,7 +72,7 @@ func (p *ExternalMetricProvider) GetExternalMetric(ctx context.Context, namespac
 		}
 
 		for _, prediction := range predictions {
-			timeSeries, err := utils.GetReadyPredictionMetric(info.Metric, resourceIdentifier, &prediction)
+			timeSeries, err := utils.GetReadyPredictionBoundMetric(info.Metric, resourceIdentifier, utils.GetPredictionMetricBound(info.Metric), &prediction)
 			if err != nil {
 				return nil, err
 			}
@@ -201,6 +201,9 @@ func (p *ExternalMetricProvider) ListAllExternalMetrics() []provider.ExternalMet
 	metricInfos = append(metricInfos, provider.ExternalMetricInfo{Metric: known.MetricNameCron})
 	//add prediction metric
 	metricInfos = append(metricInfos, provider.ExternalMetricInfo{Metric: known.MetricNamePrediction})
+	//add metrics of the bounds of the prediction interval
+	metricInfos = append(metricInfos, provider.ExternalMetricInfo{Metric: known.MetricNamePredictionLower})
+	metricInfos = append(metricInfos, provider.ExternalMetricInfo{Metric: known.MetricNamePredictionUpper})
 
 	if p.remoteAdapter != nil {
 		metricInfos = append(metricInfos, p.remoteAdapter.ListAllExternalMetrics()...)
@@ -210,7 +213,7 @@ func (p *ExternalMetricProvider) ListAllExternalMetrics() []provider.ExternalMet
 
 func IsLocalExternalMetric(metricInfo provider.ExternalMetricInfo, client client.Client) bool {
 	switch metricInfo.Metric {
-	case known.MetricNameCron, known.MetricNamePrediction:
+	case known.MetricNameCron, known.MetricNamePrediction, known.MetricNamePredictionLower, known.MetricNamePredictionUpper:
 		return true
 	}
 	return false
@@ -55,8 +55,49 @@ var (
 		},
 		[]string{"type", "apiversion", "owner_kind", "namespace", "owner_name", "update_status", "result_status"},
 	)
+
+	RecommendationDrift = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "crane",
+			Subsystem: "analysis",
+			Name:      "recommendation_drift",
+			Help:      "The relative difference between the workload and the recommended value, positive if the workload is larger",
+		},
+		[]string{"type", "apiversion", "owner_kind", "namespace", "owner_name", "container", "resource"},
+	)
+
+	RecommendationMonthlySavings = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "crane",
+			Subsystem: "analysis",
+			Name:      "recommendation_monthly_savings",
+			Help:      "The estimated monthly savings of recommendations per target namespace and recommendation rule",
+		},
+		[]string{"namespace", "recommendation_rule"},
+	)
+
+	RecommendationPhaseDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "crane",
+			Subsystem: "analysis",
+			Name:      "recommendation_phase_duration_seconds",
+			Help:      "The latency of the phases of recommenders, by the result of the phase",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+		},
+		[]string{"recommender", "phase", "result"},
+	)
+
+	RecommendationPhaseFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "crane",
+			Subsystem: "analysis",
+			Name:      "recommendation_phase_failures_total",
+			Help:      "The number of failed phases of recommenders, by the reason of the failure",
+		},
+		[]string{"recommender", "phase", "reason"},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(RecommendationExecutionCounter, ResourceRecommendation, ReplicasRecommendation, SelectTargets, RecommendationsStatus)
+	metrics.Registry.MustRegister(RecommendationExecutionCounter, ResourceRecommendation, ReplicasRecommendation, SelectTargets, RecommendationsStatus, RecommendationDrift, RecommendationMonthlySavings, RecommendationPhaseDuration, RecommendationPhaseFailures)
 }
@@ -40,6 +40,8 @@ const (
 	StepCollect            StepLabel = "collect"
 	StepAvoid              StepLabel = "avoid"
 	StepRestore            StepLabel = "restore"
+	StepRetry              StepLabel = "retry"
+	StepReconcile          StepLabel = "reconcile"
 	StepUpdateConfig       StepLabel = "updateConfig"
 	StepUpdateNodeResource StepLabel = "updateNodeResource"
 	StepUpdatePodResource  StepLabel = "updatePodResource"
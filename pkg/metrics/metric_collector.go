@@ -19,8 +19,10 @@ import (
 	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
 
 	"github.com/gocrane/crane/pkg/features"
+	"github.com/gocrane/crane/pkg/known"
 	. "github.com/gocrane/crane/pkg/metricprovider"
 	prometheus_adapter "github.com/gocrane/crane/pkg/prometheus-adapter"
+	"github.com/gocrane/crane/pkg/utils"
 )
 
 type CraneMetricCollector struct {
@@ -31,6 +33,9 @@ type CraneMetricCollector struct {
 	metricAutoScalingCron *prometheus.Desc
 	//external metrics of prediction for hpa
 	metricAutoScalingPrediction *prometheus.Desc
+	//external metrics of the bounds of the prediction interval for hpa
+	metricAutoScalingPredictionLower *prometheus.Desc
+	metricAutoScalingPredictionUpper *prometheus.Desc
 	//model metrics of tsp
 	metricPredictionTsp   *prometheus.Desc
 	metricMetricRuleError *prometheus.Desc
@@ -64,6 +69,18 @@ func NewCraneMetricCollector(client client.Client, scaleClient scale.ScalesGette
 			[]string{"targetKind", "targetName", "targetNamespace", "resourceIdentifier", "algorithm"},
 			nil,
 		),
+		metricAutoScalingPredictionLower: prometheus.NewDesc(
+			prometheus.BuildFQName("crane", "autoscaling", "prediction_lower"),
+			"external metrics value of the lower bound of the prediction interval for HorizontalPodAutoscaler",
+			[]string{"targetKind", "targetName", "targetNamespace", "resourceIdentifier", "algorithm"},
+			nil,
+		),
+		metricAutoScalingPredictionUpper: prometheus.NewDesc(
+			prometheus.BuildFQName("crane", "autoscaling", "prediction_upper"),
+			"external metrics value of the upper bound of the prediction interval for HorizontalPodAutoscaler",
+			[]string{"targetKind", "targetName", "targetNamespace", "resourceIdentifier", "algorithm"},
+			nil,
+		),
 		metricPredictionTsp: prometheus.NewDesc(
 			prometheus.BuildFQName("crane", "prediction", "tsp"),
 			"model metrics value of tsp for Prediction",
@@ -85,6 +102,8 @@ func NewCraneMetricCollector(client client.Client, scaleClient scale.ScalesGette
 func (c *CraneMetricCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.metricAutoScalingCron
 	ch <- c.metricAutoScalingPrediction
+	ch <- c.metricAutoScalingPredictionLower
+	ch <- c.metricAutoScalingPredictionUpper
 	ch <- c.metricPredictionTsp
 	ch <- c.metricMetricRuleError
 }
@@ -221,26 +240,38 @@ func (c *CraneMetricCollector) computePredictionMetric(tsp *predictionapi.TimeSe
 			Algorithm:          string(metricConf.Algorithm.AlgorithmType),
 		}
 
+		// the bounds of the prediction interval are only collected as the external metrics of their own
+		bound := utils.GetPredictionBound(data)
+		autoScalingDesc := c.metricAutoScalingPrediction
+		switch bound {
+		case known.PredictionBoundLower:
+			autoScalingDesc = c.metricAutoScalingPredictionLower
+		case known.PredictionBoundUpper:
+			autoScalingDesc = c.metricAutoScalingPredictionUpper
+		}
+
 		samples := data.Samples
 		sort.Slice(samples, func(i, j int) bool {
 			return samples[i].Timestamp < samples[j].Timestamp
 		})
 
-		// just one timestamp point, because prometheus collector will hash the label values, same label values is not valid
-		for _, v := range samples {
-			if v.Timestamp >= now {
-				ts := time.Unix(v.Timestamp, 0)
-				value, err := strconv.ParseFloat(v.Value, 64)
-				if err != nil {
-					klog.ErrorS(err, "Failed to parse sample value", "value", value)
-					continue
+		if bound == "" {
+			// just one timestamp point, because prometheus collector will hash the label values, same label values is not valid
+			for _, v := range samples {
+				if v.Timestamp >= now {
+					ts := time.Unix(v.Timestamp, 0)
+					value, err := strconv.ParseFloat(v.Value, 64)
+					if err != nil {
+						klog.ErrorS(err, "Failed to parse sample value", "value", value)
+						continue
+					}
+					//collect model metric of tsp for Prediction
+					predictionMetric.Desc = c.metricPredictionTsp
+					predictionMetric.MetricValue = value
+					predictionMetric.Timestamp = ts
+					predictionMetrics = append(predictionMetrics, predictionMetric)
+					break
 				}
-				//collect model metric of tsp for Prediction
-				predictionMetric.Desc = c.metricPredictionTsp
-				predictionMetric.MetricValue = value
-				predictionMetric.Timestamp = ts
-				predictionMetrics = append(predictionMetrics, predictionMetric)
-				break
 			}
 		}
 
@@ -275,7 +306,7 @@ func (c *CraneMetricCollector) computePredictionMetric(tsp *predictionapi.TimeSe
 		}
 
 		//collect external metric of prediction for HorizontalPodAutoscaler
-		predictionMetric.Desc = c.metricAutoScalingPrediction
+		predictionMetric.Desc = autoScalingDesc
 		predictionMetric.MetricValue = metricValue
 		predictionMetric.Timestamp = timestampStart
 		predictionMetrics = append(predictionMetrics, predictionMetric)
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	PredictionAccuracyMAPE = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "crane",
+			Subsystem: "prediction",
+			Name:      "accuracy_mape",
+			Help:      "The rolling mean absolute percentage error of the predictions of TimeSeriesPrediction, the under predictions are amplified",
+		},
+		[]string{"namespace", "name", "resource_identifier", "algorithm"},
+	)
+
+	PredictionAccuracyMAE = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "crane",
+			Subsystem: "prediction",
+			Name:      "accuracy_mae",
+			Help:      "The rolling mean absolute error of the predictions of TimeSeriesPrediction",
+		},
+		[]string{"namespace", "name", "resource_identifier", "algorithm"},
+	)
+
+	PredictionAnomalies = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "crane",
+			Subsystem: "prediction",
+			Name:      "anomalies_total",
+			Help:      "The number of the anomalies of the live values against the predictions of TimeSeriesPrediction",
+		},
+		[]string{"namespace", "name", "resource_identifier", "type"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(PredictionAccuracyMAPE, PredictionAccuracyMAE, PredictionAnomalies)
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
 )
 
 var (
@@ -68,3 +70,26 @@ func TestMAE(t *testing.T) {
 	assert.NoError(t, err)
 	fmt.Println(mae)
 }
+
+func TestNewScore(t *testing.T) {
+	actual := []common.Sample{{Timestamp: 60, Value: 1}, {Timestamp: 120, Value: 2}, {Timestamp: 180, Value: 4}}
+	predicted := []common.Sample{{Timestamp: 120, Value: 2.2}, {Timestamp: 180, Value: 4.4}, {Timestamp: 240, Value: 8}}
+
+	a, p := AlignSamples(actual, predicted)
+	assert.Equal(t, []float64{2, 4}, a)
+	assert.Equal(t, []float64{2.2, 4.4}, p)
+
+	score, err := NewScore(a, p)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, score.Count)
+	assert.InEpsilon(t, 0.1, score.MAPE, epsilon)
+	assert.InEpsilon(t, 0.3, score.MAE, epsilon)
+
+	score, err = NewScore([]float64{0, 1}, []float64{1, 1})
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(score.MAPE))
+	assert.InEpsilon(t, 0.5, score.MAE, epsilon)
+
+	_, err = NewScore(nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package accuracy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Residuals returns the differences of the actual values and the predicted values.
+func Residuals(actual, predicted []float64) ([]float64, error) {
+	if len(actual) != len(predicted) {
+		return nil, fmt.Errorf("actual and predicted series are not the same length")
+	}
+
+	residuals := make([]float64, len(actual))
+	for i := range actual {
+		residuals[i] = actual[i] - predicted[i]
+	}
+	return residuals, nil
+}
+
+// Quantile returns the q (0 <= q <= 1) quantile of the values with linear interpolation, it returns 0 if there is
+// no value.
+func Quantile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower < 0 {
+		return sorted[0]
+	}
+	if upper >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
@@ -0,0 +1,35 @@
+package accuracy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResiduals(t *testing.T) {
+	residuals, err := Residuals([]float64{1, 2, 3}, []float64{2, 2, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{-1, 0, 2}, residuals)
+
+	_, err = Residuals([]float64{1, 2}, []float64{1})
+	assert.Error(t, err)
+}
+
+func TestQuantile(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3}
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{q: 0, want: 1},
+		{q: 0.1, want: 1.4},
+		{q: 0.5, want: 3},
+		{q: 0.9, want: 4.6},
+		{q: 1, want: 5},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.want, Quantile(values, tt.q), epsilon)
+	}
+	assert.Equal(t, []float64{5, 1, 4, 2, 3}, values, "values should not be sorted in place")
+	assert.Equal(t, 0.0, Quantile(nil, 0.5))
+}
@@ -0,0 +1,54 @@
+package accuracy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+// Score is the accuracy of predicted values against the actual ones.
+type Score struct {
+	// MAPE is NaN if any actual value is too close to zero
+	MAPE float64
+	MAE  float64
+	// Count is the number of scored values
+	Count int
+}
+
+// NewScore scores the predicted values against the actual ones.
+func NewScore(actual, predicted []float64) (*Score, error) {
+	if len(actual) == 0 {
+		return nil, fmt.Errorf("no values to score")
+	}
+	mae, err := MAE(actual, predicted)
+	if err != nil {
+		return nil, err
+	}
+	mape, err := MAPE(actual, predicted)
+	if err != nil {
+		mape = math.NaN()
+	}
+	return &Score{MAPE: mape, MAE: mae, Count: len(actual)}, nil
+}
+
+func (s *Score) String() string {
+	return fmt.Sprintf("mape=%.4f, mae=%.4f, count=%d", s.MAPE, s.MAE, s.Count)
+}
+
+// AlignSamples joins the actual and predicted samples by timestamp.
+func AlignSamples(actual, predicted []common.Sample) ([]float64, []float64) {
+	values := make(map[int64]float64, len(predicted))
+	for _, sample := range predicted {
+		values[sample.Timestamp] = sample.Value
+	}
+
+	var a, p []float64
+	for _, sample := range actual {
+		if value, ok := values[sample.Timestamp]; ok {
+			a = append(a, sample.Value)
+			p = append(p, value)
+		}
+	}
+	return a, p
+}
@@ -0,0 +1,40 @@
+package prediction
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+)
+
+// AnomalyType is the type of the anomaly of the live values against the forecast
+type AnomalyType string
+
+const (
+	// AnomalyTypeOutOfBand means the live values are out of the interval to detect anomalies consecutively
+	AnomalyTypeOutOfBand AnomalyType = "OutOfBand"
+	// AnomalyTypeLevelShift means the live values shift away from the forecast, which is a changepoint of the time series
+	AnomalyTypeLevelShift AnomalyType = "LevelShift"
+)
+
+// Anomaly is an anomaly of the live values of a predicted time series
+type Anomaly struct {
+	Type   AnomalyType
+	Labels []common.Label
+	// Timestamp is the timestamp of the live value the anomaly is detected at
+	Timestamp int64
+	Value     float64
+	Predicted float64
+	// Lower and Upper are the bounds of the interval to detect anomalies, which is wider than the prediction interval
+	Lower float64
+	Upper float64
+	// Retrained is true if the model is retrained because of the anomaly
+	Retrained bool
+}
+
+// AnomalyInterface is implemented by the predictors which detect the anomalies of the live values against the forecast.
+type AnomalyInterface interface {
+	// QueryAnomalies returns the recent anomalies of the query which are detected at live values after the time
+	QueryAnomalies(ctx context.Context, metricNamer metricnaming.MetricNamer, after time.Time) ([]Anomaly, error)
+}
@@ -0,0 +1,249 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction/accuracy"
+	"github.com/gocrane/crane/pkg/prediction/dsp"
+	"github.com/gocrane/crane/pkg/prediction/percentile"
+	"github.com/gocrane/crane/pkg/providers"
+)
+
+// Candidate is an algorithm configuration to backtest.
+type Candidate struct {
+	// Name identifies the candidate in the results
+	Name      string
+	Algorithm predictionapi.Algorithm
+}
+
+// Config is the config of a backtest. The history is replayed from the start to the end of the backtest, at each
+// cutoff every candidate forecasts the next Horizon from the HistoryLength before the cutoff.
+type Config struct {
+	// HistoryLength is the length of the history each forecast is made from
+	HistoryLength time.Duration
+	// Horizon is the length of each forecast
+	Horizon time.Duration
+	// Step is the interval between two successive cutoffs
+	Step time.Duration
+	// SampleInterval is the resolution of the history
+	SampleInterval time.Duration
+}
+
+// Result is the accuracy of a candidate over all the forecasts of a backtest.
+type Result struct {
+	Candidate Candidate
+	// Score is nil if none of the forecasts is scored. All the candidates of a backtest are scored on the same points,
+	// the ones forecasted by every candidate which forecasts any.
+	Score *accuracy.Score
+	// Forecasts is the number of the successful forecasts
+	Forecasts int
+	// Err is the last error of the forecasts
+	Err error
+}
+
+// DefaultCandidates returns the default dsp and percentile configurations with the history length and sample interval.
+func DefaultCandidates(historyLength, sampleInterval string) []Candidate {
+	return []Candidate{
+		{
+			Name: string(predictionapi.AlgorithmTypeDSP),
+			Algorithm: predictionapi.Algorithm{
+				AlgorithmType: predictionapi.AlgorithmTypeDSP,
+				DSP: &predictionapi.DSP{
+					SampleInterval: sampleInterval,
+					HistoryLength:  historyLength,
+				},
+			},
+		},
+		{
+			Name: string(predictionapi.AlgorithmTypePercentile),
+			Algorithm: predictionapi.Algorithm{
+				AlgorithmType: predictionapi.AlgorithmTypePercentile,
+				Percentile: &predictionapi.Percentile{
+					Aggregated:     true,
+					SampleInterval: sampleInterval,
+					HistoryLength:  historyLength,
+					Histogram: predictionapi.HistogramConfig{
+						HalfLife: "24h",
+					},
+				},
+			},
+		},
+	}
+}
+
+// Forecast forecasts the samples in [start, end) from the history with the algorithm. The samples are forecasted the
+// same way as the predictor does, but without a data provider, so that the algorithm can be backtested on any history.
+func Forecast(algorithm predictionapi.Algorithm, history []common.Sample, start, end time.Time) ([]common.Sample, error) {
+	switch algorithm.AlgorithmType {
+	case predictionapi.AlgorithmTypeDSP:
+		return dsp.Forecast(history, algorithm.DSP, start, end)
+	case predictionapi.AlgorithmTypePercentile:
+		return percentile.Forecast(history, algorithm.Percentile, start, end)
+	}
+	return nil, fmt.Errorf("backtest of algorithm type %s is not supported", algorithm.AlgorithmType)
+}
+
+// Run queries the history of the metric from the history provider and replays it in [start, end).
+func Run(ctx context.Context, history providers.History, namer metricnaming.MetricNamer, start, end time.Time, config Config, candidates []Candidate) ([]*Result, error) {
+	queryStart := start.Add(-config.HistoryLength)
+
+	var tsList []*common.TimeSeries
+	var err error
+	if h, ok := history.(providers.ContextHistory); ok {
+		tsList, err = h.QueryTimeSeriesWithContext(ctx, namer, queryStart, end, config.SampleInterval)
+	} else {
+		tsList, err = history.QueryTimeSeries(namer, queryStart, end, config.SampleInterval)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(tsList) != 1 {
+		return nil, fmt.Errorf("backtest needs exactly one time series, got %d", len(tsList))
+	}
+
+	// some providers return the samples out of the range
+	samples := samplesInRange(tsList[0].Samples, queryStart, end)
+	return Replay(ctx, samples, start, end, config, candidates)
+}
+
+// point is a forecasted sample of a cutoff, the forecasts of different cutoffs may overlap if the step is shorter
+// than the horizon.
+type point struct {
+	cutoff    int64
+	timestamp int64
+}
+
+// Replay replays the samples in [start, end) and scores the forecasts of the candidates at each cutoff against the
+// actual samples. The candidates are scored on the common points they all forecast so that their scores are
+// comparable.
+func Replay(ctx context.Context, samples []common.Sample, start, end time.Time, config Config, candidates []Candidate) ([]*Result, error) {
+	if config.Horizon <= 0 || config.Step <= 0 {
+		return nil, fmt.Errorf("horizon and step of backtest should be positive")
+	}
+
+	results := make([]*Result, len(candidates))
+	predicteds := make([]map[point]float64, len(candidates))
+	for i := range candidates {
+		results[i] = &Result{Candidate: candidates[i]}
+		predicteds[i] = map[point]float64{}
+	}
+	actuals := map[point]float64{}
+	var points []point
+
+	for cutoff := start; !cutoff.Add(config.Horizon).After(end); cutoff = cutoff.Add(config.Step) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		horizonEnd := cutoff.Add(config.Horizon)
+		history := samplesInRange(samples, cutoff.Add(-config.HistoryLength), cutoff)
+		actual := samplesInRange(samples, cutoff, horizonEnd)
+		if len(history) == 0 || len(actual) == 0 {
+			klog.V(4).Infof("Skip backtest cutoff %v: %d history samples, %d actual samples", cutoff, len(history), len(actual))
+			continue
+		}
+
+		for _, sample := range actual {
+			pt := point{cutoff: cutoff.Unix(), timestamp: sample.Timestamp}
+			actuals[pt] = sample.Value
+			points = append(points, pt)
+		}
+
+		for i, candidate := range candidates {
+			predicted, err := Forecast(candidate.Algorithm, history, cutoff, horizonEnd)
+			if err != nil {
+				results[i].Err = fmt.Errorf("forecast at %v failed: %v", cutoff, err)
+				continue
+			}
+			for _, sample := range predicted {
+				predicteds[i][point{cutoff: cutoff.Unix(), timestamp: sample.Timestamp}] = sample.Value
+			}
+			results[i].Forecasts++
+		}
+	}
+
+	// the points forecasted by every candidate which forecasts any
+	var common []point
+	for _, pt := range points {
+		forecasted := true
+		for i := range candidates {
+			if _, ok := predicteds[i][pt]; !ok && len(predicteds[i]) > 0 {
+				forecasted = false
+				break
+			}
+		}
+		if forecasted {
+			common = append(common, pt)
+		}
+	}
+
+	for i := range results {
+		if len(predicteds[i]) == 0 {
+			continue
+		}
+		if len(common) == 0 {
+			results[i].Err = fmt.Errorf("no points are forecasted by all the candidates")
+			continue
+		}
+		a := make([]float64, len(common))
+		p := make([]float64, len(common))
+		for j, pt := range common {
+			a[j] = actuals[pt]
+			p[j] = predicteds[i][pt]
+		}
+		score, err := accuracy.NewScore(a, p)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Score = score
+		klog.V(4).Infof("Backtest candidate %s: %s", results[i].Candidate.Name, score)
+	}
+	return results, nil
+}
+
+// Best returns the scored result with the least prediction error, nil if none of the results is scored. The results
+// are compared by MAPE, or by MAE if the MAPE of any result is not available.
+func Best(results []*Result) *Result {
+	useMAPE := true
+	for _, result := range results {
+		if result.Score != nil && math.IsNaN(result.Score.MAPE) {
+			useMAPE = false
+		}
+	}
+	predictionError := func(score *accuracy.Score) float64 {
+		if useMAPE {
+			return score.MAPE
+		}
+		return score.MAE
+	}
+
+	var best *Result
+	for _, result := range results {
+		if result.Score == nil {
+			continue
+		}
+		if best == nil || predictionError(result.Score) < predictionError(best.Score) {
+			best = result
+		}
+	}
+	return best
+}
+
+func samplesInRange(samples []common.Sample, start, end time.Time) []common.Sample {
+	var result []common.Sample
+	for _, sample := range samples {
+		if sample.Timestamp >= start.Unix() && sample.Timestamp < end.Unix() {
+			result = append(result, sample)
+		}
+	}
+	return result
+}
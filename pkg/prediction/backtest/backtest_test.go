@@ -0,0 +1,90 @@
+package backtest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction/accuracy"
+	"github.com/gocrane/crane/pkg/providers/csv"
+)
+
+// dailySeries writes a csv of a series with a daily period sampled every minute
+func dailySeries(start time.Time, days int) *bytes.Buffer {
+	var buf bytes.Buffer
+	buf.WriteString("ts,value\n")
+	for t := start; t.Before(start.Add(time.Duration(days) * 24 * time.Hour)); t = t.Add(time.Minute) {
+		phase := 2 * math.Pi * float64(t.Sub(start)) / float64(24*time.Hour)
+		buf.WriteString(fmt.Sprintf("%d,%f\n", t.Unix(), 5+4*math.Sin(phase)))
+	}
+	return &buf
+}
+
+func TestRun(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	provider, err := csv.NewProvider(dailySeries(start, 5))
+	assert.NoError(t, err)
+
+	config := Config{
+		HistoryLength:  3 * 24 * time.Hour,
+		Horizon:        24 * time.Hour,
+		Step:           24 * time.Hour,
+		SampleInterval: time.Minute,
+	}
+	results, err := Run(context.TODO(), provider, &metricnaming.GeneralMetricNamer{}, start.Add(3*24*time.Hour), start.Add(5*24*time.Hour), config, DefaultCandidates("3d", "1m"))
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	for _, result := range results {
+		assert.NoError(t, result.Err, result.Candidate.Name)
+		assert.Equal(t, 2, result.Forecasts, result.Candidate.Name)
+		assert.NotNil(t, result.Score, result.Candidate.Name)
+	}
+
+	best := Best(results)
+	assert.NotNil(t, best)
+	assert.Equal(t, string(predictionapi.AlgorithmTypeDSP), best.Candidate.Name)
+	// the percentile forecast of the peak overestimates the most of the day
+	assert.Less(t, best.Score.MAPE, results[1].Score.MAPE)
+	// the candidates are scored on the same points
+	assert.Equal(t, results[0].Score.Count, results[1].Score.Count)
+}
+
+func TestBest(t *testing.T) {
+	results := []*Result{
+		{Candidate: Candidate{Name: "a"}, Score: &accuracy.Score{MAPE: 0.1, MAE: 2, Count: 10}},
+		{Candidate: Candidate{Name: "b"}, Score: &accuracy.Score{MAPE: 0.2, MAE: 1, Count: 10}},
+		{Candidate: Candidate{Name: "c"}},
+	}
+	assert.Equal(t, "a", Best(results).Candidate.Name)
+
+	// the mae is compared if the mape of any result is not available
+	results[1].Score.MAPE = math.NaN()
+	assert.Equal(t, "b", Best(results).Candidate.Name)
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	config := Config{HistoryLength: time.Hour, Horizon: time.Hour, Step: time.Hour, SampleInterval: time.Minute}
+
+	candidates := []Candidate{{Name: "unknown", Algorithm: predictionapi.Algorithm{AlgorithmType: "unknown"}}}
+	results, err := Replay(context.TODO(), nil, start, start.Add(time.Hour), config, candidates)
+	assert.NoError(t, err)
+	assert.Nil(t, results[0].Score)
+	assert.Nil(t, Best(results))
+
+	_, err = Replay(context.TODO(), nil, start, start.Add(time.Hour), Config{}, candidates)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	_, err = Replay(ctx, nil, start, start.Add(time.Hour), config, candidates)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,202 @@
+package calendar
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+// ConfigMapDataKey is the key of the calendar in the data of the configmap
+const ConfigMapDataKey = "calendar.yaml"
+
+// Calendar is a list of special periods whose predictions are overridden, such as sales events and month-end batches
+// which can not be learned from the history window of the predictors. The later period wins if periods overlap.
+type Calendar struct {
+	Periods []Period `json:"periods"`
+}
+
+// Period is a special period, which is either a one-off period from Start to End, or a recurring period starting
+// at each time of the cron Schedule and lasting for Duration. The predictions in the period are either multiplied
+// by Multiplier, or replaced by the actual values of the same length from HistoryFrom.
+type Period struct {
+	Name       string     `json:"name"`
+	Start      *time.Time `json:"start,omitempty"`
+	End        *time.Time `json:"end,omitempty"`
+	Schedule   string     `json:"schedule,omitempty"`
+	Duration   string     `json:"duration,omitempty"`
+	Multiplier *float64   `json:"multiplier,omitempty"`
+	// HistoryFrom is only supported by the one-off periods
+	HistoryFrom *time.Time `json:"historyFrom,omitempty"`
+
+	schedule cron.Schedule
+	duration time.Duration
+}
+
+// Occurrence is an occurrence of a period in [Start, End)
+type Occurrence struct {
+	Period *Period
+	Start  time.Time
+	End    time.Time
+}
+
+// HistoryFunc returns the actual time series in [start, end] for a period using history
+type HistoryFunc func(start, end time.Time) (*common.TimeSeries, error)
+
+// Parse parses and validates a calendar in yaml
+func Parse(data []byte) (*Calendar, error) {
+	c := &Calendar{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for i := range c.Periods {
+		period := &c.Periods[i]
+		if period.Name == "" {
+			return nil, fmt.Errorf("period %d has no name", i)
+		}
+		if names[period.Name] {
+			return nil, fmt.Errorf("period %s is duplicated", period.Name)
+		}
+		names[period.Name] = true
+
+		if err := period.validate(); err != nil {
+			return nil, fmt.Errorf("period %s is invalid: %v", period.Name, err)
+		}
+	}
+	return c, nil
+}
+
+func (p *Period) validate() error {
+	if p.Schedule != "" {
+		if p.Start != nil || p.End != nil {
+			return fmt.Errorf("start and end are not allowed with schedule")
+		}
+		schedule, err := cron.ParseStandard(p.Schedule)
+		if err != nil {
+			return fmt.Errorf("schedule %q is invalid: %v", p.Schedule, err)
+		}
+		duration, err := time.ParseDuration(p.Duration)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("duration %q is not a positive duration", p.Duration)
+		}
+		if p.HistoryFrom != nil {
+			return fmt.Errorf("historyFrom is not allowed with schedule")
+		}
+		p.schedule, p.duration = schedule, duration
+	} else {
+		if p.Start == nil || p.End == nil || !p.End.After(*p.Start) {
+			return fmt.Errorf("start and end are required and end must be after start")
+		}
+		if p.Duration != "" {
+			return fmt.Errorf("duration is only allowed with schedule")
+		}
+	}
+
+	if (p.Multiplier == nil) == (p.HistoryFrom == nil) {
+		return fmt.Errorf("exactly one of multiplier and historyFrom is required")
+	}
+	if p.Multiplier != nil && *p.Multiplier < 0 {
+		return fmt.Errorf("multiplier %v is negative", *p.Multiplier)
+	}
+	return nil
+}
+
+// Occurrences returns the occurrences of the periods overlapping [start, end], in the order of the periods
+func (c *Calendar) Occurrences(start, end time.Time) []Occurrence {
+	var occurrences []Occurrence
+	for i := range c.Periods {
+		period := &c.Periods[i]
+		if period.schedule == nil {
+			if period.Start.After(end) || !period.End.After(start) {
+				continue
+			}
+			occurrences = append(occurrences, Occurrence{Period: period, Start: *period.Start, End: *period.End})
+			continue
+		}
+		// the occurrence started in the last duration may still be in effect
+		for t := period.schedule.Next(start.Add(-period.duration)); !t.After(end); t = period.schedule.Next(t) {
+			occurrences = append(occurrences, Occurrence{Period: period, Start: t, End: t.Add(period.duration)})
+		}
+	}
+	return occurrences
+}
+
+// Apply overrides the samples of the predicted time series in the occurrences, and moves the samples of its bounds,
+// which have the same timestamps, along with it. It returns the overridden copies of the time series.
+func Apply(occurrences []Occurrence, history HistoryFunc, predicted *common.TimeSeries, bounds ...*common.TimeSeries) (*common.TimeSeries, []*common.TimeSeries, error) {
+	// an adjusted value is value*scale+shift
+	type adjustment struct {
+		scale float64
+		shift float64
+	}
+	adjustments := map[int64]adjustment{}
+	for _, occurrence := range occurrences {
+		var actual *common.TimeSeries
+		if occurrence.Period.HistoryFrom != nil {
+			var err error
+			historyStart := *occurrence.Period.HistoryFrom
+			actual, err = history(historyStart, historyStart.Add(occurrence.End.Sub(occurrence.Start)))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to query history of period %s: %v", occurrence.Period.Name, err)
+			}
+		}
+
+		for _, sample := range predicted.Samples {
+			t := time.Unix(sample.Timestamp, 0)
+			if t.Before(occurrence.Start) || !t.Before(occurrence.End) {
+				continue
+			}
+			if occurrence.Period.Multiplier != nil {
+				adjustments[sample.Timestamp] = adjustment{scale: *occurrence.Period.Multiplier}
+				continue
+			}
+			value, ok := valueAt(actual, occurrence.Period.HistoryFrom.Add(t.Sub(occurrence.Start)).Unix())
+			if !ok {
+				continue
+			}
+			adjustments[sample.Timestamp] = adjustment{scale: 1, shift: value - sample.Value}
+		}
+	}
+
+	adjust := func(ts *common.TimeSeries) *common.TimeSeries {
+		if ts == nil {
+			return nil
+		}
+		samples := make([]common.Sample, len(ts.Samples))
+		for i, sample := range ts.Samples {
+			samples[i] = sample
+			if a, ok := adjustments[sample.Timestamp]; ok {
+				samples[i].Value = math.Max(sample.Value*a.scale+a.shift, 0)
+			}
+		}
+		return &common.TimeSeries{Labels: ts.Labels, Samples: samples}
+	}
+
+	adjustedBounds := make([]*common.TimeSeries, len(bounds))
+	for i := range bounds {
+		adjustedBounds[i] = adjust(bounds[i])
+	}
+	return adjust(predicted), adjustedBounds, nil
+}
+
+// valueAt returns the value of the last sample not after the timestamp, if it is in the time series
+func valueAt(ts *common.TimeSeries, timestamp int64) (float64, bool) {
+	if ts == nil {
+		return 0, false
+	}
+	n := len(ts.Samples)
+	if n == 0 || timestamp < ts.Samples[0].Timestamp || timestamp > ts.Samples[n-1].Timestamp {
+		return 0, false
+	}
+	i := sort.Search(n, func(i int) bool {
+		return ts.Samples[i].Timestamp > timestamp
+	})
+	return ts.Samples[i-1].Value, true
+}
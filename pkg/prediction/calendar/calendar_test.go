@@ -0,0 +1,160 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  bool
+	}{
+		{
+			name: "one-off and recurring periods",
+			data: `
+periods:
+- name: sales
+  start: "2022-11-11T00:00:00+08:00"
+  end: "2022-11-12T00:00:00+08:00"
+  historyFrom: "2021-11-11T00:00:00+08:00"
+- name: month-end
+  schedule: "CRON_TZ=Asia/Shanghai 0 18 28 * *"
+  duration: 6h
+  multiplier: 2
+`,
+		},
+		{
+			name: "no rule",
+			data: `
+periods:
+- name: sales
+  start: "2022-11-11T00:00:00+08:00"
+  end: "2022-11-12T00:00:00+08:00"
+`,
+			err: true,
+		},
+		{
+			name: "both rules",
+			data: `
+periods:
+- name: sales
+  start: "2022-11-11T00:00:00+08:00"
+  end: "2022-11-12T00:00:00+08:00"
+  multiplier: 2
+  historyFrom: "2021-11-11T00:00:00+08:00"
+`,
+			err: true,
+		},
+		{
+			name: "end before start",
+			data: `
+periods:
+- name: sales
+  start: "2022-11-12T00:00:00+08:00"
+  end: "2022-11-11T00:00:00+08:00"
+  multiplier: 2
+`,
+			err: true,
+		},
+		{
+			name: "history with schedule",
+			data: `
+periods:
+- name: month-end
+  schedule: "0 18 28 * *"
+  duration: 6h
+  historyFrom: "2021-11-11T00:00:00+08:00"
+`,
+			err: true,
+		},
+		{
+			name: "invalid schedule",
+			data: `
+periods:
+- name: month-end
+  schedule: "0 18 28 *"
+  duration: 6h
+  multiplier: 2
+`,
+			err: true,
+		},
+		{
+			name: "duplicated names",
+			data: `
+periods:
+- name: month-end
+  schedule: "0 18 28 * *"
+  duration: 6h
+  multiplier: 2
+- name: month-end
+  schedule: "0 18 28 * *"
+  duration: 6h
+  multiplier: 3
+`,
+			err: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			assert.Equal(t, tt.err, err != nil, "err: %v", err)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	c, err := Parse([]byte(`
+periods:
+- name: hourly
+  schedule: "CRON_TZ=UTC 58 * * * *"
+  duration: 3m
+  multiplier: 2
+- name: sales
+  start: "2022-11-11T00:00:00Z"
+  end: "2022-11-11T00:03:00Z"
+  historyFrom: "2021-11-11T00:00:00Z"
+`))
+	assert.NoError(t, err)
+
+	// the predictions from 23:55 to 00:05 are 10, with the lower and upper bounds 8 and 12
+	start := time.Date(2022, 11, 10, 23, 55, 0, 0, time.UTC)
+	predicted, lower, upper := &common.TimeSeries{}, &common.TimeSeries{}, &common.TimeSeries{}
+	for i := 0; i <= 10; i++ {
+		ts := start.Add(time.Duration(i) * time.Minute).Unix()
+		predicted.Samples = append(predicted.Samples, common.Sample{Timestamp: ts, Value: 10})
+		lower.Samples = append(lower.Samples, common.Sample{Timestamp: ts, Value: 8})
+		upper.Samples = append(upper.Samples, common.Sample{Timestamp: ts, Value: 12})
+	}
+
+	occurrences := c.Occurrences(start, start.Add(10*time.Minute))
+	assert.Len(t, occurrences, 2)
+
+	history := func(historyStart, historyEnd time.Time) (*common.TimeSeries, error) {
+		assert.Equal(t, time.Date(2021, 11, 11, 0, 0, 0, 0, time.UTC), historyStart)
+		assert.Equal(t, 3*time.Minute, historyEnd.Sub(historyStart))
+		ts := &common.TimeSeries{}
+		for t := historyStart; !t.After(historyEnd); t = t.Add(time.Minute) {
+			ts.Samples = append(ts.Samples, common.Sample{Timestamp: t.Unix(), Value: 30})
+		}
+		return ts, nil
+	}
+
+	predicted, bounds, err := Apply(occurrences, history, predicted, lower, upper)
+	assert.NoError(t, err)
+	var values, lowerValues, upperValues []float64
+	for i := range predicted.Samples {
+		values = append(values, predicted.Samples[i].Value)
+		lowerValues = append(lowerValues, bounds[0].Samples[i].Value)
+		upperValues = append(upperValues, bounds[1].Samples[i].Value)
+	}
+	// 23:58 and 23:59 are doubled, and 00:00 is doubled and then replaced by the history since the later period wins
+	assert.Equal(t, []float64{10, 10, 10, 20, 20, 30, 30, 30, 10, 10, 10}, values)
+	assert.Equal(t, []float64{8, 8, 8, 16, 16, 28, 28, 28, 8, 8, 8}, lowerValues)
+	assert.Equal(t, []float64{12, 12, 12, 24, 24, 32, 32, 32, 12, 12, 12}, upperValues)
+}
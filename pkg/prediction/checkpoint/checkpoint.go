@@ -0,0 +1,85 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	StoreTypeConfigMap = "configmap"
+	StoreTypeFile      = "file"
+
+	// DefaultInterval is the default interval to save the checkpoints
+	DefaultInterval = 10 * time.Minute
+)
+
+// Checkpoint is the serialized model of a query of a predictor, so that the model can be restored after craned
+// restarts without querying the history again.
+type Checkpoint struct {
+	Algorithm string `json:"algorithm"`
+	QueryExpr string `json:"queryExpr"`
+	// Version is the version of the model data, the checkpoints of other versions are discarded
+	Version string `json:"version"`
+	// Fingerprint identifies the config of the model, the checkpoints of other configs are discarded
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Time        time.Time `json:"time"`
+	// Data is the model data specific to the algorithm
+	Data json.RawMessage `json:"data"`
+}
+
+// Validate returns an error if the checkpoint is not of the version and fingerprint, or older than maxAge.
+func (c *Checkpoint) Validate(version, fingerprint string, maxAge time.Duration) error {
+	if c.Version != version {
+		return fmt.Errorf("checkpoint version %q is not %q", c.Version, version)
+	}
+	if c.Fingerprint != fingerprint {
+		return fmt.Errorf("checkpoint fingerprint %q is not %q", c.Fingerprint, fingerprint)
+	}
+	if maxAge > 0 && time.Since(c.Time) > maxAge {
+		return fmt.Errorf("checkpoint at %v is older than %v", c.Time, maxAge)
+	}
+	return nil
+}
+
+// Store saves and loads the checkpoints, a checkpoint is identified by the algorithm and query expression.
+type Store interface {
+	Save(c *Checkpoint) error
+	// Load returns nil if there is no checkpoint of the query
+	Load(algorithm, queryExpr string) (*Checkpoint, error)
+	Delete(algorithm, queryExpr string) error
+}
+
+// Config is the config of the checkpoint store
+type Config struct {
+	// Type is the type of the store, configmap or file, checkpointing is disabled if it is empty
+	Type string
+	// Path is the directory of the file store
+	Path string
+	// Namespace is the namespace of the configmap store
+	Namespace string
+}
+
+// NewStore creates the store of the config, nil is returned if the checkpointing is disabled.
+func NewStore(config Config, client kubernetes.Interface) (Store, error) {
+	switch config.Type {
+	case "":
+		return nil, nil
+	case StoreTypeConfigMap:
+		return NewConfigMapStore(client, config.Namespace), nil
+	case StoreTypeFile:
+		return NewFileStore(config.Path)
+	default:
+		return nil, fmt.Errorf("unknown checkpoint store %q", config.Type)
+	}
+}
+
+// name returns a name of the checkpoint which is valid for both file and configmap
+func name(algorithm, queryExpr string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(queryExpr))
+	return fmt.Sprintf("%s-%x", algorithm, h.Sum64())
+}
@@ -0,0 +1,97 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStores(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]Store{
+		StoreTypeFile:      fileStore,
+		StoreTypeConfigMap: NewConfigMapStore(fake.NewSimpleClientset(), "crane-system"),
+	}
+
+	for storeType, store := range stores {
+		c, err := store.Load("dsp", "cpu")
+		if err != nil || c != nil {
+			t.Errorf("%s: expect no checkpoint, got %v, %v", storeType, c, err)
+		}
+
+		for _, data := range []string{`{"a":1}`, `{"a":2}`} {
+			if err = store.Save(&Checkpoint{Algorithm: "dsp", QueryExpr: "cpu", Version: "v1", Time: time.Now(), Data: json.RawMessage(data)}); err != nil {
+				t.Fatalf("%s: failed to save: %v", storeType, err)
+			}
+		}
+
+		c, err = store.Load("dsp", "cpu")
+		if err != nil || c == nil || string(c.Data) != `{"a":2}` {
+			t.Errorf("%s: expect the latest checkpoint, got %v, %v", storeType, c, err)
+		}
+		if c, _ = store.Load("percentile", "cpu"); c != nil {
+			t.Errorf("%s: expect no checkpoint of another algorithm, got %v", storeType, c)
+		}
+
+		if err = store.Delete("dsp", "cpu"); err != nil {
+			t.Errorf("%s: failed to delete: %v", storeType, err)
+		}
+		if err = store.Delete("dsp", "cpu"); err != nil {
+			t.Errorf("%s: failed to delete a deleted checkpoint: %v", storeType, err)
+		}
+		if c, _ = store.Load("dsp", "cpu"); c != nil {
+			t.Errorf("%s: expect no checkpoint after deletion, got %v", storeType, c)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := &Checkpoint{Version: "v1", Fingerprint: "f", Time: time.Now().Add(-time.Hour)}
+
+	tests := []struct {
+		version     string
+		fingerprint string
+		maxAge      time.Duration
+		valid       bool
+	}{
+		{"v1", "f", 2 * time.Hour, true},
+		{"v1", "f", 0, true},
+		{"v2", "f", 2 * time.Hour, false},
+		{"v1", "g", 2 * time.Hour, false},
+		{"v1", "f", time.Minute, false},
+	}
+
+	for i, test := range tests {
+		if err := c.Validate(test.version, test.fingerprint, test.maxAge); (err == nil) != test.valid {
+			t.Errorf("case %d: expect valid %v, got %v", i, test.valid, err)
+		}
+	}
+}
+
+func TestConfigMapStoreOnlyTouchesCheckpoints(t *testing.T) {
+	other := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapPrefix + name("dsp", "cpu"), Namespace: "crane-system"}}
+	client := fake.NewSimpleClientset(other)
+	store := NewConfigMapStore(client, "crane-system")
+
+	if err := store.Save(&Checkpoint{Algorithm: "dsp", QueryExpr: "cpu", Version: "v1", Time: time.Now()}); err == nil {
+		t.Errorf("expect the configmap not labeled as a checkpoint not to be overwritten")
+	}
+	if c, err := store.Load("dsp", "cpu"); err != nil || c != nil {
+		t.Errorf("expect no checkpoint from the configmap not labeled, got %v, %v", c, err)
+	}
+	if err := store.Delete("dsp", "cpu"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().ConfigMaps("crane-system").Get(context.TODO(), other.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("expect the configmap not labeled as a checkpoint not to be deleted, got %v", err)
+	}
+}
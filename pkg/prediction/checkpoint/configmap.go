@@ -0,0 +1,137 @@
+package checkpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	configMapPrefix = "prediction-checkpoint-"
+	// configMapDataKey is the key of the gzipped checkpoint in the binary data of the configmap
+	configMapDataKey = "checkpoint.json.gz"
+	// LabelAlgorithm is the label of the checkpoint configmaps, the value is the algorithm
+	LabelAlgorithm = "prediction.crane.io/checkpoint-algorithm"
+)
+
+type configMapStore struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+// NewConfigMapStore creates a store which saves a checkpoint in a configmap of the namespace, the checkpoint is
+// gzipped because the size of a configmap is limited to 1MB.
+func NewConfigMapStore(client kubernetes.Interface, namespace string) Store {
+	return &configMapStore{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+func (s *configMapStore) Save(c *Checkpoint) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapPrefix + name(c.Algorithm, c.QueryExpr),
+			Namespace: s.namespace,
+			Labels:    map[string]string{LabelAlgorithm: c.Algorithm},
+		},
+		BinaryData: map[string][]byte{configMapDataKey: buf.Bytes()},
+	}
+
+	ctx := context.TODO()
+	_, err = s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{})
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	// only the configmap of the checkpoint is overwritten
+	existing, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, cm.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if existing.Labels[LabelAlgorithm] != c.Algorithm {
+		return fmt.Errorf("configmap %s/%s is not a checkpoint of %s", s.namespace, cm.Name, c.Algorithm)
+	}
+	cm.ResourceVersion = existing.ResourceVersion
+	_, err = s.client.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+func (s *configMapStore) Load(algorithm, queryExpr string) (*Checkpoint, error) {
+	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(context.TODO(), configMapPrefix+name(algorithm, queryExpr), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := cm.BinaryData[configMapDataKey]
+	if !ok || cm.Labels[LabelAlgorithm] != algorithm {
+		return nil, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err = io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Checkpoint{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	// the name is a hash of the query, skip the checkpoint of another query
+	if c.Algorithm != algorithm || c.QueryExpr != queryExpr {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// Delete deletes the configmap of the checkpoint, which is selected by the label of the algorithm so that no other
+// configmap in the namespace is deleted.
+func (s *configMapStore) Delete(algorithm, queryExpr string) error {
+	ctx := context.TODO()
+	cms, err := s.client.CoreV1().ConfigMaps(s.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{LabelAlgorithm: algorithm}).String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, cm := range cms.Items {
+		if cm.Name != configMapPrefix+name(algorithm, queryExpr) {
+			continue
+		}
+		err = s.client.CoreV1().ConfigMaps(s.namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{Preconditions: metav1.NewUIDPreconditions(string(cm.UID))})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
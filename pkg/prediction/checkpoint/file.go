@@ -0,0 +1,65 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store which saves a checkpoint as a json file in the dir.
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &fileStore{dir: dir}, nil
+}
+
+func (s *fileStore) path(algorithm, queryExpr string) string {
+	return filepath.Join(s.dir, name(algorithm, queryExpr)+".json")
+}
+
+func (s *fileStore) Save(c *Checkpoint) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	path := s.path(c.Algorithm, c.QueryExpr)
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *fileStore) Load(algorithm, queryExpr string) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path(algorithm, queryExpr))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Checkpoint{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	// the name is a hash of the query, skip the checkpoint of another query
+	if c.Algorithm != algorithm || c.QueryExpr != queryExpr {
+		return nil, nil
+	}
+	return c, nil
+}
+
+func (s *fileStore) Delete(algorithm, queryExpr string) error {
+	err := os.Remove(s.path(algorithm, queryExpr))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -8,6 +8,41 @@ import (
 
 type AlgorithmModelConfig struct {
 	UpdateInterval time.Duration
+	// CheckpointInterval is the interval to save the models to the checkpoint store
+	CheckpointInterval time.Duration
+	// LowerQuantile and UpperQuantile are the quantiles of the lower and upper bounds of the prediction interval
+	LowerQuantile float64
+	UpperQuantile float64
+	// AnomalyCheckInterval is the interval to check the live values against the forecast of dsp, the check is
+	// disabled if it is not positive
+	AnomalyCheckInterval time.Duration
+	// AnomalyRetrainInterval is the min interval between the model update and the retraining triggered by anomalies
+	AnomalyRetrainInterval time.Duration
+	// AnomalyLowerQuantile and AnomalyUpperQuantile are the quantiles of the bounds of the interval to detect
+	// anomalies, it is wider than the prediction interval so that the normal values are rarely out of it
+	AnomalyLowerQuantile float64
+	AnomalyUpperQuantile float64
+	// AnomalyOutOfBandCount is the number of the consecutive live values out of the detection interval to be an anomaly
+	AnomalyOutOfBandCount int
+	// AnomalyCusumSlack and AnomalyCusumThreshold are the slack and the threshold of the cusum to detect level shifts
+	AnomalyCusumSlack     float64
+	AnomalyCusumThreshold float64
+}
+
+// RemoteConfig is the config of the remote predictor, which sends the history time series to a model server
+// over grpc and gets the forecast back.
+type RemoteConfig struct {
+	// Address is the address of the model server, the remote predictor is disabled if it is empty
+	Address string
+	Timeout time.Duration
+	// Model is the model name passed to the model server, such as prophet, arima or lstm
+	Model string
+	// HistoryLength is the length of the history sent to the model server
+	HistoryLength time.Duration
+	// HistoryResolution is the step of the history and the forecast
+	HistoryResolution time.Duration
+	// Future is the length of the forecast, it should be longer than the update interval of the model
+	Future time.Duration
 }
 
 type ModelInitMode string
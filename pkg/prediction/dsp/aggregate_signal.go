@@ -8,9 +8,15 @@ import (
 
 type aggregateSignal struct {
 	predictedTimeSeries *common.TimeSeries
-	startTime           time.Time
-	endTime             time.Time
-	lastUpdateTime      time.Time
+	// lowerTimeSeries and upperTimeSeries are the bounds of the prediction interval
+	lowerTimeSeries *common.TimeSeries
+	upperTimeSeries *common.TimeSeries
+	// anomalyLowerTimeSeries and anomalyUpperTimeSeries are the bounds of the wider interval to detect anomalies
+	anomalyLowerTimeSeries *common.TimeSeries
+	anomalyUpperTimeSeries *common.TimeSeries
+	startTime              time.Time
+	endTime                time.Time
+	lastUpdateTime         time.Time
 }
 
 func newAggregateSignal() *aggregateSignal {
@@ -26,3 +32,13 @@ func (a *aggregateSignal) setPredictedTimeSeries(ts *common.TimeSeries) {
 		a.lastUpdateTime = time.Now()
 	}
 }
+
+func (a *aggregateSignal) setBounds(lower, upper *common.TimeSeries) {
+	a.lowerTimeSeries = lower
+	a.upperTimeSeries = upper
+}
+
+func (a *aggregateSignal) setAnomalyBounds(lower, upper *common.TimeSeries) {
+	a.anomalyLowerTimeSeries = lower
+	a.anomalyUpperTimeSeries = upper
+}
@@ -0,0 +1,276 @@
+package dsp
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction"
+)
+
+const (
+	// defaultAnomalyLowerQuantile and defaultAnomalyUpperQuantile are the quantiles of the residuals bounding the
+	// interval to detect anomalies, about 2% of the normal values are out of it
+	defaultAnomalyLowerQuantile = 0.01
+	defaultAnomalyUpperQuantile = 0.99
+	// defaultOutOfBandCount is the number of the consecutive live values out of the detection interval to be an anomaly
+	defaultOutOfBandCount = 3
+	// defaultCusumSlack and defaultCusumThreshold are the slack and the threshold of the cusum of the residuals
+	// normalized by the half width of the detection interval, a level shift is detected once the cusum exceeds the
+	// threshold
+	defaultCusumSlack     = 0.5
+	defaultCusumThreshold = 5.0
+	// maxAnomalies is the max number of the recent anomalies kept for each query
+	maxAnomalies = 20
+)
+
+// detectorConfig is the thresholds of the anomaly detection
+type detectorConfig struct {
+	outOfBandCount int
+	cusumSlack     float64
+	cusumThreshold float64
+}
+
+// getDetectorConfig returns the thresholds of the anomaly detection in the model config, the defaults are used for the
+// ones not set
+func (p *periodicSignalPrediction) getDetectorConfig() detectorConfig {
+	c := detectorConfig{
+		outOfBandCount: p.modelConfig.AnomalyOutOfBandCount,
+		cusumSlack:     p.modelConfig.AnomalyCusumSlack,
+		cusumThreshold: p.modelConfig.AnomalyCusumThreshold,
+	}
+	if c.outOfBandCount == 0 {
+		c.outOfBandCount = defaultOutOfBandCount
+	}
+	if c.cusumSlack == 0.0 {
+		c.cusumSlack = defaultCusumSlack
+	}
+	if c.cusumThreshold == 0.0 {
+		c.cusumThreshold = defaultCusumThreshold
+	}
+	return c
+}
+
+// anomalyQuantiles returns the quantiles of the bounds of the interval to detect anomalies, the defaults are used if
+// they are not set
+func (p *periodicSignalPrediction) anomalyQuantiles() (float64, float64) {
+	lower, upper := p.modelConfig.AnomalyLowerQuantile, p.modelConfig.AnomalyUpperQuantile
+	if lower == 0.0 && upper == 0.0 {
+		return defaultAnomalyLowerQuantile, defaultAnomalyUpperQuantile
+	}
+	return lower, upper
+}
+
+// seriesDetector detects the anomalies of the live values of a predicted time series
+type seriesDetector struct {
+	// outOfBand is the number of the consecutive live values out of the detection interval
+	outOfBand int
+	// cusumUp and cusumDown are the cumulative sums of the normalized residuals above and below the forecast
+	cusumUp   float64
+	cusumDown float64
+	// lastTimestamp is the timestamp of the last observed live value
+	lastTimestamp int64
+}
+
+// observe observes a live value with the forecast and the detection interval at its timestamp, it returns the types
+// of the anomalies detected at the value.
+func (d *seriesDetector) observe(c detectorConfig, value, predicted, lower, upper float64) []prediction.AnomalyType {
+	var types []prediction.AnomalyType
+
+	if value < lower || value > upper {
+		d.outOfBand++
+		// the anomaly is reported once until the live values are back in the detection interval
+		if d.outOfBand == c.outOfBandCount {
+			types = append(types, prediction.AnomalyTypeOutOfBand)
+		}
+	} else {
+		d.outOfBand = 0
+	}
+
+	halfWidth := (upper - lower) / 2
+	if halfWidth <= 0 {
+		return types
+	}
+	z := (value - predicted) / halfWidth
+	d.cusumUp = math.Max(0, d.cusumUp+z-c.cusumSlack)
+	d.cusumDown = math.Max(0, d.cusumDown-z-c.cusumSlack)
+	if d.cusumUp > c.cusumThreshold || d.cusumDown > c.cusumThreshold {
+		types = append(types, prediction.AnomalyTypeLevelShift)
+		d.cusumUp, d.cusumDown = 0, 0
+	}
+	return types
+}
+
+// anomalyStore keeps the detectors and the recent anomalies of the queries
+type anomalyStore struct {
+	mutex sync.Mutex
+	// detectors is the detectors of the time series of each query, they are reset once the model is updated
+	detectors map[string] /*expr*/ map[string] /*key*/ *seriesDetector
+	anomalies map[string] /*expr*/ []prediction.Anomaly
+}
+
+func newAnomalyStore() *anomalyStore {
+	return &anomalyStore{
+		detectors: map[string]map[string]*seriesDetector{},
+		anomalies: map[string][]prediction.Anomaly{},
+	}
+}
+
+func (s *anomalyStore) detector(queryExpr, key string) *seriesDetector {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.detectors[queryExpr]; !exists {
+		s.detectors[queryExpr] = map[string]*seriesDetector{}
+	}
+	if _, exists := s.detectors[queryExpr][key]; !exists {
+		s.detectors[queryExpr][key] = &seriesDetector{}
+	}
+	return s.detectors[queryExpr][key]
+}
+
+// reset drops the detectors of the query since their states are relative to the previous model
+func (s *anomalyStore) reset(queryExpr string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.detectors, queryExpr)
+}
+
+func (s *anomalyStore) record(queryExpr string, anomalies []prediction.Anomaly, retrained bool) {
+	if len(anomalies) == 0 {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, anomaly := range anomalies {
+		anomaly.Retrained = retrained
+		s.anomalies[queryExpr] = append(s.anomalies[queryExpr], anomaly)
+	}
+	if n := len(s.anomalies[queryExpr]); n > maxAnomalies {
+		s.anomalies[queryExpr] = s.anomalies[queryExpr][n-maxAnomalies:]
+	}
+}
+
+func (s *anomalyStore) list(queryExpr string, after int64) []prediction.Anomaly {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var anomalies []prediction.Anomaly
+	for _, anomaly := range s.anomalies[queryExpr] {
+		if anomaly.Timestamp > after {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	return anomalies
+}
+
+func (s *anomalyStore) delete(queryExpr string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.detectors, queryExpr)
+	delete(s.anomalies, queryExpr)
+}
+
+func (p *periodicSignalPrediction) QueryAnomalies(_ context.Context, namer metricnaming.MetricNamer, after time.Time) ([]prediction.Anomaly, error) {
+	return p.anomalies.list(namer.BuildUniqueKey(), after.Unix()), nil
+}
+
+// detectAnomalies checks the latest live values of the query against the forecast, it returns the anomalies detected
+// and whether the model should be retrained because of them.
+func (p *periodicSignalPrediction) detectAnomalies(namer metricnaming.MetricNamer) ([]prediction.Anomaly, bool) {
+	queryExpr := namer.BuildUniqueKey()
+	signals, status := p.a.GetSignals(queryExpr)
+	if status != prediction.StatusReady || len(signals) == 0 || p.GetRealtimeProvider() == nil {
+		return nil, false
+	}
+
+	tsList, err := p.GetRealtimeProvider().QueryLatestTimeSeries(namer)
+	if err != nil {
+		klog.V(4).InfoS("Failed to query latest time series to detect anomalies.", "queryExpr", queryExpr, "err", err)
+		return nil, false
+	}
+
+	var anomalies []prediction.Anomaly
+	dc := p.getDetectorConfig()
+	lastUpdateTime := time.Now()
+	for _, ts := range tsList {
+		if len(ts.Samples) == 0 {
+			continue
+		}
+		key := prediction.AggregateSignalKey(ts.Labels)
+		signal, ok := signals[key]
+		// the live time series is joined with the forecast directly if there is only one time series on both sides
+		if !ok && len(tsList) == 1 && len(signals) == 1 {
+			for k, s := range signals {
+				key, signal, ok = k, s, true
+			}
+		}
+		if !ok || signal.anomalyLowerTimeSeries == nil || signal.anomalyUpperTimeSeries == nil {
+			continue
+		}
+		if signal.lastUpdateTime.Before(lastUpdateTime) {
+			lastUpdateTime = signal.lastUpdateTime
+		}
+
+		sample := ts.Samples[len(ts.Samples)-1]
+		detector := p.anomalies.detector(queryExpr, key)
+		if sample.Timestamp <= detector.lastTimestamp {
+			continue
+		}
+		predicted, ok1 := valueAt(signal.predictedTimeSeries, sample.Timestamp)
+		lower, ok2 := valueAt(signal.anomalyLowerTimeSeries, sample.Timestamp)
+		upper, ok3 := valueAt(signal.anomalyUpperTimeSeries, sample.Timestamp)
+		if !ok1 || !ok2 || !ok3 {
+			continue
+		}
+		predicted, lower, upper = p.calendarValues(namer, signal.predictedTimeSeries.Labels, sample.Timestamp, predicted, lower, upper)
+		detector.lastTimestamp = sample.Timestamp
+
+		for _, anomalyType := range detector.observe(dc, sample.Value, predicted, lower, upper) {
+			klog.V(4).InfoS("Detected anomaly.", "queryExpr", queryExpr, "labels", key, "type", anomalyType, "value", sample.Value, "predicted", predicted, "lower", lower, "upper", upper)
+			anomalies = append(anomalies, prediction.Anomaly{
+				Type:      anomalyType,
+				Labels:    signal.predictedTimeSeries.Labels,
+				Timestamp: sample.Timestamp,
+				Value:     sample.Value,
+				Predicted: predicted,
+				Lower:     lower,
+				Upper:     upper,
+			})
+		}
+	}
+
+	retrain := len(anomalies) > 0 && time.Since(lastUpdateTime) >= p.modelConfig.AnomalyRetrainInterval
+	return anomalies, retrain
+}
+
+// calendarValues overrides the forecast and the detection interval at the timestamp with the calendar of the query,
+// so that the special periods are not detected as anomalies.
+func (p *periodicSignalPrediction) calendarValues(namer metricnaming.MetricNamer, labels []common.Label, timestamp int64, predicted, lower, upper float64) (float64, float64, float64) {
+	series := func(value float64) *common.TimeSeries {
+		return &common.TimeSeries{Labels: labels, Samples: []common.Sample{{Timestamp: timestamp, Value: value}}}
+	}
+	t := time.Unix(timestamp, 0)
+	predictedTs, bounds := p.applyCalendar(namer, t, t, series(predicted), series(lower), series(upper))
+	return predictedTs.Samples[0].Value, bounds[0].Samples[0].Value, bounds[1].Samples[0].Value
+}
+
+// valueAt returns the value of the time series at the timestamp, which is the value of the last sample not after it
+func valueAt(ts *common.TimeSeries, timestamp int64) (float64, bool) {
+	n := len(ts.Samples)
+	if n == 0 || timestamp < ts.Samples[0].Timestamp || timestamp > ts.Samples[n-1].Timestamp {
+		return 0, false
+	}
+	i := sort.Search(n, func(i int) bool {
+		return ts.Samples[i].Timestamp > timestamp
+	})
+	return ts.Samples[i-1].Value, true
+}
@@ -0,0 +1,160 @@
+package dsp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/metricquery"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/config"
+)
+
+type fakeRealtime struct {
+	sample common.Sample
+}
+
+func (f *fakeRealtime) QueryLatestTimeSeries(_ metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
+	return []*common.TimeSeries{{Samples: []common.Sample{f.sample}}}, nil
+}
+
+func TestSeriesDetectorObserve(t *testing.T) {
+	c := detectorConfig{outOfBandCount: defaultOutOfBandCount, cusumSlack: defaultCusumSlack, cusumThreshold: defaultCusumThreshold}
+	d := &seriesDetector{}
+
+	// the values out of the detection interval are an anomaly once they are consecutive
+	assert.Empty(t, d.observe(c, 12, 10, 9, 11))
+	assert.Empty(t, d.observe(c, 12, 10, 9, 11))
+	assert.Equal(t, []prediction.AnomalyType{prediction.AnomalyTypeOutOfBand}, d.observe(c, 12, 10, 9, 11))
+	assert.Empty(t, d.observe(c, 10, 10, 9, 11))
+
+	// the values in the detection interval but consistently above the forecast are a level shift
+	d = &seriesDetector{}
+	var types []prediction.AnomalyType
+	for i := 0; i < 20 && len(types) == 0; i++ {
+		types = d.observe(c, 10.9, 10, 9, 11)
+	}
+	assert.Equal(t, []prediction.AnomalyType{prediction.AnomalyTypeLevelShift}, types)
+	assert.Equal(t, 0.0, d.cusumUp)
+
+	// the values around the forecast are not a level shift
+	d = &seriesDetector{}
+	for i := 0; i < 100; i++ {
+		assert.Empty(t, d.observe(c, 10+0.5*float64(i%3-1), 10, 9, 11))
+	}
+
+	// the thresholds are configurable
+	c = detectorConfig{outOfBandCount: 5, cusumSlack: 1, cusumThreshold: 5}
+	d = &seriesDetector{}
+	for i := 0; i < 4; i++ {
+		assert.Empty(t, d.observe(c, 12, 10, 9, 11))
+	}
+	assert.Equal(t, []prediction.AnomalyType{prediction.AnomalyTypeOutOfBand}, d.observe(c, 12, 10, 9, 11))
+	d = &seriesDetector{}
+	for i := 0; i < 100; i++ {
+		assert.Empty(t, d.observe(c, 10.9, 10, 9, 11))
+	}
+}
+
+func TestDetectorConfig(t *testing.T) {
+	p := NewPrediction(nil, nil, config.AlgorithmModelConfig{}, nil).(*periodicSignalPrediction)
+	assert.Equal(t, detectorConfig{outOfBandCount: defaultOutOfBandCount, cusumSlack: defaultCusumSlack, cusumThreshold: defaultCusumThreshold}, p.getDetectorConfig())
+	lower, upper := p.anomalyQuantiles()
+	assert.Equal(t, defaultAnomalyLowerQuantile, lower)
+	assert.Equal(t, defaultAnomalyUpperQuantile, upper)
+
+	p = NewPrediction(nil, nil, config.AlgorithmModelConfig{
+		AnomalyLowerQuantile:  0.05,
+		AnomalyUpperQuantile:  0.95,
+		AnomalyOutOfBandCount: 5,
+		AnomalyCusumSlack:     1,
+		AnomalyCusumThreshold: 10,
+	}, nil).(*periodicSignalPrediction)
+	assert.Equal(t, detectorConfig{outOfBandCount: 5, cusumSlack: 1, cusumThreshold: 10}, p.getDetectorConfig())
+	lower, upper = p.anomalyQuantiles()
+	assert.Equal(t, 0.05, lower)
+	assert.Equal(t, 0.95, upper)
+}
+
+func TestValueAt(t *testing.T) {
+	ts := &common.TimeSeries{Samples: []common.Sample{{Timestamp: 60, Value: 1}, {Timestamp: 120, Value: 2}, {Timestamp: 180, Value: 3}}}
+
+	tests := []struct {
+		timestamp int64
+		value     float64
+		ok        bool
+	}{
+		{timestamp: 59, ok: false},
+		{timestamp: 60, value: 1, ok: true},
+		{timestamp: 150, value: 2, ok: true},
+		{timestamp: 180, value: 3, ok: true},
+		{timestamp: 181, ok: false},
+	}
+	for _, tt := range tests {
+		value, ok := valueAt(ts, tt.timestamp)
+		assert.Equal(t, tt.ok, ok, "timestamp %d", tt.timestamp)
+		assert.Equal(t, tt.value, value, "timestamp %d", tt.timestamp)
+	}
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	namer := &metricnaming.GeneralMetricNamer{
+		Metric: &metricquery.Metric{
+			Type: metricquery.PromQLMetricType,
+			Prom: &metricquery.PromNamerInfo{
+				QueryExpr: "cpu",
+				Selector:  labels.Nothing(),
+			},
+		}}
+	queryExpr := namer.BuildUniqueKey()
+
+	now := time.Now().Truncate(time.Minute)
+	var predicted []common.Sample
+	for ts := now.Add(-time.Hour); ts.Before(now.Add(time.Hour)); ts = ts.Add(time.Minute) {
+		predicted = append(predicted, common.Sample{Timestamp: ts.Unix(), Value: 10})
+	}
+	signal := newAggregateSignal()
+	signal.setPredictedTimeSeries(&common.TimeSeries{Samples: predicted})
+	// the prediction interval is narrower than the detection interval
+	signal.setBounds(lowerBoundTimeSeries(signal.predictedTimeSeries, []float64{-0.5}, 0), upperBoundTimeSeries(signal.predictedTimeSeries, []float64{0.5}, 0))
+	signal.setAnomalyBounds(lowerBoundTimeSeries(signal.predictedTimeSeries, []float64{-1}, 0), upperBoundTimeSeries(signal.predictedTimeSeries, []float64{1}, 0))
+
+	realtime := &fakeRealtime{}
+	p := NewPrediction(realtime, nil, config.AlgorithmModelConfig{AnomalyRetrainInterval: 0}, nil).(*periodicSignalPrediction)
+	p.a.Add(prediction.QueryExprWithCaller{MetricNamer: namer, Caller: "test"})
+	p.a.SetSignals(queryExpr, map[string]*aggregateSignal{"": signal})
+
+	var anomalies []prediction.Anomaly
+	var retrain bool
+	for i := 0; i < defaultOutOfBandCount; i++ {
+		realtime.sample = common.Sample{Timestamp: now.Add(time.Duration(i-defaultOutOfBandCount) * time.Minute).Unix(), Value: 11.5}
+		anomalies, retrain = p.detectAnomalies(namer)
+		if i < defaultOutOfBandCount-1 {
+			assert.Empty(t, anomalies)
+		}
+	}
+	assert.True(t, retrain)
+	assert.Len(t, anomalies, 1)
+	assert.Equal(t, prediction.AnomalyTypeOutOfBand, anomalies[0].Type)
+	assert.Equal(t, 11.5, anomalies[0].Value)
+	assert.Equal(t, 10.0, anomalies[0].Predicted)
+	assert.Equal(t, 9.0, anomalies[0].Lower)
+	assert.Equal(t, 11.0, anomalies[0].Upper)
+
+	// the same live value is not observed again
+	anomalies, _ = p.detectAnomalies(namer)
+	assert.Empty(t, anomalies)
+
+	p.anomalies.record(queryExpr, []prediction.Anomaly{{Type: prediction.AnomalyTypeOutOfBand, Timestamp: now.Unix()}}, true)
+	recorded, err := p.QueryAnomalies(context.TODO(), namer, now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, recorded, 1)
+	assert.True(t, recorded[0].Retrained)
+	recorded, _ = p.QueryAnomalies(context.TODO(), namer, now)
+	assert.Empty(t, recorded)
+}
@@ -0,0 +1,120 @@
+package dsp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/calendar"
+)
+
+// calendarStore keeps the calendars of the queries and the history of their periods
+type calendarStore struct {
+	mutex     sync.Mutex
+	calendars map[string] /*expr*/ *calendar.Calendar
+	// history is the actual time series in the ranges of the periods using history, which never change
+	history map[string] /*expr*/ map[string] /*range*/ []*common.TimeSeries
+}
+
+func newCalendarStore() *calendarStore {
+	return &calendarStore{
+		calendars: map[string]*calendar.Calendar{},
+		history:   map[string]map[string][]*common.TimeSeries{},
+	}
+}
+
+func (s *calendarStore) get(queryExpr string) *calendar.Calendar {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calendars[queryExpr]
+}
+
+func (s *calendarStore) set(queryExpr string, c *calendar.Calendar) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if c == nil {
+		delete(s.calendars, queryExpr)
+		delete(s.history, queryExpr)
+		return
+	}
+	s.calendars[queryExpr] = c
+}
+
+func (s *calendarStore) getHistory(queryExpr, key string) ([]*common.TimeSeries, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	tsList, ok := s.history[queryExpr][key]
+	return tsList, ok
+}
+
+func (s *calendarStore) setHistory(queryExpr, key string, tsList []*common.TimeSeries) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exists := s.history[queryExpr]; !exists {
+		s.history[queryExpr] = map[string][]*common.TimeSeries{}
+	}
+	s.history[queryExpr][key] = tsList
+}
+
+func (p *periodicSignalPrediction) WithCalendar(namer metricnaming.MetricNamer, c *calendar.Calendar) {
+	p.calendars.set(namer.BuildUniqueKey(), c)
+}
+
+// applyCalendar overrides the predicted time series and its bounds in [start, end] with the calendar of the query,
+// the time series are returned as they are if there is no calendar or it fails to be applied.
+func (p *periodicSignalPrediction) applyCalendar(namer metricnaming.MetricNamer, start, end time.Time, predicted *common.TimeSeries, bounds ...*common.TimeSeries) (*common.TimeSeries, []*common.TimeSeries) {
+	queryExpr := namer.BuildUniqueKey()
+	c := p.calendars.get(queryExpr)
+	if c == nil {
+		return predicted, bounds
+	}
+	occurrences := c.Occurrences(start, end)
+	if len(occurrences) == 0 {
+		return predicted, bounds
+	}
+
+	history := func(historyStart, historyEnd time.Time) (*common.TimeSeries, error) {
+		return p.queryCalendarHistory(namer, predicted.Labels, historyStart, historyEnd)
+	}
+	overridden, overriddenBounds, err := calendar.Apply(occurrences, history, predicted, bounds...)
+	if err != nil {
+		klog.ErrorS(err, "Failed to apply calendar.", "queryExpr", queryExpr)
+		return predicted, bounds
+	}
+	return overridden, overriddenBounds
+}
+
+// queryCalendarHistory returns the actual time series with the labels in [start, end]
+func (p *periodicSignalPrediction) queryCalendarHistory(namer metricnaming.MetricNamer, labels []common.Label, start, end time.Time) (*common.TimeSeries, error) {
+	queryExpr := namer.BuildUniqueKey()
+	key := fmt.Sprintf("%d-%d", start.Unix(), end.Unix())
+	tsList, ok := p.calendars.getHistory(queryExpr, key)
+	if !ok {
+		if p.GetHistoryProvider() == nil {
+			return nil, fmt.Errorf("history provider not provisioned")
+		}
+		var err error
+		tsList, err = p.GetHistoryProvider().QueryTimeSeries(namer, start, end, p.a.GetConfig(queryExpr).historyResolution)
+		if err != nil {
+			return nil, err
+		}
+		p.calendars.setHistory(queryExpr, key, tsList)
+	}
+
+	// the history is joined with the prediction directly if there is only one time series
+	if len(tsList) == 1 {
+		return tsList[0], nil
+	}
+	signalKey := prediction.AggregateSignalKey(labels)
+	for _, ts := range tsList {
+		if prediction.AggregateSignalKey(ts.Labels) == signalKey {
+			return ts, nil
+		}
+	}
+	return nil, nil
+}
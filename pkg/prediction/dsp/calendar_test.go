@@ -0,0 +1,105 @@
+package dsp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/metricquery"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/calendar"
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/providers/csv"
+)
+
+func TestCalendar(t *testing.T) {
+	namer := &metricnaming.GeneralMetricNamer{
+		Metric: &metricquery.Metric{
+			Type: metricquery.PromQLMetricType,
+			Prom: &metricquery.PromNamerInfo{
+				QueryExpr: "cpu",
+				Selector:  labels.Nothing(),
+			},
+		}}
+	queryExpr := namer.BuildUniqueKey()
+
+	// the prediction is 10 from 23:00 to 01:00, with the prediction interval [8, 12]
+	start := time.Date(2022, 11, 10, 23, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	var predicted []common.Sample
+	for ts := start; ts.Before(end); ts = ts.Add(time.Minute) {
+		predicted = append(predicted, common.Sample{Timestamp: ts.Unix(), Value: 10})
+	}
+	signal := newAggregateSignal()
+	signal.setPredictedTimeSeries(&common.TimeSeries{Samples: predicted})
+	signal.setBounds(lowerBoundTimeSeries(signal.predictedTimeSeries, []float64{-2}, 0), upperBoundTimeSeries(signal.predictedTimeSeries, []float64{2}, 0))
+
+	// the actual value is 40 on the same day last year
+	var buf bytes.Buffer
+	buf.WriteString("ts,value\n")
+	historyStart := time.Date(2021, 11, 11, 0, 0, 0, 0, time.UTC)
+	for ts := historyStart; ts.Before(historyStart.Add(time.Hour)); ts = ts.Add(time.Minute) {
+		buf.WriteString(fmt.Sprintf("%d,40\n", ts.Unix()))
+	}
+	history, err := csv.NewProvider(&buf)
+	assert.NoError(t, err)
+
+	p := NewPrediction(nil, history, config.AlgorithmModelConfig{}, nil).(*periodicSignalPrediction)
+	p.a.Add(prediction.QueryExprWithCaller{MetricNamer: namer, Caller: "test"})
+	p.a.SetSignals(queryExpr, map[string]*aggregateSignal{"": signal})
+
+	c, err := calendar.Parse([]byte(`
+periods:
+- name: warm-up
+  start: "2022-11-10T23:30:00Z"
+  end: "2022-11-11T00:00:00Z"
+  multiplier: 1.5
+- name: sales
+  start: "2022-11-11T00:00:00Z"
+  end: "2022-11-11T01:00:00Z"
+  historyFrom: "2021-11-11T00:00:00Z"
+`))
+	assert.NoError(t, err)
+	p.WithCalendar(namer, c)
+
+	tsList, err := p.QueryPredictedTimeSeries(context.TODO(), namer, start, end)
+	assert.NoError(t, err)
+	lower, upper, err := p.QueryPredictedIntervals(context.TODO(), namer, start, end)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		time      time.Time
+		predicted float64
+		lower     float64
+		upper     float64
+	}{
+		{time: start, predicted: 10, lower: 8, upper: 12},
+		{time: start.Add(45 * time.Minute), predicted: 15, lower: 12, upper: 18},
+		{time: start.Add(90 * time.Minute), predicted: 40, lower: 38, upper: 42},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.predicted, valueOf(t, tsList, tt.time), tt.time)
+		assert.Equal(t, tt.lower, valueOf(t, lower, tt.time), tt.time)
+		assert.Equal(t, tt.upper, valueOf(t, upper, tt.time), tt.time)
+	}
+
+	// the predictions are not overridden once the calendar is removed
+	p.WithCalendar(namer, nil)
+	tsList, err = p.QueryPredictedTimeSeries(context.TODO(), namer, start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, valueOf(t, tsList, start.Add(90*time.Minute)))
+}
+
+func valueOf(t *testing.T, tsList []*common.TimeSeries, at time.Time) float64 {
+	assert.Len(t, tsList, 1)
+	value, ok := valueAt(tsList[0], at.Unix())
+	assert.True(t, ok)
+	return value
+}
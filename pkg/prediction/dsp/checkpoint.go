@@ -0,0 +1,115 @@
+package dsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
+)
+
+// checkpointVersion is the version of the checkpoint data, bump it if the format is changed incompatibly
+const checkpointVersion = "v2"
+
+var checkpointAlgorithm = string(v1alpha1.AlgorithmTypeDSP)
+
+// checkpointSignal is the checkpoint data of an aggregate signal
+type checkpointSignal struct {
+	Predicted *common.TimeSeries `json:"predicted"`
+	Lower     *common.TimeSeries `json:"lower,omitempty"`
+	Upper     *common.TimeSeries `json:"upper,omitempty"`
+	// AnomalyLower and AnomalyUpper are the bounds of the interval to detect anomalies
+	AnomalyLower *common.TimeSeries `json:"anomalyLower,omitempty"`
+	AnomalyUpper *common.TimeSeries `json:"anomalyUpper,omitempty"`
+}
+
+// saveCheckpoint saves the predicted time series of the query and the bounds of their prediction intervals, it is
+// done after each update of the model
+func (p *periodicSignalPrediction) saveCheckpoint(queryExpr string) error {
+	if p.checkpointStore == nil {
+		return nil
+	}
+
+	signals, _ := p.a.GetSignals(queryExpr)
+	predicted := map[string]*checkpointSignal{}
+	for key, signal := range signals {
+		if signal.predictedTimeSeries != nil {
+			predicted[key] = &checkpointSignal{
+				Predicted:    signal.predictedTimeSeries,
+				Lower:        signal.lowerTimeSeries,
+				Upper:        signal.upperTimeSeries,
+				AnomalyLower: signal.anomalyLowerTimeSeries,
+				AnomalyUpper: signal.anomalyUpperTimeSeries,
+			}
+		}
+	}
+	data, err := json.Marshal(predicted)
+	if err != nil {
+		return err
+	}
+
+	return p.checkpointStore.Save(&checkpoint.Checkpoint{
+		Algorithm:   checkpointAlgorithm,
+		QueryExpr:   queryExpr,
+		Version:     checkpointVersion,
+		Fingerprint: p.a.GetConfig(queryExpr).String(),
+		Time:        time.Now(),
+		Data:        data,
+	})
+}
+
+// initByCheckpoint restores the predicted time series from the checkpoint, the checkpoint older than the update
+// interval is discarded and the model is updated from history.
+func (p *periodicSignalPrediction) initByCheckpoint(namer metricnaming.MetricNamer) error {
+	if p.checkpointStore == nil {
+		return fmt.Errorf("checkpoint store not configured")
+	}
+
+	queryExpr := namer.BuildUniqueKey()
+	c, err := p.checkpointStore.Load(checkpointAlgorithm, queryExpr)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return fmt.Errorf("checkpoint not found")
+	}
+	if err = c.Validate(checkpointVersion, p.a.GetConfig(queryExpr).String(), p.modelConfig.UpdateInterval); err != nil {
+		return err
+	}
+
+	var predicted map[string]*checkpointSignal
+	if err = json.Unmarshal(c.Data, &predicted); err != nil {
+		return err
+	}
+
+	signals := map[string]*aggregateSignal{}
+	for key, cs := range predicted {
+		if cs.Predicted == nil {
+			continue
+		}
+		signal := newAggregateSignal()
+		signal.setPredictedTimeSeries(cs.Predicted)
+		signal.setBounds(cs.Lower, cs.Upper)
+		signal.setAnomalyBounds(cs.AnomalyLower, cs.AnomalyUpper)
+		signals[key] = signal
+	}
+	p.a.SetSignals(queryExpr, signals)
+
+	klog.V(4).InfoS("Restored dsp model from checkpoint.", "queryExpr", queryExpr, "checkpointTime", c.Time)
+	return nil
+}
+
+func (p *periodicSignalPrediction) deleteCheckpoint(queryExpr string) {
+	if p.checkpointStore == nil {
+		return
+	}
+	if err := p.checkpointStore.Delete(checkpointAlgorithm, queryExpr); err != nil {
+		klog.ErrorS(err, "Failed to delete dsp checkpoint.", "queryExpr", queryExpr)
+	}
+}
@@ -24,6 +24,10 @@ var defaultEstimators = []Estimator{
 	&fftEstimator{minNumOfSpectrumItems: 50, lowAmplitudeThreshold: 0.05, marginFraction: 0.10},
 	&fftEstimator{minNumOfSpectrumItems: 50, lowAmplitudeThreshold: 0.05, marginFraction: 0.15},
 	&fftEstimator{minNumOfSpectrumItems: 50, lowAmplitudeThreshold: 0.05, marginFraction: 0.20},
+	&holtWintersEstimator{marginFraction: 0.01},
+	&holtWintersEstimator{marginFraction: 0.10},
+	&holtWintersEstimator{marginFraction: 0.15},
+	&holtWintersEstimator{marginFraction: 0.20},
 }
 
 type internalConfig struct {
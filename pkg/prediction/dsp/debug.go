@@ -6,7 +6,6 @@ import (
 
 	"k8s.io/klog/v2"
 
-	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/prediction"
 	"github.com/gocrane/crane/pkg/prediction/config"
@@ -29,12 +28,12 @@ func Debug(predictor prediction.Interface, namer metricnaming.MetricNamer, confi
 	var nPeriods int
 	var chosenEstimator Estimator
 	for _, ts := range historyTimeSeriesList {
-		periodLength := findPeriod(ts, internalConfig.historyResolution)
+		periodLength := findPeriod(ts.TimeSeries, internalConfig.historyResolution)
 		if periodLength == Day || periodLength == Week {
 			signal = SamplesToSignal(ts.Samples, internalConfig.historyResolution)
 			signal, nPeriods = signal.Truncate(periodLength)
 			if nPeriods >= 2 {
-				chosenEstimator = bestEstimator(queryExpr, internalConfig.estimators, signal, nPeriods, periodLength)
+				chosenEstimator, _ = bestEstimator(queryExpr, internalConfig.estimators, signal, nPeriods, periodLength)
 			}
 			if chosenEstimator != nil {
 				samplesPerPeriod := len(signal.Samples) / nPeriods
@@ -55,7 +54,7 @@ func Debug(predictor prediction.Interface, namer metricnaming.MetricNamer, confi
 	return nil, nil, nil, fmt.Errorf("no prediction result")
 }
 
-func queryHistoryTimeSeries(predictor *periodicSignalPrediction, namer metricnaming.MetricNamer, config *internalConfig) ([]*common.TimeSeries, error) {
+func queryHistoryTimeSeries(predictor *periodicSignalPrediction, namer metricnaming.MetricNamer, config *internalConfig) ([]*historyTimeSeries, error) {
 	p := predictor.GetHistoryProvider()
 	if p == nil {
 		return nil, fmt.Errorf("history provider not provisioned")
@@ -17,6 +17,8 @@ const (
 	defaultFFTMarginFraction      = 0.0
 	defaultMaxValueMarginFraction = 0.0
 	defaultFFTMinValue            = 0.01
+	defaultHoltWintersAlpha       = 0.1
+	defaultHoltWintersGamma       = 0.3
 )
 
 type Estimator interface {
@@ -38,6 +40,17 @@ func NewFFTEstimator(minNumOfSpectrumItems, maxNumOfSpectrumItems int, highFrequ
 	}
 }
 
+// NewHoltWintersEstimator returns an estimator of additive Holt-Winters without the trend term, alpha and gamma are the
+// smoothing factors of the level and seasonality. The linear trend is removed from the history in preprocessing and
+// added back to the forecast, so the estimator does not model it again.
+func NewHoltWintersEstimator(alpha, gamma, marginFraction float64) Estimator {
+	return &holtWintersEstimator{
+		alpha:          alpha,
+		gamma:          gamma,
+		marginFraction: marginFraction,
+	}
+}
+
 type maxValueEstimator struct {
 	marginFraction float64
 }
@@ -50,6 +63,12 @@ type fftEstimator struct {
 	marginFraction         float64
 }
 
+type holtWintersEstimator struct {
+	alpha          float64
+	gamma          float64
+	marginFraction float64
+}
+
 func (m *maxValueEstimator) GetEstimation(signal *Signal, periodLength time.Duration) *Signal {
 	nSamplesPerPeriod := int(periodLength.Seconds() * signal.SampleRate)
 	estimation := make([]float64, 0, nSamplesPerPeriod)
@@ -185,3 +204,65 @@ func (f *fftEstimator) String() string {
 	return fmt.Sprintf("FFT Estimator {minNumOfSpectrumItems: %d, maxNumOfSpectrumItems: %d, highFrequencyThreshold: %f, lowAmplitudeThreshold: %f, marginFraction: %f}",
 		minNumOfSpectrumItems, maxNumOfSpectrumItems, highFrequencyThreshold, lowAmplitudeThreshold, marginFraction)
 }
+
+func (h *holtWintersEstimator) parameters() (float64, float64) {
+	alpha, gamma := h.alpha, h.gamma
+	if alpha == 0.0 {
+		alpha = defaultHoltWintersAlpha
+	}
+	if gamma == 0.0 {
+		gamma = defaultHoltWintersGamma
+	}
+	return alpha, gamma
+}
+
+// GetEstimation smooths the level and seasonality of the signal, and forecasts the next period with them.
+// It needs at least two periods of the signal, one to initialize the seasonality and one to smooth, nil is returned
+// if not.
+func (h *holtWintersEstimator) GetEstimation(signal *Signal, periodLength time.Duration) *Signal {
+	alpha, gamma := h.parameters()
+
+	x := signal.Samples
+	nSamples := len(x)
+	nSamplesPerPeriod := int(periodLength.Seconds() * signal.SampleRate)
+	if nSamplesPerPeriod <= 0 || nSamples < 2*nSamplesPerPeriod {
+		return nil
+	}
+
+	// Initialize the level by the mean of the first period, and the seasonality by the deviations from it
+	var level float64
+	for i := 0; i < nSamplesPerPeriod; i++ {
+		level += x[i]
+	}
+	level /= float64(nSamplesPerPeriod)
+
+	seasonal := make([]float64, nSamplesPerPeriod)
+	for i := range seasonal {
+		seasonal[i] = x[i] - level
+	}
+
+	for i := nSamplesPerPeriod; i < nSamples; i++ {
+		s := seasonal[i%nSamplesPerPeriod]
+		level = alpha*(x[i]-s) + (1-alpha)*level
+		seasonal[i%nSamplesPerPeriod] = gamma*(x[i]-level) + (1-gamma)*s
+	}
+
+	samples := make([]float64, nSamplesPerPeriod)
+	for i := range samples {
+		a := level + seasonal[(nSamples+i)%nSamplesPerPeriod]
+		if a <= 0.0 {
+			a = defaultFFTMinValue
+		}
+		samples[i] = a * (1.0 + h.marginFraction)
+	}
+
+	return &Signal{
+		SampleRate: signal.SampleRate,
+		Samples:    samples,
+	}
+}
+
+func (h *holtWintersEstimator) String() string {
+	alpha, gamma := h.parameters()
+	return fmt.Sprintf("Holt-Winters Estimator {alpha: %f, gamma: %f, marginFraction: %f}", alpha, gamma, h.marginFraction)
+}
@@ -2,14 +2,19 @@ package dsp
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/components"
 	"github.com/go-echarts/go-echarts/v2/opts"
 	"github.com/go-echarts/go-echarts/v2/types"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction/accuracy"
 )
 
 // Run this test to see the actual time series (black) and two forecasting time series
@@ -71,3 +76,46 @@ func TestFftEstimator_GetEstimation(t *testing.T) {
 	fmt.Println("Open your browser and access 'http://localhost:7001'")
 	//http.ListenAndServe(":7001", nil)
 }
+
+func TestHoltWintersEstimator_GetEstimation(t *testing.T) {
+	// a daily periodic time series growing 0.5 a day, the last day is held out
+	end := time.Now().Truncate(time.Minute).Unix()
+	n := 1440 * 8
+	values := make([]float64, n+1440)
+	history := &common.TimeSeries{Samples: make([]common.Sample, n)}
+	for i := range values {
+		values[i] = 10 + 3*math.Sin(2*math.Pi*float64(i)/1440) + 0.5*float64(i)/1440
+		if i < n {
+			history.Samples[i] = common.Sample{Timestamp: end - int64(n-1-i)*60, Value: values[i]}
+		}
+	}
+	actual := values[n:]
+
+	// the estimator is fed the detrended history as in prediction
+	trend, err := preProcessTimeSeries(history, &defaultInternalConfig, time.Minute)
+	assert.NoError(t, err)
+	assert.Greater(t, trend.slope, 0.0)
+
+	hw := NewHoltWintersEstimator(0, 0, 0)
+	signal, nPeriods := SamplesToSignal(history.Samples, time.Minute).Truncate(Day)
+	estimator, residuals := bestEstimator("test", []Estimator{hw}, signal, nPeriods, Day)
+	assert.Equal(t, hw, estimator)
+	for _, residual := range residuals {
+		assert.InDelta(t, 0, residual, 0.1)
+	}
+
+	config := defaultInternalConfig
+	config.estimators = []Estimator{hw}
+	predicted, _ := estimateTimeSeries("test", &historyTimeSeries{TimeSeries: history, trend: trend}, &config)
+	assert.NotNil(t, predicted)
+	estimated := make([]float64, len(actual))
+	for i := range estimated {
+		estimated[i] = predicted.Samples[i].Value
+	}
+	pe, err := accuracy.PredictionError(actual, estimated)
+	assert.NoError(t, err)
+	assert.Less(t, pe, 0.01)
+
+	// two periods are needed at least
+	assert.Nil(t, hw.GetEstimation(&Signal{SampleRate: 1.0 / 60, Samples: values[:1440]}, Day))
+}
@@ -0,0 +1,40 @@
+package dsp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+// Forecast forecasts the samples in [start, end) from the history samples with the dsp predictor.
+func Forecast(history []common.Sample, conf *v1alpha1.DSP, start, end time.Time) ([]common.Sample, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("dsp config is required")
+	}
+	config, err := makeInternalConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := &common.TimeSeries{Samples: append([]common.Sample{}, history...)}
+	trend, err := preProcessTimeSeries(ts, config, Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	predicted, _ := estimateTimeSeries("forecast", &historyTimeSeries{TimeSeries: ts, trend: trend}, config)
+	if predicted == nil {
+		return nil, fmt.Errorf("history is not periodic")
+	}
+
+	var samples []common.Sample
+	for _, sample := range predicted.Samples {
+		if sample.Timestamp >= start.Unix() && sample.Timestamp < end.Unix() {
+			samples = append(samples, sample)
+		}
+	}
+	return samples, nil
+}
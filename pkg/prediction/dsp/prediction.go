@@ -14,6 +14,7 @@ import (
 	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/prediction"
 	"github.com/gocrane/crane/pkg/prediction/accuracy"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
 	"github.com/gocrane/crane/pkg/prediction/config"
 	"github.com/gocrane/crane/pkg/providers"
 )
@@ -35,6 +36,12 @@ type periodicSignalPrediction struct {
 	// record the query routine already started
 	queryRoutines sync.Map
 	modelConfig   config.AlgorithmModelConfig
+	// checkpointStore saves the model after each update, checkpointing is disabled if it is nil
+	checkpointStore checkpoint.Store
+	// anomalies keeps the anomalies of the live values against the forecast
+	anomalies *anomalyStore
+	// calendars keeps the calendars overriding the predictions in the special periods
+	calendars *calendarStore
 }
 
 func (p *periodicSignalPrediction) QueryPredictionStatus(ctx context.Context, metricNamer metricnaming.MetricNamer) (prediction.Status, error) {
@@ -42,7 +49,7 @@ func (p *periodicSignalPrediction) QueryPredictionStatus(ctx context.Context, me
 	return status, nil
 }
 
-func NewPrediction(realtimeProvider providers.RealTime, historyProvider providers.History, mc config.AlgorithmModelConfig) prediction.Interface {
+func NewPrediction(realtimeProvider providers.RealTime, historyProvider providers.History, mc config.AlgorithmModelConfig, store checkpoint.Store) prediction.Interface {
 	withCh, delCh := make(chan prediction.QueryExprWithCaller), make(chan prediction.QueryExprWithCaller)
 	return &periodicSignalPrediction{
 		GenericPrediction: prediction.NewGenericPrediction(realtimeProvider, historyProvider, withCh, delCh),
@@ -50,6 +57,9 @@ func NewPrediction(realtimeProvider providers.RealTime, historyProvider provider
 		stopChMap:         sync.Map{},
 		queryRoutines:     sync.Map{},
 		modelConfig:       mc,
+		checkpointStore:   store,
+		anomalies:         newAnomalyStore(),
+		calendars:         newCalendarStore(),
 	}
 }
 
@@ -109,21 +119,59 @@ func (p *periodicSignalPrediction) Run(stopCh <-chan struct{}) {
 				ticker := time.NewTicker(p.modelConfig.UpdateInterval)
 				defer ticker.Stop()
 
+				// the live values are checked against the forecast periodically, the channel blocks forever if the check is disabled
+				var anomalyCh <-chan time.Time
+				if p.modelConfig.AnomalyCheckInterval > 0 {
+					anomalyTicker := time.NewTicker(p.modelConfig.AnomalyCheckInterval)
+					defer anomalyTicker.Stop()
+					anomalyCh = anomalyTicker.C
+				}
+
 				v, _ := p.stopChMap.LoadOrStore(queryExpr, make(chan struct{}))
 				predStopCh := v.(chan struct{})
 
+				// the model restored from checkpoint is updated in the next interval
+				restored := false
+				if err := p.initByCheckpoint(namer); err != nil {
+					klog.V(4).InfoS("Failed to restore dsp model from checkpoint.", "queryExpr", queryExpr, "reason", err)
+				} else {
+					restored = true
+				}
+
+				// pending is the anomalies which trigger the retraining, they are recorded once the model is retrained
+				var pending []prediction.Anomaly
 				for {
-					if err := p.updateAggregateSignalsWithQuery(namer); err != nil {
-						klog.ErrorS(err, "Failed to updateAggregateSignalsWithQuery.")
+					if !restored {
+						err := p.updateAggregateSignalsWithQuery(namer)
+						if err != nil {
+							klog.ErrorS(err, "Failed to updateAggregateSignalsWithQuery.")
+						}
+						p.anomalies.reset(queryExpr)
+						p.anomalies.record(queryExpr, pending, err == nil)
+						pending = nil
 					}
-
-					select {
-					case <-predStopCh:
-						p.queryRoutines.Delete(queryExpr)
-						klog.V(4).InfoS("Prediction routine stopped.", "queryExpr", queryExpr)
-						return
-					case <-ticker.C:
-						continue
+					restored = false
+
+					update := false
+					for !update {
+						select {
+						case <-predStopCh:
+							p.queryRoutines.Delete(queryExpr)
+							klog.V(4).InfoS("Prediction routine stopped.", "queryExpr", queryExpr)
+							return
+						case <-ticker.C:
+							update = true
+						case <-anomalyCh:
+							anomalies, retrain := p.detectAnomalies(namer)
+							if !retrain {
+								p.anomalies.record(queryExpr, anomalies, false)
+								continue
+							}
+							klog.V(4).InfoS("Retrain the model on anomalies.", "queryExpr", queryExpr, "anomalies", len(anomalies))
+							pending = anomalies
+							update = true
+							ticker.Reset(p.modelConfig.UpdateInterval)
+						}
 					}
 				}
 			}(qc.MetricNamer)
@@ -143,6 +191,9 @@ func (p *periodicSignalPrediction) Run(stopCh <-chan struct{}) {
 						predStopCh := val.(chan struct{})
 						predStopCh <- struct{}{}
 					}
+					p.deleteCheckpoint(QueryExpr)
+					p.anomalies.delete(QueryExpr)
+					p.calendars.set(QueryExpr, nil)
 				}
 			}(qc)
 		}
@@ -159,7 +210,7 @@ func (p *periodicSignalPrediction) updateAggregateSignalsWithQuery(namer metricn
 	// Query history data for prediction
 	maxAttempts := 10
 	attempts := 0
-	var tsList []*common.TimeSeries
+	var tsList []*historyTimeSeries
 	var err error
 	queryExpr := namer.BuildUniqueKey()
 	for attempts < maxAttempts {
@@ -184,10 +235,14 @@ func (p *periodicSignalPrediction) updateAggregateSignalsWithQuery(namer metricn
 
 	p.updateAggregateSignals(queryExpr, tsList, cfg)
 
+	if err = p.saveCheckpoint(queryExpr); err != nil {
+		klog.ErrorS(err, "Failed to save dsp checkpoint.", "queryExpr", queryExpr)
+	}
+
 	return nil
 }
 
-func (p *periodicSignalPrediction) queryHistoryTimeSeries(namer metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
+func (p *periodicSignalPrediction) queryHistoryTimeSeries(namer metricnaming.MetricNamer) ([]*historyTimeSeries, error) {
 	if p.GetHistoryProvider() == nil {
 		return nil, fmt.Errorf("history provider not provisioned")
 	}
@@ -209,70 +264,105 @@ func (p *periodicSignalPrediction) queryHistoryTimeSeries(namer metricnaming.Met
 	return preProcessTimeSeriesList(tsList, config)
 }
 
-func (p *periodicSignalPrediction) updateAggregateSignals(queryExpr string, historyTimeSeriesList []*common.TimeSeries, config *internalConfig) {
-	var predictedTimeSeriesList []*common.TimeSeries
-
+func (p *periodicSignalPrediction) updateAggregateSignals(queryExpr string, historyTimeSeriesList []*historyTimeSeries, config *internalConfig) {
+	signals := map[string]*aggregateSignal{}
 	for _, ts := range historyTimeSeriesList {
-		if klog.V(6).Enabled() {
-			sampleData, err := json.Marshal(ts.Samples)
-			klog.V(6).Infof("Got time series, queryExpr: %s, samples: %v, labels: %v, err: %v", queryExpr, string(sampleData), ts.Labels, err)
-		}
-		var chosenEstimator Estimator
-		var signal *Signal
-		var nPeriods int
-		var periodLength time.Duration = 0
-
-		p := findPeriod(ts, config.historyResolution)
-		if p == Day || p == Week {
-			periodLength = p
-			klog.V(4).InfoS("This is a periodic time series.", "queryExpr", queryExpr, "labels", ts.Labels, "periodLength", periodLength)
-		} else {
-			klog.V(4).InfoS("This is not a periodic time series.", "queryExpr", queryExpr, "labels", ts.Labels)
+		predicted, residuals := estimateTimeSeries(queryExpr, ts, config)
+		if predicted == nil {
+			continue
 		}
+		signal := newAggregateSignal()
+		signal.setPredictedTimeSeries(predicted)
+		signal.setBounds(lowerBoundTimeSeries(predicted, residuals, p.modelConfig.LowerQuantile), upperBoundTimeSeries(predicted, residuals, p.modelConfig.UpperQuantile))
+		lowerQuantile, upperQuantile := p.anomalyQuantiles()
+		signal.setAnomalyBounds(lowerBoundTimeSeries(predicted, residuals, lowerQuantile), upperBoundTimeSeries(predicted, residuals, upperQuantile))
+		signals[prediction.AggregateSignalKey(predicted.Labels)] = signal
+	}
+	p.a.SetSignals(queryExpr, signals)
+}
 
-		if periodLength > 0 {
-			signal = SamplesToSignal(ts.Samples, config.historyResolution)
-			signal, nPeriods = signal.Truncate(periodLength)
-			if nPeriods >= 2 {
-				chosenEstimator = bestEstimator(queryExpr, config.estimators, signal, nPeriods, periodLength)
-			}
-		}
+// lowerBoundTimeSeries returns the lower bound of the prediction interval by shifting the predicted time series with
+// the q quantile of the residuals of the estimator. It is never above the predicted time series.
+func lowerBoundTimeSeries(predicted *common.TimeSeries, residuals []float64, q float64) *common.TimeSeries {
+	return shiftTimeSeries(predicted, math.Min(accuracy.Quantile(residuals, q), 0))
+}
 
-		if chosenEstimator != nil {
-			estimatedSignal := chosenEstimator.GetEstimation(signal, periodLength)
-			intervalSeconds := int64(config.historyResolution.Seconds())
-			nextTimestamp := ts.Samples[len(ts.Samples)-1].Timestamp + intervalSeconds
-
-			n := len(estimatedSignal.Samples)
-			samples := make([]common.Sample, n*nPeriods)
-			for k := 0; k < nPeriods; k++ {
-				for i := range estimatedSignal.Samples {
-					samples[i+k*n] = common.Sample{
-						Value:     estimatedSignal.Samples[i],
-						Timestamp: nextTimestamp,
-					}
-					nextTimestamp += intervalSeconds
-				}
-			}
+// upperBoundTimeSeries returns the upper bound of the prediction interval by shifting the predicted time series with
+// the q quantile of the residuals of the estimator. It is never below the predicted time series.
+func upperBoundTimeSeries(predicted *common.TimeSeries, residuals []float64, q float64) *common.TimeSeries {
+	return shiftTimeSeries(predicted, math.Max(accuracy.Quantile(residuals, q), 0))
+}
+
+func shiftTimeSeries(predicted *common.TimeSeries, offset float64) *common.TimeSeries {
+	samples := make([]common.Sample, len(predicted.Samples))
+	for i, sample := range predicted.Samples {
+		samples[i] = common.Sample{Value: math.Max(sample.Value+offset, 0), Timestamp: sample.Timestamp}
+	}
+	return &common.TimeSeries{
+		Labels:  predicted.Labels,
+		Samples: samples,
+	}
+}
+
+// estimateTimeSeries estimates the time series following the history with the best estimator, it returns nil if the
+// history is not periodic. The residuals of the estimator on the last period of the history are returned as well.
+func estimateTimeSeries(queryExpr string, ts *historyTimeSeries, config *internalConfig) (*common.TimeSeries, []float64) {
+	if klog.V(6).Enabled() {
+		sampleData, err := json.Marshal(ts.Samples)
+		klog.V(6).Infof("Got time series, queryExpr: %s, samples: %v, labels: %v, err: %v", queryExpr, string(sampleData), ts.Labels, err)
+	}
+	var chosenEstimator Estimator
+	var residuals []float64
+	var signal *Signal
+	var nPeriods int
+	var periodLength time.Duration = 0
+
+	p := findPeriod(ts.TimeSeries, config.historyResolution)
+	if p == Day || p == Week {
+		periodLength = p
+		klog.V(4).InfoS("This is a periodic time series.", "queryExpr", queryExpr, "labels", ts.Labels, "periodLength", periodLength)
+	} else {
+		klog.V(4).InfoS("This is not a periodic time series.", "queryExpr", queryExpr, "labels", ts.Labels)
+	}
 
-			predictedTimeSeriesList = append(predictedTimeSeriesList, &common.TimeSeries{
-				Labels:  ts.Labels,
-				Samples: samples,
-			})
+	if periodLength > 0 {
+		signal = SamplesToSignal(ts.Samples, config.historyResolution)
+		signal, nPeriods = signal.Truncate(periodLength)
+		if nPeriods >= 2 {
+			chosenEstimator, residuals = bestEstimator(queryExpr, config.estimators, signal, nPeriods, periodLength)
 		}
 	}
 
-	signals := map[string]*aggregateSignal{}
-	for i := range predictedTimeSeriesList {
-		key := prediction.AggregateSignalKey(predictedTimeSeriesList[i].Labels)
-		signal := newAggregateSignal()
-		signal.setPredictedTimeSeries(predictedTimeSeriesList[i])
-		signals[key] = signal
+	if chosenEstimator == nil {
+		return nil, nil
 	}
-	p.a.SetSignals(queryExpr, signals)
+
+	estimatedSignal := chosenEstimator.GetEstimation(signal, periodLength)
+	intervalSeconds := int64(config.historyResolution.Seconds())
+	nextTimestamp := ts.Samples[len(ts.Samples)-1].Timestamp + intervalSeconds
+
+	n := len(estimatedSignal.Samples)
+	samples := make([]common.Sample, n*nPeriods)
+	for k := 0; k < nPeriods; k++ {
+		for i := range estimatedSignal.Samples {
+			// add the trend removed in preprocessing back
+			samples[i+k*n] = common.Sample{
+				Value:     math.Max(estimatedSignal.Samples[i]+ts.trend.valueAt(nextTimestamp), 0),
+				Timestamp: nextTimestamp,
+			}
+			nextTimestamp += intervalSeconds
+		}
+	}
+
+	return &common.TimeSeries{
+		Labels:  ts.Labels,
+		Samples: samples,
+	}, residuals
 }
 
-func bestEstimator(id string, estimators []Estimator, signal *Signal, nPeriods int, periodLength time.Duration) Estimator {
+// bestEstimator returns the estimator with the least prediction error of the last period which is held out from the
+// history, and the residuals of its estimation of the last period.
+func bestEstimator(id string, estimators []Estimator, signal *Signal, nPeriods int, periodLength time.Duration) (Estimator, []float64) {
 	samplesPerPeriod := len(signal.Samples) / nPeriods
 
 	history := &Signal{
@@ -287,6 +377,7 @@ func bestEstimator(id string, estimators []Estimator, signal *Signal, nPeriods i
 
 	minPE := math.MaxFloat64
 	var bestEstimator Estimator
+	var bestResiduals []float64
 	for i := range estimators {
 		estimated := estimators[i].GetEstimation(history, periodLength)
 		if estimated != nil {
@@ -295,12 +386,13 @@ func bestEstimator(id string, estimators []Estimator, signal *Signal, nPeriods i
 			if err == nil && pe < minPE {
 				minPE = pe
 				bestEstimator = estimators[i]
+				bestResiduals, _ = accuracy.Residuals(actual.Samples, estimated.Samples)
 			}
 		}
 	}
 
 	klog.V(4).InfoS("Got the best estimator.", "key", id, "estimator", bestEstimator.String(), "minPE", minPE, "periods", nPeriods)
-	return bestEstimator
+	return bestEstimator, bestResiduals
 }
 
 func (p *periodicSignalPrediction) QueryPredictedTimeSeries(ctx context.Context, namer metricnaming.MetricNamer, startTime time.Time, endTime time.Time) ([]*common.TimeSeries, error) {
@@ -337,8 +429,45 @@ func (p *periodicSignalPrediction) QueryRealtimePredictedValues(ctx context.Cont
 	return realtimePredictedTimeSeries, nil
 }
 
+// QueryPredictedIntervals returns the bounds of the prediction interval, which are the predicted time series shifted
+// by the quantiles of the residuals of the chosen estimator, and moved along with it in the periods of the calendar.
+func (p *periodicSignalPrediction) QueryPredictedIntervals(ctx context.Context, namer metricnaming.MetricNamer, startTime time.Time, endTime time.Time) ([]*common.TimeSeries, []*common.TimeSeries, error) {
+	var lower, upper []*common.TimeSeries
+	for _, signal := range p.waitSignals(ctx, namer) {
+		if signal.lowerTimeSeries == nil || signal.upperTimeSeries == nil {
+			continue
+		}
+		predicted := timeSeriesInRange(signal.predictedTimeSeries, startTime, endTime)
+		lowerTs := timeSeriesInRange(signal.lowerTimeSeries, startTime, endTime)
+		upperTs := timeSeriesInRange(signal.upperTimeSeries, startTime, endTime)
+		if predicted == nil || lowerTs == nil || upperTs == nil {
+			continue
+		}
+		_, bounds := p.applyCalendar(namer, startTime, endTime, predicted, lowerTs, upperTs)
+		lower = append(lower, bounds[0])
+		upper = append(upper, bounds[1])
+	}
+	return lower, upper, nil
+}
+
 func (p *periodicSignalPrediction) getPredictedTimeSeriesList(ctx context.Context, namer metricnaming.MetricNamer, start, end time.Time) []*common.TimeSeries {
 	var predictedTimeSeriesList []*common.TimeSeries
+	queryExpr := namer.BuildUniqueKey()
+	for key, signal := range p.waitSignals(ctx, namer) {
+		n := 0
+		if ts := timeSeriesInRange(signal.predictedTimeSeries, start, end); ts != nil {
+			ts, _ = p.applyCalendar(namer, start, end, ts)
+			predictedTimeSeriesList = append(predictedTimeSeriesList, ts)
+			n = len(ts.Samples)
+		}
+		klog.InfoS("Got DSP predicted samples.", "queryExpr", queryExpr, "labels", key, "len", n)
+	}
+	return predictedTimeSeriesList
+}
+
+// waitSignals waits until the aggregate signals of the query are ready, it returns nil if the query is deleted or
+// the context is done.
+func (p *periodicSignalPrediction) waitSignals(ctx context.Context, namer metricnaming.MetricNamer) map[string]*aggregateSignal {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -347,42 +476,44 @@ func (p *periodicSignalPrediction) getPredictedTimeSeriesList(ctx context.Contex
 		signals, status := p.a.GetSignals(queryExpr)
 		if status == prediction.StatusDeleted {
 			klog.V(4).InfoS("Aggregated has been deleted.", "queryExpr", queryExpr)
-			return predictedTimeSeriesList
+			return nil
 		}
 		if signals != nil && status == prediction.StatusReady {
-			for key, signal := range signals {
-				var samples []common.Sample
-				for _, sample := range signal.predictedTimeSeries.Samples {
-					t := time.Unix(sample.Timestamp, 0)
-					// Check if t is in [startTime, endTime]
-					if !t.Before(start) && !t.After(end) {
-						samples = append(samples, sample)
-					} else if t.After(end) {
-						break
-					}
-				}
-
-				if len(samples) > 0 {
-					predictedTimeSeriesList = append(predictedTimeSeriesList, &common.TimeSeries{
-						Labels:  signal.predictedTimeSeries.Labels,
-						Samples: samples,
-					})
-				}
-
-				klog.InfoS("Got DSP predicted samples.", "queryExpr", queryExpr, "labels", key, "len", len(samples))
-			}
-			return predictedTimeSeriesList
+			return signals
 		}
 		select {
 		case <-ctx.Done():
 			klog.Infoln("Time out.")
-			return predictedTimeSeriesList
+			return nil
 		case <-ticker.C:
 			continue
 		}
 	}
 }
 
+// timeSeriesInRange returns the samples of the time series in [start, end], it returns nil if there is no sample in
+// the range.
+func timeSeriesInRange(ts *common.TimeSeries, start, end time.Time) *common.TimeSeries {
+	var samples []common.Sample
+	for _, sample := range ts.Samples {
+		t := time.Unix(sample.Timestamp, 0)
+		// Check if t is in [startTime, endTime]
+		if !t.Before(start) && !t.After(end) {
+			samples = append(samples, sample)
+		} else if t.After(end) {
+			break
+		}
+	}
+
+	if len(samples) == 0 {
+		return nil
+	}
+	return &common.TimeSeries{
+		Labels:  ts.Labels,
+		Samples: samples,
+	}
+}
+
 func (p *periodicSignalPrediction) Name() string {
 	return "Periodic"
 }
@@ -3,10 +3,12 @@ package dsp
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/providers/csv"
 	"github.com/stretchr/testify/assert"
@@ -39,7 +41,8 @@ func TestPreProcessTimeSeries(t *testing.T) {
 	timeSeries := tsList[0]
 	assert.Equal(t, 5, len(timeSeries.Samples))
 
-	assert.NoError(t, preProcessTimeSeries(timeSeries, &defaultInternalConfig, time.Minute))
+	_, err = preProcessTimeSeries(timeSeries, &defaultInternalConfig, time.Minute)
+	assert.NoError(t, err)
 	assert.Equal(t, 11, len(timeSeries.Samples))
 
 	for i := 1; i < len(timeSeries.Samples); i++ {
@@ -48,7 +51,8 @@ func TestPreProcessTimeSeries(t *testing.T) {
 	}
 
 	// Truncate the time series to multiple of 10 minutes.
-	assert.NoError(t, preProcessTimeSeries(timeSeries, &defaultInternalConfig, 10*time.Minute))
+	_, err = preProcessTimeSeries(timeSeries, &defaultInternalConfig, 10*time.Minute)
+	assert.NoError(t, err)
 	assert.Equal(t, 10, len(timeSeries.Samples))
 
 	for i := 1; i < len(timeSeries.Samples); i++ {
@@ -56,3 +60,39 @@ func TestPreProcessTimeSeries(t *testing.T) {
 		assert.Equal(t, int64(60), timeSeries.Samples[i].Timestamp-timeSeries.Samples[i-1].Timestamp)
 	}
 }
+
+func TestBestEstimatorResiduals(t *testing.T) {
+	samplesPerPeriod := int(Day / time.Minute)
+	nPeriods := 3
+	values := make([]float64, samplesPerPeriod*nPeriods)
+	for i := range values {
+		values[i] = 10 + 5*math.Sin(2*math.Pi*float64(i)/float64(samplesPerPeriod)) + float64(i%7)/10
+	}
+	signal := &Signal{SampleRate: 1.0 / time.Minute.Seconds(), Samples: values}
+
+	estimator, residuals := bestEstimator("test", defaultInternalConfig.estimators, signal, nPeriods, Day)
+	assert.NotNil(t, estimator)
+	assert.Equal(t, samplesPerPeriod, len(residuals))
+}
+
+func TestBoundTimeSeries(t *testing.T) {
+	predicted := &common.TimeSeries{
+		Labels:  []common.Label{{Name: "container", Value: "app"}},
+		Samples: []common.Sample{{Timestamp: 60, Value: 1}, {Timestamp: 120, Value: 3}},
+	}
+	residuals := []float64{-2, -1, 0, 1, 2}
+
+	lower := lowerBoundTimeSeries(predicted, residuals, 0.25)
+	assert.Equal(t, predicted.Labels, lower.Labels)
+	assert.Equal(t, []common.Sample{{Timestamp: 60, Value: 0}, {Timestamp: 120, Value: 2}}, lower.Samples)
+
+	upper := upperBoundTimeSeries(predicted, residuals, 1)
+	assert.Equal(t, []common.Sample{{Timestamp: 60, Value: 3}, {Timestamp: 120, Value: 5}}, upper.Samples)
+
+	// the bounds are clamped to the predicted time series if the residuals are biased
+	lower = lowerBoundTimeSeries(predicted, []float64{1, 2}, 0)
+	assert.Equal(t, predicted.Samples, lower.Samples)
+	upper = upperBoundTimeSeries(predicted, []float64{-2, -1}, 1)
+	assert.Equal(t, predicted.Samples, upper.Samples)
+	assert.Equal(t, 1.0, predicted.Samples[0].Value, "predicted time series should not be changed")
+}
@@ -2,6 +2,7 @@ package dsp
 
 import (
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -63,8 +64,84 @@ func fillMissingData(ts *common.TimeSeries, config *internalConfig, unit time.Du
 	return nil
 }
 
-func deTrend() error {
-	return nil
+const (
+	// minTrendHistory is the min length of history to find the trend, the changes within a day are seasonality
+	minTrendHistory = 48 * time.Hour
+	// minTrendFraction is the min fraction of the mean value a trend changes over the history to be removed
+	minTrendFraction = 0.05
+)
+
+// linearTrend is the trend removed from a time series, the value at a timestamp is slope * (timestamp - anchor)
+// higher than the detrended one, so the samples at the anchor, i.e. the last sample, keep the current level.
+type linearTrend struct {
+	// slope is the change per second
+	slope  float64
+	anchor int64
+}
+
+func (l linearTrend) valueAt(timestamp int64) float64 {
+	return l.slope * float64(timestamp-l.anchor)
+}
+
+// historyTimeSeries is a preprocessed time series, the trend of it is added back to the forecast
+type historyTimeSeries struct {
+	*common.TimeSeries
+	trend linearTrend
+}
+
+// deTrend finds the linear trend of the daily means of the whole days at the end of the time series by least
+// squares, and removes it from the samples if the trend is significant. The daily means are used so that the
+// seasonality within a day does not bias the trend.
+func deTrend(ts *common.TimeSeries, config *internalConfig) (linearTrend, error) {
+	if ts == nil || len(ts.Samples) == 0 {
+		return linearTrend{}, fmt.Errorf("empty time series")
+	}
+
+	n := len(ts.Samples)
+	trend := linearTrend{anchor: ts.Samples[n-1].Timestamp}
+	samplesPerDay := int(Day / config.historyResolution)
+	nDays := n / samplesPerDay
+	if time.Duration(nDays)*Day < minTrendHistory {
+		return trend, nil
+	}
+
+	days := make([]common.Sample, nDays)
+	for d := range days {
+		samples := ts.Samples[n-(nDays-d)*samplesPerDay : n-(nDays-d-1)*samplesPerDay]
+		for _, s := range samples {
+			days[d].Timestamp += s.Timestamp - trend.anchor
+			days[d].Value += s.Value
+		}
+		days[d].Timestamp /= int64(samplesPerDay)
+		days[d].Value /= float64(samplesPerDay)
+	}
+
+	var meanT, meanV float64
+	for _, d := range days {
+		meanT += float64(d.Timestamp)
+		meanV += d.Value
+	}
+	meanT /= float64(nDays)
+	meanV /= float64(nDays)
+
+	var covariance, variance float64
+	for _, d := range days {
+		dt := float64(d.Timestamp) - meanT
+		covariance += dt * (d.Value - meanV)
+		variance += dt * dt
+	}
+
+	slope := covariance / variance
+	change := math.Abs(slope * float64(nDays) * Day.Seconds())
+	if change < minTrendFraction*math.Abs(meanV) {
+		return trend, nil
+	}
+
+	trend.slope = slope
+	for i := range ts.Samples {
+		ts.Samples[i].Value -= trend.valueAt(ts.Samples[i].Timestamp)
+	}
+	return trend, nil
 }
 
 func removeExtremeOutliers(ts *common.TimeSeries) error {
@@ -92,44 +169,47 @@ func removeExtremeOutliers(ts *common.TimeSeries) error {
 	return nil
 }
 
-func preProcessTimeSeries(ts *common.TimeSeries, config *internalConfig, unit time.Duration) error {
+func preProcessTimeSeries(ts *common.TimeSeries, config *internalConfig, unit time.Duration) (linearTrend, error) {
 	var err error
 
 	err = fillMissingData(ts, config, unit)
 	if err != nil {
-		return err
+		return linearTrend{}, err
 	}
 
-	_ = deTrend()
+	trend, err := deTrend(ts, config)
+	if err != nil {
+		return linearTrend{}, err
+	}
 
 	_ = removeExtremeOutliers(ts)
 
-	return nil
+	return trend, nil
 }
 
-func preProcessTimeSeriesList(tsList []*common.TimeSeries, config *internalConfig) ([]*common.TimeSeries, error) {
+func preProcessTimeSeriesList(tsList []*common.TimeSeries, config *internalConfig) ([]*historyTimeSeries, error) {
 	var wg sync.WaitGroup
 
 	n := len(tsList)
 	wg.Add(n)
-	tsCh := make(chan *common.TimeSeries, n)
+	tsCh := make(chan *historyTimeSeries, n)
 	for i := range tsList {
 		go func(ts *common.TimeSeries) {
 			defer wg.Done()
-			if err := preProcessTimeSeries(ts, config, Hour); err != nil {
+			if trend, err := preProcessTimeSeries(ts, config, Hour); err != nil {
 				klog.ErrorS(err, "Dsp failed to pre process time series.")
 			} else {
-				tsCh <- ts
+				tsCh <- &historyTimeSeries{TimeSeries: ts, trend: trend}
 			}
 		}(tsList[i])
 	}
 	wg.Wait()
 	close(tsCh)
 
-	tsList = make([]*common.TimeSeries, 0, n)
+	historyList := make([]*historyTimeSeries, 0, n)
 	for ts := range tsCh {
-		tsList = append(tsList, ts)
+		historyList = append(historyList, ts)
 	}
 
-	return tsList, nil
+	return historyList, nil
 }
@@ -1,6 +1,7 @@
 package dsp
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -69,3 +70,40 @@ func TestRemoveExtremeOutliers2(t *testing.T) {
 	//fmt.Println("Open your browser and access 'http://localhost:7001'")
 	//http.ListenAndServe(":7001", nil)
 }
+
+func TestDeTrend(t *testing.T) {
+	end := time.Now().Truncate(time.Minute).Unix()
+	newTimeSeries := func(d time.Duration, slope float64) *common.TimeSeries {
+		n := int(d / time.Minute)
+		ts := &common.TimeSeries{Samples: make([]common.Sample, n)}
+		for i := 0; i < n; i++ {
+			timestamp := end - int64(n-1-i)*60
+			ts.Samples[i] = common.Sample{
+				Timestamp: timestamp,
+				Value:     10 + math.Sin(2*math.Pi*float64(i)/1440) + slope*float64(timestamp-end),
+			}
+		}
+		return ts
+	}
+
+	// the linear growth of a periodic time series is removed, and the last sample keeps the current level
+	slope := 1.0 / Day.Seconds()
+	ts := newTimeSeries(4*Day, slope)
+	last := ts.Samples[len(ts.Samples)-1].Value
+	trend, err := deTrend(ts, &defaultInternalConfig)
+	assert.NoError(t, err)
+	assert.InEpsilon(t, slope, trend.slope, 0.05)
+	assert.InDelta(t, last, ts.Samples[len(ts.Samples)-1].Value, 1e-9)
+	assert.InDelta(t, 10, ts.Samples[0].Value, 0.1)
+	assert.InDelta(t, slope*Day.Seconds(), trend.valueAt(end+int64(Day.Seconds())), 0.05)
+
+	// the insignificant trend is kept
+	trend, err = deTrend(newTimeSeries(4*Day, 0.01/Day.Seconds()), &defaultInternalConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, trend.slope)
+
+	// the history shorter than two days has no trend
+	trend, err = deTrend(newTimeSeries(36*time.Hour, slope), &defaultInternalConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, trend.slope)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction/calendar"
 	"github.com/gocrane/crane/pkg/prediction/config"
 )
 
@@ -33,3 +34,17 @@ type Interface interface {
 
 	Name() string
 }
+
+// IntervalInterface is implemented by the predictors which predict the prediction interval besides the point forecast.
+type IntervalInterface interface {
+	// QueryPredictedIntervals returns the lower and upper bounds of the prediction interval of the predicted time series
+	// in [startTime, endTime], the bounds have the same labels as the predicted time series.
+	QueryPredictedIntervals(ctx context.Context, metricNamer metricnaming.MetricNamer, startTime time.Time, endTime time.Time) (lower []*common.TimeSeries, upper []*common.TimeSeries, err error)
+}
+
+// CalendarInterface is implemented by the predictors whose predictions can be overridden in the special periods of a
+// calendar.
+type CalendarInterface interface {
+	// WithCalendar sets the calendar applied to the predictions of the query, the calendar is removed if it is nil.
+	WithCalendar(metricNamer metricnaming.MetricNamer, calendar *calendar.Calendar)
+}
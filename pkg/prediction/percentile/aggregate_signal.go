@@ -2,6 +2,7 @@ package percentile
 
 import (
 	"math"
+	"sync"
 	"time"
 
 	vpa "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/util"
@@ -10,6 +11,8 @@ import (
 )
 
 type aggregateSignal struct {
+	// mutex protects the histogram from being checkpointed while adding samples
+	mutex             sync.Mutex
 	histogram         vpa.Histogram
 	firstSampleTime   time.Time
 	lastSampleTime    time.Time
@@ -21,6 +24,9 @@ type aggregateSignal struct {
 }
 
 func (a *aggregateSignal) addSample(sampleTime time.Time, sampleValue float64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
 	a.histogram.AddSample(sampleValue, math.Max(a.minSampleWeight, sampleValue), sampleTime)
 	if a.lastSampleTime.Before(sampleTime) {
 		a.lastSampleTime = sampleTime
@@ -88,6 +88,18 @@ func (a *aggregateSignals) Delete(qc prediction.QueryExprWithCaller) bool /*need
 	return true
 }
 
+// GetQueryExprs returns the query expressions registered
+func (a *aggregateSignals) GetQueryExprs() []string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	queryExprs := make([]string, 0, len(a.signalMap))
+	for queryExpr := range a.signalMap {
+		queryExprs = append(queryExprs, queryExpr)
+	}
+	return queryExprs
+}
+
 func (a *aggregateSignals) GetConfig(queryExpr string) *internalConfig {
 	a.mutex.RLock()
 	defer a.mutex.RUnlock()
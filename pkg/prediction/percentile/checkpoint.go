@@ -0,0 +1,163 @@
+package percentile
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	vpatypes "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
+)
+
+// checkpointVersion is the version of signalCheckpoint, bump it if the format is changed incompatibly
+const checkpointVersion = "v1"
+
+var checkpointAlgorithm = string(v1alpha1.AlgorithmTypePercentile)
+
+type signalCheckpoint struct {
+	Labels            []common.Label                `json:"labels,omitempty"`
+	Histogram         *vpatypes.HistogramCheckpoint `json:"histogram"`
+	FirstSampleTime   time.Time                     `json:"firstSampleTime"`
+	LastSampleTime    time.Time                     `json:"lastSampleTime"`
+	TotalSamplesCount int                           `json:"totalSamplesCount"`
+}
+
+func (a *aggregateSignal) saveToCheckpoint() (*signalCheckpoint, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	h, err := a.histogram.SaveToChekpoint()
+	if err != nil {
+		return nil, err
+	}
+	return &signalCheckpoint{
+		Labels:            a.labels,
+		Histogram:         h,
+		FirstSampleTime:   a.firstSampleTime,
+		LastSampleTime:    a.lastSampleTime,
+		TotalSamplesCount: a.totalSamplesCount,
+	}, nil
+}
+
+func (a *aggregateSignal) loadFromCheckpoint(c *signalCheckpoint) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if err := a.histogram.LoadFromCheckpoint(c.Histogram); err != nil {
+		return err
+	}
+	a.labels = c.Labels
+	a.firstSampleTime = c.FirstSampleTime
+	a.lastSampleTime = c.LastSampleTime
+	a.totalSamplesCount = c.TotalSamplesCount
+	return nil
+}
+
+// fingerprint identifies the config of the histograms, the checkpoint of another config can not be restored
+func fingerprint(c *internalConfig) string {
+	n := c.histogramOptions.NumBuckets()
+	return fmt.Sprintf("aggregated=%v,sampleInterval=%v,minSampleWeight=%v,buckets=%d,maxBucketStart=%v",
+		c.aggregated, c.sampleInterval, c.minSampleWeight, n, c.histogramOptions.GetBucketStart(n-1))
+}
+
+// saveCheckpoints saves the models which are ready or initializing to the checkpoint store
+func (p *percentilePrediction) saveCheckpoints() {
+	for _, queryExpr := range p.a.GetQueryExprs() {
+		if err := p.saveCheckpoint(queryExpr); err != nil {
+			klog.ErrorS(err, "Failed to save percentile checkpoint.", "queryExpr", queryExpr)
+		}
+	}
+}
+
+func (p *percentilePrediction) saveCheckpoint(queryExpr string) error {
+	signals, status := p.a.GetSignals(queryExpr)
+	if len(signals) == 0 || (status != prediction.StatusReady && status != prediction.StatusInitializing) {
+		return nil
+	}
+
+	checkpoints := map[string]*signalCheckpoint{}
+	for key, signal := range signals {
+		c, err := signal.saveToCheckpoint()
+		if err != nil {
+			return err
+		}
+		checkpoints[key] = c
+	}
+	data, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+
+	klog.V(6).InfoS("Save percentile checkpoint.", "queryExpr", queryExpr, "signals", len(checkpoints))
+	return p.checkpointStore.Save(&checkpoint.Checkpoint{
+		Algorithm:   checkpointAlgorithm,
+		QueryExpr:   queryExpr,
+		Version:     checkpointVersion,
+		Fingerprint: fingerprint(p.a.GetConfig(queryExpr)),
+		Time:        time.Now(),
+		Data:        data,
+	})
+}
+
+// initByCheckPoint restores the histograms from the checkpoint, the checkpoint older than the history length is
+// discarded. The model is ready if the window length of any restored histogram reaches the history length.
+func (p *percentilePrediction) initByCheckPoint(namer metricnaming.MetricNamer) error {
+	if p.checkpointStore == nil {
+		return fmt.Errorf("checkpoint store not configured")
+	}
+
+	queryExpr := namer.BuildUniqueKey()
+	c, err := p.checkpointStore.Load(checkpointAlgorithm, queryExpr)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return fmt.Errorf("checkpoint not found")
+	}
+
+	cfg := p.a.GetConfig(queryExpr)
+	if err = c.Validate(checkpointVersion, fingerprint(cfg), cfg.historyLength); err != nil {
+		return err
+	}
+
+	var checkpoints map[string]*signalCheckpoint
+	if err = json.Unmarshal(c.Data, &checkpoints); err != nil {
+		return err
+	}
+	if len(checkpoints) == 0 {
+		return fmt.Errorf("empty checkpoint")
+	}
+
+	status := prediction.StatusInitializing
+	signals := map[string]*aggregateSignal{}
+	for key, sc := range checkpoints {
+		signal := newAggregateSignal(cfg)
+		if err = signal.loadFromCheckpoint(sc); err != nil {
+			return err
+		}
+		if signal.GetAggregationWindowLength() >= cfg.historyLength {
+			status = prediction.StatusReady
+		}
+		signals[key] = signal
+	}
+	p.a.SetSignalsWithStatus(queryExpr, signals, status)
+
+	klog.V(4).InfoS("Restored percentile model from checkpoint.", "queryExpr", queryExpr, "checkpointTime", c.Time, "status", status)
+	return nil
+}
+
+func (p *percentilePrediction) deleteCheckpoint(queryExpr string) {
+	if p.checkpointStore == nil {
+		return
+	}
+	if err := p.checkpointStore.Delete(checkpointAlgorithm, queryExpr); err != nil {
+		klog.ErrorS(err, "Failed to delete percentile checkpoint.", "queryExpr", queryExpr)
+	}
+}
@@ -1,6 +1,8 @@
 package percentile
 
 import (
+	"math"
+
 	vpa "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/util"
 )
 
@@ -22,6 +24,14 @@ type targetUtilizationEstimator struct {
 	baseEstimator     Estimator
 }
 
+// boundEstimator clamps the estimation of the base estimator to be no more (lower bound) or no less (upper bound)
+// than the estimation of the point estimator
+type boundEstimator struct {
+	upper          bool
+	pointEstimator Estimator
+	baseEstimator  Estimator
+}
+
 func NewPercentileEstimator(percentile float64) Estimator {
 	return &percentileEstimator{percentile}
 }
@@ -34,6 +44,14 @@ func WithTargetUtilization(targetUtilization float64, baseEstimator Estimator) E
 	return &targetUtilizationEstimator{targetUtilization, baseEstimator}
 }
 
+func WithLowerBound(pointEstimator Estimator, baseEstimator Estimator) Estimator {
+	return &boundEstimator{false, pointEstimator, baseEstimator}
+}
+
+func WithUpperBound(pointEstimator Estimator, baseEstimator Estimator) Estimator {
+	return &boundEstimator{true, pointEstimator, baseEstimator}
+}
+
 func (e *percentileEstimator) GetEstimation(h vpa.Histogram) float64 {
 	return h.Percentile(e.percentile)
 }
@@ -45,3 +63,10 @@ func (e *marginEstimator) GetEstimation(h vpa.Histogram) float64 {
 func (e *targetUtilizationEstimator) GetEstimation(h vpa.Histogram) float64 {
 	return e.baseEstimator.GetEstimation(h) / e.targetUtilization
 }
+
+func (e *boundEstimator) GetEstimation(h vpa.Histogram) float64 {
+	if e.upper {
+		return math.Max(e.baseEstimator.GetEstimation(h), e.pointEstimator.GetEstimation(h))
+	}
+	return math.Min(e.baseEstimator.GetEstimation(h), e.pointEstimator.GetEstimation(h))
+}
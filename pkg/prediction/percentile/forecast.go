@@ -0,0 +1,34 @@
+package percentile
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+// Forecast forecasts the samples in [start, end) from the history samples with the percentile predictor.
+func Forecast(history []common.Sample, conf *v1alpha1.Percentile, start, end time.Time) ([]common.Sample, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("percentile config is required")
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("empty history")
+	}
+	cfg, err := makeInternalConfig(conf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	signal := newAggregateSignal(cfg)
+	for _, s := range history {
+		signal.addSample(time.Unix(s.Timestamp, 0), s.Value)
+	}
+
+	estimator := NewPercentileEstimator(cfg.percentile)
+	estimator = WithMargin(cfg.marginFraction, estimator)
+	estimator = WithTargetUtilization(cfg.targetUtilization, estimator)
+	return generateSamplesFromWindow(estimator.GetEstimation(signal.histogram), start, end, cfg.sampleInterval), nil
+}
@@ -6,16 +6,19 @@ import (
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 
 	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
 	"github.com/gocrane/crane/pkg/prediction/config"
 	"github.com/gocrane/crane/pkg/providers"
 )
 
 var _ prediction.Interface = &percentilePrediction{}
+var _ prediction.IntervalInterface = &percentilePrediction{}
 var keyAll = "__all__"
 
 type percentilePrediction struct {
@@ -24,6 +27,12 @@ type percentilePrediction struct {
 	// record the query routine already started
 	queryRoutines sync.Map
 	stopChMap     sync.Map
+	// checkpointStore saves the histograms periodically, checkpointing is disabled if it is nil
+	checkpointStore    checkpoint.Store
+	checkpointInterval time.Duration
+	// lowerQuantile and upperQuantile are the percentiles of the histogram as the bounds of the prediction interval
+	lowerQuantile float64
+	upperQuantile float64
 }
 
 func (p *percentilePrediction) QueryPredictionStatus(_ context.Context, metricNamer metricnaming.MetricNamer) (prediction.Status, error) {
@@ -32,21 +41,45 @@ func (p *percentilePrediction) QueryPredictionStatus(_ context.Context, metricNa
 }
 
 func (p *percentilePrediction) QueryPredictedTimeSeries(ctx context.Context, namer metricnaming.MetricNamer, startTime time.Time, endTime time.Time) ([]*common.TimeSeries, error) {
-	var predictedTimeSeriesList []*common.TimeSeries
-	queryExpr := namer.BuildUniqueKey()
-	cfg := p.a.GetConfig(queryExpr)
-	tsList := p.getPredictedValues(ctx, namer)
+	cfg := p.a.GetConfig(namer.BuildUniqueKey())
+	return generateTimeSeriesFromWindow(p.getPredictedValues(ctx, namer), startTime, endTime, cfg.sampleInterval), nil
+}
+
+// QueryPredictedIntervals returns the bounds of the prediction interval, which are the lower and upper percentiles of
+// the histogram adjusted by the target utilization. The predicted time series is a higher percentile with a margin,
+// so the bounds are clamped around it, i.e. lower <= predicted <= upper.
+func (p *percentilePrediction) QueryPredictedIntervals(ctx context.Context, namer metricnaming.MetricNamer, startTime time.Time, endTime time.Time) ([]*common.TimeSeries, []*common.TimeSeries, error) {
+	signals := p.waitSignals(ctx, namer)
+	if signals == nil {
+		return nil, nil, nil
+	}
+
+	cfg := p.a.GetConfig(namer.BuildUniqueKey())
+	lower, upper := p.getPredictedBoundsFromSignals(signals, cfg)
+	return generateTimeSeriesFromWindow(lower, startTime, endTime, cfg.sampleInterval), generateTimeSeriesFromWindow(upper, startTime, endTime, cfg.sampleInterval), nil
+}
+
+// getPredictedBoundsFromSignals returns the lower and upper bounds of each signal clamped around its predicted value
+func (p *percentilePrediction) getPredictedBoundsFromSignals(signals map[string]*aggregateSignal, cfg *internalConfig) ([]*common.TimeSeries, []*common.TimeSeries) {
+	predicted := pointEstimator(cfg)
+	lower := WithLowerBound(predicted, WithTargetUtilization(cfg.targetUtilization, NewPercentileEstimator(p.lowerQuantile)))
+	upper := WithUpperBound(predicted, WithTargetUtilization(cfg.targetUtilization, NewPercentileEstimator(p.upperQuantile)))
+	return estimateSignals(signals, cfg, lower), estimateSignals(signals, cfg, upper)
+}
+
+// generateTimeSeriesFromWindow fills the window with the last estimated value of each time series
+func generateTimeSeriesFromWindow(tsList []*common.TimeSeries, start time.Time, end time.Time, step time.Duration) []*common.TimeSeries {
+	var result []*common.TimeSeries
 	for _, ts := range tsList {
 		n := len(ts.Samples)
 		if n > 0 {
-			samples := generateSamplesFromWindow(ts.Samples[n-1].Value, startTime, endTime, cfg.sampleInterval)
-			predictedTimeSeriesList = append(predictedTimeSeriesList, &common.TimeSeries{
+			result = append(result, &common.TimeSeries{
 				Labels:  ts.Labels,
-				Samples: samples,
+				Samples: generateSamplesFromWindow(ts.Samples[n-1].Value, start, end, step),
 			})
 		}
 	}
-	return predictedTimeSeriesList, nil
+	return result
 }
 
 func generateSamplesFromWindow(value float64, start time.Time, end time.Time, step time.Duration) []common.Sample {
@@ -59,14 +92,23 @@ func generateSamplesFromWindow(value float64, start time.Time, end time.Time, st
 }
 
 func (p *percentilePrediction) getPredictedValuesFromSignals(queryExpr string, signals map[string]*aggregateSignal, cfg *internalConfig) []*common.TimeSeries {
-	var predictedTimeSeriesList []*common.TimeSeries
-
 	if cfg == nil {
 		cfg = p.a.GetConfig(queryExpr)
 	}
+	return estimateSignals(signals, cfg, pointEstimator(cfg))
+}
+
+// pointEstimator returns the estimator of the predicted value, which is the percentile with margin
+func pointEstimator(cfg *internalConfig) Estimator {
 	estimator := NewPercentileEstimator(cfg.percentile)
 	estimator = WithMargin(cfg.marginFraction, estimator)
-	estimator = WithTargetUtilization(cfg.targetUtilization, estimator)
+	return WithTargetUtilization(cfg.targetUtilization, estimator)
+}
+
+// estimateSignals returns the estimated value of the histogram of each signal, or of all the signals if they are
+// aggregated
+func estimateSignals(signals map[string]*aggregateSignal, cfg *internalConfig, estimator Estimator) []*common.TimeSeries {
+	var predictedTimeSeriesList []*common.TimeSeries
 	now := time.Now().Unix()
 
 	if cfg.aggregated {
@@ -101,8 +143,16 @@ func (p *percentilePrediction) getPredictedValuesFromSignals(queryExpr string, s
 }
 
 func (p *percentilePrediction) getPredictedValues(ctx context.Context, namer metricnaming.MetricNamer) []*common.TimeSeries {
-	var predictedTimeSeriesList []*common.TimeSeries
+	signals := p.waitSignals(ctx, namer)
+	if signals == nil {
+		return nil
+	}
+	return p.getPredictedValuesFromSignals(namer.BuildUniqueKey(), signals, nil)
+}
 
+// waitSignals waits until the aggregate signals of the query are ready, it returns nil if the query is unknown or
+// the context is done.
+func (p *percentilePrediction) waitSignals(ctx context.Context, namer metricnaming.MetricNamer) map[string]*aggregateSignal {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -111,15 +161,15 @@ func (p *percentilePrediction) getPredictedValues(ctx context.Context, namer met
 		signals, status := p.a.GetSignals(queryExpr)
 		if status == prediction.StatusUnknown {
 			klog.V(4).InfoS("Aggregated has been deleted and unknown", "queryExpr", queryExpr)
-			return predictedTimeSeriesList
+			return nil
 		}
 		if signals != nil && status == prediction.StatusReady {
-			return p.getPredictedValuesFromSignals(queryExpr, signals, nil)
+			return signals
 		}
 		select {
 		case <-ctx.Done():
 			klog.Info("Time out.")
-			return predictedTimeSeriesList
+			return nil
 		case <-ticker.C:
 			continue
 		}
@@ -200,13 +250,21 @@ func (p *percentilePrediction) process(namer metricnaming.MetricNamer, cfg *inte
 	return p.getPredictedValuesFromSignals(queryExpr, signals, cfg), nil
 }
 
-func NewPrediction(realtimeProvider providers.RealTime, historyProvider providers.History) prediction.Interface {
+func NewPrediction(realtimeProvider providers.RealTime, historyProvider providers.History, mc config.AlgorithmModelConfig, store checkpoint.Store) prediction.Interface {
 	withCh, delCh := make(chan prediction.QueryExprWithCaller), make(chan prediction.QueryExprWithCaller)
+	checkpointInterval := mc.CheckpointInterval
+	if checkpointInterval <= 0 {
+		checkpointInterval = checkpoint.DefaultInterval
+	}
 	return &percentilePrediction{
-		GenericPrediction: prediction.NewGenericPrediction(realtimeProvider, historyProvider, withCh, delCh),
-		a:                 newAggregateSignals(),
-		queryRoutines:     sync.Map{},
-		stopChMap:         sync.Map{},
+		GenericPrediction:  prediction.NewGenericPrediction(realtimeProvider, historyProvider, withCh, delCh),
+		a:                  newAggregateSignals(),
+		queryRoutines:      sync.Map{},
+		stopChMap:          sync.Map{},
+		checkpointStore:    store,
+		checkpointInterval: checkpointInterval,
+		lowerQuantile:      mc.LowerQuantile,
+		upperQuantile:      mc.UpperQuantile,
 	}
 }
 
@@ -235,17 +293,18 @@ func (p *percentilePrediction) Run(stopCh <-chan struct{}) {
 			// we start the real time model updating directly. but there is a window time for each metricNamer in the algorithm config to ready status
 			c := p.a.GetConfig(QueryExpr)
 
+			// a valid checkpoint is restored first whatever the init mode is, because it is the same model as the
+			// one before restarting. If there is no such checkpoint, the checkpoint mode falls back to history
 			var initError error
-			switch c.initMode {
-			case config.ModelInitModeLazyTraining:
-				p.initByRealTimeProvider(qc.MetricNamer)
-			case config.ModelInitModeCheckpoint:
-				initError = p.initByCheckPoint(qc.MetricNamer)
-			case config.ModelInitModeHistory:
-				fallthrough
-			default:
-				// blocking
-				initError = p.initFromHistory(qc.MetricNamer)
+			if err := p.initByCheckPoint(qc.MetricNamer); err != nil {
+				klog.V(4).InfoS("Failed to restore percentile model from checkpoint.", "queryExpr", QueryExpr, "initMode", c.initMode, "reason", err)
+				switch c.initMode {
+				case config.ModelInitModeLazyTraining:
+					p.initByRealTimeProvider(qc.MetricNamer)
+				default:
+					// history and checkpoint mode, blocking
+					initError = p.initFromHistory(qc.MetricNamer)
+				}
 			}
 
 			if initError != nil {
@@ -294,11 +353,16 @@ func (p *percentilePrediction) Run(stopCh <-chan struct{}) {
 						predStopCh := val.(chan struct{})
 						predStopCh <- struct{}{}
 					}
+					p.deleteCheckpoint(QueryExpr)
 				}
 			}(qc)
 		}
 	}()
 
+	if p.checkpointStore != nil {
+		go wait.Until(p.saveCheckpoints, p.checkpointInterval, stopCh)
+	}
+
 	klog.Infof("predictor %v started", p.Name())
 
 	<-stopCh
@@ -339,12 +403,6 @@ func (p *percentilePrediction) initByRealTimeProvider(namer metricnaming.MetricN
 	}
 }
 
-// todo:
-// nolint:unused
-func (p *percentilePrediction) initByCheckPoint(_ metricnaming.MetricNamer) error {
-	return fmt.Errorf("checkpoint not supported")
-}
-
 func (p *percentilePrediction) initFromHistory(namer metricnaming.MetricNamer) error {
 	queryExpr := namer.BuildUniqueKey()
 	cfg := p.a.GetConfig(queryExpr)
@@ -0,0 +1,45 @@
+package percentile
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetPredictedBoundsFromSignals(t *testing.T) {
+	cfg := defaultInternalConfig
+	cfg.percentile = 0.99
+	cfg.marginFraction = 0.15
+
+	signal := newAggregateSignal(&cfg)
+	now := time.Now()
+	for i := 0; i < 1000; i++ {
+		signal.addSample(now.Add(time.Duration(i)*time.Minute), float64(i%50))
+	}
+	signals := map[string]*aggregateSignal{keyAll: signal}
+
+	tests := []struct {
+		name          string
+		lowerQuantile float64
+		upperQuantile float64
+	}{
+		{name: "default quantiles", lowerQuantile: 0.1, upperQuantile: 0.9},
+		{name: "upper quantile above the percentile", lowerQuantile: 0.01, upperQuantile: 0.999},
+		{name: "lower quantile above the percentile", lowerQuantile: 0.999, upperQuantile: 0.9999},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &percentilePrediction{lowerQuantile: tt.lowerQuantile, upperQuantile: tt.upperQuantile}
+			predicted := estimateSignals(signals, &cfg, pointEstimator(&cfg))
+			lower, upper := p.getPredictedBoundsFromSignals(signals, &cfg)
+			if len(predicted) != 1 || len(lower) != 1 || len(upper) != 1 {
+				t.Fatalf("expect one time series of each, got %d, %d, %d", len(predicted), len(lower), len(upper))
+			}
+
+			value := predicted[0].Samples[0].Value
+			lowerValue, upperValue := lower[0].Samples[0].Value, upper[0].Samples[0].Value
+			if !(lowerValue <= value && value <= upperValue) {
+				t.Errorf("expect lower %f <= predicted %f <= upper %f", lowerValue, value, upperValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/klog/v2"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/remote/pb"
+	"github.com/gocrane/crane/pkg/providers"
+)
+
+// AlgorithmTypeRemote is the algorithm which forwards the history to an external model server, such as a server
+// of prophet, arima or lstm models, and returns the forecast of it.
+const AlgorithmTypeRemote predictionapi.AlgorithmType = "remote"
+
+const (
+	defaultFuture = time.Hour
+)
+
+type query struct {
+	callers   map[string]struct{}
+	status    prediction.Status
+	predicted []*common.TimeSeries
+	stopCh    chan struct{}
+}
+
+type remotePrediction struct {
+	prediction.GenericPrediction
+	config      config.RemoteConfig
+	modelConfig config.AlgorithmModelConfig
+
+	mutex   sync.RWMutex
+	queries map[string]*query
+}
+
+func NewPrediction(realtimeProvider providers.RealTime, historyProvider providers.History, mc config.AlgorithmModelConfig, rc config.RemoteConfig) prediction.Interface {
+	withCh, delCh := make(chan prediction.QueryExprWithCaller), make(chan prediction.QueryExprWithCaller)
+	return &remotePrediction{
+		GenericPrediction: prediction.NewGenericPrediction(realtimeProvider, historyProvider, withCh, delCh),
+		config:            rc,
+		modelConfig:       mc,
+		queries:           map[string]*query{},
+	}
+}
+
+func (p *remotePrediction) Run(stopCh <-chan struct{}) {
+	if p.GetHistoryProvider() == nil {
+		klog.ErrorS(fmt.Errorf("history provider not provisioned"), "Failed to run remotePrediction.")
+		return
+	}
+
+	go func() {
+		for {
+			select {
+			case qc := <-p.WithCh:
+				p.addQuery(qc)
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			select {
+			case qc := <-p.DelCh:
+				p.deleteQuery(qc)
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	klog.Infof("predictor %v started", p.Name())
+
+	<-stopCh
+
+	p.mutex.Lock()
+	for queryExpr, q := range p.queries {
+		close(q.stopCh)
+		delete(p.queries, queryExpr)
+	}
+	p.mutex.Unlock()
+
+	klog.Infof("predictor %v stopped", p.Name())
+}
+
+// addQuery adds the caller of the query, and starts the routine to update the forecast if it is a new query
+func (p *remotePrediction) addQuery(qc prediction.QueryExprWithCaller) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	queryExpr := qc.MetricNamer.BuildUniqueKey()
+	if q, exists := p.queries[queryExpr]; exists {
+		q.callers[qc.Caller] = struct{}{}
+		return
+	}
+
+	klog.V(6).InfoS("Register a query expression for prediction.", "queryExpr", queryExpr, "caller", qc.Caller)
+	q := &query{
+		callers: map[string]struct{}{qc.Caller: {}},
+		status:  prediction.StatusNotStarted,
+		stopCh:  make(chan struct{}),
+	}
+	p.queries[queryExpr] = q
+
+	go func(namer metricnaming.MetricNamer, stopCh <-chan struct{}) {
+		ticker := time.NewTicker(p.modelConfig.UpdateInterval)
+		defer ticker.Stop()
+
+		for {
+			p.updatePrediction(namer)
+
+			select {
+			case <-stopCh:
+				klog.V(4).InfoS("Prediction routine stopped.", "queryExpr", namer.BuildUniqueKey())
+				return
+			case <-ticker.C:
+				continue
+			}
+		}
+	}(qc.MetricNamer, q.stopCh)
+}
+
+// deleteQuery deletes the caller of the query, and stops the routine if there is no caller any more
+func (p *remotePrediction) deleteQuery(qc prediction.QueryExprWithCaller) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	queryExpr := qc.MetricNamer.BuildUniqueKey()
+	klog.V(4).InfoS("Unregister a query expression from prediction.", "queryExpr", queryExpr, "caller", qc.Caller)
+
+	q, exists := p.queries[queryExpr]
+	if !exists {
+		return
+	}
+	delete(q.callers, qc.Caller)
+	if len(q.callers) > 0 {
+		return
+	}
+	close(q.stopCh)
+	delete(p.queries, queryExpr)
+}
+
+func (p *remotePrediction) updatePrediction(namer metricnaming.MetricNamer) {
+	queryExpr := namer.BuildUniqueKey()
+	predicted, err := p.forecast(namer, time.Now())
+	if err != nil {
+		// keep the last forecast, it is updated in the next interval
+		klog.ErrorS(err, "Failed to get the forecast of remote predictor.", "queryExpr", queryExpr)
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if q, exists := p.queries[queryExpr]; exists {
+		q.predicted = predicted
+		q.status = prediction.StatusReady
+	}
+	klog.V(6).InfoS("Update remote predicted time series.", "queryExpr", queryExpr, "timeSeriesLength", len(predicted))
+}
+
+// forecast sends the history before now to the model server, and returns the forecast after now
+func (p *remotePrediction) forecast(namer metricnaming.MetricNamer, now time.Time) ([]*common.TimeSeries, error) {
+	if p.GetHistoryProvider() == nil {
+		return nil, fmt.Errorf("history provider not provisioned")
+	}
+
+	end := now.Truncate(p.config.HistoryResolution)
+	start := end.Add(-p.config.HistoryLength)
+	history, err := p.GetHistoryProvider().QueryTimeSeries(namer, start, end, p.config.HistoryResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history time series: %v", err)
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("no history time series")
+	}
+
+	req := &pb.PredictRequest{
+		Model:      p.config.Model,
+		MetricName: metricName(namer),
+		History:    pbTimeSeriesList(history),
+		StartTime:  end.Add(p.config.HistoryResolution).Unix(),
+		EndTime:    end.Add(p.config.Future).Unix(),
+		Step:       int64(p.config.HistoryResolution / time.Second),
+	}
+
+	conn, err := grpc.Dial(p.config.Address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	c := pb.NewPredictorClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+	resp, err := c.Predict(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return commonTimeSeriesList(resp.TimeSeriesList), nil
+}
+
+func (p *remotePrediction) QueryPredictionStatus(ctx context.Context, namer metricnaming.MetricNamer) (prediction.Status, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	q, exists := p.queries[namer.BuildUniqueKey()]
+	if !exists {
+		return prediction.StatusUnknown, nil
+	}
+	return q.status, nil
+}
+
+func (p *remotePrediction) QueryPredictedTimeSeries(ctx context.Context, namer metricnaming.MetricNamer, startTime time.Time, endTime time.Time) ([]*common.TimeSeries, error) {
+	predicted, err := p.getPredictedTimeSeriesList(ctx, namer)
+	if err != nil {
+		return nil, err
+	}
+	return filterTimeSeriesList(predicted, startTime, endTime), nil
+}
+
+func (p *remotePrediction) QueryRealtimePredictedValues(ctx context.Context, namer metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
+	predicted, err := p.getPredictedTimeSeriesList(ctx, namer)
+	if err != nil {
+		return nil, err
+	}
+	return maxValues(predicted, time.Now()), nil
+}
+
+func (p *remotePrediction) QueryRealtimePredictedValuesOnce(ctx context.Context, namer metricnaming.MetricNamer, config config.Config) ([]*common.TimeSeries, error) {
+	now := time.Now()
+	predicted, err := p.forecast(namer, now)
+	if err != nil {
+		return nil, err
+	}
+	return maxValues(predicted, now), nil
+}
+
+// getPredictedTimeSeriesList waits until the forecast of the query is ready or the context is done
+func (p *remotePrediction) getPredictedTimeSeriesList(ctx context.Context, namer metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	queryExpr := namer.BuildUniqueKey()
+	for {
+		p.mutex.RLock()
+		q, exists := p.queries[queryExpr]
+		var status prediction.Status
+		var predicted []*common.TimeSeries
+		if exists {
+			status, predicted = q.status, q.predicted
+		}
+		p.mutex.RUnlock()
+
+		if !exists {
+			return nil, fmt.Errorf("query %s is not registered", queryExpr)
+		}
+		if status == prediction.StatusReady {
+			return predicted, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("prediction of query %s is not ready: %v", queryExpr, ctx.Err())
+		case <-ticker.C:
+			continue
+		}
+	}
+}
+
+func (p *remotePrediction) Name() string {
+	return "Remote"
+}
+
+// filterTimeSeriesList returns the samples in [start, end], the time series without any sample are dropped
+func filterTimeSeriesList(tsList []*common.TimeSeries, start, end time.Time) []*common.TimeSeries {
+	var result []*common.TimeSeries
+	for _, ts := range tsList {
+		var samples []common.Sample
+		for _, sample := range ts.Samples {
+			t := time.Unix(sample.Timestamp, 0)
+			if !t.Before(start) && !t.After(end) {
+				samples = append(samples, sample)
+			}
+		}
+		if len(samples) > 0 {
+			result = append(result, &common.TimeSeries{
+				Labels:  ts.Labels,
+				Samples: samples,
+			})
+		}
+	}
+	return result
+}
+
+// maxValues returns the max value of each time series in the next hour after now
+func maxValues(tsList []*common.TimeSeries, now time.Time) []*common.TimeSeries {
+	var result []*common.TimeSeries
+	for _, ts := range filterTimeSeriesList(tsList, now.Truncate(time.Minute), now.Add(defaultFuture)) {
+		maxValue := ts.Samples[0].Value
+		for i := 1; i < len(ts.Samples); i++ {
+			if maxValue < ts.Samples[i].Value {
+				maxValue = ts.Samples[i].Value
+			}
+		}
+		result = append(result, &common.TimeSeries{
+			Labels:  ts.Labels,
+			Samples: []common.Sample{{Value: maxValue, Timestamp: now.Unix()}},
+		})
+	}
+	return result
+}
+
+func metricName(namer metricnaming.MetricNamer) string {
+	if gmn, ok := namer.(*metricnaming.GeneralMetricNamer); ok && gmn.Metric != nil {
+		return gmn.Metric.MetricName
+	}
+	return ""
+}
+
+func pbTimeSeriesList(tsList []*common.TimeSeries) []*pb.TimeSeries {
+	var res = make([]*pb.TimeSeries, len(tsList))
+	for i := range tsList {
+		res[i] = &pb.TimeSeries{
+			Labels:  make([]*pb.Label, len(tsList[i].Labels)),
+			Samples: make([]*pb.Sample, len(tsList[i].Samples)),
+		}
+		for j := range tsList[i].Labels {
+			res[i].Labels[j] = &pb.Label{
+				Name:  tsList[i].Labels[j].Name,
+				Value: tsList[i].Labels[j].Value,
+			}
+		}
+		for j := range tsList[i].Samples {
+			res[i].Samples[j] = &pb.Sample{
+				Timestamp: tsList[i].Samples[j].Timestamp,
+				Value:     tsList[i].Samples[j].Value,
+			}
+		}
+	}
+	return res
+}
+
+func commonTimeSeriesList(tsList []*pb.TimeSeries) []*common.TimeSeries {
+	var res = make([]*common.TimeSeries, len(tsList))
+	for i := range tsList {
+		res[i] = &common.TimeSeries{
+			Labels:  make([]common.Label, len(tsList[i].Labels)),
+			Samples: make([]common.Sample, len(tsList[i].Samples)),
+		}
+		for j := range tsList[i].Labels {
+			res[i].Labels[j] = common.Label{
+				Name:  tsList[i].Labels[j].Name,
+				Value: tsList[i].Labels[j].Value,
+			}
+		}
+		for j := range tsList[i].Samples {
+			res[i].Samples[j] = common.Sample{
+				Timestamp: tsList[i].Samples[j].Timestamp,
+				Value:     tsList[i].Samples[j].Value,
+			}
+		}
+	}
+	return res
+}
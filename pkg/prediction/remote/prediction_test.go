@@ -0,0 +1,123 @@
+package remote
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/metricquery"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/remote/pb"
+)
+
+// stubServer forecasts the last value of each history time series plus one
+type stubServer struct {
+	pb.UnimplementedPredictorServer
+	mutex    sync.Mutex
+	requests []*pb.PredictRequest
+}
+
+func (s *stubServer) Predict(ctx context.Context, req *pb.PredictRequest) (*pb.PredictResponse, error) {
+	s.mutex.Lock()
+	s.requests = append(s.requests, req)
+	s.mutex.Unlock()
+
+	resp := &pb.PredictResponse{}
+	for _, ts := range req.History {
+		last := ts.Samples[len(ts.Samples)-1].Value
+		predicted := &pb.TimeSeries{Labels: ts.Labels}
+		for t := req.StartTime; t <= req.EndTime; t += req.Step {
+			predicted.Samples = append(predicted.Samples, &pb.Sample{Timestamp: t, Value: last + 1})
+		}
+		resp.TimeSeriesList = append(resp.TimeSeriesList, predicted)
+	}
+	return resp, nil
+}
+
+type fakeHistory struct{}
+
+func (fakeHistory) QueryTimeSeries(namer metricnaming.MetricNamer, start time.Time, end time.Time, step time.Duration) ([]*common.TimeSeries, error) {
+	ts := &common.TimeSeries{Labels: []common.Label{{Name: "container", Value: "app"}}}
+	for t := start; !t.After(end); t = t.Add(step) {
+		ts.Samples = append(ts.Samples, common.Sample{Timestamp: t.Unix(), Value: 1})
+	}
+	return []*common.TimeSeries{ts}, nil
+}
+
+func startStubServer(t *testing.T) (*stubServer, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &stubServer{}
+	server := grpc.NewServer()
+	pb.RegisterPredictorServer(server, stub)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return stub, lis.Addr().String()
+}
+
+func TestRemotePrediction(t *testing.T) {
+	stub, addr := startStubServer(t)
+
+	p := NewPrediction(nil, fakeHistory{}, config.AlgorithmModelConfig{UpdateInterval: time.Hour}, config.RemoteConfig{
+		Address:           addr,
+		Timeout:           time.Second,
+		Model:             "prophet",
+		HistoryLength:     time.Hour,
+		HistoryResolution: time.Minute,
+		Future:            2 * time.Hour,
+	})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go p.Run(stopCh)
+
+	namer := &metricnaming.GeneralMetricNamer{
+		CallerName: "test",
+		Metric:     &metricquery.Metric{Type: metricquery.NodeMetricType, MetricName: "cpu_usage", Node: &metricquery.NodeNamerInfo{Name: "node"}},
+	}
+	assert.NoError(t, p.WithQuery(namer, "test", config.Config{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	now := time.Now()
+	tsList, err := p.QueryPredictedTimeSeries(ctx, namer, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(tsList))
+	assert.Equal(t, "app", tsList[0].Labels[0].Value)
+	assert.True(t, len(tsList[0].Samples) >= 59)
+	for _, s := range tsList[0].Samples {
+		assert.Equal(t, 2.0, s.Value)
+	}
+
+	status, err := p.QueryPredictionStatus(ctx, namer)
+	assert.NoError(t, err)
+	assert.Equal(t, prediction.StatusReady, status)
+
+	values, err := p.QueryRealtimePredictedValues(ctx, namer)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(values))
+	assert.Equal(t, 2.0, values[0].Samples[0].Value)
+
+	stub.mutex.Lock()
+	req := stub.requests[0]
+	stub.mutex.Unlock()
+	assert.Equal(t, "prophet", req.Model)
+	assert.Equal(t, "cpu_usage", req.MetricName)
+	assert.Equal(t, int64(60), req.Step)
+	assert.Equal(t, 61, len(req.History[0].Samples))
+
+	assert.NoError(t, p.DeleteQuery(namer, "test"))
+	assert.Eventually(t, func() bool {
+		status, _ := p.QueryPredictionStatus(ctx, namer)
+		return status == prediction.StatusUnknown
+	}, time.Second, 10*time.Millisecond)
+}
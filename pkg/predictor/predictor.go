@@ -9,9 +9,11 @@ import (
 	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
 
 	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
 	predconf "github.com/gocrane/crane/pkg/prediction/config"
 	"github.com/gocrane/crane/pkg/prediction/dsp"
 	"github.com/gocrane/crane/pkg/prediction/percentile"
+	"github.com/gocrane/crane/pkg/prediction/remote"
 	"github.com/gocrane/crane/pkg/providers"
 )
 
@@ -25,19 +27,33 @@ type AlgorithmDataProviders struct {
 type Config struct {
 	DataProviders AlgorithmDataProviders
 	ModelConfig   predconf.AlgorithmModelConfig
+	// RemoteConfig is only used by the remote predictor
+	RemoteConfig predconf.RemoteConfig
+	// CheckpointStore is used by the percentile and dsp predictors to save and restore models, nil to disable it
+	CheckpointStore checkpoint.Store
 }
 
 // DefaultPredictorsConfig will use all datasources you for real time and history provider. data proxy will select the first available.
 // Now, for RealTimeProvider if you specified metricserver in command args, it is [metricserver,prom] in order, if not, it is [prom]. for HistoryProvider is [prom]
-func DefaultPredictorsConfig(modelConfig predconf.AlgorithmModelConfig) map[predictionapi.AlgorithmType]Config {
+// The remote predictor is enabled only if the address of the model server is specified.
+func DefaultPredictorsConfig(modelConfig predconf.AlgorithmModelConfig, remoteConfig predconf.RemoteConfig, store checkpoint.Store) map[predictionapi.AlgorithmType]Config {
 	configs := map[predictionapi.AlgorithmType]Config{
 		predictionapi.AlgorithmTypeDSP: {
-			ModelConfig: modelConfig,
+			ModelConfig:     modelConfig,
+			CheckpointStore: store,
 		},
 		predictionapi.AlgorithmTypePercentile: {
-			DataProviders: AlgorithmDataProviders{},
+			DataProviders:   AlgorithmDataProviders{},
+			ModelConfig:     modelConfig,
+			CheckpointStore: store,
 		},
 	}
+	if len(remoteConfig.Address) != 0 {
+		configs[remote.AlgorithmTypeRemote] = Config{
+			ModelConfig:  modelConfig,
+			RemoteConfig: remoteConfig,
+		}
+	}
 	return configs
 }
 
@@ -104,15 +120,20 @@ func NewManager(realtimeProviders map[providers.DataSourceType]providers.RealTim
 
 		switch algo {
 		case predictionapi.AlgorithmTypePercentile:
-			pctPredictor := percentile.NewPrediction(algorithmRealTimeProxy, algorithmHistoryProxy)
+			pctPredictor := percentile.NewPrediction(algorithmRealTimeProxy, algorithmHistoryProxy, predictorConf.ModelConfig, predictorConf.CheckpointStore)
 			m.predictors[algo] = pctPredictor
 			m.historyDataProxys[algo] = algorithmHistoryProxy
 			m.realTimeDataProxys[algo] = algorithmRealTimeProxy
 		case predictionapi.AlgorithmTypeDSP:
-			dspPredictor := dsp.NewPrediction(algorithmRealTimeProxy, algorithmHistoryProxy, predictorConf.ModelConfig)
+			dspPredictor := dsp.NewPrediction(algorithmRealTimeProxy, algorithmHistoryProxy, predictorConf.ModelConfig, predictorConf.CheckpointStore)
 			m.predictors[algo] = dspPredictor
 			m.historyDataProxys[algo] = algorithmHistoryProxy
 			m.realTimeDataProxys[algo] = algorithmRealTimeProxy
+		case remote.AlgorithmTypeRemote:
+			remotePredictor := remote.NewPrediction(algorithmRealTimeProxy, algorithmHistoryProxy, predictorConf.ModelConfig, predictorConf.RemoteConfig)
+			m.predictors[algo] = remotePredictor
+			m.historyDataProxys[algo] = algorithmHistoryProxy
+			m.realTimeDataProxys[algo] = algorithmRealTimeProxy
 		default:
 			klog.Errorf("Unknown predictor %v", algo)
 			continue
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"time"
 
 	"github.com/gocrane/crane/pkg/common"
@@ -25,3 +26,9 @@ type History interface {
 	// QueryTimeSeries returns the time series that meet thw given metricNamer.
 	QueryTimeSeries(metricNamer metricnaming.MetricNamer, startTime time.Time, endTime time.Time, step time.Duration) ([]*common.TimeSeries, error)
 }
+
+// ContextHistory is a History whose query is able to be canceled by context.
+type ContextHistory interface {
+	// QueryTimeSeriesWithContext returns the time series that meet the given metricNamer, the query is aborted once ctx is done.
+	QueryTimeSeriesWithContext(ctx context.Context, metricNamer metricnaming.MetricNamer, startTime time.Time, endTime time.Time, step time.Duration) ([]*common.TimeSeries, error)
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/gocrane/crane/pkg/providers"
 )
 
+var _ providers.ContextHistory = &prom{}
+
 type prom struct {
 	ctx    *context
 	config *providers.PromConfig
@@ -38,6 +40,10 @@ func NewProvider(config *providers.PromConfig) (Provider, error) {
 }
 
 func (p *prom) QueryTimeSeries(namer metricnaming.MetricNamer, startTime time.Time, endTime time.Time, step time.Duration) ([]*common.TimeSeries, error) {
+	return p.QueryTimeSeriesWithContext(gocontext.Background(), namer, startTime, endTime, step)
+}
+
+func (p *prom) QueryTimeSeriesWithContext(ctx gocontext.Context, namer metricnaming.MetricNamer, startTime time.Time, endTime time.Time, step time.Duration) ([]*common.TimeSeries, error) {
 	promBuilder := namer.QueryBuilder().Builder(metricquery.PrometheusMetricSource)
 	promQuery, err := promBuilder.BuildQuery()
 	if err != nil {
@@ -45,7 +51,7 @@ func (p *prom) QueryTimeSeries(namer metricnaming.MetricNamer, startTime time.Ti
 		return nil, err
 	}
 	klog.V(6).Infof("QueryTimeSeries metricNamer %v, timeout: %v, query: %v", namer.BuildUniqueKey(), p.config.Timeout, promQuery.Prometheus.Query)
-	timeoutCtx, cancelFunc := gocontext.WithTimeout(gocontext.Background(), p.config.Timeout)
+	timeoutCtx, cancelFunc := gocontext.WithTimeout(ctx, p.config.Timeout)
 	defer cancelFunc()
 	timeSeries, err := p.ctx.QueryRangeSync(timeoutCtx, promQuery.Prometheus.Query, startTime, endTime, step)
 	if err != nil {
@@ -39,6 +39,12 @@ type ProposedRecommendation struct {
 
 	// ResourceRequest is the proposed recommendation for type Resource
 	ResourceRequest *ResourceRequestRecommendation `json:"resourceRequest,omitempty"`
+
+	// StorageRequest is the proposed recommendation for type Storage
+	StorageRequest *StorageRequestRecommendation `json:"storageRequest,omitempty"`
+
+	// NodePool is the proposed recommendation for type NodePool
+	NodePool *NodePoolRecommendation `json:"nodePool,omitempty"`
 }
 
 type ReplicasRecommendation struct {
@@ -46,10 +52,13 @@ type ReplicasRecommendation struct {
 }
 
 type EffectiveHorizontalPodAutoscalerRecommendation struct {
-	MinReplicas *int32                     `json:"minReplicas,omitempty"`
-	MaxReplicas *int32                     `json:"maxReplicas,omitempty"`
-	Metrics     []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
-	Prediction  *autoscalingapi.Prediction `json:"prediction,omitempty"`
+	MinReplicas *int32                                         `json:"minReplicas,omitempty"`
+	MaxReplicas *int32                                         `json:"maxReplicas,omitempty"`
+	Metrics     []autoscalingv2.MetricSpec                     `json:"metrics,omitempty"`
+	Prediction  *autoscalingapi.Prediction                     `json:"prediction,omitempty"`
+	Behavior    *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+	// Annotations are the metric queries of the external metrics
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type ResourceRequestRecommendation struct {
@@ -61,4 +70,45 @@ type ContainerRecommendation struct {
 	Target        ResourceList `json:"target,omitempty"`
 }
 
+type StorageRequestRecommendation struct {
+	VolumeClaimTemplates []VolumeClaimTemplateRecommendation `json:"volumeClaimTemplates,omitempty"`
+}
+
+type VolumeClaimTemplateRecommendation struct {
+	Name string `json:"name,omitempty"`
+	// Target is the proposed storage request of the volume claim template
+	Target string `json:"target,omitempty"`
+	// PeakUsage is the peak used bytes of the volumes in history and prediction
+	PeakUsage string `json:"peakUsage,omitempty"`
+	// PersistentVolumeClaims are the bound pvcs of the volume claim template whose storage request is not the proposed one
+	PersistentVolumeClaims []PersistentVolumeClaimRecommendation `json:"persistentVolumeClaims,omitempty"`
+}
+
+type PersistentVolumeClaimRecommendation struct {
+	Name    string `json:"name,omitempty"`
+	Current string `json:"current,omitempty"`
+	Target  string `json:"target,omitempty"`
+}
+
+type NodePoolRecommendation struct {
+	// RequestedCPU, RequestedMemory, UsedCPU and UsedMemory are the ratios of the allocatable resources of the node pool
+	RequestedCPU    string        `json:"requestedCPU,omitempty"`
+	RequestedMemory string        `json:"requestedMemory,omitempty"`
+	UsedCPU         string        `json:"usedCPU,omitempty"`
+	UsedMemory      string        `json:"usedMemory,omitempty"`
+	Current         *NodePoolPlan `json:"current,omitempty"`
+	Proposed        *NodePoolPlan `json:"proposed,omitempty"`
+}
+
+// NodePoolPlan is the number of nodes of each instance type in a node pool
+type NodePoolPlan struct {
+	Nodes       []NodeCount `json:"nodes"`
+	MonthlyCost float64     `json:"monthlyCost"`
+}
+
+type NodeCount struct {
+	InstanceType string `json:"instanceType"`
+	Count        int    `json:"count"`
+}
+
 type ResourceList map[corev1.ResourceName]string
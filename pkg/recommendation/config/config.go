@@ -66,11 +66,16 @@ func GetRecommendersFromConfiguration(file string) (map[string]apis.Recommender,
 		klog.Errorf("load recommender configuration failed, %v", err)
 		return nil, err
 	}
+	return GetRecommenders(config), nil
+}
+
+// GetRecommenders returns the recommenders in configuration keyed by name.
+func GetRecommenders(config *apis.RecommenderConfiguration) map[string]apis.Recommender {
 	recommenders := make(map[string]apis.Recommender, len(config.Recommenders))
 	for _, recommender := range config.Recommenders {
 		recommenders[recommender.Name] = recommender
 	}
-	return recommenders, nil
+	return recommenders
 }
 
 func GetKeysOfMap(m map[string]string) (keys []string) {
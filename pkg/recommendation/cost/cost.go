@@ -0,0 +1,235 @@
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	analysisapi "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/recommend/types"
+	"github.com/gocrane/crane/pkg/recommendation/recommender"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+)
+
+const (
+	DefaultInstanceTypeLabel = "node.kubernetes.io/instance-type"
+	betaInstanceTypeLabel    = "beta.kubernetes.io/instance-type"
+
+	gib = 1024 * 1024 * 1024
+)
+
+// workloadPatch is the part of workload spec in the recommended info and current info of recommendations
+type workloadPatch struct {
+	Spec struct {
+		Replicas *int32 `json:"replicas,omitempty"`
+		Template struct {
+			Spec struct {
+				Containers []corev1.Container `json:"containers,omitempty"`
+			} `json:"spec,omitempty"`
+		} `json:"template,omitempty"`
+	} `json:"spec,omitempty"`
+}
+
+// EstimateMonthlyCostDelta estimates the monthly cost delta if the recommendation is adopted, it is negative if the
+// recommendation saves cost. It returns false if the cost of the recommendation can not be estimated.
+func EstimateMonthlyCostDelta(recommendation *analysisapi.Recommendation, target *unstructured.Unstructured, pricing *apis.PricingModel) (float64, bool, error) {
+	if pricing == nil {
+		return 0, false, nil
+	}
+
+	switch recommendation.Status.Action {
+	case "Delete":
+		switch target.GetKind() {
+		case "Node":
+			var node corev1.Node
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(target.Object, &node); err != nil {
+				return 0, false, err
+			}
+			return -nodePrice(&node, pricing), true, nil
+		case "PersistentVolume":
+			var pv corev1.PersistentVolume
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(target.Object, &pv); err != nil {
+				return 0, false, err
+			}
+			storage := pv.Spec.Capacity[corev1.ResourceStorage]
+			return -storage.AsApproximateFloat64() / gib * pricing.Disk, true, nil
+		}
+	case "Patch", "None":
+		if len(recommendation.Status.RecommendedInfo) == 0 || len(recommendation.Status.CurrentInfo) == 0 {
+			return 0, false, nil
+		}
+		if recommendation.Spec.Type == analysisapi.AnalysisType(recommender.NodePoolRecommender) {
+			// the plans of node pools are priced by the instance type catalog of the recommender
+			var recommended, current types.NodePoolPlan
+			if err := json.Unmarshal([]byte(recommendation.Status.RecommendedInfo), &recommended); err != nil {
+				return 0, false, fmt.Errorf("unmarshal recommended info failed: %v", err)
+			}
+			if err := json.Unmarshal([]byte(recommendation.Status.CurrentInfo), &current); err != nil {
+				return 0, false, fmt.Errorf("unmarshal current info failed: %v", err)
+			}
+			return recommended.MonthlyCost - current.MonthlyCost, true, nil
+		}
+
+		if recommendation.Spec.Type == analysisapi.AnalysisType(recommender.StorageRecommender) {
+			// the storage recommendations are the patches of the bound pvcs of statefulset
+			var recommended, current []corev1.PersistentVolumeClaim
+			if err := json.Unmarshal([]byte(recommendation.Status.RecommendedInfo), &recommended); err != nil {
+				return 0, false, fmt.Errorf("unmarshal recommended info failed: %v", err)
+			}
+			if err := json.Unmarshal([]byte(recommendation.Status.CurrentInfo), &current); err != nil {
+				return 0, false, fmt.Errorf("unmarshal current info failed: %v", err)
+			}
+			return persistentVolumeClaimsPrice(recommended, pricing) - persistentVolumeClaimsPrice(current, pricing), true, nil
+		}
+
+		var recommended, current, workload workloadPatch
+		if err := json.Unmarshal([]byte(recommendation.Status.RecommendedInfo), &recommended); err != nil {
+			return 0, false, fmt.Errorf("unmarshal recommended info failed: %v", err)
+		}
+		if err := json.Unmarshal([]byte(recommendation.Status.CurrentInfo), &current); err != nil {
+			return 0, false, fmt.Errorf("unmarshal current info failed: %v", err)
+		}
+		targetBytes, err := json.Marshal(target.Object)
+		if err != nil {
+			return 0, false, err
+		}
+		if err = json.Unmarshal(targetBytes, &workload); err != nil {
+			return 0, false, fmt.Errorf("unmarshal target failed: %v", err)
+		}
+
+		switch recommendation.Spec.Type {
+		case analysisapi.AnalysisTypeResource:
+			delta := containersPrice(recommended.Spec.Template.Spec.Containers, pricing) - containersPrice(current.Spec.Template.Spec.Containers, pricing)
+			return delta * float64(replicasOf(target)), true, nil
+		case analysisapi.AnalysisTypeReplicas:
+			if recommended.Spec.Replicas == nil || current.Spec.Replicas == nil {
+				return 0, false, nil
+			}
+			delta := float64(*recommended.Spec.Replicas - *current.Spec.Replicas)
+			return delta * containersPrice(workload.Spec.Template.Spec.Containers, pricing), true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// SetMonthlyCostDelta records the monthly cost delta of the recommendation in annotation
+func SetMonthlyCostDelta(recommendation *analysisapi.Recommendation, delta float64) {
+	if recommendation.Annotations == nil {
+		recommendation.Annotations = map[string]string{}
+	}
+	recommendation.Annotations[known.MonthlyCostDeltaAnnotation] = strconv.FormatFloat(delta, 'f', 2, 64)
+}
+
+// GetMonthlyCostDelta returns the monthly cost delta recorded in annotation
+func GetMonthlyCostDelta(recommendation *analysisapi.Recommendation) (float64, bool) {
+	value, ok := recommendation.Annotations[known.MonthlyCostDeltaAnnotation]
+	if !ok {
+		return 0, false
+	}
+	delta, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return delta, true
+}
+
+// Savings is the estimated monthly savings of the recommendations for targets in a namespace, which are created
+// by a RecommendationRule.
+type Savings struct {
+	Namespace          string  `json:"namespace"`
+	RecommendationRule string  `json:"recommendationRule"`
+	Recommendations    int     `json:"recommendations"`
+	MonthlySavings     float64 `json:"monthlySavings"`
+}
+
+// AggregateSavings sums up the monthly savings of recommendations per target namespace and RecommendationRule
+func AggregateSavings(recommendations []analysisapi.Recommendation) []Savings {
+	type key struct {
+		namespace string
+		rule      string
+	}
+	savingsMap := map[key]*Savings{}
+	for i := range recommendations {
+		delta, ok := GetMonthlyCostDelta(&recommendations[i])
+		if !ok {
+			continue
+		}
+		k := key{
+			namespace: recommendations[i].Spec.TargetRef.Namespace,
+			rule:      recommendations[i].Labels[known.RecommendationRuleNameLabel],
+		}
+		savings, ok := savingsMap[k]
+		if !ok {
+			savings = &Savings{Namespace: k.namespace, RecommendationRule: k.rule}
+			savingsMap[k] = savings
+		}
+		savings.Recommendations++
+		savings.MonthlySavings -= delta
+	}
+
+	result := make([]Savings, 0, len(savingsMap))
+	for _, savings := range savingsMap {
+		result = append(result, *savings)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].RecommendationRule < result[j].RecommendationRule
+	})
+	return result
+}
+
+func containersPrice(containers []corev1.Container, pricing *apis.PricingModel) float64 {
+	var price float64
+	for _, container := range containers {
+		price += resourcesPrice(container.Resources.Requests, pricing)
+	}
+	return price
+}
+
+func resourcesPrice(resources corev1.ResourceList, pricing *apis.PricingModel) float64 {
+	cpu, memory := resources[corev1.ResourceCPU], resources[corev1.ResourceMemory]
+	return cpu.AsApproximateFloat64()*pricing.CPU + memory.AsApproximateFloat64()/gib*pricing.Memory
+}
+
+func persistentVolumeClaimsPrice(claims []corev1.PersistentVolumeClaim, pricing *apis.PricingModel) float64 {
+	var price float64
+	for _, claim := range claims {
+		storage := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		price += storage.AsApproximateFloat64() / gib * pricing.Disk
+	}
+	return price
+}
+
+func nodePrice(node *corev1.Node, pricing *apis.PricingModel) float64 {
+	label := pricing.InstanceTypeLabel
+	if len(label) == 0 {
+		label = DefaultInstanceTypeLabel
+	}
+	for _, l := range []string{label, betaInstanceTypeLabel} {
+		if price, ok := pricing.InstanceTypes[node.Labels[l]]; ok {
+			return price
+		}
+	}
+	return resourcesPrice(node.Status.Capacity, pricing)
+}
+
+// replicasOf returns the number of pods of the workload
+func replicasOf(target *unstructured.Unstructured) int64 {
+	if replicas, found, err := unstructured.NestedInt64(target.Object, "spec", "replicas"); found && err == nil {
+		return replicas
+	}
+	if desired, found, err := unstructured.NestedInt64(target.Object, "status", "desiredNumberScheduled"); found && err == nil {
+		return desired
+	}
+	return 1
+}
@@ -0,0 +1,169 @@
+package cost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	analysisapi "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+)
+
+func TestEstimateMonthlyCostDelta(t *testing.T) {
+	pricing := &apis.PricingModel{
+		CPU:           20,
+		Memory:        3,
+		Disk:          0.1,
+		InstanceTypes: map[string]float64{"S5.LARGE8": 100},
+	}
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":      "app",
+							"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "1", "memory": "2Gi"}},
+						},
+					},
+				},
+			},
+		},
+	}}
+	node := func(labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Node",
+			"metadata":   map[string]interface{}{"name": "node", "labels": labels},
+			"status":     map[string]interface{}{"capacity": map[string]interface{}{"cpu": "4", "memory": "8Gi"}},
+		}}
+	}
+	pv := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "PersistentVolume",
+		"spec":       map[string]interface{}{"capacity": map[string]interface{}{"storage": "100Gi"}},
+	}}
+
+	tests := []struct {
+		name            string
+		recommendedType analysisapi.AnalysisType
+		action          string
+		recommendedInfo string
+		currentInfo     string
+		target          *unstructured.Unstructured
+		want            float64
+		wantOK          bool
+	}{
+		{
+			name:            "resource",
+			recommendedType: analysisapi.AnalysisTypeResource,
+			action:          "Patch",
+			recommendedInfo: `{"spec":{"template":{"spec":{"containers":[{"name":"app","resources":{"requests":{"cpu":"500m","memory":"1Gi"}}}]}}}}`,
+			currentInfo:     `{"spec":{"template":{"spec":{"containers":[{"name":"app","resources":{"requests":{"cpu":"1","memory":"2Gi"}}}]}}}}`,
+			target:          deployment,
+			want:            -(0.5*20 + 1*3) * 3,
+			wantOK:          true,
+		},
+		{
+			name:            "replicas",
+			recommendedType: analysisapi.AnalysisTypeReplicas,
+			action:          "Patch",
+			recommendedInfo: `{"spec":{"replicas":1}}`,
+			currentInfo:     `{"spec":{"replicas":3}}`,
+			target:          deployment,
+			want:            -2 * (20 + 2*3),
+			wantOK:          true,
+		},
+		{
+			name:            "storage",
+			recommendedType: analysisapi.AnalysisType("Storage"),
+			action:          "Patch",
+			recommendedInfo: `[{"metadata":{"name":"data-web-0"},"spec":{"resources":{"requests":{"storage":"20Gi"}}}},{"metadata":{"name":"data-web-1"},"spec":{"resources":{"requests":{"storage":"20Gi"}}}}]`,
+			currentInfo:     `[{"metadata":{"name":"data-web-0"},"spec":{"resources":{"requests":{"storage":"10Gi"}}}},{"metadata":{"name":"data-web-1"},"spec":{"resources":{"requests":{"storage":"15Gi"}}}}]`,
+			target:          deployment,
+			want:            15 * 0.1,
+			wantOK:          true,
+		},
+		{
+			name:            "node pool",
+			recommendedType: analysisapi.AnalysisType("NodePool"),
+			action:          "Patch",
+			recommendedInfo: `{"nodes":[{"instanceType":"S5.LARGE8","count":2}],"monthlyCost":200}`,
+			currentInfo:     `{"nodes":[{"instanceType":"S5.LARGE8","count":3}],"monthlyCost":300}`,
+			target:          &unstructured.Unstructured{Object: map[string]interface{}{"kind": "MachineDeployment"}},
+			want:            -100,
+			wantOK:          true,
+		},
+		{
+			name:   "idle node of known instance type",
+			action: "Delete",
+			target: node(map[string]interface{}{DefaultInstanceTypeLabel: "S5.LARGE8"}),
+			want:   -100,
+			wantOK: true,
+		},
+		{
+			name:   "idle node of unknown instance type",
+			action: "Delete",
+			target: node(map[string]interface{}{DefaultInstanceTypeLabel: "S5.XLARGE16"}),
+			want:   -(4*20 + 8*3),
+			wantOK: true,
+		},
+		{
+			name:   "orphan volume",
+			action: "Delete",
+			target: pv,
+			want:   -10,
+			wantOK: true,
+		},
+		{
+			name:            "hpa",
+			recommendedType: analysisapi.AnalysisType("HPA"),
+			action:          "Create",
+			target:          deployment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recommendation := &analysisapi.Recommendation{}
+			recommendation.Spec.Type = tt.recommendedType
+			recommendation.Status.Action = tt.action
+			recommendation.Status.RecommendedInfo = tt.recommendedInfo
+			recommendation.Status.CurrentInfo = tt.currentInfo
+
+			delta, ok, err := EstimateMonthlyCostDelta(recommendation, tt.target, pricing)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.InDelta(t, tt.want, delta, 1e-9)
+		})
+	}
+}
+
+func TestAggregateSavings(t *testing.T) {
+	recommendation := func(namespace, rule string, delta float64) analysisapi.Recommendation {
+		r := analysisapi.Recommendation{}
+		r.Spec.TargetRef.Namespace = namespace
+		r.Labels = map[string]string{known.RecommendationRuleNameLabel: rule}
+		SetMonthlyCostDelta(&r, delta)
+		return r
+	}
+	recommendations := []analysisapi.Recommendation{
+		recommendation("b", "rule", -10),
+		recommendation("a", "rule", -5),
+		recommendation("a", "rule", 2),
+		recommendation("a", "other", -1),
+		{},
+	}
+
+	assert.Equal(t, []Savings{
+		{Namespace: "a", RecommendationRule: "other", Recommendations: 1, MonthlySavings: 1},
+		{Namespace: "a", RecommendationRule: "rule", Recommendations: 2, MonthlySavings: 3},
+		{Namespace: "b", RecommendationRule: "rule", Recommendations: 1, MonthlySavings: 10},
+	}, AggregateSavings(recommendations))
+}
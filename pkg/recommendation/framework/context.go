@@ -30,6 +30,8 @@ import (
 )
 
 type RecommendationContext struct {
+	// Context is canceled once the deadline of the recommendation rule is exceeded or the rule is deleted. The
+	// recommendation should stop executing as soon as possible.
 	Context context.Context
 	// The kubernetes resource object reference of recommendation flow.
 	Identity ObjectIdentity
@@ -45,8 +47,6 @@ type RecommendationContext struct {
 	DataProviders map[providers.DataSourceType]providers.History
 	// Recommendation store result of recommendation flow.
 	Recommendation *v1alpha1.Recommendation
-	// When cancel channel accept signal indicates that the context has been canceled. The recommendation should stop executing as soon as possible.
-	// CancelCh <-chan struct{}
 	// RecommendationRule for the context
 	RecommendationRule *v1alpha1.RecommendationRule
 	// metrics namer for datasource provider
@@ -76,20 +76,25 @@ type RecommendationContext struct {
 }
 
 func NewRecommendationContext(context context.Context, identity ObjectIdentity, recommendationRule *v1alpha1.RecommendationRule, predictorMgr predictormgr.Manager, dataProviders map[providers.DataSourceType]providers.History, recommendation *v1alpha1.Recommendation, client client.Client, scaleClient scale.ScalesGetter, oomRecorder oom.Recorder) RecommendationContext {
+	// queries of data providers are aborted once the context is canceled
+	cancelableProviders := make(map[providers.DataSourceType]providers.History, len(dataProviders))
+	for dataSourceType, provider := range dataProviders {
+		cancelableProviders[dataSourceType] = NewCancelableHistory(context, provider)
+	}
+
 	return RecommendationContext{
 		Context:            context,
 		Identity:           identity,
 		Object:             &identity.Object,
 		inputValues:        make(map[string][]*common.TimeSeries),
 		PredictorMgr:       predictorMgr,
-		DataProviders:      dataProviders,
+		DataProviders:      cancelableProviders,
 		RecommendationRule: recommendationRule,
 		Recommendation:     recommendation,
 		Client:             client,
 		RestMapper:         client.RESTMapper(),
 		ScaleClient:        scaleClient,
 		OOMRecorder:        oomRecorder,
-		//CancelCh:       context.Done(),
 	}
 }
 
@@ -114,6 +119,17 @@ func (ctx *RecommendationContext) InputValue(key string) []*common.TimeSeries {
 	return ctx.inputValues[key]
 }
 
+// InputValues returns a copy of all time series collected in the prepare phase, keyed by input name.
+func (ctx *RecommendationContext) InputValues() map[string][]*common.TimeSeries {
+	ctx.inputValuesMutex.RLock()
+	defer ctx.inputValuesMutex.RUnlock()
+	values := make(map[string][]*common.TimeSeries, len(ctx.inputValues))
+	for key, timeSeries := range ctx.inputValues {
+		values[key] = timeSeries
+	}
+	return values
+}
+
 func (ctx *RecommendationContext) String() string {
 	return fmt.Sprintf("RecommendationRule(%s) Target(%s/%s)", ctx.RecommendationRule.Name, ctx.Object.GetNamespace(), ctx.Object.GetName())
 }
@@ -131,14 +147,19 @@ func (id ObjectIdentity) GetObjectReference() corev1.ObjectReference {
 	return corev1.ObjectReference{Kind: id.Kind, APIVersion: id.APIVersion, Namespace: id.Namespace, Name: id.Name}
 }
 
-//func (ctx *RecommendationContext) Canceled() bool {
-//	select {
-//	case <-ctx.CancelCh:
-//		return true
-//	default:
-//		return false
-//	}
-//}
+// Canceled returns whether the context has been canceled or its deadline is exceeded.
+func (ctx *RecommendationContext) Canceled() bool {
+	if ctx.Context == nil {
+		return false
+	}
+
+	select {
+	case <-ctx.Context.Done():
+		return true
+	default:
+		return false
+	}
+}
 
 func ObjectConversion(object interface{}, target interface{}) error {
 	bytes, err := json.Marshal(object)
@@ -0,0 +1,53 @@
+package framework
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/providers"
+)
+
+// cancelableHistory is a history provider whose queries are aborted once the context is done.
+type cancelableHistory struct {
+	ctx     context.Context
+	history providers.History
+}
+
+// NewCancelableHistory returns a history provider whose queries are aborted once ctx is done.
+func NewCancelableHistory(ctx context.Context, history providers.History) providers.History {
+	if ctx == nil {
+		return history
+	}
+	return &cancelableHistory{ctx: ctx, history: history}
+}
+
+type queryResult struct {
+	timeSeries []*common.TimeSeries
+	err        error
+}
+
+func (h *cancelableHistory) QueryTimeSeries(metricNamer metricnaming.MetricNamer, startTime time.Time, endTime time.Time, step time.Duration) ([]*common.TimeSeries, error) {
+	if err := h.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if contextHistory, ok := h.history.(providers.ContextHistory); ok {
+		return contextHistory.QueryTimeSeriesWithContext(h.ctx, metricNamer, startTime, endTime, step)
+	}
+
+	// the query is not able to be canceled, stop waiting for it once the context is done
+	resultCh := make(chan queryResult, 1)
+	go func() {
+		timeSeries, err := h.history.QueryTimeSeries(metricNamer, startTime, endTime, step)
+		resultCh <- queryResult{timeSeries: timeSeries, err: err}
+	}()
+
+	select {
+	case result := <-resultCh:
+		return result.timeSeries, result.err
+	case <-h.ctx.Done():
+		return nil, h.ctx.Err()
+	}
+}
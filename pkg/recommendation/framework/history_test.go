@@ -0,0 +1,43 @@
+package framework
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+)
+
+// blockingHistory blocks the query until release is closed
+type blockingHistory struct {
+	release chan struct{}
+}
+
+func (h *blockingHistory) QueryTimeSeries(metricnaming.MetricNamer, time.Time, time.Time, time.Duration) ([]*common.TimeSeries, error) {
+	<-h.release
+	return []*common.TimeSeries{{}}, nil
+}
+
+func TestCancelableHistory(t *testing.T) {
+	history := &blockingHistory{release: make(chan struct{})}
+	defer close(history.release)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := NewCancelableHistory(ctx, history).QueryTimeSeries(nil, time.Now(), time.Now(), time.Minute)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the query is not sent once the context is done
+	_, err = NewCancelableHistory(ctx, &blockingHistory{}).QueryTimeSeries(nil, time.Now(), time.Now(), time.Minute)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	released := &blockingHistory{release: make(chan struct{})}
+	close(released.release)
+	tsList, err := NewCancelableHistory(context.TODO(), released).QueryTimeSeries(nil, time.Now(), time.Now(), time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, tsList, 1)
+}
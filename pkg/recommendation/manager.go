@@ -1,22 +1,30 @@
 package recommendation
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"k8s.io/klog/v2"
 
 	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/metrics"
 	"github.com/gocrane/crane/pkg/recommendation/config"
 	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/recommendation/plugin"
 	"github.com/gocrane/crane/pkg/recommendation/recommender"
 	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
 	_ "github.com/gocrane/crane/pkg/recommendation/recommender/hpa"
 	_ "github.com/gocrane/crane/pkg/recommendation/recommender/idlenode"
+	_ "github.com/gocrane/crane/pkg/recommendation/recommender/nodepool"
 	_ "github.com/gocrane/crane/pkg/recommendation/recommender/replicas"
 	_ "github.com/gocrane/crane/pkg/recommendation/recommender/resource"
 	_ "github.com/gocrane/crane/pkg/recommendation/recommender/service"
+	_ "github.com/gocrane/crane/pkg/recommendation/recommender/storage"
 	_ "github.com/gocrane/crane/pkg/recommendation/recommender/volume"
 )
 
@@ -25,6 +33,8 @@ type RecommenderManager interface {
 	GetRecommender(recommenderName string) (recommender.Recommender, error)
 	// GetRecommenderWithRule return a registered recommender, its config merged with recommendationRule
 	GetRecommenderWithRule(recommenderName string, recommendationRule analysisv1alph1.RecommendationRule) (recommender.Recommender, error)
+	// GetPricingModel return the pricing model in configuration, nil if not configured
+	GetPricingModel() *apis.PricingModel
 }
 
 func NewRecommenderManager(recommendationConfiguration string) RecommenderManager {
@@ -51,6 +61,8 @@ type manager struct {
 
 	lock               sync.Mutex
 	recommenderConfigs map[string]apis.Recommender
+	recommenderPlugins []apis.RecommenderPlugin
+	pricing            *apis.PricingModel
 }
 
 func (m *manager) GetRecommender(recommenderName string) (recommender.Recommender, error) {
@@ -62,12 +74,24 @@ func (m *manager) GetRecommenderWithRule(recommenderName string, recommendationR
 	defer m.lock.Unlock()
 
 	if recommenderConfig, ok := m.recommenderConfigs[recommenderName]; ok {
-		return recommender.GetRecommenderProvider(recommenderName, recommenderConfig, recommendationRule)
+		r, err := recommender.GetRecommenderProvider(recommenderName, recommenderConfig, recommendationRule)
+		if err != nil {
+			return nil, err
+		}
+		// wrap the builtin recommender so that registered plugins are called at each phase.
+		return plugin.NewRecommender(r, m.recommenderPlugins)
 	}
 
 	return nil, fmt.Errorf("unknown recommender name: %s", recommenderName)
 }
 
+func (m *manager) GetPricingModel() *apis.PricingModel {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.pricing
+}
+
 func (m *manager) watchConfigFile() {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -119,75 +143,91 @@ func (m *manager) loadConfigFile() error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	recommenderConfigs, err := config.GetRecommendersFromConfiguration(m.recommendationConfiguration)
+	configuration, err := config.LoadRecommenderConfigFromFile(m.recommendationConfiguration)
 	if err != nil {
 		klog.ErrorS(err, "Failed to load recommendation config file", "file", m.recommendationConfiguration)
 		return err
 	}
-	m.recommenderConfigs = recommenderConfigs
+	m.recommenderConfigs = config.GetRecommenders(configuration)
+	m.recommenderPlugins = plugin.SortPlugins(configuration.RecommenderPlugins)
+	m.pricing = configuration.Pricing
 	klog.Info("Recommendation Config updated.")
 	return nil
 }
 
-func Run(ctx *framework.RecommendationContext, recommender recommender.Recommender) error {
-	klog.Infof("%s: start to run recommender %q.", ctx.String(), recommender.Name())
-
-	// 1. Filter phase
-	err := recommender.Filter(ctx)
-	if err != nil {
-		klog.Errorf("%s: recommender %q failed at filter phase: %v", ctx.String(), recommender.Name(), err)
-		return err
-	}
-
-	// 2. PrePrepare phase
-	err = recommender.CheckDataProviders(ctx)
-	if err != nil {
-		klog.Errorf("%s: recommender %q failed at prepare check data provider phase: %v", ctx.String(), recommender.Name(), err)
-		return err
-	}
+// phase is a phase of the recommendation flow
+type phase struct {
+	// name is the label of the phase in metrics
+	name string
+	// description is the phase in logs
+	description string
+	run         func(ctx *framework.RecommendationContext) error
+}
 
-	// 3. Prepare phase
-	err = recommender.CollectData(ctx)
-	if err != nil {
-		klog.Errorf("%s: recommender %q failed at prepare collect data phase: %v", ctx.String(), recommender.Name(), err)
-		return err
-	}
+const (
+	phaseResultSuccess          = "Success"
+	phaseResultError            = "Error"
+	phaseResultCanceled         = "Canceled"
+	phaseResultDeadlineExceeded = "DeadlineExceeded"
+)
 
-	// 4. PostPrepare phase
-	err = recommender.PostProcessing(ctx)
-	if err != nil {
-		klog.Errorf("%s: recommender %q failed at prepare data post processing phase: %v", ctx.String(), recommender.Name(), err)
-		return err
-	}
+func Run(ctx *framework.RecommendationContext, recommender recommender.Recommender) error {
+	klog.Infof("%s: start to run recommender %q.", ctx.String(), recommender.Name())
 
-	// 5. PreRecommend phase
-	err = recommender.PreRecommend(ctx)
-	if err != nil {
-		klog.Errorf("%s: recommender %q failed at pre commend phase: %v", ctx.String(), recommender.Name(), err)
-		return err
+	phases := []phase{
+		// 1. Filter phase
+		{name: "Filter", description: "filter", run: recommender.Filter},
+		// 2. PrePrepare phase
+		{name: "CheckDataProviders", description: "prepare check data provider", run: recommender.CheckDataProviders},
+		// 3. Prepare phase
+		{name: "CollectData", description: "prepare collect data", run: recommender.CollectData},
+		// 4. PostPrepare phase
+		{name: "PostProcessing", description: "prepare data post processing", run: recommender.PostProcessing},
+		// 5. PreRecommend phase
+		{name: "PreRecommend", description: "pre commend", run: recommender.PreRecommend},
+		// 6. Recommend phase
+		{name: "Recommend", description: "recommend", run: recommender.Recommend},
+		// 7. PostRecommend phase, add policy
+		{name: "Policy", description: "recommend policy", run: recommender.Policy},
+		// 8. Observe phase
+		{name: "Observe", description: "observe", run: recommender.Observe},
 	}
 
-	// 6. Recommend phase
-	err = recommender.Recommend(ctx)
-	if err != nil {
-		klog.Errorf("%s: recommender %q failed at recommend phase: %v", ctx.String(), recommender.Name(), err)
-		return err
-	}
+	for _, p := range phases {
+		// stop executing as soon as possible once the context is canceled
+		if ctx.Canceled() {
+			result := canceledResult(ctx)
+			metrics.RecommendationPhaseFailures.WithLabelValues(recommender.Name(), p.name, result).Inc()
+			klog.Errorf("%s: recommender %q is canceled before %s phase: %v", ctx.String(), recommender.Name(), p.description, ctx.Context.Err())
+			return fmt.Errorf("canceled before %s phase: %w", p.description, ctx.Context.Err())
+		}
 
-	// 7. PostRecommend phase, add policy
-	err = recommender.Policy(ctx)
-	if err != nil {
-		klog.Errorf("%s: recommender %q failed at recommend policy phase: %v", ctx.String(), recommender.Name(), err)
-		return err
-	}
+		start := time.Now()
+		err := p.run(ctx)
+		result := phaseResultSuccess
+		if err != nil {
+			result = phaseResultError
+			if ctx.Canceled() {
+				result = canceledResult(ctx)
+			}
+			metrics.RecommendationPhaseFailures.WithLabelValues(recommender.Name(), p.name, result).Inc()
+		}
+		metrics.RecommendationPhaseDuration.WithLabelValues(recommender.Name(), p.name, result).Observe(time.Since(start).Seconds())
 
-	// 8. Observe phase
-	err = recommender.Observe(ctx)
-	if err != nil {
-		klog.Errorf("%s: recommender %q failed at observe phase: %v", ctx.String(), recommender.Name(), err)
-		return err
+		if err != nil {
+			klog.Errorf("%s: recommender %q failed at %s phase: %v", ctx.String(), recommender.Name(), p.description, err)
+			return err
+		}
 	}
 
 	klog.Infof("%s: finish to run recommender %q.", ctx.String(), recommender.Name())
 	return nil
 }
+
+// canceledResult returns the result of a phase aborted by the canceled context
+func canceledResult(ctx *framework.RecommendationContext) string {
+	if errors.Is(ctx.Context.Err(), context.DeadlineExceeded) {
+		return phaseResultDeadlineExceeded
+	}
+	return phaseResultCanceled
+}
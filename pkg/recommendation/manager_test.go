@@ -0,0 +1,102 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+)
+
+// fakeRecommender records the phases it runs, and runs hook in the CollectData phase
+type fakeRecommender struct {
+	phases []string
+	hook   func(ctx *framework.RecommendationContext) error
+}
+
+func (f *fakeRecommender) Name() string { return "Fake" }
+
+func (f *fakeRecommender) record(phase string) error {
+	f.phases = append(f.phases, phase)
+	return nil
+}
+
+func (f *fakeRecommender) Filter(*framework.RecommendationContext) error { return f.record("Filter") }
+func (f *fakeRecommender) CheckDataProviders(*framework.RecommendationContext) error {
+	return f.record("CheckDataProviders")
+}
+func (f *fakeRecommender) CollectData(ctx *framework.RecommendationContext) error {
+	_ = f.record("CollectData")
+	if f.hook != nil {
+		return f.hook(ctx)
+	}
+	return nil
+}
+func (f *fakeRecommender) PostProcessing(*framework.RecommendationContext) error {
+	return f.record("PostProcessing")
+}
+func (f *fakeRecommender) PreRecommend(*framework.RecommendationContext) error {
+	return f.record("PreRecommend")
+}
+func (f *fakeRecommender) Recommend(*framework.RecommendationContext) error {
+	return f.record("Recommend")
+}
+func (f *fakeRecommender) Policy(*framework.RecommendationContext) error { return f.record("Policy") }
+func (f *fakeRecommender) Observe(*framework.RecommendationContext) error {
+	return f.record("Observe")
+}
+
+func newFakeContext(ctx context.Context) *framework.RecommendationContext {
+	return &framework.RecommendationContext{
+		Context:            ctx,
+		Object:             &unstructured.Unstructured{},
+		RecommendationRule: &analysisv1alph1.RecommendationRule{},
+	}
+}
+
+func TestRun(t *testing.T) {
+	allPhases := []string{"Filter", "CheckDataProviders", "CollectData", "PostProcessing", "PreRecommend", "Recommend", "Policy", "Observe"}
+
+	t.Run("success", func(t *testing.T) {
+		r := &fakeRecommender{}
+		assert.NoError(t, Run(newFakeContext(context.TODO()), r))
+		assert.Equal(t, allPhases, r.phases)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		r := &fakeRecommender{hook: func(ctx *framework.RecommendationContext) error {
+			return fmt.Errorf("no data")
+		}}
+		assert.EqualError(t, Run(newFakeContext(context.TODO()), r), "no data")
+		assert.Equal(t, allPhases[:3], r.phases)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		r := &fakeRecommender{hook: func(*framework.RecommendationContext) error {
+			cancel()
+			return nil
+		}}
+		err := Run(newFakeContext(ctx), r)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, allPhases[:3], r.phases)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), time.Millisecond)
+		defer cancel()
+		r := &fakeRecommender{hook: func(ctx *framework.RecommendationContext) error {
+			<-ctx.Context.Done()
+			return ctx.Context.Err()
+		}}
+		err := Run(newFakeContext(ctx), r)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, allPhases[:3], r.phases)
+	})
+}
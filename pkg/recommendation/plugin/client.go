@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+)
+
+const DefaultTimeout = 30 * time.Second
+
+// Client calls a recommender plugin server over http.
+type Client struct {
+	apis.RecommenderPlugin
+	httpClient *http.Client
+}
+
+// NewClient create a client for the recommender plugin, the timeout can be overridden by config key "timeout".
+func NewClient(plugin apis.RecommenderPlugin) (*Client, error) {
+	if len(plugin.ServerConfig.UrlPrefix) == 0 {
+		return nil, fmt.Errorf("recommender plugin %s has no urlPrefix", plugin.Name)
+	}
+
+	timeout := DefaultTimeout
+	if value, exists := plugin.Config["timeout"]; exists {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("recommender plugin %s has invalid timeout %q: %v", plugin.Name, value, err)
+		}
+		timeout = d
+	}
+
+	return &Client{
+		RecommenderPlugin: plugin,
+		httpClient:        &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Call posts the request to {urlPrefix}/{phase} and decodes the response.
+func (c *Client) Call(ctx context.Context, request *PhaseRequest) (*PhaseResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+	url := strings.TrimSuffix(c.ServerConfig.UrlPrefix, "/") + "/" + string(request.Phase)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("recommender plugin %s returned status %d: %s", c.Name, resp.StatusCode, string(respBody))
+	}
+
+	response := &PhaseResponse{}
+	if len(respBody) == 0 {
+		return response, nil
+	}
+	if err = json.Unmarshal(respBody, response); err != nil {
+		return nil, fmt.Errorf("recommender plugin %s returned invalid response: %v", c.Name, err)
+	}
+	return response, nil
+}
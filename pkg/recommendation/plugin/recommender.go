@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/recommendation/recommender"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+)
+
+var _ recommender.Recommender = &Recommender{}
+
+// Recommender wraps a builtin recommender and calls the plugin servers after each phase of it.
+type Recommender struct {
+	recommender.Recommender
+	clients []*Client
+}
+
+// SortPlugins sorts plugins by priority, plugin with higher priority is executed first.
+func SortPlugins(plugins []apis.RecommenderPlugin) []apis.RecommenderPlugin {
+	sorted := make([]apis.RecommenderPlugin, len(plugins))
+	copy(sorted, plugins)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	return sorted
+}
+
+// NewRecommender wraps the recommender with plugins, the plugins are executed in the order of priority.
+func NewRecommender(r recommender.Recommender, plugins []apis.RecommenderPlugin) (recommender.Recommender, error) {
+	if len(plugins) == 0 {
+		return r, nil
+	}
+
+	var clients []*Client
+	for _, p := range SortPlugins(plugins) {
+		client, err := NewClient(p)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+
+	return &Recommender{
+		Recommender: r,
+		clients:     clients,
+	}, nil
+}
+
+func (pr *Recommender) Filter(ctx *framework.RecommendationContext) error {
+	if err := pr.Recommender.Filter(ctx); err != nil {
+		return err
+	}
+	return pr.callPlugins(ctx, PhaseFilter)
+}
+
+func (pr *Recommender) PostProcessing(ctx *framework.RecommendationContext) error {
+	if err := pr.Recommender.PostProcessing(ctx); err != nil {
+		return err
+	}
+	return pr.callPlugins(ctx, PhasePrepare)
+}
+
+func (pr *Recommender) Recommend(ctx *framework.RecommendationContext) error {
+	if err := pr.Recommender.Recommend(ctx); err != nil {
+		return err
+	}
+	return pr.callPlugins(ctx, PhaseRecommend)
+}
+
+func (pr *Recommender) Policy(ctx *framework.RecommendationContext) error {
+	if err := pr.Recommender.Policy(ctx); err != nil {
+		return err
+	}
+	return pr.callPlugins(ctx, PhasePolicy)
+}
+
+func (pr *Recommender) Observe(ctx *framework.RecommendationContext) error {
+	if err := pr.Recommender.Observe(ctx); err != nil {
+		return err
+	}
+	return pr.callPlugins(ctx, PhaseObserve)
+}
+
+func (pr *Recommender) callPlugins(ctx *framework.RecommendationContext, phase Phase) error {
+	for _, client := range pr.clients {
+		request := pr.buildRequest(ctx, client, phase)
+		response, err := client.Call(ctx.Context, request)
+		if err != nil {
+			return fmt.Errorf("recommender plugin %s failed at %s phase: %v", client.Name, phase, err)
+		}
+		if len(response.Error) != 0 {
+			return fmt.Errorf("recommender plugin %s failed at %s phase: %s", client.Name, phase, response.Error)
+		}
+		if response.Status != nil && ctx.Recommendation != nil {
+			klog.V(4).Infof("Recommender plugin %s updated recommendation status of %s at %s phase.", client.Name, klog.KObj(ctx.Recommendation), phase)
+			ctx.Recommendation.Status = *response.Status
+		}
+	}
+	return nil
+}
+
+func (pr *Recommender) buildRequest(ctx *framework.RecommendationContext, client *Client, phase Phase) *PhaseRequest {
+	request := &PhaseRequest{
+		Phase:       phase,
+		Recommender: pr.Name(),
+		Target:      ctx.Identity.GetObjectReference(),
+		Config:      client.Config,
+		InputValues: ctx.InputValues(),
+	}
+	if ctx.RecommendationRule != nil {
+		request.RecommendationRule = ctx.RecommendationRule.Name
+	}
+	if ctx.Recommendation != nil {
+		request.Status = ctx.Recommendation.Status
+	}
+	return request
+}
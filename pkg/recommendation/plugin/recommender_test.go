@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/base"
+)
+
+func TestSortPlugins(t *testing.T) {
+	plugins := []apis.RecommenderPlugin{
+		{Name: "low", Priority: 1},
+		{Name: "high", Priority: 10},
+		{Name: "middle", Priority: 5},
+	}
+
+	sorted := SortPlugins(plugins)
+	want := []string{"high", "middle", "low"}
+	for i, p := range sorted {
+		if p.Name != want[i] {
+			t.Errorf("expect plugin %s at %d, got %s", want[i], i, p.Name)
+		}
+	}
+	if plugins[0].Name != "low" {
+		t.Errorf("SortPlugins should not modify the input slice")
+	}
+}
+
+func TestRecommenderCallPlugins(t *testing.T) {
+	var phases []Phase
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := PhaseRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		phases = append(phases, request.Phase)
+
+		response := PhaseResponse{}
+		if request.Phase == PhaseRecommend {
+			if len(request.InputValues["cpu"]) != 1 {
+				response.Error = "missing input values"
+			} else {
+				status := request.Status
+				status.RecommendedValue = "from-plugin"
+				response.Status = &status
+			}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	r, err := NewRecommender(base.NewBaseRecommender(apis.Recommender{}), []apis.RecommenderPlugin{
+		{Name: "test", ServerConfig: apis.ServerConfig{UrlPrefix: server.URL}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := framework.NewRecommendationContextForObserve(&analysisv1alph1.Recommendation{}, nil, nil)
+	ctx.Context = context.TODO()
+	ctx.AddInputValue("cpu", []*common.TimeSeries{{Samples: []common.Sample{{Value: 1, Timestamp: 1}}}})
+
+	if err = r.Recommend(&ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Observe(&ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if ctx.Recommendation.Status.RecommendedValue != "from-plugin" {
+		t.Errorf("expect recommended value updated by plugin, got %q", ctx.Recommendation.Status.RecommendedValue)
+	}
+	if len(phases) != 2 || phases[0] != PhaseRecommend || phases[1] != PhaseObserve {
+		t.Errorf("unexpected phases called: %v", phases)
+	}
+}
@@ -0,0 +1,48 @@
+package plugin
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+// Phase is the recommendation framework phase a plugin server is called at.
+type Phase string
+
+const (
+	PhaseFilter    Phase = "filter"
+	PhasePrepare   Phase = "prepare"
+	PhaseRecommend Phase = "recommend"
+	PhasePolicy    Phase = "policy"
+	PhaseObserve   Phase = "observe"
+)
+
+// PhaseRequest is the json body posted to {urlPrefix}/{phase} of a plugin server.
+type PhaseRequest struct {
+	// Phase that is being executed
+	Phase Phase `json:"phase"`
+	// Recommender is the name of the builtin recommender the plugin is attached to
+	Recommender string `json:"recommender"`
+	// RecommendationRule is the name of the rule that triggered the recommendation flow
+	RecommendationRule string `json:"recommendationRule,omitempty"`
+	// Target is the kubernetes object being recommended
+	Target corev1.ObjectReference `json:"target"`
+	// Config is the plugin config from the recommendation configuration
+	Config map[string]string `json:"config,omitempty"`
+	// InputValues are the time series collected in the prepare phase
+	InputValues map[string][]*common.TimeSeries `json:"inputValues,omitempty"`
+	// Status is the proposed recommendation status so far
+	Status analysisv1alph1.RecommendationStatus `json:"status"`
+}
+
+// PhaseResponse is the json body returned by a plugin server.
+type PhaseResponse struct {
+	// Status, if set, replaces the proposed recommendation status
+	// +optional
+	Status *analysisv1alph1.RecommendationStatus `json:"status,omitempty"`
+	// Error aborts the recommendation flow with the message when not empty
+	// +optional
+	Error string `json:"error,omitempty"`
+}
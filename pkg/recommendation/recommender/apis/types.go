@@ -14,6 +14,27 @@ type RecommenderConfiguration struct {
 
 	// Recommender Plugin list
 	RecommenderPlugins []RecommenderPlugin `json:"recommenderPlugins"`
+
+	// Pricing is used to estimate the monthly cost delta of recommendations
+	// +optional
+	Pricing *PricingModel `json:"pricing,omitempty"`
+}
+
+// PricingModel is the monthly price of resources
+type PricingModel struct {
+	// CPU is the monthly price of a vCPU
+	CPU float64 `json:"cpu,omitempty"`
+	// Memory is the monthly price of a GiB of memory
+	Memory float64 `json:"memory,omitempty"`
+	// Disk is the monthly price of a GiB of persistent volume
+	Disk float64 `json:"disk,omitempty"`
+	// InstanceTypeLabel is the node label of instance type, default is node.kubernetes.io/instance-type
+	// +optional
+	InstanceTypeLabel string `json:"instanceTypeLabel,omitempty"`
+	// InstanceTypes is the monthly price of a node keyed by instance type, the price of a node of other instance
+	// types is estimated by its cpu and memory capacity.
+	// +optional
+	InstanceTypes map[string]float64 `json:"instanceTypes,omitempty"`
 }
 
 type Recommender struct {
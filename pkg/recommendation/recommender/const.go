@@ -13,9 +13,15 @@ const (
 	// IdleNodeRecommender name
 	IdleNodeRecommender string = "IdleNode"
 
+	// NodePoolRecommender name
+	NodePoolRecommender string = "NodePool"
+
 	// VolumeRecommender name
 	VolumeRecommender string = "Volume"
 
+	// StorageRecommender name
+	StorageRecommender string = "Storage"
+
 	// ServiceRecommender name
 	ServiceRecommender string = "Service"
 )
@@ -0,0 +1,289 @@
+package hpa
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/montanaflynn/stats"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/replicas"
+)
+
+const (
+	// metricQueryPrefix is the config key prefix of the promql of a custom candidate metric
+	metricQueryPrefix = "metric-query."
+	// replicasInputKey is the input key of the running replicas of workload
+	replicasInputKey = "replicas"
+	// defaultScaleDownStabilizationWindowSeconds is the default of kubernetes hpa
+	defaultScaleDownStabilizationWindowSeconds = int32(300)
+)
+
+// candidateMetric is a metric evaluated to scale the workload on
+type candidateMetric struct {
+	name string
+	// correlation is the pearson correlation of the metric with the replica demand
+	correlation float64
+	// targetPerPod is the value of the metric one pod serves
+	targetPerPod float64
+	// percentile is the percentile value of the metric of the workload
+	percentile float64
+	// demandSource is whether the metric is the demand itself, its correlation is always 1 and tells nothing
+	demandSource bool
+}
+
+// evaluateCandidates evaluates all candidate metrics, the ones failed to evaluate are skipped
+func (rr *HPARecommender) evaluateCandidates(ctx *framework.RecommendationContext) ([]*candidateMetric, error) {
+	replicas := ctx.InputValue(replicasInputKey)[0].Samples
+	demand, source := demandSamples(replicas, ctx.InputValue(string(corev1.ResourceCPU))[0].Samples)
+
+	var candidates []*candidateMetric
+	for _, name := range rr.CandidateMetrics {
+		candidate, err := evaluateMetric(name, ctx.InputValue(name)[0].Samples, replicas, demand, rr.MetricPercentile)
+		if err != nil {
+			klog.Warningf("%s: evaluate metric %s failed: %v", ctx.String(), name, err)
+			continue
+		}
+		candidate.demandSource = name == source
+		klog.V(4).Infof("%s: metric %s correlation %f targetPerPod %f percentile %f", ctx.String(), name, candidate.correlation, candidate.targetPerPod, candidate.percentile)
+		candidates = append(candidates, candidate)
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("none of candidate metrics %v is evaluated", rr.CandidateMetrics)
+	}
+	return candidates, nil
+}
+
+// isScaled returns whether the replicas are changed in the history
+func isScaled(replicas []common.Sample) bool {
+	for _, sample := range replicas {
+		if sample.Value != replicas[0].Value {
+			return true
+		}
+	}
+	return false
+}
+
+// demandSamples returns the replicas as the demand if the workload is scaled in the history, otherwise the cpu usage is
+// the demand since the requests of pods are sized by cpu. The name of the candidate metric the demand comes from is
+// returned too, it is empty if the demand is the replicas.
+func demandSamples(replicas, cpu []common.Sample) ([]common.Sample, string) {
+	if isScaled(replicas) {
+		return replicas, ""
+	}
+	return cpu, corev1.ResourceCPU.String()
+}
+
+// alignSamples joins the values of two time series by timestamp
+func alignSamples(a, b []common.Sample) ([]float64, []float64) {
+	values := make(map[int64]float64, len(b))
+	for _, sample := range b {
+		values[sample.Timestamp] = sample.Value
+	}
+
+	var x, y []float64
+	for _, sample := range a {
+		if value, ok := values[sample.Timestamp]; ok {
+			x = append(x, sample.Value)
+			y = append(y, value)
+		}
+	}
+	return x, y
+}
+
+// evaluateMetric evaluates the correlation of the metric with the demand and the value of the metric one pod serves
+func evaluateMetric(name string, metric, replicas, demand []common.Sample, percentile float64) (*candidateMetric, error) {
+	x, y := alignSamples(metric, demand)
+	correlation, err := stats.Correlation(x, y)
+	if err != nil {
+		return nil, fmt.Errorf("correlate with demand failed: %v", err)
+	}
+
+	var totals, perPod []float64
+	x, y = alignSamples(metric, replicas)
+	for i := range x {
+		if y[i] > 0 {
+			totals = append(totals, x[i])
+			perPod = append(perPod, x[i]/y[i])
+		}
+	}
+
+	targetPerPod, err := stats.Percentile(perPod, percentile)
+	if err != nil {
+		return nil, fmt.Errorf("get percentile per pod failed: %v", err)
+	}
+	if targetPerPod <= 0 {
+		return nil, fmt.Errorf("percentile per pod %f is not positive", targetPerPod)
+	}
+
+	percentileTotal, err := stats.Percentile(totals, percentile)
+	if err != nil {
+		return nil, fmt.Errorf("get percentile failed: %v", err)
+	}
+
+	return &candidateMetric{
+		name:         name,
+		correlation:  correlation,
+		targetPerPod: targetPerPod,
+		percentile:   percentileTotal,
+	}, nil
+}
+
+// selectMetrics selects the best correlated metric and at most maxMetrics metrics whose correlation reach minCorrelation.
+// The metric the demand comes from is ranked after the others, it is selected only if none of the others reaches
+// minCorrelation.
+func selectMetrics(candidates []*candidateMetric, maxMetrics int, minCorrelation float64) []*candidateMetric {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].demandSource != candidates[j].demandSource {
+			return candidates[j].demandSource
+		}
+		return candidates[i].correlation > candidates[j].correlation
+	})
+
+	if candidates[0].correlation < minCorrelation {
+		for _, candidate := range candidates {
+			if candidate.demandSource {
+				return []*candidateMetric{candidate}
+			}
+		}
+	}
+
+	selected := candidates[:1]
+	for _, candidate := range candidates[1:] {
+		if len(selected) >= maxMetrics || candidate.demandSource || candidate.correlation < minCorrelation {
+			break
+		}
+		selected = append(selected, candidate)
+	}
+	return selected
+}
+
+// metricSpec makes the metric spec to scale on the metric, the custom metric is an external metric whose query is
+// in the returned annotation.
+func (rr *HPARecommender) metricSpec(ctx *framework.RecommendationContext, metric *candidateMetric, targetUtilization int32) (autoscalingv2.MetricSpec, map[string]string) {
+	switch metric.name {
+	case corev1.ResourceCPU.String():
+		return autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: &targetUtilization,
+				},
+			},
+		}, nil
+	case corev1.ResourceMemory.String():
+		return autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceMemory,
+				Target: autoscalingv2.MetricTarget{
+					Type:         autoscalingv2.AverageValueMetricType,
+					AverageValue: resource.NewQuantity(int64(math.Ceil(metric.targetPerPod)), resource.BinarySI),
+				},
+			},
+		}, nil
+	}
+
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{
+				Name: metric.name,
+			},
+			Target: autoscalingv2.MetricTarget{
+				Type:         autoscalingv2.AverageValueMetricType,
+				AverageValue: resource.NewMilliQuantity(int64(math.Ceil(metric.targetPerPod*1000)), resource.DecimalSI),
+			},
+		},
+	}, map[string]string{
+		known.EffectiveHorizontalPodAutoscalerExternalMetricsAnnotationPrefix + "/" + metric.name: replicas.RenderMetricQuery(ctx, rr.MetricQueries[metric.name]),
+	}
+}
+
+// sameMetric returns whether the two metric specs scale on the same metric
+func sameMetric(a, b autoscalingv2.MetricSpec) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		return a.Resource != nil && b.Resource != nil && a.Resource.Name == b.Resource.Name
+	case autoscalingv2.ExternalMetricSourceType:
+		return a.External != nil && b.External != nil && a.External.Metric.Name == b.External.Metric.Name
+	case autoscalingv2.PodsMetricSourceType:
+		return a.Pods != nil && b.Pods != nil && a.Pods.Metric.Name == b.Pods.Metric.Name
+	case autoscalingv2.ObjectMetricSourceType:
+		return a.Object != nil && b.Object != nil && a.Object.Metric.Name == b.Object.Metric.Name
+	}
+	return false
+}
+
+// scaleSpeeds returns the max number of pods added and removed between two adjacent samples
+func scaleSpeeds(replicas []common.Sample) (int32, int32) {
+	var up, down float64
+	for i := 1; i < len(replicas); i++ {
+		delta := replicas[i].Value - replicas[i-1].Value
+		if delta > up {
+			up = delta
+		}
+		if -delta > down {
+			down = -delta
+		}
+	}
+	return int32(math.Ceil(up)), int32(math.Ceil(down))
+}
+
+// proposeBehavior derives the scaling behavior from the observed scale speeds of the replicas. If the workload is not
+// scaled in the history, the speeds are those of the replicas demanded by the metric.
+func proposeBehavior(replicas, metric []common.Sample, targetPerPod float64, periodSeconds int32) *autoscalingv2.HorizontalPodAutoscalerBehavior {
+	samples := replicas
+	if !isScaled(replicas) {
+		samples = make([]common.Sample, 0, len(metric))
+		for _, sample := range metric {
+			samples = append(samples, common.Sample{Timestamp: sample.Timestamp, Value: math.Ceil(sample.Value / targetPerPod)})
+		}
+	}
+
+	up, down := scaleSpeeds(samples)
+	if up < 1 {
+		up = 1
+	}
+	if down < 1 {
+		down = 1
+	}
+
+	// the period of hpa scaling policy must be in (0, 1800]
+	if periodSeconds < 1 {
+		periodSeconds = 1
+	}
+	if periodSeconds > 1800 {
+		periodSeconds = 1800
+	}
+
+	scaleUpStabilizationWindowSeconds := int32(0)
+	scaleDownStabilizationWindowSeconds := defaultScaleDownStabilizationWindowSeconds
+	return &autoscalingv2.HorizontalPodAutoscalerBehavior{
+		ScaleUp: &autoscalingv2.HPAScalingRules{
+			StabilizationWindowSeconds: &scaleUpStabilizationWindowSeconds,
+			Policies: []autoscalingv2.HPAScalingPolicy{
+				{Type: autoscalingv2.PodsScalingPolicy, Value: up, PeriodSeconds: periodSeconds},
+			},
+		},
+		ScaleDown: &autoscalingv2.HPAScalingRules{
+			StabilizationWindowSeconds: &scaleDownStabilizationWindowSeconds,
+			Policies: []autoscalingv2.HPAScalingPolicy{
+				{Type: autoscalingv2.PodsScalingPolicy, Value: down, PeriodSeconds: periodSeconds},
+			},
+		},
+	}
+}
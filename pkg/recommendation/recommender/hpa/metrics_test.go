@@ -0,0 +1,183 @@
+package hpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+
+	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+)
+
+func makeSamples(values ...float64) []common.Sample {
+	samples := make([]common.Sample, 0, len(values))
+	for i, value := range values {
+		samples = append(samples, common.Sample{Timestamp: int64(i * 60), Value: value})
+	}
+	return samples
+}
+
+func TestEvaluateMetric(t *testing.T) {
+	replicas := makeSamples(2, 2, 4, 4, 2)
+	// the qps follows the replicas and one pod serves 100 qps
+	qps := makeSamples(200, 200, 400, 400, 200)
+	// the memory is flat whatever the replicas are
+	memory := makeSamples(1000, 1100, 1000, 1100, 1000)
+
+	demand, source := demandSamples(replicas, makeSamples(1, 1, 1, 1, 1))
+	assert.Equal(t, replicas, demand)
+	assert.Empty(t, source)
+
+	candidate, err := evaluateMetric("qps", qps, replicas, demand, 95)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, candidate.correlation, 1e-9)
+	assert.InDelta(t, 100, candidate.targetPerPod, 1e-9)
+	assert.InDelta(t, 400, candidate.percentile, 1e-9)
+
+	candidate, err = evaluateMetric("memory", memory, replicas, demand, 95)
+	assert.NoError(t, err)
+	assert.Less(t, candidate.correlation, 0.5)
+
+	_, err = evaluateMetric("qps", makeSamples(0, 0, 0, 0, 0), replicas, demand, 95)
+	assert.Error(t, err)
+}
+
+func TestDemandSamplesNotScaled(t *testing.T) {
+	cpu := makeSamples(1, 2, 3)
+	demand, source := demandSamples(makeSamples(3, 3, 3), cpu)
+	assert.Equal(t, cpu, demand)
+	assert.Equal(t, "cpu", source)
+}
+
+func TestSelectMetrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		correlations   map[string]float64
+		demandSource   string
+		maxMetrics     int
+		minCorrelation float64
+		want           []string
+	}{
+		{
+			name:           "best one",
+			correlations:   map[string]float64{"cpu": 0.6, "qps": 0.9, "memory": 0.2},
+			maxMetrics:     1,
+			minCorrelation: 0.5,
+			want:           []string{"qps"},
+		},
+		{
+			name:           "best ones above min correlation",
+			correlations:   map[string]float64{"cpu": 0.6, "qps": 0.9, "memory": 0.2},
+			maxMetrics:     3,
+			minCorrelation: 0.5,
+			want:           []string{"qps", "cpu"},
+		},
+		{
+			name:           "best one is kept below min correlation",
+			correlations:   map[string]float64{"cpu": 0.3},
+			maxMetrics:     2,
+			minCorrelation: 0.5,
+			want:           []string{"cpu"},
+		},
+		{
+			name:           "demand source is ranked after correlated metrics",
+			correlations:   map[string]float64{"cpu": 1, "qps": 0.8, "memory": 0.2},
+			demandSource:   "cpu",
+			maxMetrics:     3,
+			minCorrelation: 0.5,
+			want:           []string{"qps"},
+		},
+		{
+			name:           "demand source is kept if none is correlated",
+			correlations:   map[string]float64{"cpu": 1, "qps": 0.3, "memory": 0.2},
+			demandSource:   "cpu",
+			maxMetrics:     3,
+			minCorrelation: 0.5,
+			want:           []string{"cpu"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var candidates []*candidateMetric
+			for name, correlation := range tt.correlations {
+				candidates = append(candidates, &candidateMetric{name: name, correlation: correlation, demandSource: name == tt.demandSource})
+			}
+			var got []string
+			for _, metric := range selectMetrics(candidates, tt.maxMetrics, tt.minCorrelation) {
+				got = append(got, metric.name)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProposeBehavior(t *testing.T) {
+	// scaled from 2 to 5 in one minute and from 5 to 3 in one minute
+	behavior := proposeBehavior(makeSamples(2, 5, 5, 3), nil, 0, 60)
+	assert.Equal(t, []autoscalingv2.HPAScalingPolicy{{Type: autoscalingv2.PodsScalingPolicy, Value: 3, PeriodSeconds: 60}}, behavior.ScaleUp.Policies)
+	assert.Equal(t, []autoscalingv2.HPAScalingPolicy{{Type: autoscalingv2.PodsScalingPolicy, Value: 2, PeriodSeconds: 60}}, behavior.ScaleDown.Policies)
+
+	// not scaled, the demand of 100 per pod grows from 2 to 6 pods in one minute
+	behavior = proposeBehavior(makeSamples(3, 3, 3), makeSamples(150, 550, 500), 100, 60)
+	assert.Equal(t, int32(4), behavior.ScaleUp.Policies[0].Value)
+	assert.Equal(t, int32(1), behavior.ScaleDown.Policies[0].Value)
+	assert.Equal(t, defaultScaleDownStabilizationWindowSeconds, *behavior.ScaleDown.StabilizationWindowSeconds)
+}
+
+func TestSameMetric(t *testing.T) {
+	cpu := autoscalingv2.MetricSpec{Type: autoscalingv2.ResourceMetricSourceType, Resource: &autoscalingv2.ResourceMetricSource{Name: corev1.ResourceCPU}}
+	memory := autoscalingv2.MetricSpec{Type: autoscalingv2.ResourceMetricSourceType, Resource: &autoscalingv2.ResourceMetricSource{Name: corev1.ResourceMemory}}
+	qps := autoscalingv2.MetricSpec{Type: autoscalingv2.ExternalMetricSourceType, External: &autoscalingv2.ExternalMetricSource{Metric: autoscalingv2.MetricIdentifier{Name: "qps"}}}
+
+	assert.True(t, sameMetric(cpu, cpu))
+	assert.False(t, sameMetric(cpu, memory))
+	assert.True(t, sameMetric(qps, qps))
+	assert.False(t, sameMetric(cpu, qps))
+}
+
+func TestNewHPARecommenderCandidateMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "default cpu",
+			config: map[string]string{},
+			want:   []string{"cpu"},
+		},
+		{
+			name:   "custom metric",
+			config: map[string]string{"candidate-metrics": "cpu, memory, qps", "metric-query.qps": "sum(rate(http_requests_total{namespace=\"${namespace}\"}[1m]))"},
+			want:   []string{"cpu", "memory", "qps"},
+		},
+		{
+			name:    "custom metric without query",
+			config:  map[string]string{"candidate-metrics": "cpu,qps"},
+			wantErr: true,
+		},
+		{
+			name:    "reserved name",
+			config:  map[string]string{"candidate-metrics": "custom", "metric-query.custom": "up"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewHPARecommender(apis.Recommender{Name: "HPA", Config: tt.config}, analysisv1alph1.RecommendationRule{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, r.(*HPARecommender).CandidateMetrics)
+		})
+	}
+}
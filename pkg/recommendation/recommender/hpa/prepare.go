@@ -1,7 +1,18 @@
 package hpa
 
 import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/providers"
 	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/replicas"
+	"github.com/gocrane/crane/pkg/utils"
 )
 
 // CheckDataProviders in PrePrepare phase, will create data source provider via your recommendation config.
@@ -10,7 +21,48 @@ func (rr *HPARecommender) CheckDataProviders(ctx *framework.RecommendationContex
 }
 
 func (rr *HPARecommender) CollectData(ctx *framework.RecommendationContext) error {
-	return rr.ReplicasRecommender.CollectData(ctx)
+	if err := rr.ReplicasRecommender.CollectData(ctx); err != nil {
+		return err
+	}
+
+	labelSelector := labels.SelectorFromSet(ctx.Identity.Labels)
+	caller := fmt.Sprintf(callerFormat, klog.KObj(ctx.Recommendation), ctx.Recommendation.UID)
+	historyLength, err := utils.ParseDuration(rr.HistoryLength)
+	if err != nil {
+		return err
+	}
+	sampleInterval, err := utils.ParseDuration(rr.SampleInterval)
+	if err != nil {
+		return err
+	}
+	timeNow := time.Now()
+
+	// the replicas are the demand that candidate metrics are correlated with
+	target := ctx.Recommendation.Spec.TargetRef
+	metricNamers := map[string]metricnaming.MetricNamer{
+		replicasInputKey: metricnaming.ResourceToGeneralMetricNamer(utils.GetWorkloadReplicasExpression(target.Namespace, target.Name, target.Kind), corev1.ResourcePods, labelSelector, caller),
+	}
+	for name, query := range rr.MetricQueries {
+		metricNamers[name] = metricnaming.ResourceToGeneralMetricNamer(replicas.RenderMetricQuery(ctx, query), corev1.ResourceName(name), labelSelector, caller)
+	}
+
+	for key, metricNamer := range metricNamers {
+		if err := metricNamer.Validate(); err != nil {
+			return err
+		}
+
+		klog.Infof("%s: %s %s query %s", ctx.String(), rr.Name(), key, metricNamer.BuildUniqueKey())
+		tsList, err := ctx.DataProviders[providers.PrometheusDataSource].QueryTimeSeries(metricNamer, timeNow.Add(-historyLength), timeNow, sampleInterval)
+		if err != nil {
+			return fmt.Errorf("%s query historic metrics failed: %v ", rr.Name(), err)
+		}
+		if len(tsList) != 1 {
+			return fmt.Errorf("%s query historic metrics data is unexpected, List length is %d ", rr.Name(), len(tsList))
+		}
+		ctx.AddInputValue(key, tsList)
+	}
+
+	return nil
 }
 
 func (rr *HPARecommender) PostProcessing(ctx *framework.RecommendationContext) error {
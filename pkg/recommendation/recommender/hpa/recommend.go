@@ -53,12 +53,35 @@ func (rr *HPARecommender) Policy(ctx *framework.RecommendationContext) error {
 		return err
 	}
 
-	err = rr.checkMinCpuUsageThreshold(cpuMax)
+	candidates, err := rr.evaluateCandidates(ctx)
 	if err != nil {
-		return fmt.Errorf("checkMinCpuUsageThreshold failed: %v", err)
+		return err
 	}
+	selected := selectMetrics(candidates, int(rr.MaxMetrics), rr.MinCorrelation)
+	primary := selected[0]
+	klog.Infof("%s: selected metric %s with correlation %f", ctx.String(), primary.name, primary.correlation)
+
+	var targetUtilization int32
+	for _, metric := range selected {
+		if metric.name != corev1.ResourceCPU.String() {
+			continue
+		}
+
+		err = rr.checkMinCpuUsageThreshold(cpuMax)
+		if err != nil {
+			return fmt.Errorf("checkMinCpuUsageThreshold failed: %v", err)
+		}
 
-	medianMin, medianMax, err := rr.minMaxMedians(ctx.InputValue(string(corev1.ResourceCPU)))
+		var requestTotal int64
+		targetUtilization, requestTotal, err = rr.proposeTargetUtilization(ctx)
+		if err != nil {
+			return fmt.Errorf("proposeTargetUtilization failed: %v", err)
+		}
+		// the cpu cores one pod serves at the target utilization
+		metric.targetPerPod = float64(requestTotal) * float64(targetUtilization) / 100. / 1000.
+	}
+
+	medianMin, medianMax, err := rr.minMaxMedians(ctx.InputValue(primary.name))
 	if err != nil {
 		return fmt.Errorf("minMaxMedians failed: %v", err)
 	}
@@ -68,42 +91,46 @@ func (rr *HPARecommender) Policy(ctx *framework.RecommendationContext) error {
 		return fmt.Errorf("%s checkFluctuation failed: %v", rr.Name(), err)
 	}
 
-	targetUtilization, _, err := rr.proposeTargetUtilization(ctx)
-	if err != nil {
-		return fmt.Errorf("proposeTargetUtilization failed: %v", err)
-	}
-
-	maxReplicas, err := rr.proposeMaxReplicas(&ctx.PodTemplate, percentileCpu, targetUtilization, minReplicas)
-	if err != nil {
-		return fmt.Errorf("proposeMaxReplicas failed: %v", err)
+	maxReplicas := minReplicas
+	for _, metric := range selected {
+		var metricMaxReplicas int32
+		if metric.name == corev1.ResourceCPU.String() {
+			metricMaxReplicas, err = rr.proposeMaxReplicas(&ctx.PodTemplate, percentileCpu, targetUtilization, minReplicas)
+			if err != nil {
+				return fmt.Errorf("proposeMaxReplicas failed: %v", err)
+			}
+		} else {
+			metricMaxReplicas = int32(math.Ceil(metric.percentile * rr.MaxReplicasFactor / metric.targetPerPod))
+		}
+		if metricMaxReplicas > maxReplicas {
+			maxReplicas = metricMaxReplicas
+		}
 	}
 
 	defaultPredictionWindow := int32(3600)
-	resourceCpu := corev1.ResourceCPU
 
 	proposedEHPA := &types.EffectiveHorizontalPodAutoscalerRecommendation{
 		MaxReplicas: &maxReplicas,
 		MinReplicas: &minReplicas,
-		Metrics: []autoscalingv2.MetricSpec{
-			{
-				Type: autoscalingv2.ResourceMetricSourceType,
-				Resource: &autoscalingv2.ResourceMetricSource{
-					Name: resourceCpu,
-					Target: autoscalingv2.MetricTarget{
-						Type:               autoscalingv2.UtilizationMetricType,
-						AverageUtilization: &targetUtilization,
-					},
-				},
-			},
-		},
+	}
+	for _, metric := range selected {
+		metricSpec, annotations := rr.metricSpec(ctx, metric, targetUtilization)
+		proposedEHPA.Metrics = append(proposedEHPA.Metrics, metricSpec)
+		for key, value := range annotations {
+			if proposedEHPA.Annotations == nil {
+				proposedEHPA.Annotations = map[string]string{}
+			}
+			proposedEHPA.Annotations[key] = value
+		}
 	}
 
 	if predictable {
 		proposedEHPA.Prediction = &autoscalingapi.Prediction{
 			PredictionWindowSeconds: &defaultPredictionWindow,
 			PredictionAlgorithm: &autoscalingapi.PredictionAlgorithm{
-				AlgorithmType: predictionapi.AlgorithmTypeDSP,
+				AlgorithmType: rr.Algorithm,
 				DSP:           ctx.AlgorithmConfig.DSP,
+				Percentile:    ctx.AlgorithmConfig.Percentile,
 			},
 		}
 	}
@@ -111,8 +138,15 @@ func (rr *HPARecommender) Policy(ctx *framework.RecommendationContext) error {
 	// get metric spec from existing hpa and use them
 	if rr.ReferenceHpaEnabled && ctx.HPA != nil {
 		for _, metricSpec := range ctx.HPA.Spec.Metrics {
-			// don't use resource cpu, since we already configuration it before
-			if metricSpec.Type == autoscalingv2.ResourceMetricSourceType && metricSpec.Resource != nil && metricSpec.Resource.Name == resourceCpu {
+			// don't use the metrics we already configuration before
+			proposed := false
+			for _, proposedMetricSpec := range proposedEHPA.Metrics {
+				if sameMetric(metricSpec, proposedMetricSpec) {
+					proposed = true
+					break
+				}
+			}
+			if proposed {
 				continue
 			}
 
@@ -120,6 +154,16 @@ func (rr *HPARecommender) Policy(ctx *framework.RecommendationContext) error {
 		}
 	}
 
+	if rr.ReferenceHpaEnabled && ctx.HPA != nil && ctx.HPA.Spec.Behavior != nil {
+		proposedEHPA.Behavior = ctx.HPA.Spec.Behavior
+	} else if rr.BehaviorEnabled {
+		sampleInterval, err := utils.ParseDuration(rr.SampleInterval)
+		if err != nil {
+			return err
+		}
+		proposedEHPA.Behavior = proposeBehavior(ctx.InputValue(replicasInputKey)[0].Samples, ctx.InputValue(primary.name)[0].Samples, primary.targetPerPod, int32(sampleInterval.Seconds()))
+	}
+
 	result := types.ProposedRecommendation{
 		EffectiveHPA: proposedEHPA,
 	}
@@ -139,8 +183,9 @@ func (rr *HPARecommender) Policy(ctx *framework.RecommendationContext) error {
 				APIVersion: autoscalingapi.GroupVersion.String(),
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Namespace: ctx.Recommendation.Spec.TargetRef.Namespace,
-				Name:      ctx.Recommendation.Spec.TargetRef.Name,
+				Namespace:   ctx.Recommendation.Spec.TargetRef.Namespace,
+				Name:        ctx.Recommendation.Spec.TargetRef.Name,
+				Annotations: proposedEHPA.Annotations,
 			},
 			Spec: autoscalingapi.EffectiveHorizontalPodAutoscalerSpec{
 				MinReplicas:   proposedEHPA.MinReplicas,
@@ -148,6 +193,7 @@ func (rr *HPARecommender) Policy(ctx *framework.RecommendationContext) error {
 				Metrics:       proposedEHPA.Metrics,
 				ScaleStrategy: autoscalingapi.ScaleStrategyPreview,
 				Prediction:    proposedEHPA.Prediction,
+				Behavior:      proposedEHPA.Behavior,
 				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
 					Kind:       ctx.Recommendation.Spec.TargetRef.Kind,
 					APIVersion: ctx.Recommendation.Spec.TargetRef.APIVersion,
@@ -165,10 +211,14 @@ func (rr *HPARecommender) Policy(ctx *framework.RecommendationContext) error {
 		ctx.Recommendation.Status.Action = "Patch"
 
 		patchEhpa := &autoscalingapi.EffectiveHorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: proposedEHPA.Annotations,
+			},
 			Spec: autoscalingapi.EffectiveHorizontalPodAutoscalerSpec{
 				MinReplicas: proposedEHPA.MinReplicas,
 				MaxReplicas: *proposedEHPA.MaxReplicas,
 				Metrics:     proposedEHPA.Metrics,
+				Behavior:    proposedEHPA.Behavior,
 			},
 		}
 
@@ -252,7 +302,7 @@ func (rr *HPARecommender) checkFluctuation(medianMin, medianMax float64) error {
 
 	fluctuation := medianMax / medianMin
 	if fluctuation < fluctuationThreshold {
-		return fmt.Errorf("target cpu fluctuation %f is under replicas.fluctuation-threshold %f. ", fluctuation, fluctuationThreshold)
+		return fmt.Errorf("target fluctuation %f is under replicas.fluctuation-threshold %f. ", fluctuation, fluctuationThreshold)
 	}
 
 	return nil
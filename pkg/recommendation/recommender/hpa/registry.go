@@ -1,6 +1,11 @@
 package hpa
 
 import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
 	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
 	"github.com/gocrane/crane/pkg/recommendation/config"
 	"github.com/gocrane/crane/pkg/recommendation/recommender"
@@ -19,6 +24,11 @@ type HPARecommender struct {
 	MinCpuTargetUtilization int64
 	MaxCpuTargetUtilization int64
 	MaxReplicasFactor       float64
+	CandidateMetrics        []string
+	MetricQueries           map[string]string
+	MaxMetrics              int64
+	MinCorrelation          float64
+	BehaviorEnabled         bool
 }
 
 func init() {
@@ -68,6 +78,45 @@ func NewHPARecommender(recommender apis.Recommender, recommendationRule analysis
 		return nil, err
 	}
 
+	// the custom candidate metric <name> is queried by the promql in metric-query.<name>
+	metricQueries := map[string]string{}
+	var candidateMetrics []string
+	for _, metric := range strings.Split(recommender.GetConfigString("candidate-metrics", corev1.ResourceCPU.String()), ",") {
+		metric = strings.TrimSpace(metric)
+		switch metric {
+		case "":
+			continue
+		case corev1.ResourceCPU.String(), corev1.ResourceMemory.String():
+		case replicas.CustomMetric:
+			return nil, fmt.Errorf("candidate metric name %s is reserved", metric)
+		default:
+			query, ok := recommender.Config[metricQueryPrefix+metric]
+			if !ok || query == "" {
+				return nil, fmt.Errorf("%s%s is required by candidate metric %s", metricQueryPrefix, metric, metric)
+			}
+			metricQueries[metric] = query
+		}
+		candidateMetrics = append(candidateMetrics, metric)
+	}
+	if len(candidateMetrics) == 0 {
+		return nil, fmt.Errorf("candidate-metrics is empty")
+	}
+
+	maxMetricsInt, err := recommender.GetConfigInt("max-metrics", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	minCorrelationFloat, err := recommender.GetConfigFloat("min-correlation", 0.5)
+	if err != nil {
+		return nil, err
+	}
+
+	behaviorEnabled, err := recommender.GetConfigBool("behavior", true)
+	if err != nil {
+		return nil, err
+	}
+
 	replicasRecommender, err := replicas.NewReplicasRecommender(recommender, recommendationRule)
 	if err != nil {
 		return nil, err
@@ -82,5 +131,10 @@ func NewHPARecommender(recommender apis.Recommender, recommendationRule analysis
 		minCpuTargetUtilizationInt,
 		maxCpuTargetUtilizationInt,
 		maxReplicasFactorFloat,
+		candidateMetrics,
+		metricQueries,
+		maxMetricsInt,
+		minCorrelationFloat,
+		behaviorEnabled,
 	}, nil
 }
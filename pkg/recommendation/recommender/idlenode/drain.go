@@ -0,0 +1,202 @@
+package idlenode
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// drainSimulation checks whether the pods on a node can be evicted and rescheduled to the other nodes, so that
+// the node can be deleted without breaking the workloads
+type drainSimulation struct {
+	// pdbs are the PodDisruptionBudgets in the cluster
+	pdbs []policyv1beta1.PodDisruptionBudget
+	// nodes are the nodes in the cluster
+	nodes []corev1.Node
+	// pods are the pods in the cluster, which occupy the capacity of the nodes
+	pods []corev1.Pod
+}
+
+func newDrainSimulation(ctx *framework.RecommendationContext) (*drainSimulation, error) {
+	pdbList := &policyv1beta1.PodDisruptionBudgetList{}
+	if err := ctx.Client.List(ctx.Context, pdbList); err != nil {
+		return nil, err
+	}
+	nodeList := &corev1.NodeList{}
+	if err := ctx.Client.List(ctx.Context, nodeList); err != nil {
+		return nil, err
+	}
+	podList := &corev1.PodList{}
+	if err := ctx.Client.List(ctx.Context, podList); err != nil {
+		return nil, err
+	}
+
+	return &drainSimulation{
+		pdbs:  pdbList.Items,
+		nodes: nodeList.Items,
+		pods:  podList.Items,
+	}, nil
+}
+
+// nodeCapacity is the free resources of a node to reschedule the evicted pods
+type nodeCapacity struct {
+	node   *corev1.Node
+	cpu    int64
+	memory int64
+	pods   int64
+}
+
+// blockingReasons returns the reasons why the pods on the node can not be drained, empty if the node can be drained
+func (s *drainSimulation) blockingReasons(nodeName string, pods []corev1.Pod) []string {
+	var reasons []string
+
+	var evicted []corev1.Pod
+	for _, pod := range pods {
+		if utils.IsPodTerminated(&pod) || utils.IsStaticPod(&pod) || isDaemonSetPod(&pod) {
+			continue
+		}
+		if metav1.GetControllerOf(&pod) == nil {
+			reasons = append(reasons, fmt.Sprintf("pod %s is not managed by a controller", klog.KObj(&pod)))
+			continue
+		}
+		if hasLocalStorage(&pod) {
+			reasons = append(reasons, fmt.Sprintf("pod %s uses local storage", klog.KObj(&pod)))
+			continue
+		}
+		evicted = append(evicted, pod)
+	}
+
+	// the evictions of the pods protected by a PodDisruptionBudget can not exceed its allowed disruptions
+	for _, pdb := range s.pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		var matched int32
+		for _, pod := range evicted {
+			if pod.Namespace == pdb.Namespace && selector.Matches(labels.Set(pod.Labels)) {
+				matched++
+			}
+		}
+		if matched > pdb.Status.DisruptionsAllowed {
+			reasons = append(reasons, fmt.Sprintf("PodDisruptionBudget %s allows %d disruptions but %d pods are on the node",
+				klog.KObj(&pdb), pdb.Status.DisruptionsAllowed, matched))
+		}
+	}
+
+	// reschedule the larger pods first to the other nodes by the taints, the required node affinity and the spare capacity
+	capacities := s.nodeCapacities(nodeName)
+	sort.SliceStable(evicted, func(i, j int) bool {
+		return podRequest(&evicted[i], corev1.ResourceCPU) > podRequest(&evicted[j], corev1.ResourceCPU)
+	})
+	for i := range evicted {
+		if !reschedule(&evicted[i], capacities) {
+			reasons = append(reasons, fmt.Sprintf("no other node can run pod %s", klog.KObj(&evicted[i])))
+		}
+	}
+
+	return reasons
+}
+
+func (s *drainSimulation) nodeCapacities(drainedNode string) []*nodeCapacity {
+	capacityMap := map[string]*nodeCapacity{}
+	var capacities []*nodeCapacity
+	for i := range s.nodes {
+		node := &s.nodes[i]
+		if node.Name == drainedNode || node.Spec.Unschedulable || !isNodeReady(node) {
+			continue
+		}
+		capacity := &nodeCapacity{
+			node:   node,
+			cpu:    node.Status.Allocatable.Cpu().MilliValue(),
+			memory: node.Status.Allocatable.Memory().Value(),
+			pods:   node.Status.Allocatable.Pods().Value(),
+		}
+		capacityMap[node.Name] = capacity
+		capacities = append(capacities, capacity)
+	}
+
+	for i := range s.pods {
+		pod := &s.pods[i]
+		capacity, ok := capacityMap[pod.Spec.NodeName]
+		if !ok || utils.IsPodTerminated(pod) {
+			continue
+		}
+		capacity.cpu -= podRequest(pod, corev1.ResourceCPU)
+		capacity.memory -= podRequest(pod, corev1.ResourceMemory)
+		capacity.pods--
+	}
+	return capacities
+}
+
+// reschedule places the pod to the first node which can run it, and reserves the capacity
+func reschedule(pod *corev1.Pod, capacities []*nodeCapacity) bool {
+	cpu, memory := podRequest(pod, corev1.ResourceCPU), podRequest(pod, corev1.ResourceMemory)
+	affinity := nodeaffinity.GetRequiredNodeAffinity(pod)
+	for _, capacity := range capacities {
+		if capacity.cpu < cpu || capacity.memory < memory || capacity.pods < 1 {
+			continue
+		}
+		if _, untolerated := corev1helpers.FindMatchingUntoleratedTaint(capacity.node.Spec.Taints, pod.Spec.Tolerations, func(t *corev1.Taint) bool {
+			return t.Effect == corev1.TaintEffectNoSchedule || t.Effect == corev1.TaintEffectNoExecute
+		}); untolerated {
+			continue
+		}
+		if match, err := affinity.Match(capacity.node); err != nil || !match {
+			continue
+		}
+
+		capacity.cpu -= cpu
+		capacity.memory -= memory
+		capacity.pods--
+		return true
+	}
+	return false
+}
+
+// podRequest returns the request of the pod in milli cores for cpu and bytes for others
+func podRequest(pod *corev1.Pod, resourceName corev1.ResourceName) int64 {
+	var request int64
+	for _, container := range pod.Spec.Containers {
+		quantity := container.Resources.Requests[resourceName]
+		if resourceName == corev1.ResourceCPU {
+			request += quantity.MilliValue()
+		} else {
+			request += quantity.Value()
+		}
+	}
+	return request
+}
+
+func isDaemonSetPod(pod *corev1.Pod) bool {
+	controller := metav1.GetControllerOf(pod)
+	return controller != nil && controller.Kind == "DaemonSet"
+}
+
+func hasLocalStorage(pod *corev1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.HostPath != nil || volume.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package idlenode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDrainBlockingReasons(t *testing.T) {
+	isController := true
+	node := func(name string, cpu string, taints ...corev1.Taint) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"zone": name}},
+			Spec:       corev1.NodeSpec{Taints: taints},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	pod := func(name string, nodeName string, cpu string, ownerKind string) corev1.Pod {
+		p := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": name}},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{{
+					Name:      "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+				}},
+			},
+		}
+		if len(ownerKind) != 0 {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: name, Controller: &isController}}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		nodes   []corev1.Node
+		pods    []corev1.Pod
+		pdbs    []policyv1beta1.PodDisruptionBudget
+		drained []corev1.Pod
+		want    []string
+	}{
+		{
+			name:    "pods can be rescheduled",
+			nodes:   []corev1.Node{node("idle", "4"), node("other", "4")},
+			pods:    []corev1.Pod{pod("running", "other", "2", "ReplicaSet")},
+			drained: []corev1.Pod{pod("web", "idle", "1", "ReplicaSet"), pod("agent", "idle", "1", "DaemonSet")},
+		},
+		{
+			name:    "no spare capacity",
+			nodes:   []corev1.Node{node("idle", "4"), node("other", "4")},
+			pods:    []corev1.Pod{pod("running", "other", "3", "ReplicaSet")},
+			drained: []corev1.Pod{pod("web", "idle", "2", "ReplicaSet")},
+			want:    []string{"no other node can run pod default/web"},
+		},
+		{
+			name:    "taints are not tolerated",
+			nodes:   []corev1.Node{node("idle", "4"), node("other", "4", corev1.Taint{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule})},
+			drained: []corev1.Pod{pod("web", "idle", "1", "ReplicaSet")},
+			want:    []string{"no other node can run pod default/web"},
+		},
+		{
+			name:  "node selector does not match",
+			nodes: []corev1.Node{node("idle", "4"), node("other", "4")},
+			drained: func() []corev1.Pod {
+				p := pod("web", "idle", "1", "ReplicaSet")
+				p.Spec.NodeSelector = map[string]string{"zone": "idle"}
+				return []corev1.Pod{p}
+			}(),
+			want: []string{"no other node can run pod default/web"},
+		},
+		{
+			name:  "local storage and unmanaged pods",
+			nodes: []corev1.Node{node("idle", "4"), node("other", "4")},
+			drained: func() []corev1.Pod {
+				p := pod("cache", "idle", "1", "ReplicaSet")
+				p.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+				return []corev1.Pod{p, pod("bare", "idle", "1", "")}
+			}(),
+			want: []string{"pod default/cache uses local storage", "pod default/bare is not managed by a controller"},
+		},
+		{
+			name:  "pod disruption budget",
+			nodes: []corev1.Node{node("idle", "4"), node("other", "4")},
+			pdbs: []policyv1beta1.PodDisruptionBudget{{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       policyv1beta1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+				Status:     policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+			}},
+			drained: []corev1.Pod{pod("web", "idle", "1", "ReplicaSet")},
+			want:    []string{"PodDisruptionBudget default/web allows 0 disruptions but 1 pods are on the node"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &drainSimulation{pdbs: tt.pdbs, nodes: tt.nodes, pods: append(tt.pods, tt.drained...)}
+			assert.Equal(t, tt.want, s.blockingReasons("idle", tt.drained))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package idlenode
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gocrane/crane/pkg/recommendation/framework"
 )
@@ -22,9 +23,7 @@ func (inr *IdleNodeRecommender) Recommend(ctx *framework.RecommendationContext)
 	}
 
 	if allDaemonSetPod {
-		ctx.Recommendation.Status.Action = "Delete"
-		ctx.Recommendation.Status.Description = "Node is owned by DaemonSet"
-		return nil
+		return inr.recommendDelete(ctx, "Node is owned by DaemonSet")
 	}
 
 	if inr.cpuUsageUtilization == 0 && inr.memoryUsageUtilization == 0 && inr.cpuRequestUtilization == 0 && inr.memoryRequestUtilization == 0 {
@@ -75,8 +74,26 @@ func (inr *IdleNodeRecommender) Recommend(ctx *framework.RecommendationContext)
 		}
 	}
 
+	return inr.recommendDelete(ctx, "Node resource utilization is low")
+}
+
+// recommendDelete recommends deleting the idle node if its pods can be drained, otherwise the reasons blocking the
+// drain are recorded in the description
+func (inr *IdleNodeRecommender) recommendDelete(ctx *framework.RecommendationContext, description string) error {
+	if inr.drainCheck {
+		simulation, err := newDrainSimulation(ctx)
+		if err != nil {
+			return err
+		}
+		if reasons := simulation.blockingReasons(ctx.Object.GetName(), ctx.Pods); len(reasons) != 0 {
+			ctx.Recommendation.Status.Action = "None"
+			ctx.Recommendation.Status.Description = fmt.Sprintf("%s, but it can not be drained: %s", description, strings.Join(reasons, "; "))
+			return nil
+		}
+	}
+
 	ctx.Recommendation.Status.Action = "Delete"
-	ctx.Recommendation.Status.Description = "Node resource utilization is low"
+	ctx.Recommendation.Status.Description = description
 	return nil
 }
 
@@ -15,6 +15,7 @@ const (
 	memoryRequestUtilizationKey = "memory-request-utilization"
 	memoryUsageUtilizationKey   = "memory-usage-utilization"
 	memoryPercentileKey         = "memory-percentile"
+	drainCheckKey               = "drain-check"
 )
 
 var _ recommender.Recommender = &IdleNodeRecommender{}
@@ -27,6 +28,7 @@ type IdleNodeRecommender struct {
 	memoryRequestUtilization float64
 	memoryUsageUtilization   float64
 	memoryPercentile         float64
+	drainCheck               bool
 }
 
 func init() {
@@ -71,6 +73,11 @@ func NewIdleNodeRecommender(recommender apis.Recommender, recommendationRule ana
 	}
 	memoryPercentile = memoryPercentile * 100
 
+	drainCheck, err := recommender.GetConfigBool(drainCheckKey, true)
+	if err != nil {
+		return nil, err
+	}
+
 	return &IdleNodeRecommender{
 		*base.NewBaseRecommender(recommender),
 		cpuRequestUtilization,
@@ -79,5 +86,6 @@ func NewIdleNodeRecommender(recommender apis.Recommender, recommendationRule ana
 		memoryRequestUtilization,
 		memoryUsageUtilization,
 		memoryPercentile,
+		drainCheck,
 	}, err
 }
@@ -0,0 +1,136 @@
+package nodepool
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gocrane/crane/pkg/recommend/types"
+)
+
+const gib = 1024 * 1024 * 1024
+
+// resources is the cpu cores and memory bytes requested by a pod or available in a node
+type resources struct {
+	cpu    float64
+	memory float64
+}
+
+func (r resources) add(o resources) resources {
+	return resources{cpu: r.cpu + o.cpu, memory: r.memory + o.memory}
+}
+
+func (r resources) fits(capacity resources) bool {
+	return r.cpu <= capacity.cpu && r.memory <= capacity.memory
+}
+
+// packingInput is the demands of a node pool to be packed to nodes
+type packingInput struct {
+	// pods are the requests of the pods which are not run by DaemonSets
+	pods []resources
+	// overhead is the requests of the DaemonSet pods on each node
+	overhead resources
+	// used is the peak usage of the node pool
+	used resources
+	// cpuTargetUtilization and memTargetUtilization are the max ratio of a node to be requested or used
+	cpuTargetUtilization float64
+	memTargetUtilization float64
+}
+
+// capacity returns the resources of a node of the instance type to pack pods
+func (in *packingInput) capacity(instanceType InstanceType) resources {
+	return resources{
+		cpu:    instanceType.CPU*in.cpuTargetUtilization - in.overhead.cpu,
+		memory: instanceType.Memory*gib*in.memTargetUtilization - in.overhead.memory,
+	}
+}
+
+// packNodes packs the pods to nodes of the instance type by first fit decreasing, and adds nodes until the usage
+// of the node pool fits. It returns the requests packed to each node, or false if a pod does not fit the instance type.
+func (in *packingInput) packNodes(instanceType InstanceType) ([]resources, bool) {
+	capacity := in.capacity(instanceType)
+	if capacity.cpu <= 0 || capacity.memory <= 0 {
+		return nil, false
+	}
+
+	pods := make([]resources, len(in.pods))
+	copy(pods, in.pods)
+	// sort by the dominant share of the node
+	sort.SliceStable(pods, func(i, j int) bool {
+		return math.Max(pods[i].cpu/capacity.cpu, pods[i].memory/capacity.memory) > math.Max(pods[j].cpu/capacity.cpu, pods[j].memory/capacity.memory)
+	})
+
+	var nodes []resources
+	for _, pod := range pods {
+		if !pod.fits(capacity) {
+			return nil, false
+		}
+		packed := false
+		for i := range nodes {
+			if nodes[i].add(pod).fits(capacity) {
+				nodes[i] = nodes[i].add(pod)
+				packed = true
+				break
+			}
+		}
+		if !packed {
+			nodes = append(nodes, pod)
+		}
+	}
+
+	// the node pool keeps at least one node, and enough nodes for the usage
+	usedNodes := int(math.Ceil(math.Max(
+		in.used.cpu/(instanceType.CPU*in.cpuTargetUtilization),
+		in.used.memory/(instanceType.Memory*gib*in.memTargetUtilization))))
+	for len(nodes) < usedNodes || len(nodes) == 0 {
+		nodes = append(nodes, resources{})
+	}
+	return nodes, true
+}
+
+// proposePlan returns the cheapest plan to run the node pool. Besides the plans of a single instance type, it tries
+// to move the pods on the least requested node to a cheaper instance type, which makes a mix of instance types.
+func (in *packingInput) proposePlan(instanceTypes []InstanceType) (*types.NodePoolPlan, bool) {
+	var best *types.NodePoolPlan
+	for _, instanceType := range instanceTypes {
+		nodes, ok := in.packNodes(instanceType)
+		if !ok {
+			continue
+		}
+
+		plan := &types.NodePoolPlan{
+			Nodes:       []types.NodeCount{{InstanceType: instanceType.Name, Count: len(nodes)}},
+			MonthlyCost: float64(len(nodes)) * instanceType.Price,
+		}
+
+		// the usage is not bound to pods, so the mix is tried only if the nodes are required by requests
+		if len(nodes) > 1 && nodes[len(nodes)-1] != (resources{}) {
+			last := nodes[len(nodes)-1]
+			remaining := packingInput{
+				pods:                 []resources{last},
+				overhead:             in.overhead,
+				cpuTargetUtilization: in.cpuTargetUtilization,
+				memTargetUtilization: in.memTargetUtilization,
+			}
+			for _, small := range instanceTypes {
+				if small.Price >= instanceType.Price {
+					break
+				}
+				if _, ok := remaining.packNodes(small); ok {
+					plan = &types.NodePoolPlan{
+						Nodes: []types.NodeCount{
+							{InstanceType: instanceType.Name, Count: len(nodes) - 1},
+							{InstanceType: small.Name, Count: 1},
+						},
+						MonthlyCost: float64(len(nodes)-1)*instanceType.Price + small.Price,
+					}
+					break
+				}
+			}
+		}
+
+		if best == nil || plan.MonthlyCost < best.MonthlyCost {
+			best = plan
+		}
+	}
+	return best, best != nil
+}
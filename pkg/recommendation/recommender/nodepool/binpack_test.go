@@ -0,0 +1,74 @@
+package nodepool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/recommend/types"
+)
+
+func TestProposePlan(t *testing.T) {
+	instanceTypes := []InstanceType{
+		{Name: "small", CPU: 2, Memory: 4, Price: 50},
+		{Name: "large", CPU: 8, Memory: 16, Price: 160},
+	}
+	pod := func(cpu, memoryGiB float64) resources {
+		return resources{cpu: cpu, memory: memoryGiB * gib}
+	}
+
+	tests := []struct {
+		name string
+		in   packingInput
+		want *types.NodePoolPlan
+	}{
+		{
+			name: "small pods are packed to small nodes",
+			in: packingInput{
+				pods: []resources{pod(1, 1), pod(1, 1), pod(1, 1)},
+			},
+			want: &types.NodePoolPlan{Nodes: []types.NodeCount{{InstanceType: "small", Count: 2}}, MonthlyCost: 100},
+		},
+		{
+			name: "large pods need large nodes, the rest are moved to a small node",
+			in: packingInput{
+				pods: []resources{pod(7, 4), pod(7, 4), pod(2, 2)},
+			},
+			want: &types.NodePoolPlan{
+				Nodes:       []types.NodeCount{{InstanceType: "large", Count: 2}, {InstanceType: "small", Count: 1}},
+				MonthlyCost: 370,
+			},
+		},
+		{
+			name: "usage needs more nodes than requests",
+			in: packingInput{
+				pods: []resources{pod(0.5, 1)},
+				used: pod(5, 2),
+			},
+			want: &types.NodePoolPlan{Nodes: []types.NodeCount{{InstanceType: "small", Count: 3}}, MonthlyCost: 150},
+		},
+		{
+			name: "overhead of daemonsets is reserved on each node",
+			in: packingInput{
+				pods:     []resources{pod(1, 1), pod(1, 1)},
+				overhead: pod(1, 1),
+			},
+			want: &types.NodePoolPlan{Nodes: []types.NodeCount{{InstanceType: "small", Count: 2}}, MonthlyCost: 100},
+		},
+		{
+			name: "pods do not fit any instance type",
+			in: packingInput{
+				pods: []resources{pod(16, 1)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.in.cpuTargetUtilization, tt.in.memTargetUtilization = 1, 1
+			got, ok := tt.in.proposePlan(instanceTypes)
+			assert.Equal(t, tt.want != nil, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package nodepool
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InstanceType is an instance type in the catalog which the node pool can use
+type InstanceType struct {
+	Name string
+	// CPU is the cores of the instance type
+	CPU float64
+	// Memory is the GiB of memory of the instance type
+	Memory float64
+	// Price is the monthly price of a node of the instance type
+	Price float64
+}
+
+var specReg = regexp.MustCompile(`^([0-9.]+)c([0-9.]+)g$`)
+
+// GetInstanceTypes parses the instance type catalog, which is a comma separated list of <name>:<cpu>c<memory>g:<price>,
+// e.g. S5.LARGE8:4c8g:100,S5.2XLARGE16:8c16g:190. The instance types are sorted by price.
+func GetInstanceTypes(s string) ([]InstanceType, error) {
+	var instanceTypes []InstanceType
+	if len(strings.TrimSpace(s)) == 0 {
+		return instanceTypes, nil
+	}
+
+	for _, item := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(item), ":")
+		if len(fields) != 3 || len(fields[0]) == 0 {
+			return nil, fmt.Errorf("instance type %s format error", item)
+		}
+
+		spec := specReg.FindStringSubmatch(fields[1])
+		if spec == nil {
+			return nil, fmt.Errorf("instance type %s spec format error", item)
+		}
+		cpu, err := strconv.ParseFloat(spec[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("instance type %s cpu format error", item)
+		}
+		memory, err := strconv.ParseFloat(spec[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("instance type %s memory format error", item)
+		}
+		price, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("instance type %s price format error", item)
+		}
+
+		instanceTypes = append(instanceTypes, InstanceType{
+			Name:   fields[0],
+			CPU:    cpu,
+			Memory: memory,
+			Price:  price,
+		})
+	}
+
+	sort.SliceStable(instanceTypes, func(i, j int) bool {
+		return instanceTypes[i].Price < instanceTypes[j].Price
+	})
+
+	return instanceTypes, nil
+}
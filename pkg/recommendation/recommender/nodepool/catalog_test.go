@@ -0,0 +1,44 @@
+package nodepool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetInstanceTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []InstanceType
+		wantErr bool
+	}{
+		{
+			name:  "sorted by price",
+			input: "S5.2XLARGE16:8c16g:190,S5.LARGE8:4c8g:100,S5.MEDIUM2:2c2g:30.5",
+			want: []InstanceType{
+				{Name: "S5.MEDIUM2", CPU: 2, Memory: 2, Price: 30.5},
+				{Name: "S5.LARGE8", CPU: 4, Memory: 8, Price: 100},
+				{Name: "S5.2XLARGE16", CPU: 8, Memory: 16, Price: 190},
+			},
+		},
+		{
+			name:    "missing price",
+			input:   "S5.LARGE8:4c8g",
+			wantErr: true,
+		},
+		{
+			name:    "spec format error",
+			input:   "S5.LARGE8:4c:100",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetInstanceTypes(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package nodepool
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// Filter out k8s resources that are not supported by the recommender.
+func (npr *NodePoolRecommender) Filter(ctx *framework.RecommendationContext) error {
+	var err error
+
+	// filter resource that not match objectIdentity
+	if err = npr.BaseRecommender.Filter(ctx); err != nil {
+		return err
+	}
+
+	// the nodes of the node pool are labeled by the name of the node group object
+	nodeList := &corev1.NodeList{}
+	if err = ctx.Client.List(ctx.Context, nodeList, client.MatchingLabels{npr.nodePoolLabel: ctx.Object.GetName()}); err != nil {
+		return err
+	}
+	if len(nodeList.Items) == 0 {
+		return fmt.Errorf("no node found with label %s=%s", npr.nodePoolLabel, ctx.Object.GetName())
+	}
+	npr.nodes = nodeList.Items
+
+	ctx.Pods = nil
+	for _, node := range npr.nodes {
+		pods, err := utils.GetNodePods(ctx.Client, node.Name)
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods {
+			if !utils.IsPodTerminated(&pod) {
+				ctx.Pods = append(ctx.Pods, pod)
+			}
+		}
+	}
+
+	return nil
+}
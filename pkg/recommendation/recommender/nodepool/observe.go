@@ -0,0 +1,10 @@
+package nodepool
+
+import (
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+)
+
+// Observe enhance the observability.
+func (npr *NodePoolRecommender) Observe(ctx *framework.RecommendationContext) error {
+	return nil
+}
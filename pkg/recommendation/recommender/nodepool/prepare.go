@@ -0,0 +1,68 @@
+package nodepool
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/providers"
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const callerFormat = "NodePoolRecommender-%s-%s"
+
+// CheckDataProviders in PrePrepare phase, will create data source provider via your recommendation config.
+func (npr *NodePoolRecommender) CheckDataProviders(ctx *framework.RecommendationContext) error {
+	if err := npr.BaseRecommender.CheckDataProviders(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (npr *NodePoolRecommender) CollectData(ctx *framework.RecommendationContext) error {
+	caller := fmt.Sprintf(callerFormat, klog.KObj(ctx.Recommendation), ctx.Recommendation.UID)
+	timeNow := time.Now()
+
+	// the node usage expressions match instances by regex, so the usage of the node pool is queried at once
+	var nodeNames []string
+	for _, node := range npr.nodes {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	nodeNameReg := strings.Join(nodeNames, "|")
+
+	for key, query := range map[string]struct {
+		expr         string
+		resourceName corev1.ResourceName
+	}{
+		cpuUsageKey:    {utils.GetNodeCpuUsageExpression(nodeNameReg), corev1.ResourceCPU},
+		memoryUsageKey: {utils.GetNodeMemUsageExpression(nodeNameReg), corev1.ResourceMemory},
+	} {
+		metricNamer := metricnaming.ResourceToGeneralMetricNamer(query.expr, query.resourceName, labels.Everything(), caller)
+		if err := metricNamer.Validate(); err != nil {
+			return err
+		}
+
+		klog.Infof("%s: %s %s query %s", ctx.String(), npr.Name(), key, metricNamer.BuildUniqueKey())
+		tsList, err := ctx.DataProviders[providers.PrometheusDataSource].QueryTimeSeries(metricNamer, timeNow.Add(-time.Hour*24*7), timeNow, time.Minute)
+		if err != nil {
+			return fmt.Errorf("%s query node pool %s historic metrics failed: %v ", npr.Name(), key, err)
+		}
+		if len(tsList) != 1 {
+			return fmt.Errorf("%s query node pool %s historic metrics data is unexpected, List length is %d ", npr.Name(), key, len(tsList))
+		}
+		ctx.AddInputValue(key, tsList)
+	}
+
+	return nil
+}
+
+func (npr *NodePoolRecommender) PostProcessing(ctx *framework.RecommendationContext) error {
+	return nil
+}
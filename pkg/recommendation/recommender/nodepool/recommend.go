@@ -0,0 +1,198 @@
+package nodepool
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/recommend/types"
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/resource"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+func (npr *NodePoolRecommender) PreRecommend(ctx *framework.RecommendationContext) error {
+	return nil
+}
+
+func (npr *NodePoolRecommender) Recommend(ctx *framework.RecommendationContext) error {
+	if len(npr.instanceTypes) == 0 {
+		return fmt.Errorf("instance type catalog is not configured by %s", instanceTypesKey)
+	}
+
+	recommendedContainers, err := npr.getRecommendedContainers(ctx)
+	if err != nil {
+		return err
+	}
+
+	// requests of the pods are bin-packed by the resource recommendations if they exist
+	in := packingInput{
+		cpuTargetUtilization: npr.cpuTargetUtilization,
+		memTargetUtilization: npr.memTargetUtilization,
+	}
+	var requested, daemonSetRequested, allocatable resources
+	for i := range ctx.Pods {
+		pod := &ctx.Pods[i]
+		owner := utils.GetPodOwnerReference(ctx.Context, ctx.Client, pod)
+		var containers []corev1.Container
+		if owner != nil {
+			containers = recommendedContainers[recommendationKey(pod.Namespace, owner.Kind, owner.Name)]
+		}
+		request := podRequests(pod, containers)
+		requested = requested.add(request)
+		if owner != nil && owner.Kind == "DaemonSet" {
+			daemonSetRequested = daemonSetRequested.add(request)
+		} else {
+			in.pods = append(in.pods, request)
+		}
+	}
+	in.overhead = resources{
+		cpu:    daemonSetRequested.cpu / float64(len(npr.nodes)),
+		memory: daemonSetRequested.memory / float64(len(npr.nodes)),
+	}
+
+	current := &types.NodePoolPlan{}
+	counts := map[string]int{}
+	for _, node := range npr.nodes {
+		cpu, memory := node.Status.Allocatable[corev1.ResourceCPU], node.Status.Allocatable[corev1.ResourceMemory]
+		allocatable = allocatable.add(resources{cpu: cpu.AsApproximateFloat64(), memory: memory.AsApproximateFloat64()})
+
+		instanceType, ok := npr.getInstanceType(node.Labels[npr.instanceTypeLabel])
+		if !ok {
+			return fmt.Errorf("instance type %s of node %s not found in catalog", node.Labels[npr.instanceTypeLabel], node.Name)
+		}
+		counts[instanceType.Name]++
+		current.MonthlyCost += instanceType.Price
+	}
+	for name, count := range counts {
+		current.Nodes = append(current.Nodes, types.NodeCount{InstanceType: name, Count: count})
+	}
+	sort.Slice(current.Nodes, func(i, j int) bool {
+		return current.Nodes[i].InstanceType < current.Nodes[j].InstanceType
+	})
+
+	in.used.cpu, err = npr.BaseRecommender.GetPercentile(npr.cpuPercentile, ctx.InputValue(cpuUsageKey))
+	if err != nil {
+		return err
+	}
+	in.used.memory, err = npr.BaseRecommender.GetPercentile(npr.memPercentile, ctx.InputValue(memoryUsageKey))
+	if err != nil {
+		return err
+	}
+
+	proposed, ok := in.proposePlan(npr.instanceTypes)
+	if !ok {
+		return fmt.Errorf("no instance type in catalog fits the pods of node pool %s", ctx.Object.GetName())
+	}
+	klog.Infof("%s: node pool current plan %v proposed plan %v", ctx.String(), *current, *proposed)
+
+	value := types.ProposedRecommendation{
+		NodePool: &types.NodePoolRecommendation{
+			RequestedCPU:    formatRatio(requested.cpu, allocatable.cpu),
+			RequestedMemory: formatRatio(requested.memory, allocatable.memory),
+			UsedCPU:         formatRatio(in.used.cpu, allocatable.cpu),
+			UsedMemory:      formatRatio(in.used.memory, allocatable.memory),
+			Current:         current,
+			Proposed:        proposed,
+		},
+	}
+	valueBytes, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%s yaml marshal failed: %v", npr.Name(), err)
+	}
+	ctx.Recommendation.Status.RecommendedValue = string(valueBytes)
+
+	if proposed.MonthlyCost < current.MonthlyCost {
+		ctx.Recommendation.Status.Action = "Patch"
+		ctx.Recommendation.Status.Description = fmt.Sprintf("Node pool requests %s cpu and %s memory, uses %s cpu and %s memory of allocatable resources",
+			value.NodePool.RequestedCPU, value.NodePool.RequestedMemory, value.NodePool.UsedCPU, value.NodePool.UsedMemory)
+	} else {
+		ctx.Recommendation.Status.Action = "None"
+		proposed = current
+	}
+
+	newPlanBytes, err := json.Marshal(proposed)
+	if err != nil {
+		return fmt.Errorf("marshal proposed plan failed %s. ", err)
+	}
+	oldPlanBytes, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("marshal current plan failed %s. ", err)
+	}
+	ctx.Recommendation.Status.RecommendedInfo = string(newPlanBytes)
+	ctx.Recommendation.Status.CurrentInfo = string(oldPlanBytes)
+
+	return nil
+}
+
+// Policy add some logic for result of recommend phase.
+func (npr *NodePoolRecommender) Policy(ctx *framework.RecommendationContext) error {
+	return nil
+}
+
+func (npr *NodePoolRecommender) getInstanceType(name string) (InstanceType, bool) {
+	for _, instanceType := range npr.instanceTypes {
+		if instanceType.Name == name {
+			return instanceType, true
+		}
+	}
+	return InstanceType{}, false
+}
+
+// getRecommendedContainers returns the containers recommended by resource recommendations, which are indexed by
+// the namespace, kind and name of their targets
+func (npr *NodePoolRecommender) getRecommendedContainers(ctx *framework.RecommendationContext) (map[string][]corev1.Container, error) {
+	recommendations := &analysisv1alph1.RecommendationList{}
+	if err := ctx.Client.List(ctx.Context, recommendations); err != nil {
+		return nil, err
+	}
+
+	recommendedContainers := map[string][]corev1.Container{}
+	for _, recommendation := range recommendations.Items {
+		if recommendation.Spec.Type != analysisv1alph1.AnalysisTypeResource || len(recommendation.Status.RecommendedInfo) == 0 {
+			continue
+		}
+		var patch resource.PatchResource
+		if err := json.Unmarshal([]byte(recommendation.Status.RecommendedInfo), &patch); err != nil {
+			klog.Warningf("%s: unmarshal recommended info of %s failed: %v", ctx.String(), klog.KObj(&recommendation), err)
+			continue
+		}
+		targetRef := recommendation.Spec.TargetRef
+		recommendedContainers[recommendationKey(targetRef.Namespace, targetRef.Kind, targetRef.Name)] = patch.Spec.Template.Spec.Containers
+	}
+	return recommendedContainers, nil
+}
+
+func recommendationKey(namespace, kind, name string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, kind, name)
+}
+
+// podRequests returns the requests of the pod, the requests of the containers are replaced by the recommended ones
+func podRequests(pod *corev1.Pod, recommendedContainers []corev1.Container) resources {
+	var request resources
+	for _, container := range pod.Spec.Containers {
+		requests := container.Resources.Requests
+		for _, recommended := range recommendedContainers {
+			if recommended.Name == container.Name {
+				requests = recommended.Resources.Requests
+				break
+			}
+		}
+		cpu, memory := requests[corev1.ResourceCPU], requests[corev1.ResourceMemory]
+		request = request.add(resources{cpu: cpu.AsApproximateFloat64(), memory: memory.AsApproximateFloat64()})
+	}
+	return request
+}
+
+func formatRatio(value, total float64) string {
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%%", value/total*100)
+}
@@ -0,0 +1,97 @@
+package nodepool
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/recommendation/config"
+	"github.com/gocrane/crane/pkg/recommendation/recommender"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/base"
+)
+
+const (
+	nodePoolLabelKey        = "node-pool-label"
+	instanceTypeLabelKey    = "instance-type-label"
+	instanceTypesKey        = "instance-types"
+	cpuTargetUtilizationKey = "cpu-target-utilization"
+	memTargetUtilizationKey = "mem-target-utilization"
+	cpuPercentileKey        = "cpu-percentile"
+	memPercentileKey        = "mem-percentile"
+
+	// DefaultNodePoolLabel is the default node label whose value is the name of the node pool
+	DefaultNodePoolLabel = "crane.io/node-pool"
+
+	cpuUsageKey    = "cpu-usage"
+	memoryUsageKey = "memory-usage"
+)
+
+var _ recommender.Recommender = &NodePoolRecommender{}
+
+type NodePoolRecommender struct {
+	base.BaseRecommender
+	nodePoolLabel        string
+	instanceTypeLabel    string
+	instanceTypes        []InstanceType
+	cpuTargetUtilization float64
+	memTargetUtilization float64
+	cpuPercentile        float64
+	memPercentile        float64
+	// nodes of the node pool, which are retrieved in filter phase
+	nodes []corev1.Node
+}
+
+func init() {
+	recommender.RegisterRecommenderProvider(recommender.NodePoolRecommender, NewNodePoolRecommender)
+}
+
+func (npr *NodePoolRecommender) Name() string {
+	return recommender.NodePoolRecommender
+}
+
+// NewNodePoolRecommender create a new node pool recommender.
+func NewNodePoolRecommender(recommender apis.Recommender, recommendationRule analysisv1alph1.RecommendationRule) (recommender.Recommender, error) {
+	recommender = config.MergeRecommenderConfigFromRule(recommender, recommendationRule)
+
+	nodePoolLabel := recommender.GetConfigString(nodePoolLabelKey, DefaultNodePoolLabel)
+	instanceTypeLabel := recommender.GetConfigString(instanceTypeLabelKey, corev1.LabelInstanceTypeStable)
+
+	instanceTypes, err := GetInstanceTypes(recommender.GetConfigString(instanceTypesKey, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	cpuTargetUtilization, err := recommender.GetConfigFloat(cpuTargetUtilizationKey, 0.8)
+	if err != nil {
+		return nil, err
+	}
+
+	memTargetUtilization, err := recommender.GetConfigFloat(memTargetUtilizationKey, 0.8)
+	if err != nil {
+		return nil, err
+	}
+
+	cpuPercentile, err := recommender.GetConfigFloat(cpuPercentileKey, 0.99)
+	if err != nil {
+		return nil, err
+	}
+	cpuPercentile = cpuPercentile * 100
+
+	memPercentile, err := recommender.GetConfigFloat(memPercentileKey, 0.99)
+	if err != nil {
+		return nil, err
+	}
+	memPercentile = memPercentile * 100
+
+	return &NodePoolRecommender{
+		BaseRecommender:      *base.NewBaseRecommender(recommender),
+		nodePoolLabel:        nodePoolLabel,
+		instanceTypeLabel:    instanceTypeLabel,
+		instanceTypes:        instanceTypes,
+		cpuTargetUtilization: cpuTargetUtilization,
+		memTargetUtilization: memTargetUtilization,
+		cpuPercentile:        cpuPercentile,
+		memPercentile:        memPercentile,
+	}, nil
+}
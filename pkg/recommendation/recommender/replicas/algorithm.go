@@ -0,0 +1,95 @@
+package replicas
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+)
+
+const (
+	// CustomMetric is the metric queried by the promql in metric-query
+	CustomMetric = "custom"
+)
+
+// makeAlgorithmConfig makes the config of the algorithm to predict the metric from the recommender config
+func makeAlgorithmConfig(recommender apis.Recommender, algorithm predictionapi.AlgorithmType, metric string, historyLength string, sampleInterval string) (*config.Config, error) {
+	switch algorithm {
+	case predictionapi.AlgorithmTypeDSP:
+		estimators, err := makeEstimators(recommender)
+		if err != nil {
+			return nil, err
+		}
+		return &config.Config{
+			DSP: &predictionapi.DSP{
+				SampleInterval: sampleInterval,
+				HistoryLength:  historyLength,
+				Estimators:     estimators,
+			},
+		}, nil
+	case predictionapi.AlgorithmTypePercentile:
+		// the histogram is sized by the unit of the metric, cores for cpu and bytes for memory
+		var bucketSize, maxValue string
+		switch metric {
+		case corev1.ResourceCPU.String():
+			bucketSize, maxValue = "0.1", "100"
+		case corev1.ResourceMemory.String():
+			bucketSize, maxValue = "104857600", "104857600000"
+		}
+		return &config.Config{
+			Percentile: &predictionapi.Percentile{
+				Aggregated:        true,
+				HistoryLength:     historyLength,
+				SampleInterval:    sampleInterval,
+				MarginFraction:    recommender.GetConfigString("margin-fraction", "0.15"),
+				TargetUtilization: "1.0",
+				Percentile:        recommender.GetConfigString("percentile", "0.99"),
+				Histogram: predictionapi.HistogramConfig{
+					HalfLife:   recommender.GetConfigString("histogram-half-life", "24h"),
+					BucketSize: recommender.GetConfigString("histogram-bucket-size", bucketSize),
+					MaxValue:   recommender.GetConfigString("histogram-max-value", maxValue),
+				},
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("algorithm %s is not supported", algorithm)
+}
+
+// makeEstimators makes the estimators of dsp, the default estimators of dsp are used if none is configured
+func makeEstimators(recommender apis.Recommender) (predictionapi.Estimators, error) {
+	var estimators predictionapi.Estimators
+
+	if marginFraction, ok := recommender.Config["max-value-margin-fraction"]; ok {
+		estimators.MaxValueEstimators = append(estimators.MaxValueEstimators, &predictionapi.MaxValueEstimator{MarginFraction: marginFraction})
+	}
+
+	fft := &predictionapi.FFTEstimator{
+		MarginFraction:         recommender.Config["fft-margin-fraction"],
+		LowAmplitudeThreshold:  recommender.Config["fft-low-amplitude-threshold"],
+		HighFrequencyThreshold: recommender.Config["fft-high-frequency-threshold"],
+	}
+	for key, value := range map[string]**int32{
+		"fft-min-num-of-spectrum-items": &fft.MinNumOfSpectrumItems,
+		"fft-max-num-of-spectrum-items": &fft.MaxNumOfSpectrumItems,
+	} {
+		if s, ok := recommender.Config[key]; ok {
+			n, err := strconv.ParseInt(s, 10, 32)
+			if err != nil {
+				return estimators, fmt.Errorf("parse %s failed: %v", key, err)
+			}
+			n32 := int32(n)
+			*value = &n32
+		}
+	}
+	if *fft != (predictionapi.FFTEstimator{}) {
+		estimators.FFTEstimators = append(estimators.FFTEstimators, fft)
+	}
+
+	return estimators, nil
+}
@@ -0,0 +1,132 @@
+package replicas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+)
+
+func TestMakeAlgorithmConfig(t *testing.T) {
+	minItems := int32(5)
+
+	tests := []struct {
+		name      string
+		config    map[string]string
+		algorithm predictionapi.AlgorithmType
+		metric    string
+		want      func(t *testing.T, dsp *predictionapi.DSP, percentile *predictionapi.Percentile)
+		wantErr   bool
+	}{
+		{
+			name:      "dsp with default estimators",
+			algorithm: predictionapi.AlgorithmTypeDSP,
+			metric:    "cpu",
+			want: func(t *testing.T, dsp *predictionapi.DSP, percentile *predictionapi.Percentile) {
+				assert.Nil(t, percentile)
+				assert.Equal(t, "7d", dsp.HistoryLength)
+				assert.Equal(t, "1m", dsp.SampleInterval)
+				assert.Empty(t, dsp.Estimators.FFTEstimators)
+				assert.Empty(t, dsp.Estimators.MaxValueEstimators)
+			},
+		},
+		{
+			name: "dsp with estimators",
+			config: map[string]string{
+				"fft-margin-fraction":           "0.2",
+				"fft-min-num-of-spectrum-items": "5",
+				"max-value-margin-fraction":     "0.1",
+			},
+			algorithm: predictionapi.AlgorithmTypeDSP,
+			metric:    "cpu",
+			want: func(t *testing.T, dsp *predictionapi.DSP, percentile *predictionapi.Percentile) {
+				assert.Equal(t, []*predictionapi.FFTEstimator{{MarginFraction: "0.2", MinNumOfSpectrumItems: &minItems}}, dsp.Estimators.FFTEstimators)
+				assert.Equal(t, []*predictionapi.MaxValueEstimator{{MarginFraction: "0.1"}}, dsp.Estimators.MaxValueEstimators)
+			},
+		},
+		{
+			name:      "dsp with invalid estimators",
+			config:    map[string]string{"fft-max-num-of-spectrum-items": "many"},
+			algorithm: predictionapi.AlgorithmTypeDSP,
+			metric:    "cpu",
+			wantErr:   true,
+		},
+		{
+			name:      "percentile for memory",
+			config:    map[string]string{"percentile": "0.9"},
+			algorithm: predictionapi.AlgorithmTypePercentile,
+			metric:    "memory",
+			want: func(t *testing.T, dsp *predictionapi.DSP, percentile *predictionapi.Percentile) {
+				assert.Nil(t, dsp)
+				assert.Equal(t, "0.9", percentile.Percentile)
+				assert.Equal(t, "7d", percentile.HistoryLength)
+				assert.Equal(t, "104857600", percentile.Histogram.BucketSize)
+			},
+		},
+		{
+			name:      "unsupported algorithm",
+			algorithm: "arima",
+			metric:    "cpu",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := makeAlgorithmConfig(apis.Recommender{Config: tt.config}, tt.algorithm, tt.metric, "7d", "1m")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			tt.want(t, cfg.DSP, cfg.Percentile)
+		})
+	}
+}
+
+func TestNewReplicasRecommenderMetric(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "default cpu",
+			config: map[string]string{},
+		},
+		{
+			name:   "memory with percentile",
+			config: map[string]string{"metric": "memory", "algorithm": "percentile"},
+		},
+		{
+			name:   "custom",
+			config: map[string]string{"metric": "custom", "metric-query": "sum(rate(http_requests_total{namespace=\"${namespace}\"}[1m]))", "metric-target-per-pod": "100"},
+		},
+		{
+			name:    "custom without query",
+			config:  map[string]string{"metric": "custom", "metric-target-per-pod": "100"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported metric",
+			config:  map[string]string{"metric": "gpu"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid history length",
+			config:  map[string]string{"history-length": "a week"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReplicasRecommender(apis.Recommender{Name: "Replicas", Config: tt.config}, analysisv1alph1.RecommendationRule{})
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+		})
+	}
+}
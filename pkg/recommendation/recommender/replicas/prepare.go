@@ -2,15 +2,17 @@ package replicas
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/gocrane/crane/pkg/metricnaming"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 
+	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/providers"
 	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/utils"
 )
 
 const callerFormat = "ReplicasRecommendationCaller-%s-%s"
@@ -25,41 +27,57 @@ func (rr *ReplicasRecommender) CheckDataProviders(ctx *framework.RecommendationC
 }
 
 func (rr *ReplicasRecommender) CollectData(ctx *framework.RecommendationContext) error {
-	resourceCpu := corev1.ResourceCPU
 	labelSelector := labels.SelectorFromSet(ctx.Identity.Labels)
 	caller := fmt.Sprintf(callerFormat, klog.KObj(ctx.Recommendation), ctx.Recommendation.UID)
-	metricNamer := metricnaming.ResourceToWorkloadMetricNamer(ctx.Recommendation.Spec.TargetRef.DeepCopy(), &resourceCpu, labelSelector, caller)
-	if err := metricNamer.Validate(); err != nil {
+	historyLength, err := utils.ParseDuration(rr.HistoryLength)
+	if err != nil {
 		return err
 	}
-	ctx.MetricNamer = metricNamer
-
-	// get workload cpu usage
-	klog.Infof("%s: %s CpuQuery %s", ctx.String(), rr.Name(), ctx.MetricNamer.BuildUniqueKey())
-	timeNow := time.Now()
-	tsList, err := ctx.DataProviders[providers.PrometheusDataSource].QueryTimeSeries(ctx.MetricNamer, timeNow.Add(-time.Hour*24*7), timeNow, time.Minute)
+	sampleInterval, err := utils.ParseDuration(rr.SampleInterval)
 	if err != nil {
-		return fmt.Errorf("%s query historic metrics failed: %v ", rr.Name(), err)
-	}
-	if len(tsList) != 1 {
-		return fmt.Errorf("%s query historic metrics data is unexpected, List length is %d ", rr.Name(), len(tsList))
+		return err
 	}
-	ctx.AddInputValue(string(corev1.ResourceCPU), tsList)
+	timeNow := time.Now()
 
-	resourceMemory := corev1.ResourceMemory
-	metricNamerMemory := metricnaming.ResourceToWorkloadMetricNamer(ctx.Recommendation.Spec.TargetRef.DeepCopy(), &resourceMemory, labelSelector, caller)
-	klog.Infof("%s: %s MemoryQuery %s", ctx.String(), rr.Name(), metricNamerMemory.BuildUniqueKey())
-	tsListMemory, err := ctx.DataProviders[providers.PrometheusDataSource].QueryTimeSeries(metricNamerMemory, timeNow.Add(-time.Hour*24*7), timeNow, time.Minute)
-	if err != nil {
-		return fmt.Errorf("%s query historic metrics failed: %v ", rr.Name(), err)
+	// cpu and memory usages are always collected, they are needed to propose replicas by requests
+	metricNamers := map[string]metricnaming.MetricNamer{}
+	for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		resourceName := resourceName
+		metricNamers[resourceName.String()] = metricnaming.ResourceToWorkloadMetricNamer(ctx.Recommendation.Spec.TargetRef.DeepCopy(), &resourceName, labelSelector, caller)
+	}
+	if rr.Metric == CustomMetric {
+		metricNamers[CustomMetric] = metricnaming.ResourceToGeneralMetricNamer(RenderMetricQuery(ctx, rr.MetricQuery), corev1.ResourceName(CustomMetric), labelSelector, caller)
 	}
-	if len(tsListMemory) != 1 {
-		return fmt.Errorf("%s query historic metrics data is unexpected, List length is %d ", rr.Name(), len(tsListMemory))
+
+	for key, metricNamer := range metricNamers {
+		if err := metricNamer.Validate(); err != nil {
+			return err
+		}
+
+		klog.Infof("%s: %s %s query %s", ctx.String(), rr.Name(), key, metricNamer.BuildUniqueKey())
+		tsList, err := ctx.DataProviders[providers.PrometheusDataSource].QueryTimeSeries(metricNamer, timeNow.Add(-historyLength), timeNow, sampleInterval)
+		if err != nil {
+			return fmt.Errorf("%s query historic metrics failed: %v ", rr.Name(), err)
+		}
+		if len(tsList) != 1 {
+			return fmt.Errorf("%s query historic metrics data is unexpected, List length is %d ", rr.Name(), len(tsList))
+		}
+		ctx.AddInputValue(key, tsList)
 	}
-	ctx.AddInputValue(string(corev1.ResourceMemory), tsList)
+
+	// the selected metric is the one to predict
+	ctx.MetricNamer = metricNamers[rr.Metric]
 	return nil
 }
 
+// RenderMetricQuery renders the workload namespace and name into the metric query
+func RenderMetricQuery(ctx *framework.RecommendationContext, query string) string {
+	return strings.NewReplacer(
+		"${namespace}", ctx.Recommendation.Spec.TargetRef.Namespace,
+		"${name}", ctx.Recommendation.Spec.TargetRef.Name,
+	).Replace(query)
+}
+
 func (rr *ReplicasRecommender) PostProcessing(ctx *framework.RecommendationContext) error {
 	return nil
 }
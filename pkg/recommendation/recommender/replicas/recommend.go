@@ -11,9 +11,6 @@ import (
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
 
-	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
-
-	"github.com/gocrane/crane/pkg/prediction/config"
 	"github.com/gocrane/crane/pkg/recommend/types"
 	"github.com/gocrane/crane/pkg/recommendation/framework"
 	"github.com/gocrane/crane/pkg/utils"
@@ -29,29 +26,28 @@ type PatchReplicasSpec struct {
 
 func (rr *ReplicasRecommender) PreRecommend(ctx *framework.RecommendationContext) error {
 	// we load algorithm config in this phase
-	// TODO(chrisydxie) support configuration
-	config := &config.Config{
-		DSP: &predictionapi.DSP{
-			SampleInterval: "1m",
-			HistoryLength:  "7d",
-			Estimators:     predictionapi.Estimators{},
-		},
-	}
-	ctx.AlgorithmConfig = config
+	ctx.AlgorithmConfig = rr.AlgorithmConfig
 	return nil
 }
 
 func (rr *ReplicasRecommender) Recommend(ctx *framework.RecommendationContext) error {
-	p := ctx.PredictorMgr.GetPredictor(predictionapi.AlgorithmTypeDSP)
+	p := ctx.PredictorMgr.GetPredictor(rr.Algorithm)
+	if p == nil {
+		return fmt.Errorf("%s predictor %s is not found", rr.Name(), rr.Algorithm)
+	}
+	predictionLength, err := utils.ParseDuration(rr.PredictionLength)
+	if err != nil {
+		return err
+	}
 	timeNow := time.Now()
-	caller := fmt.Sprintf(rr.Name(), klog.KObj(ctx.RecommendationRule), ctx.RecommendationRule.UID)
+	caller := fmt.Sprintf(callerFormat, klog.KObj(ctx.Recommendation), ctx.Recommendation.UID)
 
-	// get workload cpu usage
+	// get workload usage of the selected metric
 	tsListPrediction, err := utils.QueryPredictedTimeSeriesOnce(p, caller,
 		ctx.AlgorithmConfig,
 		ctx.MetricNamer,
 		timeNow,
-		timeNow.Add(time.Hour*24*7))
+		timeNow.Add(predictionLength))
 
 	if err != nil {
 		klog.Warningf("%s: query predicted time series failed: %v ", ctx.String(), err)
@@ -125,25 +121,25 @@ func (rr *ReplicasRecommender) ProposeMinReplicas(resourceUsage float64, request
 	return minReplicas, nil
 }
 
-func (rr *ReplicasRecommender) GetMinReplicas(ctx *framework.RecommendationContext) (int32, float64, float64, error) {
-	var cpuUsages []float64
-	var cpuMax float64
-	// combine values from historic and prediction
-	for _, sample := range ctx.InputValue(string(corev1.ResourceCPU))[0].Samples {
-		cpuUsages = append(cpuUsages, sample.Value)
-		if sample.Value > cpuMax {
-			cpuMax = sample.Value
+// usages combines the historic values of the metric and the predicted values if the metric is the predicted one.
+func (rr *ReplicasRecommender) usages(ctx *framework.RecommendationContext, metric string) ([]float64, float64) {
+	var usages []float64
+	var max float64
+	samples := ctx.InputValue(metric)[0].Samples
+	if metric == rr.Metric && len(ctx.ResultValues) >= 1 {
+		samples = append(samples[:len(samples):len(samples)], ctx.ResultValues[0].Samples...)
+	}
+	for _, sample := range samples {
+		usages = append(usages, sample.Value)
+		if sample.Value > max {
+			max = sample.Value
 		}
 	}
+	return usages, max
+}
 
-	if len(ctx.ResultValues) >= 1 {
-		for _, sample := range ctx.ResultValues[0].Samples {
-			cpuUsages = append(cpuUsages, sample.Value)
-			if sample.Value > cpuMax {
-				cpuMax = sample.Value
-			}
-		}
-	}
+func (rr *ReplicasRecommender) GetMinReplicas(ctx *framework.RecommendationContext) (int32, float64, float64, error) {
+	cpuUsages, cpuMax := rr.usages(ctx, string(corev1.ResourceCPU))
 
 	// apply policy for predicted values
 	percentileCpu, err := stats.Percentile(cpuUsages, rr.CpuPercentile)
@@ -162,11 +158,7 @@ func (rr *ReplicasRecommender) GetMinReplicas(ctx *framework.RecommendationConte
 		return 0, 0, 0, fmt.Errorf("%s proposeMinReplicas for cpu failed: %v", rr.Name(), err)
 	}
 
-	var memUsages []float64
-	// combine values from historic and prediction
-	for _, sample := range ctx.InputValue(string(corev1.ResourceMemory))[0].Samples {
-		memUsages = append(memUsages, sample.Value)
-	}
+	memUsages, _ := rr.usages(ctx, string(corev1.ResourceMemory))
 
 	percentileMem, err := stats.Percentile(memUsages, rr.MemPercentile)
 	if err != nil {
@@ -181,12 +173,27 @@ func (rr *ReplicasRecommender) GetMinReplicas(ctx *framework.RecommendationConte
 	klog.Infof("%s: WorkloadMemoryUsage Percentile %f PodMemoryRequest %f MemTargetUtilization %f", ctx.String(), percentileMem, float64(requestTotalMem)/1000, rr.MemTargetUtilization)
 	minReplicasMem, err := rr.ProposeMinReplicas(percentileMem, requestTotalMem, rr.MemTargetUtilization)
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("%s proposeMinReplicas for cpu failed: %v", rr.Name(), err)
+		return 0, 0, 0, fmt.Errorf("%s proposeMinReplicas for memory failed: %v", rr.Name(), err)
 	}
 
 	if minReplicasMem > minReplicasCpu {
 		minReplicasCpu = minReplicasMem
 	}
 
+	if rr.Metric == CustomMetric {
+		customUsages, _ := rr.usages(ctx, CustomMetric)
+
+		percentileCustom, err := stats.Percentile(customUsages, rr.MetricPercentile)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("%s get percentileCustom failed: %v", rr.Name(), err)
+		}
+
+		klog.Infof("%s: WorkloadCustomMetric Percentile %f MetricTargetPerPod %f", ctx.String(), percentileCustom, rr.MetricTargetPerPod)
+		minReplicasCustom := int32(math.Ceil(percentileCustom / rr.MetricTargetPerPod))
+		if minReplicasCustom > minReplicasCpu {
+			minReplicasCpu = minReplicasCustom
+		}
+	}
+
 	return minReplicasCpu, cpuMax, percentileCpu, nil
 }
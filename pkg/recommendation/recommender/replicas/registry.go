@@ -1,11 +1,19 @@
 package replicas
 
 import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
 	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	predictionconfig "github.com/gocrane/crane/pkg/prediction/config"
 	"github.com/gocrane/crane/pkg/recommendation/config"
 	"github.com/gocrane/crane/pkg/recommendation/recommender"
 	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
 	"github.com/gocrane/crane/pkg/recommendation/recommender/base"
+	"github.com/gocrane/crane/pkg/utils"
 )
 
 var _ recommender.Recommender = &ReplicasRecommender{}
@@ -20,6 +28,15 @@ type ReplicasRecommender struct {
 	DefaultMinReplicas   int64
 	CPUTargetUtilization float64
 	MemTargetUtilization float64
+	Algorithm            predictionapi.AlgorithmType
+	HistoryLength        string
+	PredictionLength     string
+	SampleInterval       string
+	AlgorithmConfig      *predictionconfig.Config
+	Metric               string
+	MetricQuery          string
+	MetricTargetPerPod   float64
+	MetricPercentile     float64
 }
 
 func init() {
@@ -76,6 +93,46 @@ func NewReplicasRecommender(recommender apis.Recommender, recommendationRule ana
 		return nil, err
 	}
 
+	algorithm := predictionapi.AlgorithmType(recommender.GetConfigString("algorithm", string(predictionapi.AlgorithmTypeDSP)))
+	historyLength := recommender.GetConfigString("history-length", "7d")
+	if _, err = utils.ParseDuration(historyLength); err != nil {
+		return nil, fmt.Errorf("parse history-length failed: %v", err)
+	}
+	predictionLength := recommender.GetConfigString("prediction-length", "7d")
+	if _, err = utils.ParseDuration(predictionLength); err != nil {
+		return nil, fmt.Errorf("parse prediction-length failed: %v", err)
+	}
+	sampleInterval := recommender.GetConfigString("sample-interval", "1m")
+	if _, err = utils.ParseDuration(sampleInterval); err != nil {
+		return nil, fmt.Errorf("parse sample-interval failed: %v", err)
+	}
+
+	metric := recommender.GetConfigString("metric", corev1.ResourceCPU.String())
+	metricQuery := recommender.GetConfigString("metric-query", "")
+	metricTargetPerPodFloat, err := recommender.GetConfigFloat("metric-target-per-pod", 0)
+	if err != nil {
+		return nil, err
+	}
+	metricPercentileFloat, err := recommender.GetConfigFloat("metric-percentile", 0.95)
+	if err != nil {
+		return nil, err
+	}
+	metricPercentileFloat = metricPercentileFloat * 100
+	switch metric {
+	case corev1.ResourceCPU.String(), corev1.ResourceMemory.String():
+	case CustomMetric:
+		if metricQuery == "" || metricTargetPerPodFloat <= 0 {
+			return nil, fmt.Errorf("metric-query and a positive metric-target-per-pod are required by custom metric")
+		}
+	default:
+		return nil, fmt.Errorf("metric %s is not supported", metric)
+	}
+
+	algorithmConfig, err := makeAlgorithmConfig(recommender, algorithm, metric, historyLength, sampleInterval)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ReplicasRecommender{
 		*base.NewBaseRecommender(recommender),
 		workloadMinReplicasInt,
@@ -86,5 +143,14 @@ func NewReplicasRecommender(recommender apis.Recommender, recommendationRule ana
 		defaultMinReplicasInt,
 		cpuTargetUtilizationFloat,
 		memTargetUtilizationFloat,
+		algorithm,
+		historyLength,
+		predictionLength,
+		sampleInterval,
+		algorithmConfig,
+		metric,
+		metricQuery,
+		metricTargetPerPodFloat,
+		metricPercentileFloat,
 	}, nil
 }
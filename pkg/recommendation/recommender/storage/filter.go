@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+)
+
+// Filter out k8s resources that are not supported by the recommender.
+func (sr *StorageRecommender) Filter(ctx *framework.RecommendationContext) error {
+	var err error
+
+	// filter resource that not match objectIdentity
+	if err = sr.BaseRecommender.Filter(ctx); err != nil {
+		return err
+	}
+
+	var sts appsv1.StatefulSet
+	if err = framework.ObjectConversion(ctx.Object, &sts); err != nil {
+		return err
+	}
+
+	// filter statefulsets without persistent storage
+	if len(sts.Spec.VolumeClaimTemplates) == 0 {
+		return fmt.Errorf("volume claim template not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+package storage
+
+import (
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+)
+
+// Observe enhance the observability.
+func (sr *StorageRecommender) Observe(ctx *framework.RecommendationContext) error {
+	return nil
+}
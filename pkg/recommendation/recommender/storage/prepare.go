@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/providers"
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const callerFormat = "StorageRecommendationCaller-%s-%s"
+
+// CheckDataProviders in PrePrepare phase, will create data source provider via your recommendation config.
+func (sr *StorageRecommender) CheckDataProviders(ctx *framework.RecommendationContext) error {
+	if err := sr.BaseRecommender.CheckDataProviders(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sr *StorageRecommender) CollectData(ctx *framework.RecommendationContext) error {
+	var sts appsv1.StatefulSet
+	if err := framework.ObjectConversion(ctx.Object, &sts); err != nil {
+		return err
+	}
+
+	timeNow := time.Now()
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		metricNamer := sr.usedBytesMetricNamer(ctx, template.Name)
+		if err := metricNamer.Validate(); err != nil {
+			return err
+		}
+
+		// get used bytes of the pvcs of the volume claim template
+		klog.Infof("%s: %s StorageQuery %s", ctx.String(), sr.Name(), metricNamer.BuildUniqueKey())
+		tsList, err := ctx.DataProviders[providers.PrometheusDataSource].QueryTimeSeries(metricNamer, timeNow.Add(-sr.HistoryLength), timeNow, time.Minute)
+		if err != nil {
+			return fmt.Errorf("%s query historic metrics failed: %v ", sr.Name(), err)
+		}
+		if len(tsList) != 1 {
+			return fmt.Errorf("%s query historic metrics data is unexpected, List length is %d ", sr.Name(), len(tsList))
+		}
+		ctx.AddInputValue(template.Name, tsList)
+	}
+
+	return nil
+}
+
+func (sr *StorageRecommender) PostProcessing(ctx *framework.RecommendationContext) error {
+	return nil
+}
+
+func (sr *StorageRecommender) usedBytesMetricNamer(ctx *framework.RecommendationContext, claimTemplateName string) metricnaming.MetricNamer {
+	caller := fmt.Sprintf(callerFormat, klog.KObj(ctx.Recommendation), ctx.Recommendation.UID)
+	queryExpr := utils.GetStatefulSetPvcUsedBytesExpression(ctx.Object.GetNamespace(), ctx.Object.GetName(), claimTemplateName)
+	return metricnaming.ResourceToGeneralMetricNamer(queryExpr, corev1.ResourceStorage, labels.Everything(), caller)
+}
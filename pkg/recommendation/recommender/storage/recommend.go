@@ -0,0 +1,275 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/recommend/types"
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const gib = 1024 * 1024 * 1024
+
+// PatchPersistentVolumeClaim is the patch of the storage request of a bound pvc created by a volume claim template.
+// The volume claim templates of statefulset are immutable, so the pvcs are expanded instead.
+type PatchPersistentVolumeClaim struct {
+	Metadata PatchPersistentVolumeClaimMeta `json:"metadata"`
+	Spec     PatchPersistentVolumeClaimSpec `json:"spec"`
+}
+
+type PatchPersistentVolumeClaimMeta struct {
+	Name string `json:"name"`
+}
+
+type PatchPersistentVolumeClaimSpec struct {
+	Resources corev1.ResourceRequirements `json:"resources"`
+}
+
+func (sr *StorageRecommender) PreRecommend(ctx *framework.RecommendationContext) error {
+	ctx.AlgorithmConfig = &config.Config{
+		DSP: &predictionapi.DSP{
+			SampleInterval: "1m",
+			HistoryLength:  sr.HistoryLength.String(),
+			Estimators:     predictionapi.Estimators{},
+		},
+	}
+	return nil
+}
+
+func (sr *StorageRecommender) Recommend(ctx *framework.RecommendationContext) error {
+	var sts appsv1.StatefulSet
+	if err := framework.ObjectConversion(ctx.Object, &sts); err != nil {
+		return err
+	}
+
+	p := ctx.PredictorMgr.GetPredictor(predictionapi.AlgorithmTypeDSP)
+	caller := fmt.Sprintf(callerFormat, klog.KObj(ctx.Recommendation), ctx.Recommendation.UID)
+	timeNow := time.Now()
+
+	claims, err := sr.boundClaims(ctx, &sts)
+	if err != nil {
+		return err
+	}
+
+	storageRecommendation := &types.StorageRequestRecommendation{}
+	var newPatches, oldPatches []PatchPersistentVolumeClaim
+	var descriptions []string
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		tsList := ctx.InputValue(template.Name)
+		if len(tsList) < 1 || len(tsList[0].Samples) < 1 {
+			return fmt.Errorf("no used bytes of volume claim template %s", template.Name)
+		}
+		peak := maxValue(tsList[0].Samples)
+
+		// predict the growth of used bytes, it is extrapolated linearly by the history if the prediction is not ready
+		tsListPrediction, err := utils.QueryPredictedTimeSeriesOnce(p, caller, ctx.AlgorithmConfig,
+			sr.usedBytesMetricNamer(ctx, template.Name), timeNow, timeNow.Add(sr.PredictionLength))
+		if err == nil && len(tsListPrediction) == 1 && len(tsListPrediction[0].Samples) != 0 {
+			peak = math.Max(peak, maxValue(tsListPrediction[0].Samples))
+		} else {
+			klog.Warningf("%s: query predicted used bytes of volume claim template %s failed, extrapolate the history linearly: %v", ctx.String(), template.Name, err)
+			peak = math.Max(peak, linearExtrapolate(tsList[0].Samples, timeNow.Add(sr.PredictionLength)))
+		}
+
+		templateTarget := sr.proposeStorage(peak, template.Spec.Resources.Requests[corev1.ResourceStorage])
+		templateRecommendation := types.VolumeClaimTemplateRecommendation{
+			Name:      template.Name,
+			Target:    templateTarget.String(),
+			PeakUsage: resource.NewQuantity(int64(peak), resource.BinarySI).String(),
+		}
+
+		for _, claim := range claims[template.Name] {
+			current := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+			target := sr.proposeStorage(peak, current)
+			klog.Infof("%s: pvc %s peak used bytes %.0f current storage %s recommended storage %s", ctx.String(), claim.Name, peak, current.String(), target.String())
+			if target.Cmp(current) == 0 {
+				continue
+			}
+
+			templateRecommendation.PersistentVolumeClaims = append(templateRecommendation.PersistentVolumeClaims, types.PersistentVolumeClaimRecommendation{
+				Name:    claim.Name,
+				Current: current.String(),
+				Target:  target.String(),
+			})
+			if target.Cmp(current) < 0 {
+				// pvcs can not be shrunk, the data needs to be migrated to smaller volumes by users
+				descriptions = append(descriptions, fmt.Sprintf("%s could be shrunk from %s to %s by migrating to a new volume", claim.Name, current.String(), target.String()))
+				continue
+			}
+			if expandable, err := sr.allowVolumeExpansion(ctx, claim); err != nil {
+				return err
+			} else if !expandable {
+				descriptions = append(descriptions, fmt.Sprintf("%s needs to be expanded from %s to %s, but its storage class does not allow volume expansion", claim.Name, current.String(), target.String()))
+				continue
+			}
+			descriptions = append(descriptions, fmt.Sprintf("expand %s from %s to %s", claim.Name, current.String(), target.String()))
+			newPatches = append(newPatches, makePatchPersistentVolumeClaim(claim.Name, target))
+			oldPatches = append(oldPatches, makePatchPersistentVolumeClaim(claim.Name, current))
+		}
+
+		storageRecommendation.VolumeClaimTemplates = append(storageRecommendation.VolumeClaimTemplates, templateRecommendation)
+	}
+
+	value := types.ProposedRecommendation{
+		StorageRequest: storageRecommendation,
+	}
+	valueBytes, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%s yaml marshal failed: %v", sr.Name(), err)
+	}
+	ctx.Recommendation.Status.RecommendedValue = string(valueBytes)
+	ctx.Recommendation.Status.Description = strings.Join(descriptions, ", ")
+
+	// only the expansion of pvcs is an action, the shrink is informational
+	if len(newPatches) == 0 {
+		ctx.Recommendation.Status.Action = "None"
+		ctx.Recommendation.Status.RecommendedInfo = ""
+		ctx.Recommendation.Status.CurrentInfo = ""
+		return nil
+	}
+
+	newPatchBytes, err := json.Marshal(newPatches)
+	if err != nil {
+		return fmt.Errorf("marshal newPatch failed %s. ", err)
+	}
+	oldPatchBytes, err := json.Marshal(oldPatches)
+	if err != nil {
+		return fmt.Errorf("marshal oldPatch failed %s. ", err)
+	}
+
+	ctx.Recommendation.Status.Action = "Patch"
+	ctx.Recommendation.Status.RecommendedInfo = string(newPatchBytes)
+	ctx.Recommendation.Status.CurrentInfo = string(oldPatchBytes)
+
+	return nil
+}
+
+// boundClaims returns the bound pvcs of the statefulset keyed by volume claim template, the pvcs are named as
+// <template>-<statefulset>-<ordinal>.
+func (sr *StorageRecommender) boundClaims(ctx *framework.RecommendationContext, sts *appsv1.StatefulSet) (map[string][]corev1.PersistentVolumeClaim, error) {
+	var pvcList corev1.PersistentVolumeClaimList
+	if err := ctx.Client.List(ctx.Context, &pvcList, client.InNamespace(sts.Namespace)); err != nil {
+		return nil, fmt.Errorf("list pvcs failed: %v", err)
+	}
+
+	claims := make(map[string][]corev1.PersistentVolumeClaim)
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		prefix := fmt.Sprintf("%s-%s-", template.Name, sts.Name)
+		for _, pvc := range pvcList.Items {
+			if !strings.HasPrefix(pvc.Name, prefix) || pvc.Status.Phase != corev1.ClaimBound {
+				continue
+			}
+			if _, err := strconv.Atoi(strings.TrimPrefix(pvc.Name, prefix)); err != nil {
+				continue
+			}
+			claims[template.Name] = append(claims[template.Name], pvc)
+		}
+	}
+	return claims, nil
+}
+
+// allowVolumeExpansion returns true if the storage class of pvc allows volume expansion
+func (sr *StorageRecommender) allowVolumeExpansion(ctx *framework.RecommendationContext, pvc corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || len(*pvc.Spec.StorageClassName) == 0 {
+		return false, nil
+	}
+	var storageClass storagev1.StorageClass
+	if err := ctx.Client.Get(ctx.Context, client.ObjectKey{Name: *pvc.Spec.StorageClassName}, &storageClass); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("get storage class %s failed: %v", *pvc.Spec.StorageClassName, err)
+	}
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
+}
+
+// Policy add some logic for result of recommend phase.
+func (sr *StorageRecommender) Policy(ctx *framework.RecommendationContext) error {
+	return nil
+}
+
+// proposeStorage returns the storage request for the peak used bytes. The current request is kept if its utilization
+// by the peak usage is between the shrink and expand utilization, otherwise the request is resized to make the
+// utilization close to the target utilization.
+func (sr *StorageRecommender) proposeStorage(peak float64, current resource.Quantity) resource.Quantity {
+	if !current.IsZero() {
+		utilization := peak / current.AsApproximateFloat64()
+		if utilization >= sr.ShrinkUtilization && utilization <= sr.ExpandUtilization {
+			return current
+		}
+	}
+
+	// round up to GiB, which is the granularity of most storage providers
+	bytes := int64(math.Ceil(peak/sr.TargetUtilization/gib)) * gib
+	if bytes < sr.MinStorage {
+		bytes = sr.MinStorage
+	}
+	if !current.IsZero() && bytes == current.Value() {
+		return current
+	}
+	return *resource.NewQuantity(bytes, resource.BinarySI)
+}
+
+func makePatchPersistentVolumeClaim(name string, storage resource.Quantity) PatchPersistentVolumeClaim {
+	return PatchPersistentVolumeClaim{
+		Metadata: PatchPersistentVolumeClaimMeta{Name: name},
+		Spec: PatchPersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: storage},
+			},
+		},
+	}
+}
+
+func maxValue(samples []common.Sample) float64 {
+	var max float64
+	for _, sample := range samples {
+		max = math.Max(max, sample.Value)
+	}
+	return max
+}
+
+// linearExtrapolate fits the samples by least squares and returns the value of the line at the end time
+func linearExtrapolate(samples []common.Sample, end time.Time) float64 {
+	if len(samples) < 2 {
+		return maxValue(samples)
+	}
+
+	var meanX, meanY float64
+	for _, sample := range samples {
+		meanX += float64(sample.Timestamp)
+		meanY += sample.Value
+	}
+	meanX /= float64(len(samples))
+	meanY /= float64(len(samples))
+
+	var covariance, variance float64
+	for _, sample := range samples {
+		dx := float64(sample.Timestamp) - meanX
+		covariance += dx * (sample.Value - meanY)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return meanY
+	}
+	slope := covariance / variance
+	return meanY + slope*(float64(end.Unix())-meanX)
+}
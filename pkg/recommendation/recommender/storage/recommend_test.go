@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/recommendation/framework"
+)
+
+func TestProposeStorage(t *testing.T) {
+	sr := &StorageRecommender{
+		TargetUtilization: 0.7,
+		ExpandUtilization: 0.85,
+		ShrinkUtilization: 0.3,
+		MinStorage:        gib,
+	}
+
+	tests := []struct {
+		name    string
+		peak    float64
+		current string
+		want    string
+	}{
+		{
+			name:    "keep",
+			peak:    6 * gib,
+			current: "10Gi",
+			want:    "10Gi",
+		},
+		{
+			name:    "expand",
+			peak:    9 * gib,
+			current: "10Gi",
+			want:    "13Gi",
+		},
+		{
+			name:    "shrink",
+			peak:    1.5 * gib,
+			current: "10Gi",
+			want:    "3Gi",
+		},
+		{
+			name:    "shrink to min storage",
+			peak:    0.1 * gib,
+			current: "10Gi",
+			want:    "1Gi",
+		},
+		{
+			name: "no request",
+			peak: 7 * gib,
+			want: "10Gi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current resource.Quantity
+			if len(tt.current) != 0 {
+				current = resource.MustParse(tt.current)
+			}
+			got := sr.proposeStorage(tt.peak, current)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestLinearExtrapolate(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	var samples []common.Sample
+	for i := 0; i < 24; i++ {
+		samples = append(samples, common.Sample{Timestamp: start.Add(time.Duration(i) * time.Hour).Unix(), Value: float64(100 + i*10)})
+	}
+
+	assert.InDelta(t, 100+47*10, linearExtrapolate(samples, start.Add(47*time.Hour)), 1e-6)
+	assert.Equal(t, float64(5), linearExtrapolate([]common.Sample{{Timestamp: start.Unix(), Value: 5}}, start.Add(time.Hour)))
+}
+
+func TestBoundClaims(t *testing.T) {
+	expandable, fixed := "expandable", "fixed"
+	allow := true
+	newClaim := func(name string, phase corev1.PersistentVolumeClaimPhase, storageClass *string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: storageClass},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	ctx := &framework.RecommendationContext{
+		Context: context.TODO(),
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: expandable}, AllowVolumeExpansion: &allow},
+			&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: fixed}},
+			newClaim("data-web-0", corev1.ClaimBound, &expandable),
+			newClaim("data-web-1", corev1.ClaimBound, &fixed),
+			newClaim("data-web-2", corev1.ClaimPending, &expandable),
+			newClaim("data-web-backup", corev1.ClaimBound, &expandable),
+			newClaim("data-webapp-0", corev1.ClaimBound, &expandable),
+		).Build(),
+	}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: appsv1.StatefulSetSpec{
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+		},
+	}
+
+	sr := &StorageRecommender{}
+	claims, err := sr.boundClaims(ctx, sts)
+	assert.NoError(t, err)
+	var names []string
+	for _, claim := range claims["data"] {
+		names = append(names, claim.Name)
+	}
+	assert.Equal(t, []string{"data-web-0", "data-web-1"}, names)
+
+	ok, err := sr.allowVolumeExpansion(ctx, claims["data"][0])
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = sr.allowVolumeExpansion(ctx, claims["data"][1])
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"time"
+
+	analysisv1alph1 "github.com/gocrane/api/analysis/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/recommendation/config"
+	"github.com/gocrane/crane/pkg/recommendation/recommender"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/apis"
+	"github.com/gocrane/crane/pkg/recommendation/recommender/base"
+)
+
+var _ recommender.Recommender = &StorageRecommender{}
+
+type StorageRecommender struct {
+	base.BaseRecommender
+	// HistoryLength is the length of used bytes history to analyse
+	HistoryLength time.Duration
+	// PredictionLength is how far the growth of used bytes is predicted
+	PredictionLength time.Duration
+	// TargetUtilization is the utilization of the recommended storage request by the peak usage
+	TargetUtilization float64
+	// ExpandUtilization is the utilization above which the storage request is expanded
+	ExpandUtilization float64
+	// ShrinkUtilization is the utilization below which the storage request is shrunk
+	ShrinkUtilization float64
+	// MinStorage is the min recommended storage request in bytes
+	MinStorage int64
+}
+
+func init() {
+	recommender.RegisterRecommenderProvider(recommender.StorageRecommender, NewStorageRecommender)
+}
+
+func (sr *StorageRecommender) Name() string {
+	return recommender.StorageRecommender
+}
+
+// NewStorageRecommender create a new storage recommender.
+func NewStorageRecommender(recommender apis.Recommender, recommendationRule analysisv1alph1.RecommendationRule) (recommender.Recommender, error) {
+	recommender = config.MergeRecommenderConfigFromRule(recommender, recommendationRule)
+
+	historyLength, err := recommender.GetConfigDuration("history-length", 168*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	predictionLength, err := recommender.GetConfigDuration("prediction-length", 168*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	targetUtilization, err := recommender.GetConfigFloat("target-utilization", 0.7)
+	if err != nil {
+		return nil, err
+	}
+
+	expandUtilization, err := recommender.GetConfigFloat("expand-utilization", 0.85)
+	if err != nil {
+		return nil, err
+	}
+
+	shrinkUtilization, err := recommender.GetConfigFloat("shrink-utilization", 0.3)
+	if err != nil {
+		return nil, err
+	}
+
+	minStorage, err := recommender.GetConfigInt("min-storage-bytes", 1024*1024*1024)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StorageRecommender{
+		*base.NewBaseRecommender(recommender),
+		historyLength,
+		predictionLength,
+		targetUtilization,
+		expandUtilization,
+		shrinkUtilization,
+		minStorage,
+	}, nil
+}
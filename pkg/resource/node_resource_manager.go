@@ -299,6 +299,9 @@ func (o *NodeResourceManager) GetCanNotBeReclaimedResourceFromTsp(node *v1.Node)
 			continue
 		}
 		for _, timeSeries := range predictionMetric.Prediction {
+			if utils.GetPredictionBound(timeSeries) != "" {
+				continue
+			}
 			var nextUsage float64
 			var nextUsageFloat float64
 			var err error
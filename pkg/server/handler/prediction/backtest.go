@@ -0,0 +1,138 @@
+package prediction
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/controller/timeseriesprediction"
+	"github.com/gocrane/crane/pkg/prediction/backtest"
+	"github.com/gocrane/crane/pkg/providers"
+	"github.com/gocrane/crane/pkg/server/ginwrapper"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// BacktestResult is the accuracy of an algorithm configuration replaying the history of a metric
+type BacktestResult struct {
+	ResourceIdentifier string             `json:"resourceIdentifier"`
+	Candidate          string             `json:"candidate"`
+	Algorithm          v1alpha1.Algorithm `json:"algorithm"`
+	// MAPE is absent if any actual value is too close to zero
+	MAPE      *float64 `json:"mape,omitempty"`
+	MAE       *float64 `json:"mae,omitempty"`
+	Count     int      `json:"count"`
+	Forecasts int      `json:"forecasts"`
+	// Best is whether the candidate is the most accurate one of the metric
+	Best  bool   `json:"best"`
+	Error string `json:"error,omitempty"`
+}
+
+type historyProviderGetter interface {
+	GetHistoryProvider() providers.History
+}
+
+// Backtest replays the history of each metric of the tsp, and scores its configured algorithm and the default dsp and
+// percentile configurations. The query parameters historyLength, horizon, step and sampleInterval configure the backtest,
+// and window is the length of the replayed history.
+func (dh *DebugHandler) Backtest(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("tsp")
+	if len(namespace) == 0 || len(name) == 0 {
+		c.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	historyLength := c.DefaultQuery("historyLength", "7d")
+	sampleInterval := c.DefaultQuery("sampleInterval", "1m")
+	var config backtest.Config
+	var window time.Duration
+	var err error
+	for _, param := range []struct {
+		name     string
+		value    string
+		duration *time.Duration
+	}{
+		{"historyLength", historyLength, &config.HistoryLength},
+		{"sampleInterval", sampleInterval, &config.SampleInterval},
+		{"horizon", c.DefaultQuery("horizon", "1d"), &config.Horizon},
+		{"step", c.DefaultQuery("step", "1d"), &config.Step},
+		{"window", c.DefaultQuery("window", "3d"), &window},
+	} {
+		if *param.duration, err = utils.ParseDuration(param.value); err != nil {
+			ginwrapper.WriteResponse(c, fmt.Errorf("invalid %s: %v", param.name, err), nil)
+			return
+		}
+	}
+
+	tsp, err := dh.craneClient.PredictionV1alpha1().TimeSeriesPredictions(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		ginwrapper.WriteResponse(c, err, nil)
+		return
+	}
+
+	getter, ok := dh.predictorManager.GetPredictor(v1alpha1.AlgorithmTypeDSP).(historyProviderGetter)
+	if !ok || getter.GetHistoryProvider() == nil {
+		ginwrapper.WriteResponse(c, fmt.Errorf("history provider not provisioned"), nil)
+		return
+	}
+
+	mc, err := timeseriesprediction.NewMetricContext(dh.selectorFetcher, tsp, dh.predictorManager)
+	if err != nil {
+		ginwrapper.WriteResponse(c, err, nil)
+		return
+	}
+
+	end := time.Now().Truncate(config.SampleInterval)
+	start := end.Add(-window)
+	var results []BacktestResult
+	for i := range tsp.Spec.PredictionMetrics {
+		metric := &tsp.Spec.PredictionMetrics[i]
+		candidates := backtest.DefaultCandidates(historyLength, sampleInterval)
+		if metric.Algorithm.AlgorithmType == v1alpha1.AlgorithmTypeDSP || metric.Algorithm.AlgorithmType == v1alpha1.AlgorithmTypePercentile {
+			candidates = append([]backtest.Candidate{{Name: "configured", Algorithm: metric.Algorithm}}, candidates...)
+		}
+
+		namer := mc.GetMetricNamer(metric)
+		if namer == nil {
+			results = append(results, BacktestResult{ResourceIdentifier: metric.ResourceIdentifier, Error: "metric query is not supported"})
+			continue
+		}
+		metricResults, err := backtest.Run(c.Request.Context(), getter.GetHistoryProvider(), namer, start, end, config, candidates)
+		if err != nil {
+			results = append(results, BacktestResult{ResourceIdentifier: metric.ResourceIdentifier, Error: err.Error()})
+			continue
+		}
+
+		best := backtest.Best(metricResults)
+		for _, result := range metricResults {
+			r := BacktestResult{
+				ResourceIdentifier: metric.ResourceIdentifier,
+				Candidate:          result.Candidate.Name,
+				Algorithm:          result.Candidate.Algorithm,
+				Forecasts:          result.Forecasts,
+				Best:               result == best,
+			}
+			if result.Score != nil {
+				if !math.IsNaN(result.Score.MAPE) {
+					mape := result.Score.MAPE
+					r.MAPE = &mape
+				}
+				mae := result.Score.MAE
+				r.MAE = &mae
+				r.Count = result.Score.Count
+			}
+			if result.Err != nil {
+				r.Error = result.Err.Error()
+			}
+			results = append(results, r)
+		}
+	}
+
+	ginwrapper.WriteResponse(c, nil, results)
+}
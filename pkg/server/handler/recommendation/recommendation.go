@@ -2,9 +2,11 @@ package recommendation
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	patchtypes "k8s.io/apimachinery/pkg/types"
@@ -14,6 +16,7 @@ import (
 
 	analysisapi "github.com/gocrane/api/analysis/v1alpha1"
 
+	"github.com/gocrane/crane/pkg/recommendation/cost"
 	"github.com/gocrane/crane/pkg/recommendation/recommender"
 	"github.com/gocrane/crane/pkg/server/config"
 	"github.com/gocrane/crane/pkg/server/ginwrapper"
@@ -48,6 +51,17 @@ func (h *Handler) ListRecommendations(c *gin.Context) {
 	ginwrapper.WriteResponse(c, nil, recommendList)
 }
 
+// ListSavings list the estimated monthly savings of recommendations per target namespace and recommendationRule.
+func (h *Handler) ListSavings(c *gin.Context) {
+	recommendList := &analysisapi.RecommendationList{}
+	err := h.client.List(context.TODO(), recommendList)
+	if err != nil {
+		ginwrapper.WriteResponse(c, err, nil)
+		return
+	}
+	ginwrapper.WriteResponse(c, nil, cost.AggregateSavings(recommendList.Items))
+}
+
 // ListRecommendationRules list the recommendationRules in cluster.
 func (h *Handler) ListRecommendationRules(c *gin.Context) {
 	recommendationRuleList := &analysisapi.RecommendationRuleList{}
@@ -128,6 +142,28 @@ func (h *Handler) AdoptRecommendation(c *gin.Context) {
 			return
 		}
 
+		ginwrapper.WriteResponse(c, nil, nil)
+	} else if string(recommendationExist.Spec.Type) == recommender.StorageRecommender {
+		// the storage recommendation is adopted by expanding the bound pvcs, the volume claim templates are immutable
+		var patches []json.RawMessage
+		if err := json.Unmarshal([]byte(recommendationExist.Status.RecommendedInfo), &patches); err != nil {
+			ginwrapper.WriteResponse(c, fmt.Errorf("Recommendation %s has no pvc to expand: %v ", recommendationExist.Name, err), nil)
+			return
+		}
+
+		for _, patch := range patches {
+			var pvc metav1.PartialObjectMetadata
+			if err := json.Unmarshal(patch, &pvc); err != nil {
+				ginwrapper.WriteResponse(c, err, nil)
+				return
+			}
+			_, err := h.dynamicClient.Resource(corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims")).Namespace(recommendationExist.Spec.TargetRef.Namespace).Patch(context.TODO(), pvc.Name, patchtypes.StrategicMergePatchType, patch, metav1.PatchOptions{})
+			if err != nil {
+				ginwrapper.WriteResponse(c, err, nil)
+				return
+			}
+		}
+
 		ginwrapper.WriteResponse(c, nil, nil)
 	} else {
 		ginwrapper.WriteResponse(c, fmt.Errorf("Recommendation type %s is not supported for adoption ", string(recommendationExist.Spec.Type)), nil)
@@ -47,6 +47,7 @@ func (s *apiServer) initRouter() {
 		recommendv1 := v1.Group("/recommendation")
 		{
 			recommendv1.GET("", recommendationHandler.ListRecommendations)
+			recommendv1.GET("/savings", recommendationHandler.ListSavings)
 			recommendv1.POST("/adopt/:namespace/:recommendationName", recommendationHandler.AdoptRecommendation)
 		}
 
@@ -72,5 +73,9 @@ func (s *apiServer) initRouter() {
 	{
 		debug.GET(":namespace/:tsp", debugHandler.Display)
 	}
+	backtest := s.Group("/api/prediction/backtest")
+	{
+		backtest.GET(":namespace/:tsp", debugHandler.Backtest)
+	}
 
 }
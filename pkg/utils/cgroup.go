@@ -2,7 +2,11 @@ package utils
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
@@ -109,3 +113,43 @@ func ExpandSlice(slice string) (string, error) {
 	}
 	return path, nil
 }
+
+// GetCgroupRoot returns the mount point of the controller in cgroup v1, or the mount point of cgroup v2.
+func GetCgroupRoot(sysPath string, controller string) (root string, unified bool) {
+	cgroupRoot := filepath.Join(sysPath, "fs", "cgroup")
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err == nil {
+		return cgroupRoot, true
+	}
+	return filepath.Join(cgroupRoot, controller), false
+}
+
+// GetFirstPidInCgroup walks the cgroup and its descendants, returns the first process found in cgroup.procs.
+func GetFirstPidInCgroup(cgroupRoot string, cgroupPath string) (int, error) {
+	var pid int
+	errFound := fmt.Errorf("found")
+	err := filepath.Walk(filepath.Join(cgroupRoot, cgroupPath), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != "cgroup.procs" {
+			return nil
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		for _, field := range strings.Fields(string(content)) {
+			if pid, err = strconv.Atoi(field); err == nil && pid > 0 {
+				return errFound
+			}
+		}
+		return nil
+	})
+	if err == errFound {
+		return pid, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no process found in cgroup %s", cgroupPath)
+}
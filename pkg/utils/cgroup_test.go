@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -211,3 +214,29 @@ func TestExpandSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestGetFirstPidInCgroup(t *testing.T) {
+	root := t.TempDir()
+	podPath := "/kubepods/pod123"
+	for path, content := range map[string]string{
+		podPath + "/cgroup.procs":           "",
+		podPath + "/pause/cgroup.procs":     "",
+		podPath + "/container/cgroup.procs": "42\n43\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(root, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pid, err := GetFirstPidInCgroup(root, podPath)
+	if err != nil || pid != 42 {
+		t.Errorf("expect pid 42, got %d, %v", pid, err)
+	}
+
+	if _, err = GetFirstPidInCgroup(root, podPath+"/pause"); err == nil {
+		t.Errorf("expect error for cgroup without process")
+	}
+}
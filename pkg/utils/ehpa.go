@@ -45,6 +45,42 @@ func GetPredictionMetricName(sourceType autoscalingv2.MetricSourceType) (metricN
 	return metricName
 }
 
+// GetEHPAPredictionBound return the bound of the prediction interval the ehpa scales on, it is empty if the ehpa
+// scales on the point forecast
+func GetEHPAPredictionBound(ehpa *autoscalingapi.EffectiveHorizontalPodAutoscaler) string {
+	switch bound := ehpa.Annotations[known.EffectiveHorizontalPodAutoscalerPredictionBoundAnnotation]; bound {
+	case known.PredictionBoundLower, known.PredictionBoundUpper:
+		return bound
+	}
+	return ""
+}
+
+// GetPredictionBoundMetricName return metric name used by prediction for the bound of the prediction interval, it is
+// the metric name of the point forecast if the bound is empty
+func GetPredictionBoundMetricName(metricName string, bound string) string {
+	if metricName != known.MetricNamePrediction {
+		return metricName
+	}
+	switch bound {
+	case known.PredictionBoundLower:
+		return known.MetricNamePredictionLower
+	case known.PredictionBoundUpper:
+		return known.MetricNamePredictionUpper
+	}
+	return metricName
+}
+
+// GetPredictionMetricBound return the bound of the prediction interval of the metric name used by prediction
+func GetPredictionMetricBound(metricName string) string {
+	switch metricName {
+	case known.MetricNamePredictionLower:
+		return known.PredictionBoundLower
+	case known.MetricNamePredictionUpper:
+		return known.PredictionBoundUpper
+	}
+	return ""
+}
+
 // GetCronMetricName return metric name used by cron
 func GetCronMetricName() string {
 	return known.MetricNameCron
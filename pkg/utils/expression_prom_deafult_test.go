@@ -88,6 +88,27 @@ func TestGetWorkloadMemUsageExpression(t *testing.T) {
 	}
 }
 
+func TestGetWorkloadReplicasExpression(t *testing.T) {
+	test := struct {
+		description string
+		namespace   string
+		name        string
+		kind        string
+		expect      string
+	}{
+		description: "GetWorkloadReplicasExpression",
+		namespace:   "default",
+		name:        "test",
+		kind:        "Deployment",
+		expect:      "count(sum(container_memory_working_set_bytes{namespace=\"default\",pod=~\"^test-[a-z0-9]+-[a-z0-9]{5}$\",container!=\"\"}) by (pod))",
+	}
+
+	requests := GetWorkloadReplicasExpression(test.namespace, test.name, test.kind)
+	if requests != test.expect {
+		t.Errorf("expect requests %s actual requests %s", test.expect, requests)
+	}
+}
+
 func TestGetContainerCpuUsageExpression(t *testing.T) {
 	test := struct {
 		description   string
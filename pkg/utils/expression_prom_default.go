@@ -10,6 +10,8 @@ const (
 	ExtensionLabelsHolder = `EXTENSION_LABELS_HOLDER`
 	// WorkloadCpuUsageExprTemplate is used to query workload cpu usage by promql,  param is namespace,workload-name,duration str
 	WorkloadCpuUsageExprTemplate = `sum(irate(container_cpu_usage_seconds_total{namespace="%s",pod=~"%s",container!=""EXTENSION_LABELS_HOLDER}[%s]))`
+	// WorkloadCpuThrottleRatioExprTemplate is used to query the ratio of throttled cfs periods of workload by promql, param is namespace,workload-name,namespace,workload-name
+	WorkloadCpuThrottleRatioExprTemplate = `sum(rate(container_cpu_cfs_throttled_periods_total{namespace="%s",pod=~"%s",container!=""EXTENSION_LABELS_HOLDER}[3m])) / sum(rate(container_cpu_cfs_periods_total{namespace="%s",pod=~"%s",container!=""EXTENSION_LABELS_HOLDER}[3m]))`
 	// WorkloadMemUsageExprTemplate is used to query workload mem usage by promql, param is namespace, workload-name
 	WorkloadMemUsageExprTemplate = `sum(container_memory_working_set_bytes{namespace="%s",pod=~"%s",container!=""EXTENSION_LABELS_HOLDER})`
 
@@ -38,6 +40,12 @@ const (
 	// ContainerMemUsageExprTemplate is used to query container cpu usage by promql,  param is namespace,pod,container
 	ContainerMemUsageExprTemplate = `container_memory_working_set_bytes{container!="POD",namespace="%s",pod=~"%s",container="%s"EXTENSION_LABELS_HOLDER}`
 
+	// WorkloadReplicasExprTemplate is used to query the number of running pods of workload by promql, param is namespace,workload-name
+	WorkloadReplicasExprTemplate = `count(sum(container_memory_working_set_bytes{namespace="%s",pod=~"%s",container!=""EXTENSION_LABELS_HOLDER}) by (pod))`
+
+	// StatefulSetPvcUsedBytesExprTemplate is used to query the max used bytes of the pvcs of a volume claim template of statefulset by promql, param is namespace, pvc-name
+	StatefulSetPvcUsedBytesExprTemplate = `max(kubelet_volume_stats_used_bytes{namespace="%s",persistentvolumeclaim=~"%s"EXTENSION_LABELS_HOLDER})`
+
 	CustomerExprTemplate = `sum(%s{%sEXTENSION_LABELS_HOLDER})`
 
 	// Container network cumulative count of bytes received
@@ -100,6 +108,11 @@ func GetWorkloadCpuUsageExpression(namespace string, name string, kind string) s
 	return fmtSprintfInternal(WorkloadCpuUsageExprTemplate, namespace, GetPodNameReg(name, kind), "3m")
 }
 
+func GetWorkloadCpuThrottleRatioExpression(namespace string, name string, kind string) string {
+	podNameReg := GetPodNameReg(name, kind)
+	return fmtSprintfInternal(WorkloadCpuThrottleRatioExprTemplate, namespace, podNameReg, namespace, podNameReg)
+}
+
 func GetWorkloadMemUsageExpression(namespace string, name string, kind string) string {
 	return fmtSprintfInternal(WorkloadMemUsageExprTemplate, namespace, GetPodNameReg(name, kind))
 }
@@ -112,6 +125,16 @@ func GetContainerMemUsageExpression(namespace string, workloadName string, kind
 	return fmtSprintfInternal(ContainerMemUsageExprTemplate, namespace, GetPodNameReg(workloadName, kind), containerName)
 }
 
+func GetWorkloadReplicasExpression(namespace string, name string, kind string) string {
+	return fmtSprintfInternal(WorkloadReplicasExprTemplate, namespace, GetPodNameReg(name, kind))
+}
+
+// GetStatefulSetPvcUsedBytesExpression returns the expression of the max used bytes of the pvcs created by the volume
+// claim template of statefulset, which are named as <template>-<statefulset>-<ordinal>
+func GetStatefulSetPvcUsedBytesExpression(namespace string, name string, claimTemplateName string) string {
+	return fmtSprintfInternal(StatefulSetPvcUsedBytesExprTemplate, namespace, fmt.Sprintf("^%s-%s-%s", claimTemplateName, name, PostRegMatchesPodStatefulset))
+}
+
 func GetPodCpuUsageExpression(namespace string, name string) string {
 	return fmtSprintfInternal(PodCpuUsageExprTemplate, namespace, name, "3m")
 }
@@ -11,6 +11,7 @@ import (
 	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
 
 	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/known"
 	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/prediction"
 	"github.com/gocrane/crane/pkg/prediction/config"
@@ -46,19 +47,42 @@ func QueryPredictedValuesOnce(recommendation *v1alpha1.Recommendation, predictor
 }
 
 func GetReadyPredictionMetric(metric string, resourceIdentifier string, prediction *predictionapi.TimeSeriesPrediction) (*predictionapi.MetricTimeSeries, error) {
+	return GetReadyPredictionBoundMetric(metric, resourceIdentifier, "", prediction)
+}
+
+// GetReadyPredictionBoundMetric returns the time series of the bound of the prediction interval, known.PredictionBoundLower
+// or known.PredictionBoundUpper, or of the point forecast if the bound is empty.
+func GetReadyPredictionBoundMetric(metric string, resourceIdentifier string, bound string, prediction *predictionapi.TimeSeriesPrediction) (*predictionapi.MetricTimeSeries, error) {
 	for _, metricStatus := range prediction.Status.PredictionMetrics {
-		if metricStatus.ResourceIdentifier == resourceIdentifier && len(metricStatus.Prediction) == 1 {
+		if metricStatus.ResourceIdentifier != resourceIdentifier {
+			continue
+		}
+
+		var timeSeries []*predictionapi.MetricTimeSeries
+		for _, ts := range metricStatus.Prediction {
+			if GetPredictionBound(ts) == bound {
+				timeSeries = append(timeSeries, ts)
+			}
+		}
+		if len(timeSeries) == 1 {
 			if !metricStatus.Ready {
 				return nil, fmt.Errorf("TimeSeries is not ready, metric name %s resourceIdentifier %s", metric, resourceIdentifier)
 			}
 
-			if len(metricStatus.Prediction) != 1 {
-				return nil, fmt.Errorf("TimeSeries data length is unexpected: %d, metric name %s resourceIdentifier %s", len(metricStatus.Prediction), metric, resourceIdentifier)
-			}
-
-			return metricStatus.Prediction[0], nil
+			return timeSeries[0], nil
 		}
 	}
 
 	return nil, fmt.Errorf("TimeSeries not matched, metric name %s resourceIdentifier %s", metric, resourceIdentifier)
 }
+
+// GetPredictionBound returns the bound of the prediction interval the time series is, it is empty if the time series
+// is the point forecast.
+func GetPredictionBound(ts *predictionapi.MetricTimeSeries) string {
+	for _, label := range ts.Labels {
+		if label.Name == known.PredictionBoundLabel {
+			return label.Value
+		}
+	}
+	return ""
+}
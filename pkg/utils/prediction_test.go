@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func TestGetReadyPredictionBoundMetric(t *testing.T) {
+	point := &predictionapi.MetricTimeSeries{Samples: []predictionapi.Sample{{Timestamp: 60, Value: "2"}}}
+	lower := &predictionapi.MetricTimeSeries{
+		Labels:  []predictionapi.Label{{Name: known.PredictionBoundLabel, Value: known.PredictionBoundLower}},
+		Samples: []predictionapi.Sample{{Timestamp: 60, Value: "1"}},
+	}
+	upper := &predictionapi.MetricTimeSeries{
+		Labels:  []predictionapi.Label{{Name: known.PredictionBoundLabel, Value: known.PredictionBoundUpper}},
+		Samples: []predictionapi.Sample{{Timestamp: 60, Value: "3"}},
+	}
+	tsp := &predictionapi.TimeSeriesPrediction{
+		Status: predictionapi.TimeSeriesPredictionStatus{
+			PredictionMetrics: []predictionapi.PredictionMetricStatus{
+				{ResourceIdentifier: "cpu", Ready: true, Prediction: predictionapi.MetricTimeSeriesList{point, lower, upper}},
+				{ResourceIdentifier: "memory", Ready: true, Prediction: predictionapi.MetricTimeSeriesList{point}},
+			},
+		},
+	}
+
+	var cases = []struct {
+		resourceIdentifier string
+		bound              string
+		output             *predictionapi.MetricTimeSeries
+	}{
+		{resourceIdentifier: "cpu", bound: "", output: point},
+		{resourceIdentifier: "cpu", bound: known.PredictionBoundLower, output: lower},
+		{resourceIdentifier: "cpu", bound: known.PredictionBoundUpper, output: upper},
+		{resourceIdentifier: "memory", bound: "", output: point},
+		{resourceIdentifier: "memory", bound: known.PredictionBoundUpper, output: nil},
+	}
+
+	for _, c := range cases {
+		ts, err := GetReadyPredictionBoundMetric(known.MetricNamePrediction, c.resourceIdentifier, c.bound, tsp)
+		if c.output == nil {
+			if err == nil {
+				t.Fatalf("TestGetReadyPredictionBoundMetric failed {%s,%s}, expect error", c.resourceIdentifier, c.bound)
+			}
+			continue
+		}
+		if err != nil || ts != c.output {
+			t.Fatalf("TestGetReadyPredictionBoundMetric failed {%s,%s}, ts: %v, err: %v", c.resourceIdentifier, c.bound, ts, err)
+		}
+	}
+}